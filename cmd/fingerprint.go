@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/jensneuse/graphql-go-tools/pkg/printer"
+	"github.com/spf13/cobra"
+	"io/ioutil"
+)
+
+var fingerprintStripDescriptions bool
+
+// fingerprintCmd represents the fingerprint command
+var fingerprintCmd = &cobra.Command{
+	Use:   "fingerprint",
+	Short: "fingerprint prints a hash of a graphql schema file which is stable across formatting and reordering",
+	Example: `fingerprint starwars.schema.graphql
+fingerprint --strip-descriptions starwars.schema.graphql`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		if len(args) != 1 {
+			return fmt.Errorf("fingerprint: must provide 1 arg (fileName)")
+		}
+
+		data, err := ioutil.ReadFile(args[0])
+		if err != nil {
+			return err
+		}
+
+		hash, err := printer.Fingerprint(data, fingerprintStripDescriptions)
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintln(cmd.OutOrStdout(), hash)
+		return err
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(fingerprintCmd)
+
+	fingerprintCmd.Flags().BoolVar(&fingerprintStripDescriptions, "strip-descriptions", false, "ignore descriptions and comments")
+}
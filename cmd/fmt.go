@@ -1,15 +1,92 @@
 package cmd
 
 import (
+	"fmt"
+	"github.com/jensneuse/graphql-go-tools/pkg/printer"
 	"github.com/spf13/cobra"
 )
 
+var (
+	fmtIndentWidth  int
+	fmtMinify       bool
+	fmtDescriptions string
+	fmtCommas       string
+	fmtMaxLineWidth int
+	fmtOrder        string
+	fmtCanonical    bool
+	fmtStrip        bool
+)
+
 // fmtCmd represents the fmt command
 var fmtCmd = &cobra.Command{
 	Use:   "fmt",
 	Short: "fmt formats graphql document files to std out",
 }
 
+// fmtStyle returns the printer style configured by the fmt flags
+func fmtStyle() (printer.Style, error) {
+
+	if fmtCanonical {
+		style := printer.CanonicalStyle()
+		style.StripDescriptions = fmtStrip
+		return style, nil
+	}
+
+	style := printer.DefaultStyle()
+	style.StripDescriptions = fmtStrip
+	style.IndentWidth = fmtIndentWidth
+	style.MaxLineWidth = fmtMaxLineWidth
+	style.ExecutableDefinition = printer.LayoutPretty
+	if fmtMinify {
+		style.TypeSystemDefinition = printer.LayoutMinified
+		style.ExecutableDefinition = printer.LayoutMinified
+	}
+
+	switch fmtDescriptions {
+	case "auto":
+		style.Description = printer.DescriptionAuto
+	case "block":
+		style.Description = printer.DescriptionBlock
+	case "single-line":
+		style.Description = printer.DescriptionSingleLine
+	default:
+		return style, fmt.Errorf("fmt: invalid descriptions '%s', must be one of: auto, block, single-line", fmtDescriptions)
+	}
+
+	switch fmtCommas {
+	case "default":
+		style.Comma = printer.CommaDefault
+	case "none":
+		style.Comma = printer.CommaNone
+	case "separate":
+		style.Comma = printer.CommaSeparate
+	case "trailing":
+		style.Comma = printer.CommaTrailing
+	default:
+		return style, fmt.Errorf("fmt: invalid commas '%s', must be one of: default, none, separate, trailing", fmtCommas)
+	}
+
+	switch fmtOrder {
+	case "source":
+		style.Order = printer.OrderSource
+	case "sorted":
+		style.Order = printer.OrderSorted
+	default:
+		return style, fmt.Errorf("fmt: invalid order '%s', must be one of: source, sorted", fmtOrder)
+	}
+
+	return style, nil
+}
+
 func init() {
 	rootCmd.AddCommand(fmtCmd)
+
+	fmtCmd.PersistentFlags().IntVar(&fmtIndentWidth, "indent", 0, "number of spaces per indentation level, 0 indents by tabs")
+	fmtCmd.PersistentFlags().BoolVar(&fmtMinify, "minify", false, "print documents without optional whitespace")
+	fmtCmd.PersistentFlags().StringVar(&fmtDescriptions, "descriptions", "auto", "style of descriptions: auto, block, single-line")
+	fmtCmd.PersistentFlags().StringVar(&fmtCommas, "commas", "default", "placement of optional commas: default, none, separate, trailing")
+	fmtCmd.PersistentFlags().IntVar(&fmtMaxLineWidth, "max-width", 0, "wrap arguments and union members one per line if a line exceeds this width, 0 disables wrapping")
+	fmtCmd.PersistentFlags().StringVar(&fmtOrder, "order", "source", "order of definitions, fields, arguments and enum values: source, sorted")
+	fmtCmd.PersistentFlags().BoolVar(&fmtCanonical, "canonical", false, "print schemas sorted and normalized for diffing, all other style flags but --strip-descriptions are ignored")
+	fmtCmd.PersistentFlags().BoolVar(&fmtStrip, "strip-descriptions", false, "omit all descriptions and comments of schemas")
 }
@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/jensneuse/graphql-go-tools/pkg/lexer"
+	"github.com/jensneuse/graphql-go-tools/pkg/lexing/keyword"
+	"github.com/jensneuse/graphql-go-tools/pkg/lookup"
+	"github.com/jensneuse/graphql-go-tools/pkg/parser"
+	"github.com/jensneuse/graphql-go-tools/pkg/printer"
+	"github.com/spf13/cobra"
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+var (
+	queryWrite bool
+	queryCheck bool
+	// queryStdin is read if no file or '-' is given
+	queryStdin io.Reader = os.Stdin
+)
+
+// queryCmd represents the query command
+var queryCmd = &cobra.Command{
+	Use:   "query [files]",
+	Short: "query formats graphql operations and fragments to std out",
+	Long: `query formats graphql operations and fragments to std out
+if no file or '-' is given the document is read from std in`,
+	Example: `fmt query --indent 2 query.graphql
+cat query.graphql | fmt query
+fmt query --write queries/*.graphql
+fmt query --check queries/*.graphql`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		if queryWrite && queryCheck {
+			return fmt.Errorf("query: --write and --check are mutually exclusive")
+		}
+
+		style, err := fmtStyle()
+		if err != nil {
+			return err
+		}
+
+		if len(args) == 0 {
+			args = []string{"-"}
+		}
+
+		var unformatted []string
+
+		for _, fileName := range args {
+
+			var data []byte
+			if fileName == "-" {
+				if queryWrite {
+					return fmt.Errorf("query: --write can't be used with std in")
+				}
+				data, err = ioutil.ReadAll(queryStdin)
+			} else {
+				data, err = ioutil.ReadFile(fileName)
+			}
+			if err != nil {
+				return err
+			}
+
+			formatted, err := formatExecutableDefinition(data, style)
+			if err != nil {
+				return fmt.Errorf("query: %s: %s", fileName, err)
+			}
+
+			switch {
+			case queryCheck:
+				if !bytes.Equal(data, formatted) {
+					unformatted = append(unformatted, fileName)
+					fmt.Fprintln(cmd.OutOrStdout(), fileName)
+				}
+			case queryWrite:
+				if bytes.Equal(data, formatted) {
+					continue
+				}
+				info, err := os.Stat(fileName)
+				if err != nil {
+					return err
+				}
+				err = ioutil.WriteFile(fileName, formatted, info.Mode())
+				if err != nil {
+					return err
+				}
+			default:
+				_, err = cmd.OutOrStdout().Write(formatted)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		if len(unformatted) != 0 {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("query: %d of %d files are not formatted", len(unformatted), len(args))
+		}
+
+		return nil
+	},
+}
+
+// formatExecutableDefinition prints the operations and fragments of a document, terminated by a line break
+// the parser drops comments of executable definitions, so they get replaced by whitespace before parsing
+// and handed to the printer, documents containing anything but operations, fragments and ignored tokens
+// are rejected to not silently drop parts of them
+func formatExecutableDefinition(data []byte, style printer.Style) ([]byte, error) {
+
+	input, comments := extractComments(data)
+
+	p := parser.NewParser()
+	err := p.ParseExecutableDefinition(input)
+	if err != nil {
+		return nil, err
+	}
+
+	end := p.TextPosition()
+	if !isIgnored(input[offset(input, end.LineStart, end.CharStart):]) {
+		return nil, fmt.Errorf("unable to parse document at %d:%d, only operations and fragments are supported", end.LineStart, end.CharStart)
+	}
+
+	l := lookup.New(p)
+	w := lookup.NewWalker(1024, 8)
+	w.SetLookup(l)
+	w.WalkExecutable()
+
+	astPrinter := printer.New()
+	astPrinter.SetStyle(style)
+	astPrinter.SetInput(p, l, w)
+	astPrinter.SetComments(comments)
+
+	out := bytes.Buffer{}
+	err = astPrinter.PrintExecutableSchema(&out)
+	if err != nil {
+		return nil, err
+	}
+
+	if out.Len() != 0 && !bytes.HasSuffix(out.Bytes(), []byte("\n")) {
+		out.WriteByte('\n')
+	}
+
+	return out.Bytes(), nil
+}
+
+// extractComments returns a copy of data with all comments replaced by spaces and the comments
+// the positions of all other tokens stay the same
+func extractComments(data []byte) ([]byte, []printer.Comment) {
+
+	input := append([]byte(nil), data...)
+	var comments []printer.Comment
+
+	lex := lexer.NewLexer()
+	if err := lex.SetTypeSystemInput(data); err != nil {
+		return input, nil
+	}
+
+	for {
+		tok := lex.Read()
+		if tok.Keyword == keyword.EOF {
+			return input, comments
+		}
+		if tok.Keyword != keyword.COMMENT {
+			continue
+		}
+		comments = append(comments, printer.Comment{
+			Position: tok.TextPosition,
+			Text:     append([]byte(nil), lex.ByteSlice(tok.Literal)...),
+		})
+		for i := tok.Literal.Start; i < tok.Literal.End; i++ {
+			if input[i] != '\n' && input[i] != '\r' {
+				input[i] = ' '
+			}
+		}
+	}
+}
+
+// offset returns the byte offset of a one based line and char position
+func offset(data []byte, line, char uint32) int {
+	i := 0
+	for ; line > 1 && i < len(data); i++ {
+		if data[i] == '\n' {
+			line--
+		}
+	}
+	i += int(char) - 1
+	if i > len(data) {
+		return len(data)
+	}
+	return i
+}
+
+// isIgnored reports whether data only consists of whitespace, line terminators, commas and byte order marks
+func isIgnored(data []byte) bool {
+	data = bytes.Replace(data, []byte("\uFEFF"), nil, -1)
+	return len(bytes.Trim(data, " \t\r\n,")) == 0
+}
+
+func init() {
+	fmtCmd.AddCommand(queryCmd)
+
+	queryCmd.Flags().BoolVarP(&queryWrite, "write", "w", false, "write the formatted documents back to their files instead of std out")
+	queryCmd.Flags().BoolVarP(&queryCheck, "check", "c", false, "list the files which are not formatted and exit with a non zero status if there are any")
+}
@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestQueryCmd(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "fmt_query")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	formatted := "# documents of the current user\nquery Documents {\n\tdocuments {\n\t\towner\n\t\t# title\n\t}\n}\n"
+	unformatted := "# documents of the current user\nquery Documents {documents {owner\n# title\n}}"
+
+	writeFile := func(name, content string) string {
+		fileName := filepath.Join(dir, name)
+		err := ioutil.WriteFile(fileName, []byte(content), 0644)
+		if err != nil {
+			panic(err)
+		}
+		return fileName
+	}
+
+	readFile := func(fileName string) string {
+		data, err := ioutil.ReadFile(fileName)
+		if err != nil {
+			panic(err)
+		}
+		return string(data)
+	}
+
+	execute := func(stdin string, args ...string) (string, error) {
+		queryWrite, queryCheck = false, false
+		queryStdin = strings.NewReader(stdin)
+		defer func() {
+			queryStdin = os.Stdin
+		}()
+
+		out := bytes.Buffer{}
+		rootCmd.SetOutput(&out)
+		rootCmd.SetArgs(append([]string{"fmt", "query"}, args...))
+		_, err := rootCmd.ExecuteC()
+		return out.String(), err
+	}
+
+	run := func(stdin, want string, args ...string) {
+		got, err := execute(stdin, args...)
+		if err != nil {
+			panic(err)
+		}
+		if want != got {
+			panic(fmt.Errorf("want:\n%s\ngot:\n%s", want, got))
+		}
+	}
+
+	runErr := func(stdin, wantErr string, args ...string) {
+		_, err := execute(stdin, args...)
+		if err == nil || !strings.Contains(err.Error(), wantErr) {
+			panic(fmt.Errorf("want error containing '%s', got: %v", wantErr, err))
+		}
+	}
+
+	t.Run("stdin", func(t *testing.T) {
+		run(unformatted, formatted)
+		run(unformatted, formatted, "-")
+	})
+	t.Run("files", func(t *testing.T) {
+		run("", formatted+formatted, writeFile("a.graphql", unformatted), writeFile("b.graphql", formatted))
+	})
+	t.Run("check formatted files", func(t *testing.T) {
+		run("", "", "--check", writeFile("formatted.graphql", formatted))
+	})
+	t.Run("check unformatted files", func(t *testing.T) {
+		fileName := writeFile("unformatted.graphql", unformatted)
+		runErr("", "1 of 2 files are not formatted", "--check", fileName, writeFile("formatted.graphql", formatted))
+		if readFile(fileName) != unformatted {
+			panic(fmt.Errorf("want --check to leave the file unchanged"))
+		}
+	})
+	t.Run("check stdin", func(t *testing.T) {
+		runErr(unformatted, "1 of 1 files are not formatted", "--check")
+		run(formatted, "", "--check")
+	})
+	t.Run("write", func(t *testing.T) {
+		fileName := writeFile("write.graphql", unformatted)
+		run("", "", "--write", fileName)
+		if got := readFile(fileName); got != formatted {
+			panic(fmt.Errorf("want:\n%s\ngot:\n%s", formatted, got))
+		}
+	})
+	t.Run("write stdin", func(t *testing.T) {
+		runErr(unformatted, "--write can't be used with std in", "--write")
+	})
+	t.Run("write and check", func(t *testing.T) {
+		runErr("", "mutually exclusive", "--write", "--check", writeFile("both.graphql", formatted))
+	})
+	t.Run("invalid documents", func(t *testing.T) {
+		runErr("", "only operations and fragments are supported", writeFile("schema.graphql", "query Q {a} type Query {a: String}"))
+	})
+}
+
+func TestFormatExecutableDefinition(t *testing.T) {
+
+	style, err := fmtStyle()
+	if err != nil {
+		panic(err)
+	}
+
+	run := func(input, want string) {
+		got, err := formatExecutableDefinition([]byte(input), style)
+		if err != nil {
+			panic(err)
+		}
+		if want != string(got) {
+			panic(fmt.Errorf("want:\n%s\ngot:\n%s", want, string(got)))
+		}
+		reformatted, err := formatExecutableDefinition(got, style)
+		if err != nil {
+			panic(err)
+		}
+		if want != string(reformatted) {
+			panic(fmt.Errorf("formatting is not idempotent, want:\n%s\ngot:\n%s", want, string(reformatted)))
+		}
+	}
+
+	t.Run("comments before definitions", func(t *testing.T) {
+		run("# a\nquery A {a}\n# fragment\n# b\nfragment B on Query {b}", "# a\nquery A {\n\ta\n}\n\n# fragment\n# b\nfragment B on Query {\n\tb\n}\n")
+	})
+	t.Run("comments in selection sets", func(t *testing.T) {
+		run("{a # after a\n... on Query {b} ...F # end\n}", "{\n\ta\n\t# after a\n\t... on Query {\n\t\tb\n\t}\n\t...F\n\t# end\n}\n")
+	})
+	t.Run("comments at the end of the document", func(t *testing.T) {
+		run("{a}\n# end", "{\n\ta\n}\n\n# end\n")
+		run("# only a comment", "# only a comment\n")
+	})
+	t.Run("hash signs in strings", func(t *testing.T) {
+		run(`{a(text: "# not a comment")}`, "{\n\ta(text: \"# not a comment\")\n}\n")
+	})
+}
+
+func TestOffset(t *testing.T) {
+
+	run := func(data string, line, char uint32, want int) {
+		if got := offset([]byte(data), line, char); got != want {
+			panic(fmt.Errorf("offset(%q, %d, %d): want %d, got %d", data, line, char, want, got))
+		}
+	}
+
+	run("query", 1, 1, 0)
+	run("query", 1, 3, 2)
+	run("a\nbc\nd", 2, 2, 3)
+	run("a\nbc\nd", 3, 1, 5)
+	run("a\n", 2, 1, 2)
+	run("a", 1, 10, 1)
+}
+
+func TestIsIgnored(t *testing.T) {
+
+	run := func(data string, want bool) {
+		if got := isIgnored([]byte(data)); got != want {
+			panic(fmt.Errorf("isIgnored(%q): want %t, got %t", data, want, got))
+		}
+	}
+
+	run("", true)
+	run(" \t\r\n,", true)
+	run("\uFEFF\n", true)
+	run(" type Query", false)
+	run("}", false)
+}
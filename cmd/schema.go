@@ -13,7 +13,7 @@ import (
 var schemaCmd = &cobra.Command{
 	Use:     "schema",
 	Short:   "schema formats a graphql schema file to sdt out",
-	Example: "fmt schema starwars.schema.graphql > formatted.graphql",
+	Example: "fmt schema --indent 2 --order sorted starwars.schema.graphql > formatted.graphql",
 	RunE: func(cmd *cobra.Command, args []string) error {
 
 		if len(args) != 1 {
@@ -38,7 +38,13 @@ var schemaCmd = &cobra.Command{
 		w.SetLookup(l)
 		w.WalkTypeSystemDefinition()
 
+		style, err := fmtStyle()
+		if err != nil {
+			return err
+		}
+
 		astPrinter := printer.New()
+		astPrinter.SetStyle(style)
 		astPrinter.SetInput(p, l, w)
 
 		return astPrinter.PrintTypeSystemDefinition(cmd.OutOrStdout())
@@ -1,7 +1,9 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"github.com/jensneuse/graphql-go-tools/pkg/introspection"
 	"github.com/jensneuse/graphql-go-tools/pkg/middleware"
 	"github.com/jensneuse/graphql-go-tools/pkg/proxy"
 	"github.com/jensneuse/graphql-go-tools/pkg/proxy/http"
@@ -18,14 +20,25 @@ import (
 var (
 	staticProxyPrintMemory bool
 	staticProxyRunPPROF    bool
+	staticProxyIntrospect  bool
 
 	staticProxyAddr        string
 	staticProxyPprofAddr   string
 	staticProxySchemaFile  string
 	staticProxyBackendURL  string
 	staticProxyContextKeys []string
+	staticProxyMiddlewares []string
 )
 
+// staticProxyMiddlewareFactories are the middlewares which can be enabled with the middlewares flag
+var staticProxyMiddlewareFactories = map[string]func() middleware.GraphqlMiddleware{
+	"authorization": func() middleware.GraphqlMiddleware { return &middleware.AuthorizationMiddleware{} },
+	"context":       func() middleware.GraphqlMiddleware { return &middleware.ContextMiddleware{} },
+	"introspection": func() middleware.GraphqlMiddleware { return &middleware.IntrospectionMiddleware{} },
+	"validation":    func() middleware.GraphqlMiddleware { return &middleware.ValidationMiddleware{} },
+	"visibility":    func() middleware.GraphqlMiddleware { return &middleware.SchemaVisibilityMiddleware{} },
+}
+
 // staticProxyCmd represents the staticProxy command
 var staticProxyCmd = &cobra.Command{
 	Use:   "staticProxy",
@@ -56,6 +69,7 @@ Proxy Configuration:
 
 Schema Configuration:
 - schema file: %s
+- introspect backend: %t
 
 Debug Configuration:
 - pprof enabled: %t
@@ -65,6 +79,9 @@ Debug Configuration:
 Headers that will be added to the context:
 %s
 
+Middlewares:
+%s
+
 Example usage:
 curl --data '{"operationName":null,"variables":{},"query":"{documents{owner sensitiveInformation}}"}' --header 'user: "jens"' --header 'Content-Type: application/json' -v http://0.0.0.0:8888 | jq
 
@@ -72,15 +89,17 @@ curl --data '{"operationName":null,"variables":{},"query":"{documents{owner sens
 		staticProxyAddr,
 		staticProxyBackendURL,
 		staticProxySchemaFile,
+		staticProxyIntrospect,
 		staticProxyRunPPROF,
 		staticProxyPprofAddr,
 		staticProxyPrintMemory,
-		staticProxyContextKeys)
+		staticProxyContextKeys,
+		staticProxyMiddlewares)
 }
 
 func runProxyBlocking() {
 
-	schema, err := ioutil.ReadFile(staticProxySchemaFile)
+	schema, err := loadSchema()
 	if err != nil {
 		panic(err)
 	}
@@ -95,13 +114,17 @@ func runProxyBlocking() {
 		panic(err)
 	}
 
+	middlewares, err := staticProxyMiddlewareChain()
+	if err != nil {
+		panic(err)
+	}
+
 	prox := http.NewDefaultStaticProxy(proxy.RequestConfig{
 		Schema:              &schema,
 		BackendURL:          *backendURL,
 		AddHeadersToContext: addHeadersToContext,
 	},
-		&middleware.ValidationMiddleware{},
-		&middleware.ContextMiddleware{},
+		middlewares...,
 	)
 
 	err = prox.ListenAndServe(staticProxyAddr)
@@ -110,6 +133,26 @@ func runProxyBlocking() {
 	}
 }
 
+// staticProxyMiddlewareChain creates the middlewares of the middlewares flag ordered by their phase
+func staticProxyMiddlewareChain() ([]middleware.GraphqlMiddleware, error) {
+	middlewares := make([]middleware.GraphqlMiddleware, 0, len(staticProxyMiddlewares))
+	for _, name := range staticProxyMiddlewares {
+		factory, ok := staticProxyMiddlewareFactories[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown middleware '%s'", name)
+		}
+		middlewares = append(middlewares, factory())
+	}
+	return middleware.Chain(middlewares...), nil
+}
+
+func loadSchema() ([]byte, error) {
+	if staticProxyIntrospect {
+		return introspection.Fetch(context.Background(), staticProxyBackendURL, nil)
+	}
+	return ioutil.ReadFile(staticProxySchemaFile)
+}
+
 func runPPROF() {
 
 	if !staticProxyRunPPROF {
@@ -132,8 +175,10 @@ func init() {
 	staticProxyCmd.Flags().StringVar(&staticProxyAddr, "proxyAddr", "0.0.0.0:8888", "host:port the proxy should listen on")
 	staticProxyCmd.Flags().StringVar(&staticProxyPprofAddr, "pprofAddr", "0.0.0.0:8081", "host:port the pprof web server should listen on")
 	staticProxyCmd.Flags().StringVar(&staticProxySchemaFile, "schemaFile", "./schema.graphql", "the file to read the schema from")
+	staticProxyCmd.Flags().BoolVar(&staticProxyIntrospect, "introspect", false, "fetch the schema from the backend using an introspection query instead of reading the schema file")
 	staticProxyCmd.Flags().StringVar(&staticProxyBackendURL, "backendURL", "http://0.0.0.0:8080/query", "the backend URL to proxy requests to")
 	staticProxyCmd.Flags().StringSliceVar(&staticProxyContextKeys, "contextKeys", nil, "the keys that should be read from the header and set to the context")
+	staticProxyCmd.Flags().StringSliceVar(&staticProxyMiddlewares, "middlewares", []string{"validation", "context"}, "the middlewares to enable, one of authorization, context, introspection, validation or visibility, they're ordered by their phase")
 }
 
 func runPrintMemoryUsage() {
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/jensneuse/graphql-go-tools/pkg/middleware"
+)
+
+func TestStaticProxyMiddlewareChain(t *testing.T) {
+
+	defaultMiddlewares, err := staticProxyCmd.Flags().GetStringSlice("middlewares")
+	if err != nil {
+		panic(err)
+	}
+
+	staticProxyMiddlewares = defaultMiddlewares
+	defer func() { staticProxyMiddlewares = defaultMiddlewares }()
+
+	middlewares, err := staticProxyMiddlewareChain()
+	if err != nil {
+		panic(err)
+	}
+
+	invoker := middleware.NewInvoker(middlewares...)
+	err = invoker.SetSchema([]byte(staticProxyTestSchema))
+	if err != nil {
+		panic(err)
+	}
+
+	ctx := context.WithValue(context.Background(), "user", []byte("jsmith@example.org"))
+	err = invoker.InvokeMiddleWares(ctx, []byte(`query myDocuments {documents {sensitiveInformation}}`))
+	if err != nil {
+		panic(err)
+	}
+
+	buff := bytes.Buffer{}
+	err = invoker.RewriteRequest(&buff)
+	if err != nil {
+		panic(err)
+	}
+
+	want := `query myDocuments {documents(user:"jsmith@example.org") {sensitiveInformation}}`
+	if want != buff.String() {
+		panic(fmt.Errorf("want:\n%s\ngot:\n%s", want, buff.String()))
+	}
+}
+
+const staticProxyTestSchema = `
+schema {
+	query: Query
+}
+
+type Query {
+	documents: [Document] @addArgumentFromContext(name: "user",contextKey: "user")
+}
+
+type Document {
+	owner: String
+	sensitiveInformation: String
+}
+`
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"github.com/jensneuse/graphql-go-tools/pkg/lexing/literal"
+	"github.com/jensneuse/graphql-go-tools/pkg/middleware"
 	"github.com/jensneuse/graphql-go-tools/pkg/proxy"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
@@ -14,27 +15,39 @@ import (
 
 type Proxy proxy.Proxy
 
-func (f *Proxy) RewriteQuery(config proxy.RequestConfig, ctx context.Context, requestURI []byte, query []byte, out io.Writer) error {
+// RewriteQuery writes the rewritten query to out
+// if a middleware resolved the request resolved is true and out contains the response for the client instead
+func (f *Proxy) RewriteQuery(invoker *middleware.Invoker, config proxy.RequestConfig, ctx context.Context, requestURI []byte, query []byte, out io.Writer) (resolved bool, err error) {
 
-	idx, invoker := f.InvokerPool.Get()
-	defer f.InvokerPool.Free(idx)
-
-	err := invoker.SetSchema(*config.Schema)
+	err = invoker.SetSchema(*config.Schema)
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	err = invoker.InvokeMiddleWares(ctx, query)
 	if err != nil {
-		return err
+		return false, err
+	}
+
+	if response, resolved := invoker.ResolvedResponse(); resolved {
+		_, err = out.Write(response)
+		return true, err
+	}
+
+	// coerced variables replace the variables of the request so that they're sent to the backend
+	if graphqlRequest, ok := middleware.GraphQLRequestFromContext(ctx); ok && config.CoerceVariables {
+		graphqlRequest.Variables, err = invoker.CoerceVariables(graphqlRequest.OperationName, graphqlRequest.Variables, config.ScalarCoercers)
+		if err != nil {
+			return false, err
+		}
 	}
 
 	err = invoker.RewriteRequest(out)
 	if err != nil {
-		return err
+		return false, err
 	}
 
-	return err
+	return false, err
 }
 
 func (f *Proxy) HandleRequest(ctx *fasthttp.RequestCtx) {
@@ -46,6 +59,16 @@ func (f *Proxy) HandleRequest(ctx *fasthttp.RequestCtx) {
 	}
 
 	goctx := f.SetContextValues(ctx, &ctx.Request.Header, config.AddHeadersToContext)
+	if config.Authentication != nil {
+		goctx, err = config.Authentication.ContextWithClaims(goctx, ctx.Request.Header.Peek("Authorization"))
+		if err != nil {
+			f.HandleError(ctx, err)
+			return
+		}
+	}
+	if config.ValidationRules != nil {
+		goctx = middleware.WithValidationRules(goctx, config.ValidationRules)
+	}
 
 	body := ctx.Request.Body()
 
@@ -60,13 +83,56 @@ func (f *Proxy) HandleRequest(ctx *fasthttp.RequestCtx) {
 	query = bytes.TrimPrefix(query, literal.QUOTE)
 	query = bytes.TrimSuffix(query, literal.QUOTE)
 
+	graphqlRequest := middleware.GraphQLRequest{
+		OperationName: gjson.GetBytes(body, "operationName").String(),
+		Query:         result.String(),
+	}
+	if variables, ok := gjson.GetBytes(body, "variables").Value().(map[string]interface{}); ok {
+		graphqlRequest.Variables = variables
+	}
+	if persistedQuery := gjson.GetBytes(body, "extensions.persistedQuery"); persistedQuery.IsObject() {
+		graphqlRequest.Extensions = &middleware.GraphQLRequestExtensions{
+			PersistedQuery: &middleware.PersistedQueryExtension{
+				Version:    int(persistedQuery.Get("version").Int()),
+				Sha256Hash: persistedQuery.Get("sha256Hash").String(),
+			},
+		}
+	}
+	if config.PersistedQueries != nil {
+		err = config.PersistedQueries.ResolveQuery(goctx, &graphqlRequest)
+		if err != nil {
+			f.HandleError(ctx, err)
+			return
+		}
+		query = []byte(graphqlRequest.Query)
+	}
+	goctx = middleware.WithGraphQLRequest(goctx, &graphqlRequest)
+
 	buff := f.BufferPool.Get().(*bytes.Buffer)
 	buff.Reset()
 	defer f.BufferPool.Put(buff)
 
-	err = f.RewriteQuery(*config, goctx, ctx.RequestURI(), query, buff)
+	// the invoker is held until the response is written so that the response phase has access to the request AST
+	idx, invoker := f.InvokerPool.Get()
+	defer f.InvokerPool.Free(idx)
+	if config.MiddleWares != nil {
+		invoker.SetMiddleWares(config.MiddleWares...)
+	}
+
+	resolved, err := f.RewriteQuery(invoker, *config, goctx, ctx.RequestURI(), query, buff)
 	if err != nil {
-		ctx.Error(err.Error(), fasthttp.StatusInternalServerError)
+		f.HandleError(ctx, err)
+		return
+	}
+
+	if resolved {
+		err = f.RewriteResponse(invoker, goctx, buff)
+		if err != nil {
+			ctx.Error(err.Error(), fasthttp.StatusInternalServerError)
+			return
+		}
+		ctx.SetContentType("application/json")
+		ctx.SetBody(buff.Bytes())
 		return
 	}
 
@@ -78,6 +144,23 @@ func (f *Proxy) HandleRequest(ctx *fasthttp.RequestCtx) {
 		return
 	}
 
+	if graphqlRequest.OperationName != "" {
+		body, err = sjson.SetBytes(body, "operationName", graphqlRequest.OperationName)
+		if err != nil {
+			ctx.Error(err.Error(), fasthttp.StatusInternalServerError)
+			return
+		}
+	}
+
+	// middlewares might have added variables, e.g. the ContextMiddleware
+	if len(graphqlRequest.Variables) != 0 {
+		body, err = sjson.SetBytes(body, "variables", graphqlRequest.Variables)
+		if err != nil {
+			ctx.Error(err.Error(), fasthttp.StatusInternalServerError)
+			return
+		}
+	}
+
 	ctx.Request.SetRequestURIBytes([]byte(config.BackendURL.String()))
 	ctx.Request.SetBody(body)
 
@@ -91,7 +174,62 @@ func (f *Proxy) HandleRequest(ctx *fasthttp.RequestCtx) {
 		return
 	}
 
-	// todo: implement the OnResponse handlers / do something with the response
+	if ctx.Response.StatusCode() >= fasthttp.StatusBadRequest {
+		return
+	}
+
+	buff.Reset()
+	_, err = buff.Write(ctx.Response.Body())
+	if err != nil {
+		ctx.Error(err.Error(), fasthttp.StatusInternalServerError)
+		return
+	}
+
+	err = f.RewriteResponse(invoker, goctx, buff)
+	if err != nil {
+		ctx.Error(err.Error(), fasthttp.StatusInternalServerError)
+		return
+	}
+
+	ctx.Response.SetBody(buff.Bytes())
+}
+
+// HandleError responds with the graphql response of a proxy.ResponseError or the status code of a proxy.StatusCodeError
+// all other errors are answered with 500 Internal Server Error, headers of a proxy.HeaderError are added in any case
+func (f *Proxy) HandleError(ctx *fasthttp.RequestCtx, err error) {
+	if headerErr, ok := err.(proxy.HeaderError); ok {
+		for key, values := range headerErr.Header() {
+			for _, value := range values {
+				ctx.Response.Header.Add(key, value)
+			}
+		}
+	}
+	if responseErr, ok := err.(proxy.ResponseError); ok {
+		ctx.SetStatusCode(responseErr.StatusCode())
+		ctx.SetContentType("application/json")
+		ctx.SetBody(responseErr.Response())
+		return
+	}
+	statusCode := http.StatusInternalServerError
+	if statusCodeErr, ok := err.(proxy.StatusCodeError); ok {
+		statusCode = statusCodeErr.StatusCode()
+	}
+	ctx.Error(err.Error(), statusCode)
+}
+
+// RewriteResponse runs the OnResponse handlers of the middlewares over the response in buff
+// the invoker must be the one which handled the request
+func (f *Proxy) RewriteResponse(invoker *middleware.Invoker, ctx context.Context, buff *bytes.Buffer) error {
+
+	response := buff.Bytes()
+	err := invoker.InvokeMiddleWaresOnResponse(ctx, &response)
+	if err != nil {
+		return err
+	}
+
+	buff.Reset()
+	_, err = buff.Write(response)
+	return err
 }
 
 func (f *Proxy) SetContextValues(ctx context.Context, header *fasthttp.RequestHeader, addHeaders [][]byte) context.Context {
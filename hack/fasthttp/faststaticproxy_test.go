@@ -49,7 +49,7 @@ func TestFastStaticProxy(t *testing.T) {
 	go func() { // nolint
 		err := prox.ListenAndServe("0.0.0.0:" + proxyPort)
 		if err != nil {
-			t.Fatal(err)
+			t.Error(err)
 		}
 	}()
 
@@ -114,7 +114,7 @@ func BenchmarkFastStaticProxy(b *testing.B) {
 	go func() { //nolint
 		err := prox.ListenAndServe("0.0.0.0:" + proxyPort)
 		if err != nil {
-			b.Fatal(err)
+			b.Error(err)
 		}
 	}()
 
@@ -156,6 +156,28 @@ func BenchmarkFastStaticProxy(b *testing.B) {
 	})
 }
 
+func TestFastStaticProxy_CoerceVariables(t *testing.T) {
+
+	schema := []byte(testSchema)
+	prox := NewFastStaticProxy(proxy.NewStaticRequestConfigProvider(proxy.RequestConfig{
+		Schema:          &schema,
+		CoerceVariables: true,
+	}))
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetBody([]byte(`{"query":"query q($name: String!) {documents {owner}}","variables":{}}`))
+	prox.prox.HandleRequest(ctx)
+
+	if ctx.Response.StatusCode() != http.StatusBadRequest {
+		t.Fatalf("want status code: %d, got: %d", http.StatusBadRequest, ctx.Response.StatusCode())
+	}
+
+	wantBody := `{"errors":[{"message":"Variable \"$name\" of required type \"String!\" was not provided.","locations":[{"line":1,"column":9}],"extensions":{"code":"BAD_USER_INPUT"}}]}`
+	if string(ctx.Response.Body()) != wantBody {
+		t.Fatalf("want body: %s, got: %s", wantBody, string(ctx.Response.Body()))
+	}
+}
+
 var clientData = []byte("{\"operationName\":null,\"variables\":{},\"query\":\"{\n  documents{\n    owner\n    sensitiveInformation\n  }\n}\n\"}")
 var fakeResponse = []byte(`{"data":{"documents":[{"sensitiveInformation":"jsmith"},{"sensitiveInformation":"got proxied"}]}}`)
 var wantBackendRequestBody = []byte(`{"query":"{documents(user:\"jsmith@example.org\") {owner sensitiveInformation}}"}`)
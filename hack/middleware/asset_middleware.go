@@ -3,15 +3,13 @@ package middleware
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/jensneuse/graphql-go-tools/pkg/document"
-	"github.com/jensneuse/graphql-go-tools/pkg/lexing/runes"
 	"github.com/jensneuse/graphql-go-tools/pkg/lookup"
+	"github.com/jensneuse/graphql-go-tools/pkg/middleware"
 	"github.com/jensneuse/graphql-go-tools/pkg/parser"
-	"github.com/tidwall/gjson"
-	"github.com/tidwall/sjson"
-	"strconv"
-	"strings"
+	"github.com/jensneuse/graphql-go-tools/pkg/responsewalker"
 )
 
 type AssetUrlMiddleware struct {
@@ -89,73 +87,44 @@ func (a *AssetUrlMiddleware) OnRequest(ctx context.Context, l *lookup.Lookup, w
 
 func (a *AssetUrlMiddleware) OnResponse(ctx context.Context, response *[]byte, l *lookup.Lookup, w *lookup.Walker, parser *parser.Parser, mod *parser.ManualAstMod) (err error) {
 
-	w.SetLookup(l)
-	w.WalkExecutable()
-
-	assetName, _, err := mod.PutLiteralBytes([]byte("Asset"))
-	if err != nil {
-		return err
+	if len(bytes.TrimSpace(*response)) == 0 {
+		return nil
 	}
 
-	handleName, _, err := mod.PutLiteralBytes([]byte("handle"))
-	if err != nil {
-		return err
+	request, _ := middleware.GraphQLRequestFromContext(ctx)
+	operationName := ""
+	if request != nil {
+		operationName = request.OperationName
 	}
 
-	/*if assetName == -1 || handleName == -1 {
-		return nil
-	}*/
+	editor := responsewalker.NewEditor()
+	walker := responsewalker.New()
+	walker.SetInput(l)
 
-	fields := w.FieldsIterable()
-	for fields.Next() {
-		field, _, parent := fields.Value()
-		if !l.ByteSliceReferenceContentsEquals(field.Name, handleName) { // find all fields in the ast named 'url'
-			continue
+	err = walker.Walk(operationName, *response, func(field responsewalker.Field) error {
+		if !field.HasDefinition || string(l.ByteSlice(field.TypeName)) != "Asset" || string(l.ByteSlice(field.Definition.Name)) != "handle" {
+			return nil
 		}
-		setRef := w.Node(parent).Ref
-		set := l.SelectionSet(setRef)
-		setTypeName := w.SelectionSetTypeName(set, parent)
-		if !l.ByteSliceReferenceContentsEquals(setTypeName, assetName) { // verify if field 'url' sits inside an Asset type
-			continue
-		}
-
-		path := w.FieldPath(parent) // get the field path from the ast
-
-		var builder strings.Builder // build the path string (e.g. 'data.assets'
-		builder.WriteString("data")
-		for i := range path {
-			builder.WriteRune(runes.DOT)
-			builder.Write(l.ByteSlice(path[len(path)-1-i]))
-		}
-
-		builder.WriteString(".#.handle")
 
-		strPath := builder.String()
-
-		js := gjson.GetBytes(*response, strPath)
-
-		for i, handle := range js.Array() {
-			id := handle.String()
-			handlePath := strings.Replace(strPath, "#", strconv.Itoa(i), 1)
-			url := fmt.Sprintf("https://media.graphcms.com//%s", id)
-			*response, err = sjson.SetBytesOptions(*response, handlePath, url, &sjson.Options{Optimistic: true, ReplaceInPlace: true})
+		var handle string
+		err := json.Unmarshal(field.Value, &handle)
+		if err != nil {
+			return nil // null handles are kept
 		}
 
-		*response = bytes.Replace(*response, []byte(`"handle"`), []byte(`"url"`), -1)
-
-		/*children, err := jsonObject.Path(builder.String()).Children() // get the assets children
+		url, err := json.Marshal(fmt.Sprintf("https://media.graphcms.com//%s", handle))
 		if err != nil {
 			return err
 		}
 
-		for _, child := range children {
-			handle := child.Path("handle").Data().(string)                                   // extract the handle value
-			err = child.DeleteP("handle")                                                    // delete the handle value
-			_, err = child.Set(fmt.Sprintf("https://media.graphcms.com//%s", handle), "url") // set the formatted url value
-		}*/
+		editor.Replace(field.KeyOffset, field.Key, []byte(`"url"`)) // the client selected the url field
+		editor.Replace(field.Offset, field.Value, url)
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
-	// *response = jsonObject.Bytes() // overwrite the response with the updated fields
-
-	return
+	*response, err = editor.Apply(*response)
+	return err
 }
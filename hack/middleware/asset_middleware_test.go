@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"fmt"
 	"github.com/jensneuse/graphql-go-tools/pkg/middleware"
 	"github.com/jensneuse/graphql-go-tools/pkg/testhelper"
@@ -54,6 +55,39 @@ func TestAssetMiddleware(t *testing.T) {
 
 }
 
+func TestAssetMiddleware_OnResponse(t *testing.T) {
+
+	run := func(ctx context.Context, query, response, want string) {
+		got, err := middleware.InvokeMiddlewareOnResponse(&AssetUrlMiddleware{}, ctx, assetSchema, query, response)
+		if err != nil {
+			panic(err)
+		}
+		if want != got {
+			panic(fmt.Errorf("want:\n%s\ngot:\n%s\n", want, got))
+		}
+	}
+
+	t.Run("handle to url", func(t *testing.T) {
+		run(nil, `query testQueryWithoutHandle {assets(first: 1) {id url}}`,
+			`{"data":{"assets":[{"id":"1","handle":"abc"},{"handle":"def","id":"2"}]}}`,
+			`{"data":{"assets":[{"id":"1","url":"https://media.graphcms.com//abc"},{"url":"https://media.graphcms.com//def","id":"2"}]}}`)
+	})
+	t.Run("aliased asset list", func(t *testing.T) {
+		run(nil, `{images: assets(first: 1) {url}}`,
+			`{"data":{"images":[{"handle":"abc"}]}}`,
+			`{"data":{"images":[{"url":"https://media.graphcms.com//abc"}]}}`)
+	})
+	t.Run("operation name from the request", func(t *testing.T) {
+		ctx := middleware.WithGraphQLRequest(context.Background(), &middleware.GraphQLRequest{OperationName: "second"})
+		run(ctx, `query first {assets {id}} query second {assets {url}}`,
+			`{"data":{"assets":[{"handle":"abc"}]}}`,
+			`{"data":{"assets":[{"url":"https://media.graphcms.com//abc"}]}}`)
+	})
+	t.Run("errors only", func(t *testing.T) {
+		run(nil, `{assets {url}}`, `{"errors":[{"message":"failed"}]}`, `{"errors":[{"message":"failed"}]}`)
+	})
+}
+
 var assetSchema = `
 schema {
     query: Query
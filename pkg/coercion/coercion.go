@@ -0,0 +1,458 @@
+// Package coercion validates and coerces the variables of a request against the variable definitions of an operation
+//
+// See: https://facebook.github.io/graphql/draft/#sec-Coercing-Variable-Values
+package coercion
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/jensneuse/graphql-go-tools/pkg/document"
+	"github.com/jensneuse/graphql-go-tools/pkg/lexing/literal"
+	"github.com/jensneuse/graphql-go-tools/pkg/lookup"
+	"github.com/jensneuse/graphql-go-tools/pkg/transform"
+)
+
+// ScalarCoercer coerces a variable value of a custom scalar type
+// the value is the json decoded variable value, the returned value replaces it in the coerced variables
+// returning an error marks the value as invalid, the error message becomes part of the coercion error
+type ScalarCoercer func(value interface{}) (interface{}, error)
+
+// Coercer coerces variable values according to the variable definitions of an operation
+type Coercer struct {
+	l       *lookup.Lookup
+	scalars map[string]ScalarCoercer
+	errors  Errors
+	path    []string
+}
+
+func New() *Coercer {
+	return &Coercer{
+		path: make([]string, 0, 8),
+	}
+}
+
+func (c *Coercer) SetInput(l *lookup.Lookup) {
+	c.l = l
+}
+
+// SetScalarCoercers sets the hooks to coerce custom scalar values, keyed by the scalar type name
+// custom scalars without a hook are passed through unmodified
+func (c *Coercer) SetScalarCoercers(scalars map[string]ScalarCoercer) {
+	c.scalars = scalars
+}
+
+// CoerceVariableValues coerces the variables for the operation with the given name
+// operationName might be empty if the executable definition contains exactly one operation
+// the returned map contains the coerced values of all defined variables including default values
+// all invalid variable values are reported, the returned error is of type Errors
+func (c *Coercer) CoerceVariableValues(operationName string, variables map[string]interface{}) (map[string]interface{}, error) {
+
+	c.errors = c.errors[:0]
+
+	operation, err := c.operationDefinition(operationName)
+	if err != nil {
+		return nil, err
+	}
+
+	coerced := make(map[string]interface{}, len(operation.VariableDefinitions))
+
+	definitions := c.l.VariableDefinitionIterator(operation.VariableDefinitions)
+	for definitions.Next() {
+		definition, _ := definitions.Value()
+		c.coerceVariable(definition, variables, coerced)
+	}
+
+	if len(c.errors) != 0 {
+		errs := make(Errors, len(c.errors))
+		copy(errs, c.errors)
+		return nil, errs
+	}
+
+	return coerced, nil
+}
+
+func (c *Coercer) operationDefinition(operationName string) (document.OperationDefinition, error) {
+
+	operations := c.l.OperationDefinitions()
+
+	if operationName == "" {
+		if len(operations) != 1 {
+			return document.OperationDefinition{}, Errors{{Message: "Must provide operation name if query contains multiple operations.", Extensions: Extensions{Code: ErrorCode}}}
+		}
+		return operations[0], nil
+	}
+
+	for i := range operations {
+		if string(c.l.ByteSlice(operations[i].Name)) == operationName {
+			return operations[i], nil
+		}
+	}
+
+	return document.OperationDefinition{}, Errors{{Message: fmt.Sprintf("Unknown operation named \"%s\".", operationName), Extensions: Extensions{Code: ErrorCode}}}
+}
+
+func (c *Coercer) coerceVariable(definition document.VariableDefinition, variables map[string]interface{}, coerced map[string]interface{}) {
+
+	name := string(c.l.ByteSlice(definition.Variable))
+	variableType := c.l.Type(definition.Type)
+	value, hasValue := variables[name]
+
+	c.path = append(c.path[:0], name)
+
+	if !hasValue {
+		if definition.DefaultValue != -1 {
+			coerced[name] = c.constValue(definition.DefaultValue)
+			return
+		}
+		if variableType.Kind == document.TypeKindNON_NULL {
+			c.reportf(definition, "Variable \"$%s\" of required type \"%s\" was not provided.", name, c.typeString(variableType))
+		}
+		return
+	}
+
+	if value == nil {
+		if variableType.Kind == document.TypeKindNON_NULL {
+			c.reportf(definition, "Variable \"$%s\" of non-null type \"%s\" must not be null.", name, c.typeString(variableType))
+			return
+		}
+		coerced[name] = nil
+		return
+	}
+
+	errorCount := len(c.errors)
+	result := c.coerceValue(definition, value, variableType)
+	if len(c.errors) == errorCount {
+		coerced[name] = result
+	}
+}
+
+func (c *Coercer) coerceValue(definition document.VariableDefinition, value interface{}, valueType document.Type) interface{} {
+
+	if valueType.Kind == document.TypeKindNON_NULL {
+		if value == nil {
+			c.reportInvalid(definition, value, fmt.Sprintf("Expected non-nullable type \"%s\" not to be null.", c.typeString(valueType)))
+			return nil
+		}
+		valueType = c.l.Type(valueType.OfType)
+	}
+
+	if value == nil {
+		return nil
+	}
+
+	switch valueType.Kind {
+	case document.TypeKindLIST:
+		return c.coerceList(definition, value, c.l.Type(valueType.OfType))
+	case document.TypeKindNAMED:
+		return c.coerceNamed(definition, value, valueType)
+	}
+
+	return value
+}
+
+func (c *Coercer) coerceList(definition document.VariableDefinition, value interface{}, itemType document.Type) interface{} {
+
+	items, isList := value.([]interface{})
+	if !isList {
+		// input coercion allows a single value to be passed for a list type
+		return []interface{}{c.coerceValue(definition, value, itemType)}
+	}
+
+	coerced := make([]interface{}, len(items))
+	for i := range items {
+		c.path = append(c.path, strconv.Itoa(i))
+		coerced[i] = c.coerceValue(definition, items[i], itemType)
+		c.path = c.path[:len(c.path)-1]
+	}
+
+	return coerced
+}
+
+func (c *Coercer) coerceNamed(definition document.VariableDefinition, value interface{}, namedType document.Type) interface{} {
+
+	typeName := c.l.ByteSlice(namedType.Name)
+
+	switch {
+	case bytes.Equal(typeName, literal.INT):
+		return c.coerceInt(definition, value)
+	case bytes.Equal(typeName, literal.FLOAT):
+		return c.coerceFloat(definition, value)
+	case bytes.Equal(typeName, literal.STRING):
+		return c.coerceString(definition, value)
+	case bytes.Equal(typeName, literal.BOOLEAN):
+		return c.coerceBoolean(definition, value)
+	case bytes.Equal(typeName, literal.ID):
+		return c.coerceID(definition, value)
+	}
+
+	if enumTypeDefinition, ok := c.l.EnumTypeDefinitionByName(namedType.Name); ok {
+		return c.coerceEnum(definition, value, enumTypeDefinition)
+	}
+
+	if inputObjectTypeDefinition, ok := c.l.InputObjectTypeDefinitionByName(namedType.Name); ok {
+		return c.coerceInputObject(definition, value, inputObjectTypeDefinition)
+	}
+
+	if _, ok := c.l.ScalarTypeDefinitionByName(namedType.Name); ok {
+		return c.coerceCustomScalar(definition, value, string(typeName))
+	}
+
+	c.reportf(definition, "Variable \"$%s\" expected value of type \"%s\" which cannot be used as an input type.", c.path[0], string(typeName))
+	return nil
+}
+
+func (c *Coercer) coerceInt(definition document.VariableDefinition, value interface{}) interface{} {
+
+	number, ok := toFloat64(value)
+	if !ok || number != math.Trunc(number) {
+		c.reportInvalid(definition, value, "Expected type \"Int\".")
+		return nil
+	}
+
+	if number > math.MaxInt32 || number < math.MinInt32 {
+		c.reportInvalid(definition, value, "Int cannot represent non 32-bit signed integer value.")
+		return nil
+	}
+
+	return int32(number)
+}
+
+func (c *Coercer) coerceFloat(definition document.VariableDefinition, value interface{}) interface{} {
+
+	number, ok := toFloat64(value)
+	if !ok || math.IsInf(number, 0) || math.IsNaN(number) {
+		c.reportInvalid(definition, value, "Expected type \"Float\".")
+		return nil
+	}
+
+	return number
+}
+
+func (c *Coercer) coerceString(definition document.VariableDefinition, value interface{}) interface{} {
+
+	str, ok := value.(string)
+	if !ok {
+		c.reportInvalid(definition, value, "Expected type \"String\".")
+		return nil
+	}
+
+	return str
+}
+
+func (c *Coercer) coerceBoolean(definition document.VariableDefinition, value interface{}) interface{} {
+
+	boolean, ok := value.(bool)
+	if !ok {
+		c.reportInvalid(definition, value, "Expected type \"Boolean\".")
+		return nil
+	}
+
+	return boolean
+}
+
+func (c *Coercer) coerceID(definition document.VariableDefinition, value interface{}) interface{} {
+
+	if str, ok := value.(string); ok {
+		return str
+	}
+
+	number, ok := toFloat64(value)
+	if !ok || number != math.Trunc(number) {
+		c.reportInvalid(definition, value, "Expected type \"ID\".")
+		return nil
+	}
+
+	return strconv.FormatFloat(number, 'f', -1, 64)
+}
+
+func (c *Coercer) coerceEnum(definition document.VariableDefinition, value interface{}, enumTypeDefinition document.EnumTypeDefinition) interface{} {
+
+	name, ok := value.(string)
+	if ok {
+		enumValues := enumTypeDefinition.EnumValuesDefinition
+		for enumValues.Next(c.l) {
+			enumValue, _ := enumValues.Value()
+			if string(c.l.ByteSlice(enumValue.EnumValue)) == name {
+				return name
+			}
+		}
+	}
+
+	c.reportInvalid(definition, value, fmt.Sprintf("Expected type \"%s\".", string(c.l.ByteSlice(enumTypeDefinition.Name))))
+	return nil
+}
+
+func (c *Coercer) coerceInputObject(definition document.VariableDefinition, value interface{}, inputObjectTypeDefinition document.InputObjectTypeDefinition) interface{} {
+
+	typeName := string(c.l.ByteSlice(inputObjectTypeDefinition.Name))
+
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		c.reportInvalid(definition, value, fmt.Sprintf("Expected type \"%s\" to be an object.", typeName))
+		return nil
+	}
+
+	coerced := make(map[string]interface{}, len(object))
+	inputFieldsDefinition := c.l.InputFieldsDefinition(inputObjectTypeDefinition.InputFieldsDefinition)
+
+	inputValueDefinitions := inputFieldsDefinition.InputValueDefinitions
+	for inputValueDefinitions.Next(c.l) {
+		inputValueDefinition, _ := inputValueDefinitions.Value()
+		fieldName := string(c.l.ByteSlice(inputValueDefinition.Name))
+		fieldType := c.l.Type(inputValueDefinition.Type)
+
+		fieldValue, hasValue := object[fieldName]
+		if !hasValue {
+			if inputValueDefinition.DefaultValue != -1 {
+				coerced[fieldName] = c.constValue(inputValueDefinition.DefaultValue)
+			} else if fieldType.Kind == document.TypeKindNON_NULL {
+				c.reportInvalid(definition, value, fmt.Sprintf("Field \"%s\" of required type \"%s\" was not provided.", fieldName, c.typeString(fieldType)))
+			}
+			continue
+		}
+
+		c.path = append(c.path, fieldName)
+		coerced[fieldName] = c.coerceValue(definition, fieldValue, fieldType)
+		c.path = c.path[:len(c.path)-1]
+	}
+
+	for fieldName, fieldValue := range object {
+		inputValueDefinitions := inputFieldsDefinition.InputValueDefinitions
+		if !c.inputValueDefinitionsContainName(inputValueDefinitions, fieldName) {
+			c.path = append(c.path, fieldName)
+			c.reportInvalid(definition, fieldValue, fmt.Sprintf("Field \"%s\" is not defined by type \"%s\".", fieldName, typeName))
+			c.path = c.path[:len(c.path)-1]
+		}
+	}
+
+	return coerced
+}
+
+func (c *Coercer) inputValueDefinitionsContainName(definitions document.InputValueDefinitions, name string) bool {
+	for definitions.Next(c.l) {
+		definition, _ := definitions.Value()
+		if string(c.l.ByteSlice(definition.Name)) == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Coercer) coerceCustomScalar(definition document.VariableDefinition, value interface{}, typeName string) interface{} {
+
+	coerce, ok := c.scalars[typeName]
+	if !ok {
+		return value
+	}
+
+	coerced, err := coerce(value)
+	if err != nil {
+		c.reportInvalid(definition, value, fmt.Sprintf("Expected type \"%s\". %s", typeName, err.Error()))
+		return nil
+	}
+
+	return coerced
+}
+
+// constValue transforms a const value from the AST (e.g. a default value) into its json representation
+func (c *Coercer) constValue(ref int) interface{} {
+
+	value := c.l.Value(ref)
+
+	switch value.ValueType {
+	case document.ValueTypeInt:
+		integer, _ := strconv.ParseInt(string(c.l.ByteSlice(value.Raw)), 10, 32)
+		return int32(integer)
+	case document.ValueTypeFloat:
+		float, _ := strconv.ParseFloat(string(c.l.ByteSlice(value.Raw)), 64)
+		return float
+	case document.ValueTypeBoolean:
+		return value.Reference == 1
+	case document.ValueTypeString:
+		return string(transform.UnescapeString(c.l.ByteSlice(value.Raw)))
+	case document.ValueTypeEnum:
+		return string(c.l.ByteSlice(value.Raw))
+	case document.ValueTypeList:
+		list := c.l.ListValue(value.Reference)
+		out := make([]interface{}, len(list))
+		for i := range list {
+			out[i] = c.constValue(list[i])
+		}
+		return out
+	case document.ValueTypeObject:
+		object := c.l.ObjectValue(value.Reference)
+		out := make(map[string]interface{}, len(object))
+		fields := c.l.ObjectFieldsIterator(object)
+		for fields.Next() {
+			field, _ := fields.Value()
+			out[string(c.l.ByteSlice(field.Name))] = c.constValue(field.Value)
+		}
+		return out
+	default:
+		return nil
+	}
+}
+
+func (c *Coercer) typeString(documentType document.Type) string {
+	switch documentType.Kind {
+	case document.TypeKindNON_NULL:
+		return c.typeString(c.l.Type(documentType.OfType)) + "!"
+	case document.TypeKindLIST:
+		return "[" + c.typeString(c.l.Type(documentType.OfType)) + "]"
+	default:
+		return string(c.l.ByteSlice(documentType.Name))
+	}
+}
+
+func (c *Coercer) reportInvalid(definition document.VariableDefinition, value interface{}, reason string) {
+
+	printedValue, err := json.Marshal(value)
+	if err != nil {
+		printedValue = []byte(fmt.Sprintf("%v", value))
+	}
+
+	if len(c.path) > 1 {
+		c.reportf(definition, "Variable \"$%s\" got invalid value %s at \"%s\"; %s", c.path[0], printedValue, strings.Join(c.path, "."), reason)
+		return
+	}
+
+	c.reportf(definition, "Variable \"$%s\" got invalid value %s; %s", c.path[0], printedValue, reason)
+}
+
+func (c *Coercer) reportf(definition document.VariableDefinition, format string, args ...interface{}) {
+	c.errors = append(c.errors, Error{
+		Message: fmt.Sprintf(format, args...),
+		Locations: []Location{
+			{
+				Line:   definition.Position.LineStart,
+				Column: definition.Position.CharStart,
+			},
+		},
+		Extensions: Extensions{Code: ErrorCode},
+	})
+}
+
+func toFloat64(value interface{}) (float64, bool) {
+	switch number := value.(type) {
+	case float64:
+		return number, true
+	case float32:
+		return float64(number), true
+	case int:
+		return float64(number), true
+	case int32:
+		return float64(number), true
+	case int64:
+		return float64(number), true
+	case json.Number:
+		float, err := number.Float64()
+		return float, err == nil
+	default:
+		return 0, false
+	}
+}
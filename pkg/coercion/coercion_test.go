@@ -0,0 +1,245 @@
+package coercion
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jensneuse/graphql-go-tools/pkg/lookup"
+	"github.com/jensneuse/graphql-go-tools/pkg/parser"
+	"strings"
+	"testing"
+)
+
+func TestCoercer_CoerceVariableValues(t *testing.T) {
+
+	run := func(executable, operationName, variables string, scalars map[string]ScalarCoercer, wantVariables string, wantErrors ...string) {
+		p := parser.NewParser()
+		err := p.ParseTypeSystemDefinition([]byte(coercionSchema))
+		if err != nil {
+			panic(err)
+		}
+
+		err = p.ParseExecutableDefinition([]byte(executable))
+		if err != nil {
+			panic(err)
+		}
+
+		var input map[string]interface{}
+		if variables != "" {
+			err = json.Unmarshal([]byte(variables), &input)
+			if err != nil {
+				panic(err)
+			}
+		}
+
+		c := New()
+		c.SetInput(lookup.New(p))
+		c.SetScalarCoercers(scalars)
+
+		coerced, err := c.CoerceVariableValues(operationName, input)
+		if len(wantErrors) != 0 {
+			errs, ok := err.(Errors)
+			if !ok {
+				panic(fmt.Errorf("want errors: %v, got: %v", wantErrors, err))
+			}
+			if len(errs) != len(wantErrors) {
+				panic(fmt.Errorf("want %d errors, got %d: %s", len(wantErrors), len(errs), errs.Error()))
+			}
+			for i := range wantErrors {
+				if !strings.Contains(errs[i].Message, wantErrors[i]) {
+					panic(fmt.Errorf("want error message containing:\n%s\ngot:\n%s", wantErrors[i], errs[i].Message))
+				}
+			}
+			return
+		}
+
+		if err != nil {
+			panic(err)
+		}
+
+		got, err := json.Marshal(coerced)
+		if err != nil {
+			panic(err)
+		}
+
+		if wantVariables != string(got) {
+			panic(fmt.Errorf("want variables:\n%s\ngot:\n%s", wantVariables, string(got)))
+		}
+	}
+
+	t.Run("scalars", func(t *testing.T) {
+		run(`query q($id: ID!, $count: Int, $ratio: Float, $name: String, $active: Boolean) { dog { name } }`, "",
+			`{"id":1,"count":3,"ratio":1,"name":"Woof","active":true}`, nil,
+			`{"active":true,"count":3,"id":"1","name":"Woof","ratio":1}`)
+	})
+	t.Run("undefined variables are dropped", func(t *testing.T) {
+		run(`query q($name: String) { dog { name } }`, "",
+			`{"name":"Woof","unknown":1}`, nil,
+			`{"name":"Woof"}`)
+	})
+	t.Run("nullable variable might be omitted", func(t *testing.T) {
+		run(`query q($name: String) { dog { name } }`, "", ``, nil, `{}`)
+	})
+	t.Run("explicit null is kept", func(t *testing.T) {
+		run(`query q($name: String) { dog { name } }`, "", `{"name":null}`, nil, `{"name":null}`)
+	})
+	t.Run("default values", func(t *testing.T) {
+		run(`query q($name: String = "Wo\"of", $count: Int = 3, $command: DogCommand = SIT, $ids: [ID] = ["1", "2"]) { dog { name } }`, "",
+			``, nil,
+			`{"command":"SIT","count":3,"ids":["1","2"],"name":"Wo\"of"}`)
+	})
+	t.Run("provided value wins over default value", func(t *testing.T) {
+		run(`query q($count: Int = 3) { dog { name } }`, "", `{"count":5}`, nil, `{"count":5}`)
+	})
+	t.Run("enum", func(t *testing.T) {
+		run(`query q($command: DogCommand!) { dog { name } }`, "", `{"command":"HEEL"}`, nil, `{"command":"HEEL"}`)
+	})
+	t.Run("single value is coerced into list", func(t *testing.T) {
+		run(`query q($ids: [ID!]) { dog { name } }`, "", `{"ids":"1"}`, nil, `{"ids":["1"]}`)
+	})
+	t.Run("nested input object with defaults", func(t *testing.T) {
+		run(`query q($filter: DogFilter!) { dog { name } }`, "",
+			`{"filter":{"name":"Woof","owner":{"name":"Jens"},"commands":["SIT","DOWN"]}}`, nil,
+			`{"filter":{"commands":["SIT","DOWN"],"limit":10,"name":"Woof","owner":{"age":18,"name":"Jens"}}}`)
+	})
+	t.Run("select operation by name", func(t *testing.T) {
+		run(`query first($count: Int!) { dog { name } } query second($name: String!) { dog { name } }`, "second",
+			`{"name":"Woof"}`, nil, `{"name":"Woof"}`)
+	})
+	t.Run("custom scalar without hook is passed through", func(t *testing.T) {
+		run(`query q($at: DateTime) { dog { name } }`, "", `{"at":{"unix":1}}`, nil, `{"at":{"unix":1}}`)
+	})
+	t.Run("custom scalar hook", func(t *testing.T) {
+		run(`query q($at: DateTime) { dog { name } }`, "", `{"at":"2019-01-01"}`,
+			map[string]ScalarCoercer{
+				"DateTime": func(value interface{}) (interface{}, error) {
+					return strings.Replace(value.(string), "-", "/", -1), nil
+				},
+			},
+			`{"at":"2019/01/01"}`)
+	})
+	t.Run("invalid custom scalar", func(t *testing.T) {
+		run(`query q($at: DateTime) { dog { name } }`, "", `{"at":true}`,
+			map[string]ScalarCoercer{
+				"DateTime": func(value interface{}) (interface{}, error) {
+					return nil, errors.New("DateTime must be a string")
+				},
+			},
+			``,
+			`Variable "$at" got invalid value true; Expected type "DateTime". DateTime must be a string`)
+	})
+	t.Run("missing required variable", func(t *testing.T) {
+		run(`query q($id: ID!) { dog { name } }`, "", ``, nil, ``,
+			`Variable "$id" of required type "ID!" was not provided.`)
+	})
+	t.Run("null for non null variable", func(t *testing.T) {
+		run(`query q($id: ID!) { dog { name } }`, "", `{"id":null}`, nil, ``,
+			`Variable "$id" of non-null type "ID!" must not be null.`)
+	})
+	t.Run("invalid scalars", func(t *testing.T) {
+		run(`query q($count: Int, $ratio: Float, $name: String, $active: Boolean, $id: ID) { dog { name } }`, "",
+			`{"count":1.5,"ratio":"1","name":1,"active":"true","id":true}`, nil, ``,
+			`Variable "$count" got invalid value 1.5; Expected type "Int".`,
+			`Variable "$ratio" got invalid value "1"; Expected type "Float".`,
+			`Variable "$name" got invalid value 1; Expected type "String".`,
+			`Variable "$active" got invalid value "true"; Expected type "Boolean".`,
+			`Variable "$id" got invalid value true; Expected type "ID".`)
+	})
+	t.Run("int out of range", func(t *testing.T) {
+		run(`query q($count: Int) { dog { name } }`, "", `{"count":2147483648}`, nil, ``,
+			`Variable "$count" got invalid value 2147483648; Int cannot represent non 32-bit signed integer value.`)
+	})
+	t.Run("invalid enum value", func(t *testing.T) {
+		run(`query q($command: DogCommand) { dog { name } }`, "", `{"command":"JUMP"}`, nil, ``,
+			`Variable "$command" got invalid value "JUMP"; Expected type "DogCommand".`)
+	})
+	t.Run("null inside list of non null items", func(t *testing.T) {
+		run(`query q($ids: [ID!]) { dog { name } }`, "", `{"ids":["1",null]}`, nil, ``,
+			`Variable "$ids" got invalid value null at "ids.1"; Expected non-nullable type "ID!" not to be null.`)
+	})
+	t.Run("invalid nested input object", func(t *testing.T) {
+		run(`query q($filter: DogFilter) { dog { name } }`, "",
+			`{"filter":{"owner":{"age":"old"},"color":"brown"}}`, nil, ``,
+			`Variable "$filter" got invalid value "old" at "filter.owner.age"; Expected type "Int".`,
+			`Variable "$filter" got invalid value {"age":"old"} at "filter.owner"; Field "name" of required type "String!" was not provided.`,
+			`Variable "$filter" got invalid value "brown" at "filter.color"; Field "color" is not defined by type "DogFilter".`)
+	})
+	t.Run("input object must be an object", func(t *testing.T) {
+		run(`query q($filter: DogFilter) { dog { name } }`, "", `{"filter":"Woof"}`, nil, ``,
+			`Variable "$filter" got invalid value "Woof"; Expected type "DogFilter" to be an object.`)
+	})
+	t.Run("output type variable", func(t *testing.T) {
+		run(`query q($dog: Dog) { dog { name } }`, "", `{"dog":{}}`, nil, ``,
+			`Variable "$dog" expected value of type "Dog" which cannot be used as an input type.`)
+	})
+	t.Run("missing operation name", func(t *testing.T) {
+		run(`query first { dog { name } } query second { dog { name } }`, "", ``, nil, ``,
+			`Must provide operation name if query contains multiple operations.`)
+	})
+	t.Run("unknown operation name", func(t *testing.T) {
+		run(`query first { dog { name } }`, "second", ``, nil, ``,
+			`Unknown operation named "second".`)
+	})
+}
+
+func TestErrors_Locations(t *testing.T) {
+	p := parser.NewParser()
+	err := p.ParseTypeSystemDefinition([]byte(coercionSchema))
+	if err != nil {
+		panic(err)
+	}
+
+	err = p.ParseExecutableDefinition([]byte("query q(\n\t$id: ID!) { dog { name } }"))
+	if err != nil {
+		panic(err)
+	}
+
+	c := New()
+	c.SetInput(lookup.New(p))
+	_, err = c.CoerceVariableValues("", nil)
+
+	got, _ := json.Marshal(err)
+	want := `[{"message":"Variable \"$id\" of required type \"ID!\" was not provided.","locations":[{"line":2,"column":2}],"extensions":{"code":"BAD_USER_INPUT"}}]`
+	if want != string(got) {
+		t.Fatalf("want:\n%s\ngot:\n%s", want, string(got))
+	}
+}
+
+const coercionSchema = `
+schema {
+	query: Query
+}
+
+scalar ID
+scalar Int
+scalar Float
+scalar String
+scalar Boolean
+scalar DateTime
+
+type Query {
+	dog(filter: DogFilter): Dog
+}
+
+type Dog {
+	name: String!
+}
+
+enum DogCommand {
+	SIT
+	DOWN
+	HEEL
+}
+
+input Owner {
+	name: String!
+	age: Int = 18
+}
+
+input DogFilter {
+	name: String
+	owner: Owner
+	commands: [DogCommand!]
+	limit: Int = 10
+}
+`
@@ -0,0 +1,42 @@
+package coercion
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/jensneuse/graphql-go-tools/pkg/graphqlerror"
+)
+
+// ErrorCode is the code of all coercion errors, it's sent as extension so that clients can tell them apart from execution errors
+const ErrorCode = "BAD_USER_INPUT"
+
+// Error is a variable coercion error in the shape of a graphql response error
+type Error = graphqlerror.Error
+
+// Location points to the variable definition inside the executable definition
+type Location = graphqlerror.Location
+
+// Extensions are the additional information of an Error
+type Extensions = graphqlerror.Extensions
+
+// Errors are all errors collected while coercing the variables of a request
+type Errors []Error
+
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i := range e {
+		messages[i] = e[i].Message
+	}
+	return strings.Join(messages, "\n")
+}
+
+// StatusCode returns 400 because invalid variables are an error of the client
+func (e Errors) StatusCode() int {
+	return http.StatusBadRequest
+}
+
+// Response returns the graphql response for the errors, e.g.:
+// {"errors":[{"message":"Variable \"$id\" of required type \"ID!\" was not provided.","locations":[{"line":1,"column":9}],"extensions":{"code":"BAD_USER_INPUT"}}]}
+func (e Errors) Response() []byte {
+	return graphqlerror.Response(e...)
+}
@@ -0,0 +1,326 @@
+// Package cost implements a static cost analysis for graphql operations
+//
+// The cost of an operation is computed from the executable definition and the schema before the operation gets executed.
+// Each selected field adds its weight, fields returning lists multiply the cost of their selections by the value of
+// their multiplier arguments, e.g. 'first' or 'last'.
+// Weights and multipliers are configured with the @cost directive on field definitions:
+//
+//	type Query {
+//		users(first: Int): [User] @cost(weight: 2, multipliers: ["first"])
+//	}
+//
+// The query '{ users(first: 10) { name } }' costs 2 + 10 * 1 = 12 with the default weight of 1 for the field 'name'.
+package cost
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/jensneuse/graphql-go-tools/pkg/document"
+	"github.com/jensneuse/graphql-go-tools/pkg/lookup"
+)
+
+// DirectiveDefinition is the definition of the @cost directive
+// it might be added to the schema so that schema validation passes
+const DirectiveDefinition = `
+directive @cost(
+	weight: Int = 1
+	multipliers: [String!]
+) on FIELD_DEFINITION
+`
+
+var (
+	costDirectiveName       = []byte("cost")
+	weightArgumentName      = []byte("weight")
+	multipliersArgumentName = []byte("multipliers")
+)
+
+// Calculator computes the static cost of operations
+type Calculator struct {
+	// DefaultWeight is the weight of fields without a weight defined by the @cost directive
+	DefaultWeight int
+	// DefaultMultiplier is used for fields with multipliers if none of the multiplier arguments is provided
+	DefaultMultiplier int
+	// MaxMultiplier is used for multiplier arguments passed as variables which can't be resolved,
+	// e.g. variables without default value if the request variables are unknown
+	MaxMultiplier int
+
+	l                   *lookup.Lookup
+	variables           map[string]interface{}
+	variableDefinitions []int
+	fragments           []document.ByteSliceReference
+}
+
+// New returns a Calculator with a DefaultWeight and DefaultMultiplier of 1
+// and a MaxMultiplier of math.MaxInt32 so that unresolved variables can't be used to bypass cost limits
+func New() *Calculator {
+	return &Calculator{
+		DefaultWeight:     1,
+		DefaultMultiplier: 1,
+		MaxMultiplier:     math.MaxInt32,
+		fragments:         make([]document.ByteSliceReference, 0, 8),
+	}
+}
+
+func (c *Calculator) SetInput(l *lookup.Lookup) {
+	c.l = l
+}
+
+// SetVariables sets the (json decoded) variables of the request, they're used to resolve multiplier arguments
+// variables which are not provided resolve to their default value, variables without default value to MaxMultiplier
+func (c *Calculator) SetVariables(variables map[string]interface{}) {
+	c.variables = variables
+}
+
+// Cost returns the cost of the operation with the given name
+// operationName might be empty if the executable definition contains exactly one operation
+func (c *Calculator) Cost(operationName string) (int, error) {
+
+	operations := c.l.OperationDefinitions()
+
+	if operationName == "" {
+		if len(operations) != 1 {
+			return 0, fmt.Errorf("Cost: operation name must be provided if the document contains %d operations", len(operations))
+		}
+		return c.OperationCost(operations[0]), nil
+	}
+
+	for i := range operations {
+		if string(c.l.ByteSlice(operations[i].Name)) == operationName {
+			return c.OperationCost(operations[i]), nil
+		}
+	}
+
+	return 0, fmt.Errorf("Cost: operation '%s' not found", operationName)
+}
+
+// OperationCost returns the cost of the operation
+func (c *Calculator) OperationCost(operation document.OperationDefinition) int {
+
+	c.variableDefinitions = operation.VariableDefinitions
+	c.fragments = c.fragments[:0]
+
+	var rootTypeName document.ByteSliceReference
+	var rootType document.ObjectTypeDefinition
+	var ok bool
+
+	switch operation.OperationType {
+	case document.OperationTypeMutation:
+		rootType, ok = c.l.MutationObjectTypeDefinition()
+	case document.OperationTypeSubscription:
+		rootType, ok = c.l.SubscriptionObjectTypeDefinition()
+	default:
+		rootType, ok = c.l.QueryObjectTypeDefinition()
+	}
+
+	if ok {
+		rootTypeName = rootType.Name
+	}
+
+	return c.selectionSetCost(operation.SelectionSet, rootTypeName)
+}
+
+func (c *Calculator) selectionSetCost(ref int, typeName document.ByteSliceReference) (cost int) {
+
+	set := c.l.SelectionSet(ref)
+
+	for _, fieldRef := range set.Fields {
+		cost = add(cost, c.fieldCost(c.l.Field(fieldRef), typeName))
+	}
+
+	for _, inlineFragmentRef := range set.InlineFragments {
+		inlineFragment := c.l.InlineFragment(inlineFragmentRef)
+		fragmentTypeName := typeName
+		if inlineFragment.TypeCondition != -1 {
+			fragmentTypeName = c.l.Type(inlineFragment.TypeCondition).Name
+		}
+		cost = add(cost, c.selectionSetCost(inlineFragment.SelectionSet, fragmentTypeName))
+	}
+
+	for _, spreadRef := range set.FragmentSpreads {
+		spread := c.l.FragmentSpread(spreadRef)
+		if c.l.ByteSliceReferencesContainName(c.fragments, spread.FragmentName) {
+			continue // cyclic fragment spreads are rejected by validation
+		}
+		fragment, _, ok := c.l.FragmentDefinitionByName(spread.FragmentName)
+		if !ok {
+			continue
+		}
+		c.fragments = append(c.fragments, spread.FragmentName)
+		cost = add(cost, c.selectionSetCost(fragment.SelectionSet, c.l.Type(fragment.TypeCondition).Name))
+		c.fragments = c.fragments[:len(c.fragments)-1]
+	}
+
+	return
+}
+
+func (c *Calculator) fieldCost(field document.Field, typeName document.ByteSliceReference) int {
+
+	weight := c.DefaultWeight
+	multiplier := 1
+	var fieldTypeName document.ByteSliceReference
+
+	definition, ok := c.l.FieldDefinitionByNameFromDefinitions(c.l.FieldsDefinitionFromNamedType(typeName), field.Name)
+	if ok {
+		fieldTypeName = c.l.UnwrappedNamedType(c.l.Type(definition.Type)).Name
+		weight, multiplier = c.fieldDefinitionWeightAndMultiplier(definition, field)
+	}
+
+	if field.SelectionSet == -1 {
+		return weight
+	}
+
+	return add(weight, mul(multiplier, c.selectionSetCost(field.SelectionSet, fieldTypeName)))
+}
+
+func (c *Calculator) fieldDefinitionWeightAndMultiplier(definition document.FieldDefinition, field document.Field) (weight, multiplier int) {
+
+	weight = c.DefaultWeight
+	multiplier = 1
+
+	directives := c.l.DirectiveIterable(c.l.DirectiveSet(definition.DirectiveSet))
+	for directives.Next() {
+		directive, _ := directives.Value()
+		if !bytes.Equal(c.l.ByteSlice(directive.Name), costDirectiveName) {
+			continue
+		}
+
+		args := c.l.ArgumentsIterable(c.l.ArgumentSet(directive.ArgumentSet))
+		for args.Next() {
+			arg, _ := args.Value()
+			argName := c.l.ByteSlice(arg.Name)
+			switch {
+			case bytes.Equal(argName, weightArgumentName):
+				if value, ok := c.intValue(arg.Value); ok {
+					weight = value
+				}
+			case bytes.Equal(argName, multipliersArgumentName):
+				multiplier = c.multiplier(arg.Value, field)
+			}
+		}
+	}
+
+	return
+}
+
+// multiplier returns the largest value of all provided multiplier arguments of the field
+func (c *Calculator) multiplier(multipliersValueRef int, field document.Field) int {
+
+	multipliers := c.l.Value(multipliersValueRef)
+	if multipliers.ValueType != document.ValueTypeList {
+		return 1
+	}
+
+	multiplier := -1
+
+	for _, multiplierRef := range c.l.ListValue(multipliers.Reference) {
+		argumentName := c.l.ByteSlice(c.l.Value(multiplierRef).Raw)
+
+		args := c.l.ArgumentsIterable(c.l.ArgumentSet(field.ArgumentSet))
+		for args.Next() {
+			arg, _ := args.Value()
+			if !bytes.Equal(c.l.ByteSlice(arg.Name), argumentName) {
+				continue
+			}
+			if value, ok := c.argumentValue(arg.Value); ok && value > multiplier {
+				multiplier = value
+			}
+		}
+	}
+
+	if multiplier == -1 {
+		return c.DefaultMultiplier
+	}
+
+	return multiplier
+}
+
+// argumentValue resolves an argument value to an integer
+// lists resolve to their length, variables resolve to the provided or default value or MaxMultiplier otherwise
+func (c *Calculator) argumentValue(ref int) (int, bool) {
+
+	value := c.l.Value(ref)
+
+	switch value.ValueType {
+	case document.ValueTypeList:
+		return len(c.l.ListValue(value.Reference)), true
+	case document.ValueTypeVariable:
+		name := c.l.ByteSliceReference(value.Reference)
+		if variable, ok := c.variables[string(c.l.ByteSlice(name))]; ok {
+			if value, ok := variableValue(variable); ok {
+				return value, true
+			}
+			return c.MaxMultiplier, true
+		}
+		definition, ok := c.l.VariableDefinition(name, c.variableDefinitions)
+		if !ok || definition.DefaultValue == -1 {
+			return c.MaxMultiplier, true
+		}
+		return c.argumentValue(definition.DefaultValue)
+	default:
+		return c.intValue(ref)
+	}
+}
+
+func (c *Calculator) intValue(ref int) (int, bool) {
+	value := c.l.Value(ref)
+	if value.ValueType != document.ValueTypeInt {
+		return 0, false
+	}
+	integer, err := strconv.ParseInt(string(c.l.ByteSlice(value.Raw)), 10, 32)
+	if err != nil || integer < 0 {
+		return 0, false
+	}
+	return int(integer), true
+}
+
+func variableValue(value interface{}) (int, bool) {
+	switch value := value.(type) {
+	case []interface{}:
+		return len(value), true
+	case float64:
+		return clamp(value)
+	case json.Number:
+		float, err := value.Float64()
+		if err != nil {
+			return 0, false
+		}
+		return clamp(float)
+	case int:
+		return clamp(float64(value))
+	case int32:
+		return clamp(float64(value))
+	case int64:
+		return clamp(float64(value))
+	default:
+		return 0, false
+	}
+}
+
+func clamp(value float64) (int, bool) {
+	if value < 0 || value != math.Trunc(value) {
+		return 0, false
+	}
+	if value > math.MaxInt32 {
+		return math.MaxInt32, true
+	}
+	return int(value), true
+}
+
+// add and mul saturate at math.MaxInt32 so that huge multipliers can't overflow the cost
+func add(a, b int) int {
+	if a > math.MaxInt32-b {
+		return math.MaxInt32
+	}
+	return a + b
+}
+
+func mul(a, b int) int {
+	if a != 0 && b > math.MaxInt32/a {
+		return math.MaxInt32
+	}
+	return a * b
+}
@@ -0,0 +1,201 @@
+package cost
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/jensneuse/graphql-go-tools/pkg/lookup"
+	"github.com/jensneuse/graphql-go-tools/pkg/parser"
+	"math"
+	"testing"
+)
+
+func TestCalculator_Cost(t *testing.T) {
+
+	run := func(executable, operationName, variables string, wantCost int) {
+		p := parser.NewParser()
+		err := p.ParseTypeSystemDefinition([]byte(costSchema))
+		if err != nil {
+			panic(err)
+		}
+
+		err = p.ParseExecutableDefinition([]byte(executable))
+		if err != nil {
+			panic(err)
+		}
+
+		var input map[string]interface{}
+		if variables != "" {
+			err = json.Unmarshal([]byte(variables), &input)
+			if err != nil {
+				panic(err)
+			}
+		}
+
+		c := New()
+		c.SetInput(lookup.New(p))
+		c.SetVariables(input)
+
+		got, err := c.Cost(operationName)
+		if err != nil {
+			panic(err)
+		}
+
+		if wantCost != got {
+			panic(fmt.Errorf("want cost: %d, got: %d", wantCost, got))
+		}
+	}
+
+	t.Run("scalar fields", func(t *testing.T) {
+		run(`query q { viewer { name email } }`, "", "", 3)
+	})
+	t.Run("weight", func(t *testing.T) {
+		run(`query q { search(term: "foo") }`, "", "", 10)
+	})
+	t.Run("multiplier", func(t *testing.T) {
+		run(`query q { users(first: 10) { name } }`, "", "", 12)
+	})
+	t.Run("default multiplier", func(t *testing.T) {
+		run(`query q { users { name } }`, "", "", 3)
+	})
+	t.Run("largest multiplier wins", func(t *testing.T) {
+		run(`query q { users(first: 5, last: 20) { name } }`, "", "", 22)
+	})
+	t.Run("nested multipliers", func(t *testing.T) {
+		run(`query q { users(first: 10) { name friends(first: 5) { name } } }`, "", "", 2+10*(1+1+5*1))
+	})
+	t.Run("list value multiplier", func(t *testing.T) {
+		run(`query q { usersByIds(ids: ["1","2","3"]) { name } }`, "", "", 1+3*1)
+	})
+	t.Run("multiplier from variable", func(t *testing.T) {
+		run(`query q($first: Int) { users(first: $first) { name } }`, "", `{"first":50}`, 52)
+	})
+	t.Run("multiplier from variable default value", func(t *testing.T) {
+		run(`query q($first: Int = 7) { users(first: $first) { name } }`, "", "", 9)
+	})
+	t.Run("list variable multiplier", func(t *testing.T) {
+		run(`query q($ids: [ID!]!) { usersByIds(ids: $ids) { name } }`, "", `{"ids":["1","2"]}`, 3)
+	})
+	t.Run("missing variable counts as max multiplier", func(t *testing.T) {
+		run(`query q($first: Int) { users(first: $first) { name } }`, "", "", math.MaxInt32)
+	})
+	t.Run("invalid variable counts as max multiplier", func(t *testing.T) {
+		run(`query q($first: Int) { users(first: $first) { name } }`, "", `{"first":"1000000"}`, math.MaxInt32)
+	})
+	t.Run("fragments", func(t *testing.T) {
+		run(`query q { users(first: 10) { ...userFields } } fragment userFields on User { name ... on User { email } }`, "", "", 2+10*2)
+	})
+	t.Run("cyclic fragments", func(t *testing.T) {
+		run(`query q { viewer { ...first } } fragment first on User { ...second } fragment second on User { name ...first }`, "", "", 2)
+	})
+	t.Run("mutation", func(t *testing.T) {
+		run(`mutation m { createUser(name: "foo") { name } }`, "", "", 51)
+	})
+	t.Run("select operation by name", func(t *testing.T) {
+		run(`query first { viewer { name } } query second { search(term: "foo") }`, "second", "", 10)
+	})
+	t.Run("saturate on overflow", func(t *testing.T) {
+		run(`query q { users(first: 2000000000) { friends(first: 2000000000) { name } } }`, "", "", math.MaxInt32)
+	})
+}
+
+func TestCalculator_Cost_Errors(t *testing.T) {
+	p := parser.NewParser()
+	err := p.ParseTypeSystemDefinition([]byte(costSchema))
+	if err != nil {
+		panic(err)
+	}
+	err = p.ParseExecutableDefinition([]byte(`query first { viewer { name } } query second { viewer { name } }`))
+	if err != nil {
+		panic(err)
+	}
+
+	c := New()
+	c.SetInput(lookup.New(p))
+
+	if _, err := c.Cost(""); err == nil {
+		t.Fatal("want err for missing operation name")
+	}
+	if _, err := c.Cost("third"); err == nil {
+		t.Fatal("want err for unknown operation")
+	}
+}
+
+func TestMaxCost(t *testing.T) {
+
+	run := func(executable string, maxCost int, wantValid bool) {
+		p := parser.NewParser()
+		err := p.ParseTypeSystemDefinition([]byte(costSchema))
+		if err != nil {
+			panic(err)
+		}
+
+		err = p.ParseExecutableDefinition([]byte(executable))
+		if err != nil {
+			panic(err)
+		}
+
+		l := lookup.New(p)
+		w := lookup.NewWalker(512, 8)
+		w.SetLookup(l)
+		w.WalkExecutable()
+
+		result := MaxCost(maxCost)(l, w)
+		if wantValid != result.Valid {
+			panic(fmt.Errorf("want valid: %t, got: %+v", wantValid, result))
+		}
+	}
+
+	t.Run("within limit", func(t *testing.T) {
+		run(`query q { users(first: 10) { name } }`, 12, true)
+	})
+	t.Run("exceeds limit", func(t *testing.T) {
+		run(`query q { users(first: 10) { name } }`, 11, false)
+	})
+	t.Run("any operation exceeding the limit", func(t *testing.T) {
+		run(`query cheap { viewer { name } } query expensive { users(first: 100) { name } }`, 50, false)
+	})
+	t.Run("variable default value", func(t *testing.T) {
+		run(`query q($first: Int = 100) { users(first: $first) { name } }`, 50, false)
+	})
+	t.Run("variable without default value", func(t *testing.T) {
+		run(`query q($first: Int) { users(first: $first) { name } }`, 1000, false)
+	})
+}
+
+func TestError_Response(t *testing.T) {
+	want := `{"errors":[{"message":"query cost exceeds the max cost","extensions":{"code":"MAX_COST_EXCEEDED"}}]}`
+	if got := string(ErrMaxCostExceeded.Response()); got != want {
+		t.Fatalf("want: %s, got: %s", want, got)
+	}
+	if got := ErrMaxCostExceeded.StatusCode(); got != 400 {
+		t.Fatalf("want status 400, got: %d", got)
+	}
+}
+
+const costSchema = `
+schema {
+	query: Query
+	mutation: Mutation
+}
+
+type Query {
+	viewer: User
+	users(first: Int, last: Int): [User] @cost(weight: 2, multipliers: ["first", "last"])
+	usersByIds(ids: [ID!]!): [User] @cost(multipliers: ["ids"])
+	search(term: String!): [String] @cost(weight: 10)
+}
+
+type Mutation {
+	createUser(name: String!): User @cost(weight: 50)
+}
+
+type User {
+	name: String
+	email: String
+	friends(first: Int): [User] @cost(multipliers: ["first"])
+}
+
+scalar ID
+scalar Int
+scalar String
+` + DirectiveDefinition
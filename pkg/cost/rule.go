@@ -0,0 +1,54 @@
+package cost
+
+import (
+	"net/http"
+
+	"github.com/jensneuse/graphql-go-tools/pkg/graphqlerror"
+	"github.com/jensneuse/graphql-go-tools/pkg/lookup"
+	"github.com/jensneuse/graphql-go-tools/pkg/validation"
+	"github.com/jensneuse/graphql-go-tools/pkg/validation/rules"
+)
+
+// MaxCost is a validation rule which rejects all operations with a cost greater than maxCost
+// rules don't have access to the request variables, multiplier arguments using variables resolve to the variables default value
+// or the worst case (MaxMultiplier) if there's none, use a Calculator with SetVariables to compute the exact cost of a request,
+// e.g. the MaxCostMiddleware of package middleware
+func MaxCost(maxCost int) rules.Rule {
+	return func(l *lookup.Lookup, w *lookup.Walker) validation.Result {
+
+		calculator := New()
+		calculator.SetInput(l)
+
+		for _, operation := range l.OperationDefinitions() {
+			if calculator.OperationCost(operation) > maxCost {
+				return validation.Invalid(validation.MaxCost, validation.MaxCostExceeded, operation.Position, operation.Name)
+			}
+		}
+
+		return validation.Valid()
+	}
+}
+
+// Error is an error which is answered with a graphql response
+type Error struct {
+	Message string
+	Code    string
+	Status  int
+}
+
+// ErrMaxCostExceeded rejects requests with a cost greater than the max cost
+var ErrMaxCostExceeded = Error{Message: "query cost exceeds the max cost", Code: "MAX_COST_EXCEEDED", Status: http.StatusBadRequest}
+
+func (e Error) Error() string {
+	return "cost: " + e.Message
+}
+
+func (e Error) StatusCode() int {
+	return e.Status
+}
+
+// Response returns the graphql response for the error, e.g.:
+// {"errors":[{"message":"query cost exceeds the max cost","extensions":{"code":"MAX_COST_EXCEEDED"}}]}
+func (e Error) Response() []byte {
+	return graphqlerror.Response(graphqlerror.Error{Message: e.Message, Extensions: graphqlerror.Extensions{Code: e.Code}})
+}
@@ -0,0 +1,279 @@
+// Package deprecation reports the usage of deprecated schema members by operations
+//
+// Fields, arguments, input fields and enum values are deprecated using the @deprecated directive.
+// Enum values and input fields can only be detected if they're part of the executable definition,
+// values provided by variables are not taken into account.
+package deprecation
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/jensneuse/graphql-go-tools/pkg/document"
+	"github.com/jensneuse/graphql-go-tools/pkg/lexing/position"
+	"github.com/jensneuse/graphql-go-tools/pkg/lookup"
+	"github.com/jensneuse/graphql-go-tools/pkg/transform"
+)
+
+// DefaultReason is the reason used if the @deprecated directive doesn't define one
+const DefaultReason = "No longer supported"
+
+var (
+	deprecatedDirectiveName = []byte("deprecated")
+	reasonArgumentName      = []byte("reason")
+)
+
+// Kind is the kind of a deprecated schema member
+type Kind int
+
+const (
+	KindField Kind = iota
+	KindArgument
+	KindInputField
+	KindEnumValue
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindField:
+		return "Field"
+	case KindArgument:
+		return "Argument"
+	case KindInputField:
+		return "InputField"
+	case KindEnumValue:
+		return "EnumValue"
+	default:
+		return fmt.Sprintf("Kind(%d)", int(k))
+	}
+}
+
+// Usage is the usage of a deprecated schema member inside an operation
+type Usage struct {
+	// OperationName is the name of the operation using the deprecated member, empty for anonymous operations
+	OperationName string
+	Kind          Kind
+	// Coordinate identifies the deprecated member, e.g. 'Query.users', 'Query.users(first:)', 'UserFilter.age' or 'Role.ADMIN'
+	Coordinate string
+	// Reason is the deprecation reason from the schema
+	Reason string
+	// Position is the position of the usage inside the executable definition
+	Position position.Position
+	// NameRef is the name of the field, argument, input field or enum value inside the executable definition
+	NameRef document.ByteSliceReference
+}
+
+// Reporter collects all usages of deprecated schema members
+type Reporter struct {
+	l             *lookup.Lookup
+	usages        []Usage
+	operationName string
+	fragments     []document.ByteSliceReference
+}
+
+func New() *Reporter {
+	return &Reporter{
+		fragments: make([]document.ByteSliceReference, 0, 8),
+	}
+}
+
+func (r *Reporter) SetInput(l *lookup.Lookup) {
+	r.l = l
+}
+
+// Report returns the usages of deprecated members of all operations in order of appearance
+// fragments are reported for each operation they're used in
+func (r *Reporter) Report() []Usage {
+	r.usages = nil
+	for _, operation := range r.l.OperationDefinitions() {
+		r.reportOperation(operation)
+	}
+	return r.usages
+}
+
+// OperationReport returns the usages of deprecated members of a single operation in order of appearance
+func (r *Reporter) OperationReport(operation document.OperationDefinition) []Usage {
+	r.usages = nil
+	r.reportOperation(operation)
+	return r.usages
+}
+
+func (r *Reporter) reportOperation(operation document.OperationDefinition) {
+
+	r.operationName = string(r.l.ByteSlice(operation.Name))
+	r.fragments = r.fragments[:0]
+
+	var rootType document.ObjectTypeDefinition
+	var ok bool
+
+	switch operation.OperationType {
+	case document.OperationTypeMutation:
+		rootType, ok = r.l.MutationObjectTypeDefinition()
+	case document.OperationTypeSubscription:
+		rootType, ok = r.l.SubscriptionObjectTypeDefinition()
+	default:
+		rootType, ok = r.l.QueryObjectTypeDefinition()
+	}
+
+	if !ok {
+		return
+	}
+
+	r.selectionSet(operation.SelectionSet, rootType.Name)
+}
+
+func (r *Reporter) selectionSet(ref int, typeName document.ByteSliceReference) {
+
+	set := r.l.SelectionSet(ref)
+
+	for _, fieldRef := range set.Fields {
+		r.field(r.l.Field(fieldRef), typeName)
+	}
+
+	for _, inlineFragmentRef := range set.InlineFragments {
+		inlineFragment := r.l.InlineFragment(inlineFragmentRef)
+		fragmentTypeName := typeName
+		if inlineFragment.TypeCondition != -1 {
+			fragmentTypeName = r.l.Type(inlineFragment.TypeCondition).Name
+		}
+		r.selectionSet(inlineFragment.SelectionSet, fragmentTypeName)
+	}
+
+	for _, spreadRef := range set.FragmentSpreads {
+		spread := r.l.FragmentSpread(spreadRef)
+		if r.l.ByteSliceReferencesContainName(r.fragments, spread.FragmentName) {
+			continue
+		}
+		fragment, _, ok := r.l.FragmentDefinitionByName(spread.FragmentName)
+		if !ok {
+			continue
+		}
+		r.fragments = append(r.fragments, spread.FragmentName)
+		r.selectionSet(fragment.SelectionSet, r.l.Type(fragment.TypeCondition).Name)
+		r.fragments = r.fragments[:len(r.fragments)-1]
+	}
+}
+
+func (r *Reporter) field(field document.Field, typeName document.ByteSliceReference) {
+
+	definition, ok := r.l.FieldDefinitionByNameFromDefinitions(r.l.FieldsDefinitionFromNamedType(typeName), field.Name)
+	if !ok {
+		return
+	}
+
+	fieldCoordinate := string(r.l.ByteSlice(typeName)) + "." + string(r.l.ByteSlice(definition.Name))
+
+	if reason, deprecated := r.deprecationReason(definition.DirectiveSet); deprecated {
+		r.report(KindField, fieldCoordinate, reason, field.Position, field.Name)
+	}
+
+	args := r.l.ArgumentsIterable(r.l.ArgumentSet(field.ArgumentSet))
+	for args.Next() {
+		arg, _ := args.Value()
+		argumentDefinition, ok := r.l.InputValueDefinitionByNameFromDefinitions(arg.Name, r.l.ArgumentsDefinition(definition.ArgumentsDefinition).InputValueDefinitions)
+		if !ok {
+			continue
+		}
+		if reason, deprecated := r.deprecationReason(argumentDefinition.DirectiveSet); deprecated {
+			r.report(KindArgument, fieldCoordinate+"("+string(r.l.ByteSlice(arg.Name))+":)", reason, arg.Position, arg.Name)
+		}
+		r.value(arg.Value, r.l.Type(argumentDefinition.Type))
+	}
+
+	if field.SelectionSet != -1 {
+		r.selectionSet(field.SelectionSet, r.l.UnwrappedNamedType(r.l.Type(definition.Type)).Name)
+	}
+}
+
+func (r *Reporter) value(ref int, valueType document.Type) {
+
+	value := r.l.Value(ref)
+	namedType := r.l.UnwrappedNamedType(valueType)
+
+	switch value.ValueType {
+	case document.ValueTypeList:
+		itemType := valueType
+		for itemType.Kind == document.TypeKindNON_NULL {
+			itemType = r.l.Type(itemType.OfType)
+		}
+		if itemType.Kind == document.TypeKindLIST {
+			itemType = r.l.Type(itemType.OfType)
+		}
+		for _, item := range r.l.ListValue(value.Reference) {
+			r.value(item, itemType)
+		}
+	case document.ValueTypeEnum:
+		enumTypeDefinition, ok := r.l.EnumTypeDefinitionByName(namedType.Name)
+		if !ok {
+			return
+		}
+		enumValue := r.l.ByteSliceReference(value.Reference)
+		enumValues := enumTypeDefinition.EnumValuesDefinition
+		for enumValues.Next(r.l) {
+			enumValueDefinition, _ := enumValues.Value()
+			if !r.l.ByteSliceReferenceContentsEquals(enumValueDefinition.EnumValue, enumValue) {
+				continue
+			}
+			if reason, deprecated := r.deprecationReason(enumValueDefinition.DirectiveSet); deprecated {
+				r.report(KindEnumValue, string(r.l.ByteSlice(enumTypeDefinition.Name))+"."+string(r.l.ByteSlice(enumValue)), reason, value.Position, enumValue)
+			}
+			return
+		}
+	case document.ValueTypeObject:
+		inputObjectTypeDefinition, ok := r.l.InputObjectTypeDefinitionByName(namedType.Name)
+		if !ok {
+			return
+		}
+		fields := r.l.ObjectFieldsIterator(r.l.ObjectValue(value.Reference))
+		for fields.Next() {
+			field, _ := fields.Value()
+			fieldDefinition, ok := r.l.InputValueDefinitionByNameFromDefinitions(field.Name, r.l.InputFieldsDefinition(inputObjectTypeDefinition.InputFieldsDefinition).InputValueDefinitions)
+			if !ok {
+				continue
+			}
+			if reason, deprecated := r.deprecationReason(fieldDefinition.DirectiveSet); deprecated {
+				r.report(KindInputField, string(r.l.ByteSlice(inputObjectTypeDefinition.Name))+"."+string(r.l.ByteSlice(field.Name)), reason, field.Position, field.Name)
+			}
+			r.value(field.Value, r.l.Type(fieldDefinition.Type))
+		}
+	}
+}
+
+// deprecationReason returns the reason of the @deprecated directive inside the directive set
+func (r *Reporter) deprecationReason(directiveSet int) (reason string, deprecated bool) {
+
+	directives := r.l.DirectiveIterable(r.l.DirectiveSet(directiveSet))
+	for directives.Next() {
+		directive, _ := directives.Value()
+		if !bytes.Equal(r.l.ByteSlice(directive.Name), deprecatedDirectiveName) {
+			continue
+		}
+
+		args := r.l.ArgumentsIterable(r.l.ArgumentSet(directive.ArgumentSet))
+		for args.Next() {
+			arg, _ := args.Value()
+			if !bytes.Equal(r.l.ByteSlice(arg.Name), reasonArgumentName) {
+				continue
+			}
+			value := r.l.Value(arg.Value)
+			if value.ValueType == document.ValueTypeString {
+				return string(transform.UnescapeString(r.l.ByteSlice(value.Raw))), true
+			}
+		}
+
+		return DefaultReason, true
+	}
+
+	return "", false
+}
+
+func (r *Reporter) report(kind Kind, coordinate, reason string, position position.Position, nameRef document.ByteSliceReference) {
+	r.usages = append(r.usages, Usage{
+		OperationName: r.operationName,
+		Kind:          kind,
+		Coordinate:    coordinate,
+		Reason:        reason,
+		Position:      position,
+		NameRef:       nameRef,
+	})
+}
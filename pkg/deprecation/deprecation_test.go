@@ -0,0 +1,160 @@
+package deprecation
+
+import (
+	"fmt"
+	"github.com/jensneuse/graphql-go-tools/pkg/lookup"
+	"github.com/jensneuse/graphql-go-tools/pkg/parser"
+	"strings"
+	"testing"
+)
+
+func TestReporter_Report(t *testing.T) {
+
+	run := func(executable string, wantUsages ...string) {
+		p := parser.NewParser()
+		err := p.ParseTypeSystemDefinition([]byte(deprecationSchema))
+		if err != nil {
+			panic(err)
+		}
+
+		err = p.ParseExecutableDefinition([]byte(executable))
+		if err != nil {
+			panic(err)
+		}
+
+		r := New()
+		r.SetInput(lookup.New(p))
+
+		usages := r.Report()
+		got := make([]string, len(usages))
+		for i, usage := range usages {
+			got[i] = fmt.Sprintf("%s %s %s: %s", usage.OperationName, usage.Kind, usage.Coordinate, usage.Reason)
+		}
+
+		if strings.Join(wantUsages, "\n") != strings.Join(got, "\n") {
+			panic(fmt.Errorf("want usages:\n%s\ngot:\n%s", strings.Join(wantUsages, "\n"), strings.Join(got, "\n")))
+		}
+	}
+
+	t.Run("no deprecated usage", func(t *testing.T) {
+		run(`query q { users(first: 1) { name } }`)
+	})
+	t.Run("field", func(t *testing.T) {
+		run(`query q { users { name fullName } }`,
+			"q Field User.fullName: use name")
+	})
+	t.Run("default reason", func(t *testing.T) {
+		run(`query q { oldUsers { name } }`,
+			"q Field Query.oldUsers: No longer supported")
+	})
+	t.Run("argument", func(t *testing.T) {
+		run(`query q { users(limit: 10) { name } }`,
+			"q Argument Query.users(limit:): use first")
+	})
+	t.Run("enum value", func(t *testing.T) {
+		run(`query q { users(roles: [ADMIN, ROOT]) { name } }`,
+			"q EnumValue Role.ROOT: use ADMIN")
+	})
+	t.Run("input field and nested enum value", func(t *testing.T) {
+		run(`query q { users(filter: {age: 18, role: ROOT}) { name } }`,
+			"q InputField UserFilter.age: use minAge",
+			"q EnumValue Role.ROOT: use ADMIN")
+	})
+	t.Run("fragments", func(t *testing.T) {
+		run(`query q { users { ...userFields ... on User { fullName } } } fragment userFields on User { fullName }`,
+			"q Field User.fullName: use name",
+			"q Field User.fullName: use name")
+	})
+	t.Run("per operation", func(t *testing.T) {
+		run(`query first { oldUsers { name } } query second { users { fullName } } mutation third { deleteUser(hard: true) }`,
+			"first Field Query.oldUsers: No longer supported",
+			"second Field User.fullName: use name",
+			"third Argument Mutation.deleteUser(hard:): \"hard\" deletes are no longer allowed")
+	})
+}
+
+func TestNoDeprecatedUsage(t *testing.T) {
+
+	run := func(executable string, wantValid bool, wantDescription string) {
+		p := parser.NewParser()
+		err := p.ParseTypeSystemDefinition([]byte(deprecationSchema))
+		if err != nil {
+			panic(err)
+		}
+
+		err = p.ParseExecutableDefinition([]byte(executable))
+		if err != nil {
+			panic(err)
+		}
+
+		l := lookup.New(p)
+		w := lookup.NewWalker(512, 8)
+		w.SetLookup(l)
+		w.WalkExecutable()
+
+		result := NoDeprecatedUsage()(l, w)
+		if wantValid != result.Valid {
+			panic(fmt.Errorf("want valid: %t, got: %+v", wantValid, result))
+		}
+		if !wantValid && wantDescription != result.Description.String() {
+			panic(fmt.Errorf("want description: %s, got: %s", wantDescription, result.Description))
+		}
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		run(`query q { users(first: 1, roles: [ADMIN]) { name } }`, true, "")
+	})
+	t.Run("field", func(t *testing.T) {
+		run(`query q { users { fullName } }`, false, "DeprecatedFieldUsed")
+	})
+	t.Run("argument", func(t *testing.T) {
+		run(`query q { users(limit: 1) { name } }`, false, "DeprecatedArgumentUsed")
+	})
+	t.Run("input field", func(t *testing.T) {
+		run(`query q { users(filter: {age: 1}) { name } }`, false, "DeprecatedInputFieldUsed")
+	})
+	t.Run("enum value", func(t *testing.T) {
+		run(`query q { users(roles: ROOT) { name } }`, false, "DeprecatedEnumValueUsed")
+	})
+}
+
+const deprecationSchema = `
+schema {
+	query: Query
+	mutation: Mutation
+}
+
+type Query {
+	users(first: Int, limit: Int @deprecated(reason: "use first"), roles: [Role!], filter: UserFilter): [User]
+	oldUsers: [User] @deprecated
+}
+
+type Mutation {
+	deleteUser(hard: Boolean @deprecated(reason: "\"hard\" deletes are no longer allowed")): Boolean
+}
+
+type User {
+	name: String
+	fullName: String @deprecated(reason: "use name")
+}
+
+input UserFilter {
+	minAge: Int
+	age: Int @deprecated(reason: "use minAge")
+	role: Role
+}
+
+enum Role {
+	USER
+	ADMIN
+	ROOT @deprecated(reason: "use ADMIN")
+}
+
+scalar Int
+scalar String
+scalar Boolean
+
+directive @deprecated(
+	reason: String = "No longer supported"
+) on FIELD_DEFINITION | ARGUMENT_DEFINITION | INPUT_FIELD_DEFINITION | ENUM_VALUE
+`
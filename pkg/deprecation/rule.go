@@ -0,0 +1,39 @@
+package deprecation
+
+import (
+	"github.com/jensneuse/graphql-go-tools/pkg/lookup"
+	"github.com/jensneuse/graphql-go-tools/pkg/validation"
+	"github.com/jensneuse/graphql-go-tools/pkg/validation/rules"
+)
+
+// NoDeprecatedUsage is an optional validation rule which flags the first usage of a deprecated
+// field, argument, input field or enum value
+// register it with validator.SeverityWarning to only report deprecated usages or with validator.SeverityError to reject them
+func NoDeprecatedUsage() rules.Rule {
+	return func(l *lookup.Lookup, w *lookup.Walker) validation.Result {
+
+		reporter := New()
+		reporter.SetInput(l)
+
+		usages := reporter.Report()
+		if len(usages) == 0 {
+			return validation.Valid()
+		}
+
+		usage := usages[0]
+		return validation.Invalid(validation.NoDeprecatedUsage, usage.Kind.description(), usage.Position, usage.NameRef)
+	}
+}
+
+func (k Kind) description() validation.Description {
+	switch k {
+	case KindArgument:
+		return validation.DeprecatedArgumentUsed
+	case KindInputField:
+		return validation.DeprecatedInputFieldUsed
+	case KindEnumValue:
+		return validation.DeprecatedEnumValueUsed
+	default:
+		return validation.DeprecatedFieldUsed
+	}
+}
@@ -9,6 +9,7 @@ type DirectiveDefinition struct {
 	Name                ByteSliceReference
 	ArgumentsDefinition int
 	DirectiveLocations  []int
+	IsRepeatable        bool
 	Position            position.Position
 	IsExtend            bool
 }
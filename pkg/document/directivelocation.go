@@ -29,6 +29,7 @@ ENUM
 ENUM_VALUE
 INPUT_OBJECT
 INPUT_FIELD_DEFINITION
+VARIABLE_DEFINITION
 )
 */
 type DirectiveLocation int
@@ -46,9 +46,11 @@ const (
 	DirectiveLocationINPUT_OBJECT
 	// DirectiveLocationINPUT_FIELD_DEFINITION is a DirectiveLocation of type INPUT_FIELD_DEFINITION
 	DirectiveLocationINPUT_FIELD_DEFINITION
+	// DirectiveLocationVARIABLE_DEFINITION is a DirectiveLocation of type VARIABLE_DEFINITION
+	DirectiveLocationVARIABLE_DEFINITION
 )
 
-const _DirectiveLocationName = "UNKNOWNQUERYMUTATIONSUBSCRIPTIONFIELDFRAGMENT_DEFINITIONFRAGMENT_SPREADINLINE_FRAGMENTSCHEMASCALAROBJECTFIELD_DEFINITIONARGUMENT_DEFINITIONINTERFACEUNIONENUMENUM_VALUEINPUT_OBJECTINPUT_FIELD_DEFINITION"
+const _DirectiveLocationName = "UNKNOWNQUERYMUTATIONSUBSCRIPTIONFIELDFRAGMENT_DEFINITIONFRAGMENT_SPREADINLINE_FRAGMENTSCHEMASCALAROBJECTFIELD_DEFINITIONARGUMENT_DEFINITIONINTERFACEUNIONENUMENUM_VALUEINPUT_OBJECTINPUT_FIELD_DEFINITIONVARIABLE_DEFINITION"
 
 var _DirectiveLocationMap = map[DirectiveLocation]string{
 	0:  _DirectiveLocationName[0:7],
@@ -70,6 +72,7 @@ var _DirectiveLocationMap = map[DirectiveLocation]string{
 	16: _DirectiveLocationName[157:167],
 	17: _DirectiveLocationName[167:179],
 	18: _DirectiveLocationName[179:201],
+	19: _DirectiveLocationName[201:220],
 }
 
 // String implements the Stringer interface.
@@ -100,6 +103,7 @@ var _DirectiveLocationValue = map[string]DirectiveLocation{
 	_DirectiveLocationName[157:167]: 16,
 	_DirectiveLocationName[167:179]: 17,
 	_DirectiveLocationName[179:201]: 18,
+	_DirectiveLocationName[201:220]: 19,
 }
 
 // ParseDirectiveLocation attempts to convert a string to a DirectiveLocation
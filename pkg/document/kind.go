@@ -6,7 +6,7 @@ type Kind string
 
 // ... various kind types
 const (
-	KindObject      Kind = "KIND"
+	KindObject      Kind = "OBJECT"
 	KindScalar      Kind = "SCALAR"
 	KindInterface   Kind = "INTERFACE"
 	KindUnion       Kind = "UNION"
@@ -0,0 +1,39 @@
+// Package graphqlerror implements the errors of graphql responses, e.g. to answer requests rejected by the proxy
+package graphqlerror
+
+import "encoding/json"
+
+// Error is an error in the shape of a graphql response error
+// See: https://facebook.github.io/graphql/draft/#sec-Errors
+type Error struct {
+	Message   string     `json:"message"`
+	Locations []Location `json:"locations,omitempty"`
+	// Path are the response keys (and list indices) from the operation to the field of the error
+	Path       []interface{} `json:"path,omitempty"`
+	Extensions Extensions    `json:"extensions"`
+}
+
+// Location points to the position of the error inside the request
+type Location struct {
+	Line   uint32 `json:"line"`
+	Column uint32 `json:"column"`
+}
+
+// Extensions are the additional information of an Error
+type Extensions struct {
+	// Code tells clients the kind of the error, e.g. BAD_USER_INPUT
+	Code string `json:"code"`
+	// RetryAfter (optional) is the number of seconds after which a rejected request might be retried
+	RetryAfter int `json:"retryAfter,omitempty"`
+}
+
+// Response returns the graphql response for the errors, e.g.:
+// {"errors":[{"message":"rate limit exceeded","extensions":{"code":"RATE_LIMITED","retryAfter":2}}]}
+func Response(errors ...Error) []byte {
+	response, _ := json.Marshal(struct {
+		Errors []Error `json:"errors"`
+	}{
+		Errors: errors,
+	})
+	return response
+}
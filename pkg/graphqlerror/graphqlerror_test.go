@@ -0,0 +1,30 @@
+package graphqlerror
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestResponse(t *testing.T) {
+
+	run := func(errors []Error, want string) {
+		if got := string(Response(errors...)); got != want {
+			panic(fmt.Errorf("want: %s, got: %s", want, got))
+		}
+	}
+
+	t.Run("code", func(t *testing.T) {
+		run([]Error{{Message: "query is not allowed", Extensions: Extensions{Code: "PERSISTED_QUERY_NOT_ALLOWED"}}},
+			`{"errors":[{"message":"query is not allowed","extensions":{"code":"PERSISTED_QUERY_NOT_ALLOWED"}}]}`)
+	})
+	t.Run("retry after", func(t *testing.T) {
+		run([]Error{{Message: "rate limit exceeded", Extensions: Extensions{Code: "RATE_LIMITED", RetryAfter: 2}}},
+			`{"errors":[{"message":"rate limit exceeded","extensions":{"code":"RATE_LIMITED","retryAfter":2}}]}`)
+	})
+	t.Run("locations and path", func(t *testing.T) {
+		run([]Error{
+			{Message: "a", Locations: []Location{{Line: 1, Column: 9}}, Extensions: Extensions{Code: "BAD_USER_INPUT"}},
+			{Message: "b", Path: []interface{}{"documents", 0, "owner"}, Extensions: Extensions{Code: "FORBIDDEN"}},
+		}, `{"errors":[{"message":"a","locations":[{"line":1,"column":9}],"extensions":{"code":"BAD_USER_INPUT"}},{"message":"b","path":["documents",0,"owner"],"extensions":{"code":"FORBIDDEN"}}]}`)
+	})
+}
@@ -0,0 +1,220 @@
+package introspection
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// Version is the version of the graphql specification an introspection response gets encoded for
+type Version int
+
+const (
+	// VersionJune2018 encodes the response as specified in:
+	// http://spec.graphql.org/June2018/#sec-Schema-Introspection
+	// specifiedByURL, isRepeatable as well as the deprecation of arguments and input fields are omitted
+	VersionJune2018 Version = iota + 1
+	// VersionOctober2021 encodes the response as specified in:
+	// http://spec.graphql.org/October2021/#sec-Schema-Introspection
+	// this is the encoding of json.Marshal
+	VersionOctober2021
+)
+
+// Encode writes the response as json encoded for the version of the specification
+func Encode(w io.Writer, response Response, version Version) error {
+	switch version {
+	case VersionJune2018:
+		return json.NewEncoder(w).Encode(newJune2018Response(response))
+	case VersionOctober2021:
+		return json.NewEncoder(w).Encode(response)
+	default:
+		return fmt.Errorf("Encode: unsupported version %d", version)
+	}
+}
+
+// Decode reads an introspection response of any version
+// fields missing in older versions keep their zero value,
+// the misspelled 'depreciacionReason' written by previous versions of this package is accepted as well
+// responses without the data envelope, e.g. {"__schema": {...}}, are supported too
+func Decode(r io.Reader, response *Response) error {
+
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(data, response)
+	if err != nil {
+		return err
+	}
+
+	if response.Data.Schema.QueryType != nil || len(response.Data.Schema.Types) != 0 {
+		return nil
+	}
+
+	return json.Unmarshal(data, &response.Data)
+}
+
+// UnmarshalJSON accepts the misspelled 'depreciacionReason' written by previous versions of this package
+func (f *Field) UnmarshalJSON(data []byte) error {
+	type field Field
+	decoded := struct {
+		*field
+		LegacyDeprecationReason *string `json:"depreciacionReason"`
+	}{
+		field: (*field)(f),
+	}
+
+	err := json.Unmarshal(data, &decoded)
+	if err != nil {
+		return err
+	}
+
+	if f.DeprecationReason == "" && decoded.LegacyDeprecationReason != nil {
+		f.DeprecationReason = *decoded.LegacyDeprecationReason
+	}
+
+	return nil
+}
+
+// UnmarshalJSON accepts the misspelled 'depreciacionReason' written by previous versions of this package
+func (e *EnumValue) UnmarshalJSON(data []byte) error {
+	type enumValue EnumValue
+	decoded := struct {
+		*enumValue
+		LegacyDeprecationReason *string `json:"depreciacionReason"`
+	}{
+		enumValue: (*enumValue)(e),
+	}
+
+	err := json.Unmarshal(data, &decoded)
+	if err != nil {
+		return err
+	}
+
+	if e.DeprecationReason == "" && decoded.LegacyDeprecationReason != nil {
+		e.DeprecationReason = *decoded.LegacyDeprecationReason
+	}
+
+	return nil
+}
+
+// the june2018 types mirror the model without the fields added by the October 2021 specification
+
+type june2018Response struct {
+	Data struct {
+		Schema june2018Schema `json:"__schema"`
+	} `json:"data"`
+}
+
+type june2018Schema struct {
+	QueryType        *TypeName           `json:"queryType"`
+	MutationType     *TypeName           `json:"mutationType"`
+	SubscriptionType *TypeName           `json:"subscriptionType"`
+	Types            []june2018FullType  `json:"types"`
+	Directives       []june2018Directive `json:"directives"`
+}
+
+type june2018FullType struct {
+	Kind          __TypeKind           `json:"kind"`
+	Name          string               `json:"name"`
+	Description   string               `json:"description"`
+	Fields        []june2018Field      `json:"fields"`
+	InputFields   []june2018InputValue `json:"inputFields"`
+	Interfaces    []TypeRef            `json:"interfaces"`
+	EnumValues    []EnumValue          `json:"enumValues"`
+	PossibleTypes []TypeRef            `json:"possibleTypes"`
+}
+
+type june2018Field struct {
+	Name              string               `json:"name"`
+	Description       string               `json:"description"`
+	Args              []june2018InputValue `json:"args"`
+	Type              TypeRef              `json:"type"`
+	IsDeprecated      bool                 `json:"isDeprecated"`
+	DeprecationReason string               `json:"deprecationReason"`
+}
+
+type june2018InputValue struct {
+	Name         string  `json:"name"`
+	Description  string  `json:"description"`
+	Type         TypeRef `json:"type"`
+	DefaultValue *string `json:"defaultValue"`
+}
+
+type june2018Directive struct {
+	Name        string               `json:"name"`
+	Description string               `json:"description"`
+	Locations   []string             `json:"locations"`
+	Args        []june2018InputValue `json:"args"`
+}
+
+func newJune2018Response(response Response) (out june2018Response) {
+
+	schema := response.Data.Schema
+	out.Data.Schema = june2018Schema{
+		QueryType:        schema.QueryType,
+		MutationType:     schema.MutationType,
+		SubscriptionType: schema.SubscriptionType,
+	}
+
+	if schema.Types != nil {
+		out.Data.Schema.Types = make([]june2018FullType, 0, len(schema.Types))
+	}
+	for _, fullType := range schema.Types {
+		june2018Type := june2018FullType{
+			Kind:          fullType.Kind,
+			Name:          fullType.Name,
+			Description:   fullType.Description,
+			InputFields:   newJune2018InputValues(fullType.InputFields),
+			Interfaces:    fullType.Interfaces,
+			EnumValues:    fullType.EnumValues,
+			PossibleTypes: fullType.PossibleTypes,
+		}
+		if fullType.Fields != nil {
+			june2018Type.Fields = make([]june2018Field, 0, len(fullType.Fields))
+		}
+		for _, field := range fullType.Fields {
+			june2018Type.Fields = append(june2018Type.Fields, june2018Field{
+				Name:              field.Name,
+				Description:       field.Description,
+				Args:              newJune2018InputValues(field.Args),
+				Type:              field.Type,
+				IsDeprecated:      field.IsDeprecated,
+				DeprecationReason: field.DeprecationReason,
+			})
+		}
+		out.Data.Schema.Types = append(out.Data.Schema.Types, june2018Type)
+	}
+
+	if schema.Directives != nil {
+		out.Data.Schema.Directives = make([]june2018Directive, 0, len(schema.Directives))
+	}
+	for _, directive := range schema.Directives {
+		out.Data.Schema.Directives = append(out.Data.Schema.Directives, june2018Directive{
+			Name:        directive.Name,
+			Description: directive.Description,
+			Locations:   directive.Locations,
+			Args:        newJune2018InputValues(directive.Args),
+		})
+	}
+
+	return out
+}
+
+func newJune2018InputValues(inputValues []InputValue) []june2018InputValue {
+	if inputValues == nil {
+		return nil
+	}
+	out := make([]june2018InputValue, 0, len(inputValues))
+	for _, inputValue := range inputValues {
+		out = append(out, june2018InputValue{
+			Name:         inputValue.Name,
+			Description:  inputValue.Description,
+			Type:         inputValue.Type,
+			DefaultValue: inputValue.DefaultValue,
+		})
+	}
+	return out
+}
@@ -0,0 +1,234 @@
+package introspection
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultFetchTimeout is the timeout applied by Fetch if the context has no deadline
+var DefaultFetchTimeout = 10 * time.Second
+
+// Query is the standard introspection query
+// it only selects fields defined by the June 2018 specification so that older backends are supported as well
+const Query = `query IntrospectionQuery {
+	__schema {
+		queryType {
+			name
+		}
+		mutationType {
+			name
+		}
+		subscriptionType {
+			name
+		}
+		types {
+			...FullType
+		}
+		directives {
+			name
+			description
+			locations
+			args {
+				...InputValue
+			}
+		}
+	}
+}
+
+fragment FullType on __Type {
+	kind
+	name
+	description
+	fields(includeDeprecated: true) {
+		name
+		description
+		args {
+			...InputValue
+		}
+		type {
+			...TypeRef
+		}
+		isDeprecated
+		deprecationReason
+	}
+	inputFields {
+		...InputValue
+	}
+	interfaces {
+		...TypeRef
+	}
+	enumValues(includeDeprecated: true) {
+		name
+		description
+		isDeprecated
+		deprecationReason
+	}
+	possibleTypes {
+		...TypeRef
+	}
+}
+
+fragment InputValue on __InputValue {
+	name
+	description
+	type {
+		...TypeRef
+	}
+	defaultValue
+}
+
+fragment TypeRef on __Type {
+	kind
+	name
+	ofType {
+		kind
+		name
+		ofType {
+			kind
+			name
+			ofType {
+				kind
+				name
+				ofType {
+					kind
+					name
+					ofType {
+						kind
+						name
+						ofType {
+							kind
+							name
+							ofType {
+								kind
+								name
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+}
+`
+
+// Fetch sends the introspection Query to a graphql backend and returns its schema in the schema definition language
+// the result can be used as the Schema of a proxy RequestConfig
+// headers are set on the request to the backend, e.g. to authenticate the proxy
+func Fetch(ctx context.Context, url string, headers http.Header) (schema []byte, err error) {
+
+	response, err := FetchResponse(ctx, url, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	buff := bytes.Buffer{}
+	err = WriteSchemaDefinition(&buff, &response.Data.Schema)
+	if err != nil {
+		return nil, err
+	}
+
+	return buff.Bytes(), nil
+}
+
+// FetchResponse sends the introspection Query to a graphql backend and returns the decoded response
+// the request is cancelled when the context is done, DefaultFetchTimeout is used if the context has no deadline
+// graphql errors, non 200 status codes and responses without a query type are returned as errors
+func FetchResponse(ctx context.Context, url string, headers http.Header) (*Response, error) {
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultFetchTimeout)
+		defer cancel()
+	}
+
+	requestBody, err := json.Marshal(struct {
+		OperationName string `json:"operationName"`
+		Query         string `json:"query"`
+	}{
+		OperationName: "IntrospectionQuery",
+		Query:         Query,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBody))
+	if err != nil {
+		return nil, err
+	}
+
+	for key, values := range headers {
+		for _, value := range values {
+			request.Header.Add(key, value)
+		}
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+
+	res, err := http.DefaultClient.Do(request.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("FetchResponse: %s", err)
+	}
+	defer res.Body.Close()
+
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("FetchResponse: %s", err)
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("FetchResponse: unexpected status code %d: %s", res.StatusCode, bytes.TrimSpace(data))
+	}
+
+	err = responseErrors(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var response Response
+	err = Decode(bytes.NewReader(data), &response)
+	if err != nil {
+		return nil, fmt.Errorf("FetchResponse: %s", err)
+	}
+
+	if response.Data.Schema.QueryType == nil {
+		return nil, fmt.Errorf("FetchResponse: response contains no schema")
+	}
+
+	return &response, nil
+}
+
+func responseErrors(data []byte) error {
+
+	var response struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+
+	err := json.Unmarshal(data, &response)
+	if err != nil {
+		return fmt.Errorf("FetchResponse: %s", err)
+	}
+
+	if len(response.Errors) == 0 {
+		return nil
+	}
+
+	messages := make([]string, 0, len(response.Errors))
+	for _, responseError := range response.Errors {
+		messages = append(messages, responseError.Message)
+	}
+
+	return fmt.Errorf("FetchResponse: backend responded with errors: %s", strings.Join(messages, ", "))
+}
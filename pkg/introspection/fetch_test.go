@@ -0,0 +1,139 @@
+package introspection
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFetch(t *testing.T) {
+
+	backend := func(status int, response string, delay time.Duration) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				t.Errorf("want method POST, got: %s", r.Method)
+			}
+			if r.Header.Get("Content-Type") != "application/json" {
+				t.Errorf("want content type application/json, got: %s", r.Header.Get("Content-Type"))
+			}
+			var request struct {
+				Query string `json:"query"`
+			}
+			err := json.NewDecoder(r.Body).Decode(&request)
+			if err != nil {
+				t.Error(err)
+			}
+			if !strings.HasPrefix(request.Query, "query IntrospectionQuery {") || !strings.Contains(request.Query, "__schema {") ||
+				!strings.Contains(request.Query, "fragment FullType on __Type {") {
+				t.Errorf("want introspection query, got: %s", request.Query)
+			}
+			if r.Header.Get("Authorization") != "Bearer token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			select {
+			case <-time.After(delay):
+			case <-r.Context().Done():
+				return
+			}
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(response))
+		}))
+	}
+
+	headers := http.Header{"Authorization": []string{"Bearer token"}}
+
+	t.Run("schema", func(t *testing.T) {
+		responseData, err := ioutil.ReadFile("./testdata/swapi_introspection_response.json")
+		if err != nil {
+			panic(err)
+		}
+
+		server := backend(http.StatusOK, string(responseData), 0)
+		defer server.Close()
+
+		schema, err := Fetch(context.Background(), server.URL, headers)
+		if err != nil {
+			panic(err)
+		}
+
+		var response Response
+		err = Decode(bytes.NewReader(responseData), &response)
+		if err != nil {
+			panic(err)
+		}
+
+		want := bytes.Buffer{}
+		err = WriteSchemaDefinition(&want, &response.Data.Schema)
+		if err != nil {
+			panic(err)
+		}
+
+		if !bytes.Equal(want.Bytes(), schema) {
+			panic(fmt.Errorf("want:\n%s\ngot:\n%s", want.String(), string(schema)))
+		}
+		if !bytes.Contains(schema, []byte("type Query {")) {
+			panic(fmt.Errorf("want schema to contain the query type, got:\n%s", string(schema)))
+		}
+	})
+	t.Run("response", func(t *testing.T) {
+		server := backend(http.StatusOK, `{"data":{"__schema":{"queryType":{"name":"Query"},"types":[{"kind":"OBJECT","name":"Query","fields":[{"name":"hello","args":[],"type":{"kind":"SCALAR","name":"String"}}]}]}}}`, 0)
+		defer server.Close()
+
+		response, err := FetchResponse(context.Background(), server.URL, headers)
+		if err != nil {
+			panic(err)
+		}
+
+		if response.Data.Schema.QueryType.Name != "Query" {
+			panic(fmt.Errorf("want query type Query, got: %s", response.Data.Schema.QueryType.Name))
+		}
+		if len(response.Data.Schema.Types) != 1 || response.Data.Schema.Types[0].Fields[0].Name != "hello" {
+			panic(fmt.Errorf("want type Query with field hello, got: %+v", response.Data.Schema.Types))
+		}
+	})
+
+	runErr := func(ctx context.Context, server *httptest.Server, headers http.Header, wantErr string) {
+		defer server.Close()
+		_, err := Fetch(ctx, server.URL, headers)
+		if err == nil {
+			panic("want err")
+		}
+		if !strings.Contains(err.Error(), wantErr) {
+			panic(fmt.Errorf("want err containing '%s', got: %s", wantErr, err))
+		}
+	}
+
+	t.Run("graphql errors", func(t *testing.T) {
+		runErr(context.Background(), backend(http.StatusOK, `{"errors":[{"message":"introspection disabled"}]}`, 0), headers, "introspection disabled")
+	})
+	t.Run("status code", func(t *testing.T) {
+		runErr(context.Background(), backend(http.StatusOK, "", 0), nil, "unexpected status code 401")
+	})
+	t.Run("no schema", func(t *testing.T) {
+		runErr(context.Background(), backend(http.StatusOK, `{"data":null}`, 0), headers, "no schema")
+	})
+	t.Run("invalid json", func(t *testing.T) {
+		runErr(context.Background(), backend(http.StatusOK, `<html></html>`, 0), headers, "FetchResponse")
+	})
+	t.Run("context timeout", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		runErr(ctx, backend(http.StatusOK, "{}", time.Second), headers, "context deadline exceeded")
+	})
+	t.Run("default timeout", func(t *testing.T) {
+		defaultFetchTimeout := DefaultFetchTimeout
+		DefaultFetchTimeout = 10 * time.Millisecond
+		defer func() {
+			DefaultFetchTimeout = defaultFetchTimeout
+		}()
+		runErr(context.Background(), backend(http.StatusOK, "{}", time.Second), headers, "context deadline exceeded")
+	})
+}
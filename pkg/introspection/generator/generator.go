@@ -0,0 +1,455 @@
+// Package generator generates the response of an introspection query from a parsed type system definition
+//
+// The schema must contain all types referenced by fields, arguments and input fields,
+// this includes the built-in scalars and directives, e.g. added by the ValidationMiddleware.
+// Types, directives, fields, arguments, input fields and enum values keep the order of their declaration.
+// Types and directives declared more than once are only contained once.
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/jensneuse/graphql-go-tools/pkg/deprecation"
+	"github.com/jensneuse/graphql-go-tools/pkg/document"
+	"github.com/jensneuse/graphql-go-tools/pkg/introspection"
+	"github.com/jensneuse/graphql-go-tools/pkg/lexing/literal"
+	"github.com/jensneuse/graphql-go-tools/pkg/lookup"
+	"github.com/jensneuse/graphql-go-tools/pkg/transform"
+)
+
+var (
+	deprecatedDirectiveName  = []byte("deprecated")
+	reasonArgumentName       = []byte("reason")
+	specifiedByDirectiveName = []byte("specifiedBy")
+	urlArgumentName          = []byte("url")
+	metaFieldPrefix          = []byte("__")
+)
+
+type declaredType struct {
+	name     document.ByteSliceReference
+	fullType introspection.FullType
+}
+
+// Generator generates an introspection response from the type system definition of a lookup
+type Generator struct {
+	l *lookup.Lookup
+}
+
+func New() *Generator {
+	return &Generator{}
+}
+
+func (g *Generator) SetInput(l *lookup.Lookup) {
+	g.l = l
+}
+
+// Generate populates the response with the schema of the type system definition
+func (g *Generator) Generate(response *introspection.Response) error {
+
+	schema := introspection.NewSchema()
+
+	err := g.rootOperationTypes(&schema)
+	if err != nil {
+		return err
+	}
+
+	var types []declaredType
+
+	for _, definition := range g.l.ScalarTypeDefinitions() {
+		fullType := g.fullType(definition.Name, definition.Description)
+		fullType.Kind = introspection.SCALAR
+		fullType.SpecifiedByURL = g.specifiedByURL(definition.DirectiveSet)
+		types = append(types, declaredType{definition.Name, fullType})
+	}
+
+	for _, definition := range g.l.ObjectTypeDefinitions() {
+		fullType := g.fullType(definition.Name, definition.Description)
+		fullType.Kind = introspection.OBJECT
+		fullType.Fields, err = g.fields(definition.FieldsDefinition)
+		if err != nil {
+			return err
+		}
+		fullType.Interfaces = make([]introspection.TypeRef, 0)
+		interfaces := definition.ImplementsInterfaces
+		for interfaces.Next(g.l) {
+			name, _ := interfaces.Value()
+			typeRef, err := g.namedTypeRef(name)
+			if err != nil {
+				return err
+			}
+			fullType.Interfaces = append([]introspection.TypeRef{typeRef}, fullType.Interfaces...)
+		}
+		types = append(types, declaredType{definition.Name, fullType})
+	}
+
+	for _, definition := range g.l.InterfaceTypeDefinitions() {
+		fullType := g.fullType(definition.Name, definition.Description)
+		fullType.Kind = introspection.INTERFACE
+		fullType.Fields, err = g.fields(definition.FieldsDefinition)
+		if err != nil {
+			return err
+		}
+		fullType.PossibleTypes = make([]introspection.TypeRef, 0)
+		for _, object := range g.l.ObjectTypeDefinitions() {
+			if g.l.ObjectTypeDefinitionImplementsInterface(object, definition.Name) {
+				fullType.PossibleTypes = append(fullType.PossibleTypes, g.typeRef(introspection.TypeRef{Kind: introspection.OBJECT}, object.Name))
+			}
+		}
+		types = append(types, declaredType{definition.Name, fullType})
+	}
+
+	for _, definition := range g.l.UnionTypeDefinitions() {
+		fullType := g.fullType(definition.Name, definition.Description)
+		fullType.Kind = introspection.UNION
+		fullType.PossibleTypes = make([]introspection.TypeRef, 0, len(definition.UnionMemberTypes))
+		for _, member := range definition.UnionMemberTypes {
+			typeRef, err := g.namedTypeRef(g.l.ByteSliceReference(member))
+			if err != nil {
+				return err
+			}
+			fullType.PossibleTypes = append(fullType.PossibleTypes, typeRef)
+		}
+		types = append(types, declaredType{definition.Name, fullType})
+	}
+
+	for _, definition := range g.l.EnumTypeDefinitions() {
+		fullType := g.fullType(definition.Name, definition.Description)
+		fullType.Kind = introspection.ENUM
+		fullType.EnumValues = make([]introspection.EnumValue, 0)
+		enumValues := definition.EnumValuesDefinition
+		for enumValues.Next(g.l) {
+			enumValueDefinition, _ := enumValues.Value()
+			enumValue := introspection.EnumValue{
+				Name:        string(g.l.ByteSlice(enumValueDefinition.EnumValue)),
+				Description: g.description(enumValueDefinition.Description),
+			}
+			enumValue.DeprecationReason, enumValue.IsDeprecated = g.deprecationReason(enumValueDefinition.DirectiveSet)
+			fullType.EnumValues = append([]introspection.EnumValue{enumValue}, fullType.EnumValues...)
+		}
+		types = append(types, declaredType{definition.Name, fullType})
+	}
+
+	for _, definition := range g.l.InputObjectTypeDefinitions() {
+		fullType := g.fullType(definition.Name, definition.Description)
+		fullType.Kind = introspection.INPUT_OBJECT
+		fullType.InputFields = make([]introspection.InputValue, 0)
+		if definition.InputFieldsDefinition != -1 {
+			fullType.InputFields, err = g.inputValues(g.l.InputFieldsDefinition(definition.InputFieldsDefinition).InputValueDefinitions)
+			if err != nil {
+				return err
+			}
+		}
+		types = append(types, declaredType{definition.Name, fullType})
+	}
+
+	// the type definitions are grouped by kind in the AST, the position of their names restores the order of declaration
+	sort.SliceStable(types, func(i, j int) bool {
+		return types[i].name.Start < types[j].name.Start
+	})
+	// types might be declared more than once, e.g. the built-in scalars added by several middlewares, the first declaration wins
+	typeNames := make(map[string]bool, len(types))
+	for i := range types {
+		if typeNames[types[i].fullType.Name] {
+			continue
+		}
+		typeNames[types[i].fullType.Name] = true
+		schema.Types = append(schema.Types, types[i].fullType)
+	}
+
+	directiveNames := make(map[string]bool)
+	for _, definition := range g.l.DirectiveDefinitions() {
+		directive := introspection.NewDirective()
+		directive.Name = string(g.l.ByteSlice(definition.Name))
+		if directiveNames[directive.Name] {
+			continue
+		}
+		directiveNames[directive.Name] = true
+		directive.Description = g.description(definition.Description)
+		directive.IsRepeatable = definition.IsRepeatable
+		for _, location := range definition.DirectiveLocations {
+			directive.Locations = append(directive.Locations, document.DirectiveLocation(location).String())
+		}
+		if definition.ArgumentsDefinition != -1 {
+			directive.Args, err = g.inputValues(g.l.ArgumentsDefinition(definition.ArgumentsDefinition).InputValueDefinitions)
+			if err != nil {
+				return err
+			}
+		}
+		schema.Directives = append(schema.Directives, directive)
+	}
+
+	response.Data.Schema = schema
+	return nil
+}
+
+// rootOperationTypes sets the root operation types from the schema definition
+// if the schema definition is omitted the types named Query, Mutation and Subscription are used
+func (g *Generator) rootOperationTypes(schema *introspection.Schema) error {
+
+	query, mutation, subscription := []byte("Query"), []byte("Mutation"), []byte("Subscription")
+
+	if definition, ok := g.l.SchemaDefinition(); ok {
+		query, mutation, subscription = g.l.ByteSlice(definition.Query), g.l.ByteSlice(definition.Mutation), g.l.ByteSlice(definition.Subscription)
+	}
+
+	schema.QueryType = g.rootOperationType(query)
+	schema.MutationType = g.rootOperationType(mutation)
+	schema.SubscriptionType = g.rootOperationType(subscription)
+
+	if schema.QueryType == nil {
+		return fmt.Errorf("Generate: schema must define a query type")
+	}
+
+	return nil
+}
+
+func (g *Generator) rootOperationType(name []byte) *introspection.TypeName {
+	if len(name) == 0 {
+		return nil
+	}
+	for _, definition := range g.l.ObjectTypeDefinitions() {
+		if bytes.Equal(g.l.ByteSlice(definition.Name), name) {
+			return &introspection.TypeName{
+				Name: string(name),
+			}
+		}
+	}
+	return nil
+}
+
+func (g *Generator) fullType(name, description document.ByteSliceReference) introspection.FullType {
+	return introspection.FullType{
+		Name:        string(g.l.ByteSlice(name)),
+		Description: g.description(description),
+	}
+}
+
+func (g *Generator) fields(definitions document.FieldDefinitions) ([]introspection.Field, error) {
+
+	fields := make([]introspection.Field, 0)
+
+	for definitions.Next(g.l) {
+		definition, _ := definitions.Value()
+
+		// meta fields like __schema or __type are implicit and not part of the introspection response
+		if bytes.HasPrefix(g.l.ByteSlice(definition.Name), metaFieldPrefix) {
+			continue
+		}
+
+		field := introspection.NewField()
+		field.Name = string(g.l.ByteSlice(definition.Name))
+		field.Description = g.description(definition.Description)
+		field.DeprecationReason, field.IsDeprecated = g.deprecationReason(definition.DirectiveSet)
+
+		var err error
+		field.Type, err = g.typeRefFromType(g.l.Type(definition.Type))
+		if err != nil {
+			return nil, err
+		}
+
+		if definition.ArgumentsDefinition != -1 {
+			field.Args, err = g.inputValues(g.l.ArgumentsDefinition(definition.ArgumentsDefinition).InputValueDefinitions)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		// field definitions are linked in reverse order of their declaration
+		fields = append([]introspection.Field{field}, fields...)
+	}
+
+	return fields, nil
+}
+
+func (g *Generator) inputValues(definitions document.InputValueDefinitions) ([]introspection.InputValue, error) {
+
+	inputValues := make([]introspection.InputValue, 0)
+
+	for definitions.Next(g.l) {
+		definition, _ := definitions.Value()
+
+		inputValue := introspection.InputValue{
+			Name:        string(g.l.ByteSlice(definition.Name)),
+			Description: g.description(definition.Description),
+		}
+		inputValue.DeprecationReason, inputValue.IsDeprecated = g.deprecationReason(definition.DirectiveSet)
+
+		var err error
+		inputValue.Type, err = g.typeRefFromType(g.l.Type(definition.Type))
+		if err != nil {
+			return nil, err
+		}
+
+		if definition.DefaultValue != -1 {
+			buff := bytes.Buffer{}
+			g.writeValue(&buff, definition.DefaultValue)
+			defaultValue := buff.String()
+			inputValue.DefaultValue = &defaultValue
+		}
+
+		// input value definitions are linked in reverse order of their declaration
+		inputValues = append([]introspection.InputValue{inputValue}, inputValues...)
+	}
+
+	return inputValues, nil
+}
+
+func (g *Generator) typeRefFromType(graphqlType document.Type) (introspection.TypeRef, error) {
+	switch graphqlType.Kind {
+	case document.TypeKindNON_NULL, document.TypeKindLIST:
+		ofType, err := g.typeRefFromType(g.l.Type(graphqlType.OfType))
+		if err != nil {
+			return ofType, err
+		}
+		typeRef := introspection.TypeRef{
+			Kind:   introspection.LIST,
+			OfType: &ofType,
+		}
+		if graphqlType.Kind == document.TypeKindNON_NULL {
+			typeRef.Kind = introspection.NON_NULL
+		}
+		return typeRef, nil
+	default:
+		return g.namedTypeRef(graphqlType.Name)
+	}
+}
+
+// namedTypeRef returns the type reference to a named type, the kind depends on the type definition
+func (g *Generator) namedTypeRef(name document.ByteSliceReference) (introspection.TypeRef, error) {
+	if _, ok := g.l.ScalarTypeDefinitionByName(name); ok {
+		return g.typeRef(introspection.TypeRef{Kind: introspection.SCALAR}, name), nil
+	}
+	if _, ok := g.l.ObjectTypeDefinitionByName(name); ok {
+		return g.typeRef(introspection.TypeRef{Kind: introspection.OBJECT}, name), nil
+	}
+	if _, ok := g.l.InterfaceTypeDefinitionByName(name); ok {
+		return g.typeRef(introspection.TypeRef{Kind: introspection.INTERFACE}, name), nil
+	}
+	if _, ok := g.l.UnionTypeDefinitionByName(name); ok {
+		return g.typeRef(introspection.TypeRef{Kind: introspection.UNION}, name), nil
+	}
+	if _, ok := g.l.EnumTypeDefinitionByName(name); ok {
+		return g.typeRef(introspection.TypeRef{Kind: introspection.ENUM}, name), nil
+	}
+	if _, ok := g.l.InputObjectTypeDefinitionByName(name); ok {
+		return g.typeRef(introspection.TypeRef{Kind: introspection.INPUT_OBJECT}, name), nil
+	}
+	return introspection.TypeRef{}, fmt.Errorf("Generate: type '%s' is not defined", string(g.l.ByteSlice(name)))
+}
+
+// typeRef sets the name of the type reference
+func (g *Generator) typeRef(typeRef introspection.TypeRef, name document.ByteSliceReference) introspection.TypeRef {
+	typeName := string(g.l.ByteSlice(name))
+	typeRef.Name = &typeName
+	return typeRef
+}
+
+// deprecationReason returns the reason of the @deprecated directive inside the directive set
+func (g *Generator) deprecationReason(directiveSet int) (reason string, deprecated bool) {
+
+	directives := g.l.DirectiveIterable(g.l.DirectiveSet(directiveSet))
+	for directives.Next() {
+		directive, _ := directives.Value()
+		if !bytes.Equal(g.l.ByteSlice(directive.Name), deprecatedDirectiveName) {
+			continue
+		}
+
+		args := g.l.ArgumentsIterable(g.l.ArgumentSet(directive.ArgumentSet))
+		for args.Next() {
+			arg, _ := args.Value()
+			if !bytes.Equal(g.l.ByteSlice(arg.Name), reasonArgumentName) {
+				continue
+			}
+			value := g.l.Value(arg.Value)
+			if value.ValueType == document.ValueTypeString {
+				return string(transform.UnescapeString(g.l.ByteSlice(value.Raw))), true
+			}
+		}
+
+		return deprecation.DefaultReason, true
+	}
+
+	return "", false
+}
+
+// specifiedByURL returns the url of the @specifiedBy directive inside the directive set
+func (g *Generator) specifiedByURL(directiveSet int) *string {
+
+	directives := g.l.DirectiveIterable(g.l.DirectiveSet(directiveSet))
+	for directives.Next() {
+		directive, _ := directives.Value()
+		if !bytes.Equal(g.l.ByteSlice(directive.Name), specifiedByDirectiveName) {
+			continue
+		}
+
+		args := g.l.ArgumentsIterable(g.l.ArgumentSet(directive.ArgumentSet))
+		for args.Next() {
+			arg, _ := args.Value()
+			value := g.l.Value(arg.Value)
+			if bytes.Equal(g.l.ByteSlice(arg.Name), urlArgumentName) && value.ValueType == document.ValueTypeString {
+				url := string(transform.UnescapeString(g.l.ByteSlice(value.Raw)))
+				return &url
+			}
+		}
+	}
+
+	return nil
+}
+
+// description returns the value of a description
+// multi line descriptions are block strings which don't support escape sequences
+func (g *Generator) description(ref document.ByteSliceReference) string {
+	description := g.l.ByteSlice(ref)
+	if bytes.Contains(description, literal.LINETERMINATOR) {
+		return string(transform.BlockStringValue(description))
+	}
+	return string(transform.UnescapeString(description))
+}
+
+// writeValue writes the value as graphql literal, e.g. '"foo"', '[1, 2]' or '{bar: BAZ}'
+func (g *Generator) writeValue(buff *bytes.Buffer, ref int) {
+
+	value := g.l.Value(ref)
+
+	switch value.ValueType {
+	case document.ValueTypeString:
+		buff.Write(literal.QUOTE)
+		buff.Write(g.l.ByteSlice(value.Raw))
+		buff.Write(literal.QUOTE)
+	case document.ValueTypeNull:
+		buff.Write(literal.NULL)
+	case document.ValueTypeVariable:
+		buff.Write(literal.DOLLAR)
+		buff.Write(g.l.ByteSlice(g.l.ByteSliceReference(value.Reference)))
+	case document.ValueTypeEnum:
+		buff.Write(g.l.ByteSlice(g.l.ByteSliceReference(value.Reference)))
+	case document.ValueTypeList:
+		buff.Write(literal.SQUAREBRACKETOPEN)
+		for i, item := range g.l.ListValue(value.Reference) {
+			if i != 0 {
+				buff.WriteString(", ")
+			}
+			g.writeValue(buff, item)
+		}
+		buff.Write(literal.SQUAREBRACKETCLOSE)
+	case document.ValueTypeObject:
+		buff.Write(literal.CURLYBRACKETOPEN)
+		fields := g.l.ObjectFieldsIterator(g.l.ObjectValue(value.Reference))
+		var addComma bool
+		for fields.Next() {
+			if addComma {
+				buff.WriteString(", ")
+			}
+			field, _ := fields.Value()
+			buff.Write(g.l.ByteSlice(field.Name))
+			buff.WriteString(": ")
+			g.writeValue(buff, field.Value)
+			addComma = true
+		}
+		buff.Write(literal.CURLYBRACKETCLOSE)
+	default:
+		buff.Write(g.l.ByteSlice(value.Raw))
+	}
+}
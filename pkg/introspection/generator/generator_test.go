@@ -0,0 +1,280 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/jensneuse/graphql-go-tools/pkg/introspection"
+	"github.com/jensneuse/graphql-go-tools/pkg/lookup"
+	"github.com/jensneuse/graphql-go-tools/pkg/parser"
+	"io/ioutil"
+	"testing"
+)
+
+func TestGenerator_Generate(t *testing.T) {
+
+	generate := func(p *parser.Parser) introspection.Response {
+		g := New()
+		g.SetInput(lookup.New(p))
+
+		var response introspection.Response
+		err := g.Generate(&response)
+		if err != nil {
+			panic(err)
+		}
+
+		return response
+	}
+
+	mustEqual := func(want, got introspection.Response) {
+		wantJson, err := json.MarshalIndent(want, "", "  ")
+		if err != nil {
+			panic(err)
+		}
+		gotJson, err := json.MarshalIndent(got, "", "  ")
+		if err != nil {
+			panic(err)
+		}
+		if !bytes.Equal(wantJson, gotJson) {
+			wantLines, gotLines := bytes.Split(wantJson, []byte("\n")), bytes.Split(gotJson, []byte("\n"))
+			for i := range wantLines {
+				if i >= len(gotLines) || !bytes.Equal(wantLines[i], gotLines[i]) {
+					panic(fmt.Errorf("mustEqual: first difference at line %d\nwant: %s\ngot: %s", i+1, wantLines[i], gotLines[i]))
+				}
+			}
+			panic(fmt.Errorf("mustEqual: want %d lines, got: %d", len(wantLines), len(gotLines)))
+		}
+	}
+
+	t.Run("swapi round trip", func(t *testing.T) {
+
+		data, err := ioutil.ReadFile("../testdata/swapi_introspection_response.json")
+		if err != nil {
+			panic(err)
+		}
+
+		var want introspection.Response
+		err = json.Unmarshal(data, &want)
+		if err != nil {
+			panic(err)
+		}
+
+		p := parser.NewParser()
+		err = p.ParseIntrospectionResponse(&want)
+		if err != nil {
+			panic(err)
+		}
+
+		mustEqual(want, generate(p))
+	})
+	t.Run("schema definition language round trip", func(t *testing.T) {
+
+		p := parser.NewParser()
+		err := p.ParseTypeSystemDefinition([]byte(generatorSchema))
+		if err != nil {
+			panic(err)
+		}
+
+		generated := generate(p)
+
+		p = parser.NewParser()
+		err = p.ParseIntrospectionResponse(&generated)
+		if err != nil {
+			panic(err)
+		}
+
+		mustEqual(generated, generate(p))
+	})
+	t.Run("schema", func(t *testing.T) {
+
+		p := parser.NewParser()
+		err := p.ParseTypeSystemDefinition([]byte(generatorSchema))
+		if err != nil {
+			panic(err)
+		}
+
+		schema := generate(p).Data.Schema
+
+		if schema.QueryType.Name != "Query" || schema.MutationType.Name != "Mutation" || schema.SubscriptionType != nil {
+			panic(fmt.Errorf("unexpected root operation types: %+v, %+v, %+v", schema.QueryType, schema.MutationType, schema.SubscriptionType))
+		}
+
+		fullType := func(name string) introspection.FullType {
+			for _, fullType := range schema.Types {
+				if fullType.Name == name {
+					return fullType
+				}
+			}
+			panic(fmt.Errorf("type %s not found", name))
+		}
+
+		mustMarshal := func(v interface{}, want string) {
+			got, err := json.Marshal(v)
+			if err != nil {
+				panic(err)
+			}
+			if want != string(got) {
+				panic(fmt.Errorf("want:\n%s\ngot:\n%s", want, string(got)))
+			}
+		}
+
+		var names []string
+		for _, fullType := range schema.Types {
+			names = append(names, fullType.Name)
+		}
+		mustMarshal(names, `["Query","Mutation","Character","Human","Droid","SearchResult","Episode","ReviewInput","ID","Int","String","Boolean","DateTime"]`)
+
+		mustMarshal(fullType("Query"), `{"kind":"OBJECT","name":"Query","description":"the \"root\" query type","fields":[`+
+			`{"name":"hero","description":"","args":[{"name":"episode","description":"","type":{"kind":"ENUM","name":"Episode","ofType":null},"defaultValue":"NEWHOPE","isDeprecated":false,"deprecationReason":""}],"type":{"kind":"INTERFACE","name":"Character","ofType":null},"isDeprecated":false,"deprecationReason":""},`+
+			`{"name":"search","description":"","args":[{"name":"text","description":"","type":{"kind":"NON_NULL","name":null,"ofType":{"kind":"SCALAR","name":"String","ofType":null}},"defaultValue":null,"isDeprecated":false,"deprecationReason":""}],"type":{"kind":"NON_NULL","name":null,"ofType":{"kind":"LIST","name":null,"ofType":{"kind":"UNION","name":"SearchResult","ofType":null}}},"isDeprecated":false,"deprecationReason":""},`+
+			`{"name":"droid","description":"","args":[{"name":"id","description":"","type":{"kind":"NON_NULL","name":null,"ofType":{"kind":"SCALAR","name":"ID","ofType":null}},"defaultValue":null,"isDeprecated":false,"deprecationReason":""}],"type":{"kind":"OBJECT","name":"Droid","ofType":null},"isDeprecated":true,"deprecationReason":"use hero"}],`+
+			`"inputFields":null,"interfaces":[],"enumValues":null,"possibleTypes":null,"specifiedByURL":null}`)
+
+		mustMarshal(fullType("Character").PossibleTypes, `[{"kind":"OBJECT","name":"Human","ofType":null},{"kind":"OBJECT","name":"Droid","ofType":null}]`)
+		mustMarshal(fullType("Droid").Interfaces, `[{"kind":"INTERFACE","name":"Character","ofType":null}]`)
+		mustMarshal(fullType("SearchResult").PossibleTypes, `[{"kind":"OBJECT","name":"Human","ofType":null},{"kind":"OBJECT","name":"Droid","ofType":null}]`)
+		mustMarshal(fullType("Episode").EnumValues, `[{"name":"NEWHOPE","description":"","isDeprecated":false,"deprecationReason":""},{"name":"EMPIRE","description":"","isDeprecated":false,"deprecationReason":""},{"name":"JEDI","description":"","isDeprecated":true,"deprecationReason":"No longer supported"}]`)
+		mustMarshal(fullType("ReviewInput").InputFields, `[{"name":"stars","description":"","type":{"kind":"NON_NULL","name":null,"ofType":{"kind":"SCALAR","name":"Int","ofType":null}},"defaultValue":null,"isDeprecated":false,"deprecationReason":""},{"name":"tags","description":"","type":{"kind":"LIST","name":null,"ofType":{"kind":"SCALAR","name":"String","ofType":null}},"defaultValue":"[\"good\", \"bad\"]","isDeprecated":true,"deprecationReason":"use commentary"},{"name":"commentary","description":"multi line\n\tdescription","type":{"kind":"SCALAR","name":"String","ofType":null},"defaultValue":"\"none\"","isDeprecated":false,"deprecationReason":""}]`)
+		mustMarshal(fullType("Mutation").Fields[0].Args[0], `{"name":"episode","description":"","type":{"kind":"ENUM","name":"Episode","ofType":null},"defaultValue":null,"isDeprecated":true,"deprecationReason":"No longer supported"}`)
+		mustMarshal(fullType("DateTime").SpecifiedByURL, `"https://tools.ietf.org/html/rfc3339"`)
+		mustMarshal(fullType("String").SpecifiedByURL, `null`)
+		mustMarshal(schema.Directives, `[{"name":"deprecated","description":"","locations":["FIELD_DEFINITION","ARGUMENT_DEFINITION","INPUT_FIELD_DEFINITION","ENUM_VALUE"],"args":[{"name":"reason","description":"","type":{"kind":"SCALAR","name":"String","ofType":null},"defaultValue":"\"No longer supported\"","isDeprecated":false,"deprecationReason":""}],"isRepeatable":false},`+
+			`{"name":"specifiedBy","description":"","locations":["SCALAR"],"args":[{"name":"url","description":"","type":{"kind":"NON_NULL","name":null,"ofType":{"kind":"SCALAR","name":"String","ofType":null}},"defaultValue":null,"isDeprecated":false,"deprecationReason":""}],"isRepeatable":false},`+
+			`{"name":"tag","description":"","locations":["FIELD_DEFINITION"],"args":[{"name":"name","description":"","type":{"kind":"NON_NULL","name":null,"ofType":{"kind":"SCALAR","name":"String","ofType":null}},"defaultValue":null,"isDeprecated":false,"deprecationReason":""}],"isRepeatable":true}]`)
+	})
+	t.Run("extended type system definition", func(t *testing.T) {
+		p := parser.NewParser()
+		err := p.ParseTypeSystemDefinition([]byte(`type Query { foo: String bar: Int }`))
+		if err != nil {
+			panic(err)
+		}
+		err = p.ExtendTypeSystemDefinition([]byte(`scalar Int scalar String directive @skip(if: Boolean!) on FIELD scalar Boolean`))
+		if err != nil {
+			panic(err)
+		}
+		err = p.ExtendTypeSystemDefinition([]byte(`scalar String directive @skip(if: Boolean!) on FIELD`))
+		if err != nil {
+			panic(err)
+		}
+
+		schema := generate(p).Data.Schema
+
+		var names []string
+		for _, fullType := range schema.Types {
+			names = append(names, fullType.Name)
+		}
+		if want := "[Query Int String Boolean]"; want != fmt.Sprint(names) {
+			panic(fmt.Errorf("want: %s, got: %v", want, names))
+		}
+		if len(schema.Directives) != 1 || schema.Directives[0].Name != "skip" {
+			panic(fmt.Errorf("unexpected directives: %+v", schema.Directives))
+		}
+	})
+	t.Run("default root operation type names", func(t *testing.T) {
+		p := parser.NewParser()
+		err := p.ParseTypeSystemDefinition([]byte(`type Query { foo: String } type Subscription { bar: String } scalar String`))
+		if err != nil {
+			panic(err)
+		}
+
+		schema := generate(p).Data.Schema
+		if schema.QueryType.Name != "Query" || schema.MutationType != nil || schema.SubscriptionType.Name != "Subscription" {
+			panic(fmt.Errorf("unexpected root operation types: %+v, %+v, %+v", schema.QueryType, schema.MutationType, schema.SubscriptionType))
+		}
+	})
+	t.Run("undefined type", func(t *testing.T) {
+		p := parser.NewParser()
+		err := p.ParseTypeSystemDefinition([]byte(`type Query { foo: String }`))
+		if err != nil {
+			panic(err)
+		}
+
+		g := New()
+		g.SetInput(lookup.New(p))
+		err = g.Generate(&introspection.Response{})
+		if err == nil {
+			panic("want err")
+		}
+	})
+	t.Run("missing query type", func(t *testing.T) {
+		p := parser.NewParser()
+		err := p.ParseTypeSystemDefinition([]byte(`type Foo { foo: String } scalar String`))
+		if err != nil {
+			panic(err)
+		}
+
+		g := New()
+		g.SetInput(lookup.New(p))
+		err = g.Generate(&introspection.Response{})
+		if err == nil {
+			panic("want err")
+		}
+	})
+}
+
+const generatorSchema = `
+schema {
+	query: Query
+	mutation: Mutation
+}
+
+"the \"root\" query type"
+type Query {
+	hero(episode: Episode = NEWHOPE): Character
+	search(text: String!): [SearchResult]!
+	droid(id: ID!): Droid @deprecated(reason: "use hero")
+}
+
+type Mutation {
+	createReview(episode: Episode @deprecated, review: ReviewInput!): Int
+}
+
+interface Character {
+	id: ID!
+	name: String
+}
+
+type Human implements Character {
+	id: ID!
+	name: String
+	friends: [Character]
+}
+
+type Droid implements Character {
+	id: ID!
+	name: String
+	primaryFunction: String
+}
+
+union SearchResult = Human | Droid
+
+enum Episode {
+	NEWHOPE
+	EMPIRE
+	JEDI @deprecated
+}
+
+input ReviewInput {
+	stars: Int!
+	tags: [String] = ["good", "bad"] @deprecated(reason: "use commentary")
+	"""
+	multi line
+		description
+	"""
+	commentary: String = "none"
+}
+
+scalar ID
+scalar Int
+scalar String
+scalar Boolean
+scalar DateTime @specifiedBy(url: "https://tools.ietf.org/html/rfc3339")
+
+directive @deprecated(
+	reason: String = "No longer supported"
+) on FIELD_DEFINITION | ARGUMENT_DEFINITION | INPUT_FIELD_DEFINITION | ENUM_VALUE
+
+directive @specifiedBy(url: String!) on SCALAR
+
+directive @tag(name: String!) repeatable on FIELD_DEFINITION
+`
@@ -42,6 +42,8 @@ type FullType struct {
 	Interfaces    []TypeRef    `json:"interfaces"`
 	EnumValues    []EnumValue  `json:"enumValues"`
 	PossibleTypes []TypeRef    `json:"possibleTypes"`
+	// SpecifiedByURL is the url of the specification of a custom scalar, set with the @specifiedBy directive
+	SpecifiedByURL *string `json:"specifiedByURL"`
 }
 
 func NewFullType() FullType {
@@ -96,12 +98,12 @@ type TypeRef struct {
 }
 
 type Field struct {
-	Name               string       `json:"name"`
-	Description        string       `json:"description"`
-	Args               []InputValue `json:"args"`
-	Type               TypeRef      `json:"type"`
-	IsDeprecated       bool         `json:"isDeprecated"`
-	DepreciationReason string       `json:"depreciacionReason"`
+	Name              string       `json:"name"`
+	Description       string       `json:"description"`
+	Args              []InputValue `json:"args"`
+	Type              TypeRef      `json:"type"`
+	IsDeprecated      bool         `json:"isDeprecated"`
+	DeprecationReason string       `json:"deprecationReason"`
 }
 
 func NewField() Field {
@@ -111,24 +113,27 @@ func NewField() Field {
 }
 
 type EnumValue struct {
-	Name               string `json:"name"`
-	Description        string `json:"description"`
-	IsDeprecated       bool   `json:"isDeprecated"`
-	DepreciationReason string `json:"depreciacionReason"`
+	Name              string `json:"name"`
+	Description       string `json:"description"`
+	IsDeprecated      bool   `json:"isDeprecated"`
+	DeprecationReason string `json:"deprecationReason"`
 }
 
 type InputValue struct {
-	Name         string  `json:"name"`
-	Description  string  `json:"description"`
-	Type         TypeRef `json:"type"`
-	DefaultValue *string `json:"defaultValue"`
+	Name              string  `json:"name"`
+	Description       string  `json:"description"`
+	Type              TypeRef `json:"type"`
+	DefaultValue      *string `json:"defaultValue"`
+	IsDeprecated      bool    `json:"isDeprecated"`
+	DeprecationReason string  `json:"deprecationReason"`
 }
 
 type Directive struct {
-	Name        string       `json:"name"`
-	Description string       `json:"description"`
-	Locations   []string     `json:"locations"`
-	Args        []InputValue `json:"args"`
+	Name         string       `json:"name"`
+	Description  string       `json:"description"`
+	Locations    []string     `json:"locations"`
+	Args         []InputValue `json:"args"`
+	IsRepeatable bool         `json:"isRepeatable"`
 }
 
 func NewDirective() Directive {
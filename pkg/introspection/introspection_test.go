@@ -1,9 +1,13 @@
 package introspection
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"os"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -30,3 +34,191 @@ func TestIntrospectionSerialization(t *testing.T) {
 		panic(err)
 	}
 }
+
+func TestDecode(t *testing.T) {
+
+	decode := func(fileName string) Response {
+		data, err := ioutil.ReadFile("./testdata/" + fileName)
+		if err != nil {
+			panic(err)
+		}
+		var response Response
+		err = Decode(bytes.NewReader(data), &response)
+		if err != nil {
+			panic(err)
+		}
+		return response
+	}
+
+	fullType := func(response Response, name string) FullType {
+		for _, fullType := range response.Data.Schema.Types {
+			if fullType.Name == name {
+				return fullType
+			}
+		}
+		panic(fmt.Errorf("type %s not found", name))
+	}
+
+	mustEqual := func(want, got interface{}) {
+		if !reflect.DeepEqual(want, got) {
+			panic(fmt.Errorf("want: %+v, got: %+v", want, got))
+		}
+	}
+
+	t.Run("legacy encoding of previous versions", func(t *testing.T) {
+		response := decode("legacy_introspection_response.json")
+		query := fullType(response, "Query")
+		mustEqual("", query.Fields[0].DeprecationReason)
+		mustEqual(true, query.Fields[1].IsDeprecated)
+		mustEqual("use hero", query.Fields[1].DeprecationReason)
+		mustEqual("No longer supported", fullType(response, "Episode").EnumValues[1].DeprecationReason)
+		mustEqual(false, query.Fields[0].Args[0].IsDeprecated)
+	})
+	t.Run("june 2018", func(t *testing.T) {
+		response := decode("swapi_introspection_response.json")
+		mustEqual("Query", response.Data.Schema.QueryType.Name)
+		directive := fullType(response, "__Directive")
+		mustEqual("onOperation", directive.Fields[4].Name)
+		mustEqual("Use `locations`.", directive.Fields[4].DeprecationReason)
+		mustEqual((*string)(nil), fullType(response, "String").SpecifiedByURL)
+	})
+	t.Run("october 2021 without data envelope", func(t *testing.T) {
+		response := decode("october2021_introspection_response.json")
+		mustEqual("Query", response.Data.Schema.QueryType.Name)
+		mustEqual(5, len(response.Data.Schema.Types))
+		mustEqual("https://tools.ietf.org/html/rfc3339", *fullType(response, "DateTime").SpecifiedByURL)
+		mustEqual((*string)(nil), fullType(response, "String").SpecifiedByURL)
+		since := fullType(response, "Query").Fields[0].Args[1]
+		mustEqual(true, since.IsDeprecated)
+		mustEqual("Use `after`.", since.DeprecationReason)
+		mustEqual("Use `startsAt`.", fullType(response, "Event").Fields[2].DeprecationReason)
+		mustEqual(false, response.Data.Schema.Directives[0].IsRepeatable)
+		mustEqual(true, response.Data.Schema.Directives[2].IsRepeatable)
+		mustEqual([]string{"FIELD_DEFINITION", "OBJECT", "VARIABLE_DEFINITION"}, response.Data.Schema.Directives[2].Locations)
+	})
+	// the star wars dumps are responses of other servers:
+	// github.com/graphql-go/graphql v0.8.1 answering the june 2018 introspection query of its testutil package
+	// github.com/graph-gophers/graphql-go v1.9.0 answering the october 2021 fields it supports,
+	// all except isRepeatable and includeDeprecated on arguments and input fields
+	t.Run("june 2018 of graphql-go", func(t *testing.T) {
+		response := decode("graphqlgo_starwars_introspection_response.json")
+		mustEqual("Query", response.Data.Schema.QueryType.Name)
+		mustEqual((*TypeName)(nil), response.Data.Schema.MutationType)
+		mustEqual(15, len(response.Data.Schema.Types))
+		directive := fullType(response, "__Directive")
+		mustEqual("onOperation", directive.Fields[6].Name)
+		mustEqual("Use `locations`.", directive.Fields[6].DeprecationReason)
+		mustEqual((*string)(nil), fullType(response, "String").SpecifiedByURL)
+		mustEqual("\"No longer supported\"", *response.Data.Schema.Directives[2].Args[0].DefaultValue)
+	})
+	t.Run("october 2021 of graph-gophers", func(t *testing.T) {
+		response := decode("graphgophers_starwars_introspection_response.json")
+		mustEqual("Mutation", response.Data.Schema.MutationType.Name)
+		mustEqual(27, len(response.Data.Schema.Types))
+		mustEqual((*string)(nil), fullType(response, "ID").SpecifiedByURL)
+		mustEqual("NEWHOPE", *fullType(response, "Query").Fields[0].Args[0].DefaultValue)
+		mustEqual([]string{"SCALAR"}, response.Data.Schema.Directives[3].Locations)
+		mustEqual("url", response.Data.Schema.Directives[3].Args[0].Name)
+		mustEqual(false, response.Data.Schema.Directives[3].Args[0].IsDeprecated)
+	})
+	t.Run("invalid json", func(t *testing.T) {
+		err := Decode(strings.NewReader(`{"data":`), &Response{})
+		if err == nil {
+			panic("want err")
+		}
+	})
+}
+
+func TestEncode(t *testing.T) {
+
+	fixtures := []string{
+		"legacy_introspection_response.json",
+		"swapi_introspection_response.json",
+		"october2021_introspection_response.json",
+		"graphqlgo_starwars_introspection_response.json",
+		"graphgophers_starwars_introspection_response.json",
+	}
+
+	decode := func(fileName string) Response {
+		data, err := ioutil.ReadFile("./testdata/" + fileName)
+		if err != nil {
+			panic(err)
+		}
+		var response Response
+		err = Decode(bytes.NewReader(data), &response)
+		if err != nil {
+			panic(err)
+		}
+		return response
+	}
+
+	encode := func(response Response, version Version) []byte {
+		buff := bytes.Buffer{}
+		err := Encode(&buff, response, version)
+		if err != nil {
+			panic(err)
+		}
+		return buff.Bytes()
+	}
+
+	// withoutOctober2021Fields resets the fields which are not part of the June 2018 encoding
+	withoutOctober2021Fields := func(response Response) Response {
+		resetInputValues := func(inputValues []InputValue) {
+			for i := range inputValues {
+				inputValues[i].IsDeprecated = false
+				inputValues[i].DeprecationReason = ""
+			}
+		}
+		schema := response.Data.Schema
+		for i := range schema.Types {
+			schema.Types[i].SpecifiedByURL = nil
+			resetInputValues(schema.Types[i].InputFields)
+			for j := range schema.Types[i].Fields {
+				resetInputValues(schema.Types[i].Fields[j].Args)
+			}
+		}
+		for i := range schema.Directives {
+			schema.Directives[i].IsRepeatable = false
+			resetInputValues(schema.Directives[i].Args)
+		}
+		return response
+	}
+
+	for _, fixture := range fixtures {
+		t.Run(fixture, func(t *testing.T) {
+
+			october2021 := encode(decode(fixture), VersionOctober2021)
+			var got Response
+			err := Decode(bytes.NewReader(october2021), &got)
+			if err != nil {
+				panic(err)
+			}
+			if !reflect.DeepEqual(decode(fixture), got) {
+				panic(fmt.Errorf("october 2021 round trip mismatch"))
+			}
+
+			june2018 := encode(decode(fixture), VersionJune2018)
+			// keys only, the dumps of october 2021 servers have e.g. a __Type field named specifiedByURL
+			for _, field := range []string{`"specifiedByURL":`, `"isRepeatable":`, `"depreciacionReason":`} {
+				if bytes.Contains(june2018, []byte(field)) {
+					panic(fmt.Errorf("want june 2018 encoding without %s", field))
+				}
+			}
+			got = Response{}
+			err = Decode(bytes.NewReader(june2018), &got)
+			if err != nil {
+				panic(err)
+			}
+			if !reflect.DeepEqual(withoutOctober2021Fields(decode(fixture)), got) {
+				panic(fmt.Errorf("june 2018 round trip mismatch"))
+			}
+		})
+	}
+
+	t.Run("unsupported version", func(t *testing.T) {
+		err := Encode(&bytes.Buffer{}, Response{}, Version(0))
+		if err == nil {
+			panic("want err")
+		}
+	})
+}
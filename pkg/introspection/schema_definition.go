@@ -0,0 +1,316 @@
+package introspection
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/jensneuse/graphql-go-tools/pkg/transform"
+)
+
+// WriteSchemaDefinition writes the schema of an introspection response in the schema definition language
+// all types, directives, descriptions, default values, deprecations, specifiedBy urls and repeatable directives
+// of the schema are part of the output, nothing is written if the schema can't be represented
+func WriteSchemaDefinition(out io.Writer, schema *Schema) error {
+
+	w := schemaDefinitionWriter{}
+	err := w.writeSchema(schema)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.buff.WriteTo(out)
+	return err
+}
+
+type schemaDefinitionWriter struct {
+	buff bytes.Buffer
+}
+
+func (w *schemaDefinitionWriter) writeSchema(schema *Schema) error {
+
+	if schema.QueryType == nil {
+		return fmt.Errorf("WriteSchemaDefinition: schema must define a query type")
+	}
+
+	w.buff.WriteString("schema {\n")
+	w.writeRootOperationType("query", schema.QueryType)
+	w.writeRootOperationType("mutation", schema.MutationType)
+	w.writeRootOperationType("subscription", schema.SubscriptionType)
+	w.buff.WriteString("}\n")
+
+	for i := range schema.Types {
+		err := w.writeType(&schema.Types[i])
+		if err != nil {
+			return err
+		}
+	}
+
+	for i := range schema.Directives {
+		err := w.writeDirective(&schema.Directives[i])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w *schemaDefinitionWriter) writeRootOperationType(operationType string, typeName *TypeName) {
+	if typeName == nil {
+		return
+	}
+	w.buff.WriteString("\t")
+	w.buff.WriteString(operationType)
+	w.buff.WriteString(": ")
+	w.buff.WriteString(typeName.Name)
+	w.buff.WriteString("\n")
+}
+
+func (w *schemaDefinitionWriter) writeType(fullType *FullType) error {
+
+	w.buff.WriteString("\n")
+	w.writeDescription(fullType.Description, "")
+
+	switch fullType.Kind {
+	case SCALAR:
+		w.buff.WriteString("scalar ")
+		w.buff.WriteString(fullType.Name)
+		if fullType.SpecifiedByURL != nil {
+			w.buff.WriteString(" @specifiedBy(url: ")
+			w.writeString(*fullType.SpecifiedByURL)
+			w.buff.WriteString(")")
+		}
+		w.buff.WriteString("\n")
+	case OBJECT, INTERFACE:
+		if fullType.Kind == OBJECT {
+			w.buff.WriteString("type ")
+		} else {
+			w.buff.WriteString("interface ")
+		}
+		w.buff.WriteString(fullType.Name)
+		for i := range fullType.Interfaces {
+			if i == 0 {
+				w.buff.WriteString(" implements ")
+			} else {
+				w.buff.WriteString(" & ")
+			}
+			err := w.writeNamedTypeRef(&fullType.Interfaces[i])
+			if err != nil {
+				return err
+			}
+		}
+		if len(fullType.Fields) == 0 {
+			w.buff.WriteString("\n")
+			return nil
+		}
+		w.buff.WriteString(" {\n")
+		for i := range fullType.Fields {
+			err := w.writeField(&fullType.Fields[i])
+			if err != nil {
+				return err
+			}
+		}
+		w.buff.WriteString("}\n")
+	case UNION:
+		w.buff.WriteString("union ")
+		w.buff.WriteString(fullType.Name)
+		for i := range fullType.PossibleTypes {
+			if i == 0 {
+				w.buff.WriteString(" = ")
+			} else {
+				w.buff.WriteString(" | ")
+			}
+			err := w.writeNamedTypeRef(&fullType.PossibleTypes[i])
+			if err != nil {
+				return err
+			}
+		}
+		w.buff.WriteString("\n")
+	case ENUM:
+		w.buff.WriteString("enum ")
+		w.buff.WriteString(fullType.Name)
+		w.buff.WriteString(" {\n")
+		for i := range fullType.EnumValues {
+			enumValue := &fullType.EnumValues[i]
+			w.writeDescription(enumValue.Description, "\t")
+			w.buff.WriteString("\t")
+			w.buff.WriteString(enumValue.Name)
+			w.writeDeprecation(enumValue.IsDeprecated, enumValue.DeprecationReason)
+			w.buff.WriteString("\n")
+		}
+		w.buff.WriteString("}\n")
+	case INPUT_OBJECT:
+		w.buff.WriteString("input ")
+		w.buff.WriteString(fullType.Name)
+		w.buff.WriteString(" {\n")
+		for i := range fullType.InputFields {
+			w.buff.WriteString("\t")
+			err := w.writeInputValue(&fullType.InputFields[i], "\t")
+			if err != nil {
+				return err
+			}
+			w.buff.WriteString("\n")
+		}
+		w.buff.WriteString("}\n")
+	default:
+		return fmt.Errorf("WriteSchemaDefinition: unsupported kind %s for type '%s'", fullType.Kind, fullType.Name)
+	}
+
+	return nil
+}
+
+func (w *schemaDefinitionWriter) writeField(field *Field) error {
+
+	w.writeDescription(field.Description, "\t")
+	w.buff.WriteString("\t")
+	w.buff.WriteString(field.Name)
+
+	err := w.writeArguments(field.Args, "\t\t")
+	if err != nil {
+		return err
+	}
+
+	w.buff.WriteString(": ")
+	err = w.writeTypeRef(&field.Type)
+	if err != nil {
+		return err
+	}
+
+	w.writeDeprecation(field.IsDeprecated, field.DeprecationReason)
+	w.buff.WriteString("\n")
+
+	return nil
+}
+
+func (w *schemaDefinitionWriter) writeArguments(args []InputValue, indent string) error {
+
+	if len(args) == 0 {
+		return nil
+	}
+
+	w.buff.WriteString("(\n")
+	for i := range args {
+		w.buff.WriteString(indent)
+		err := w.writeInputValue(&args[i], indent)
+		if err != nil {
+			return err
+		}
+		w.buff.WriteString("\n")
+	}
+	w.buff.WriteString(indent[:len(indent)-1])
+	w.buff.WriteString(")")
+
+	return nil
+}
+
+func (w *schemaDefinitionWriter) writeInputValue(inputValue *InputValue, indent string) error {
+
+	if inputValue.Description != "" {
+		w.writeDescription(inputValue.Description, "")
+		w.buff.WriteString(indent)
+	}
+
+	w.buff.WriteString(inputValue.Name)
+	w.buff.WriteString(": ")
+
+	err := w.writeTypeRef(&inputValue.Type)
+	if err != nil {
+		return err
+	}
+
+	if inputValue.DefaultValue != nil {
+		w.buff.WriteString(" = ")
+		w.buff.WriteString(*inputValue.DefaultValue)
+	}
+
+	w.writeDeprecation(inputValue.IsDeprecated, inputValue.DeprecationReason)
+
+	return nil
+}
+
+func (w *schemaDefinitionWriter) writeDirective(directive *Directive) error {
+
+	w.buff.WriteString("\n")
+	w.writeDescription(directive.Description, "")
+	w.buff.WriteString("directive @")
+	w.buff.WriteString(directive.Name)
+
+	err := w.writeArguments(directive.Args, "\t")
+	if err != nil {
+		return err
+	}
+
+	if directive.IsRepeatable {
+		w.buff.WriteString(" repeatable")
+	}
+
+	w.buff.WriteString(" on ")
+	for i, location := range directive.Locations {
+		if i != 0 {
+			w.buff.WriteString(" | ")
+		}
+		w.buff.WriteString(location)
+	}
+	w.buff.WriteString("\n")
+
+	return nil
+}
+
+func (w *schemaDefinitionWriter) writeTypeRef(typeRef *TypeRef) error {
+	switch typeRef.Kind {
+	case NON_NULL:
+		if typeRef.OfType == nil {
+			return fmt.Errorf("WriteSchemaDefinition: NON_NULL type without ofType")
+		}
+		err := w.writeTypeRef(typeRef.OfType)
+		w.buff.WriteString("!")
+		return err
+	case LIST:
+		if typeRef.OfType == nil {
+			return fmt.Errorf("WriteSchemaDefinition: LIST type without ofType")
+		}
+		w.buff.WriteString("[")
+		err := w.writeTypeRef(typeRef.OfType)
+		w.buff.WriteString("]")
+		return err
+	default:
+		return w.writeNamedTypeRef(typeRef)
+	}
+}
+
+func (w *schemaDefinitionWriter) writeNamedTypeRef(typeRef *TypeRef) error {
+	if typeRef.Name == nil {
+		return fmt.Errorf("WriteSchemaDefinition: named type of kind %s without name", typeRef.Kind)
+	}
+	w.buff.WriteString(*typeRef.Name)
+	return nil
+}
+
+func (w *schemaDefinitionWriter) writeDeprecation(isDeprecated bool, reason string) {
+	if !isDeprecated {
+		return
+	}
+	w.buff.WriteString(" @deprecated")
+	if reason == "" {
+		return
+	}
+	w.buff.WriteString("(reason: ")
+	w.writeString(reason)
+	w.buff.WriteString(")")
+}
+
+func (w *schemaDefinitionWriter) writeDescription(description, indent string) {
+	if description == "" {
+		return
+	}
+	w.buff.WriteString(indent)
+	w.writeString(description)
+	w.buff.WriteString("\n")
+}
+
+func (w *schemaDefinitionWriter) writeString(value string) {
+	w.buff.WriteByte('"')
+	w.buff.Write(transform.EscapeString([]byte(value)))
+	w.buff.WriteByte('"')
+}
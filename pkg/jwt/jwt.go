@@ -0,0 +1,260 @@
+// Package jwt verifies json web tokens (https://tools.ietf.org/html/rfc7519) signed with HS256, RS256 or ES256
+package jwt
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+	ES256 = "ES256"
+)
+
+// Claims are the decoded claims of a verified token, numbers are decoded as json.Number
+type Claims map[string]interface{}
+
+// Value returns the claim at the dot separated path, e.g. "sub" or "realm_access.roles"
+func (c Claims) Value(path string) (interface{}, bool) {
+	var value interface{} = map[string]interface{}(c)
+	for _, key := range strings.Split(path, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		value, ok = object[key]
+		if !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// Error is returned for tokens which fail verification
+type Error struct {
+	Message string
+}
+
+func (e Error) Error() string {
+	return "jwt: " + e.Message
+}
+
+// StatusCode makes invalid tokens respond with 401 Unauthorized
+func (e Error) StatusCode() int {
+	return http.StatusUnauthorized
+}
+
+func errorf(format string, args ...interface{}) error {
+	return Error{Message: fmt.Sprintf(format, args...)}
+}
+
+// Verifier verifies the signature and the registered claims (exp, nbf, iss, aud) of tokens
+type Verifier struct {
+	Keys *KeySet
+	// Issuer (optional) must match the 'iss' claim
+	Issuer string
+	// Audience (optional) must be contained in the 'aud' claim
+	Audience string
+	// Leeway is the tolerated clock skew for 'exp' and 'nbf'
+	Leeway time.Duration
+	// Now defaults to time.Now
+	Now func() time.Time
+}
+
+func NewVerifier(keys *KeySet) *Verifier {
+	return &Verifier{
+		Keys: keys,
+	}
+}
+
+// VerifyAuthorizationHeader verifies the token of a 'Bearer <token>' Authorization header value
+func (v *Verifier) VerifyAuthorizationHeader(header []byte) (Claims, error) {
+	header = bytes.TrimSpace(header)
+	if len(header) < 7 || !strings.EqualFold(string(header[:7]), "bearer ") {
+		return nil, errorf("authorization header must use the Bearer scheme")
+	}
+	return v.Verify(bytes.TrimSpace(header[7:]))
+}
+
+// Verify verifies the token in compact serialization and returns its claims
+func (v *Verifier) Verify(token []byte) (Claims, error) {
+
+	parts := bytes.Split(token, []byte("."))
+	if len(parts) != 3 {
+		return nil, errorf("malformed token")
+	}
+
+	var header struct {
+		Algorithm string `json:"alg"`
+		KeyID     string `json:"kid"`
+	}
+
+	err := decodeSegment(parts[0], &header)
+	if err != nil {
+		return nil, errorf("malformed header: %s", err)
+	}
+
+	signature := make([]byte, base64.RawURLEncoding.DecodedLen(len(parts[2])))
+	n, err := base64.RawURLEncoding.Decode(signature, parts[2])
+	if err != nil {
+		return nil, errorf("malformed signature: %s", err)
+	}
+	signature = signature[:n]
+
+	signingInput := token[:len(parts[0])+1+len(parts[1])]
+
+	err = v.verifySignature(header.Algorithm, header.KeyID, signingInput, signature)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := Claims{}
+	err = decodeSegment(parts[1], &claims)
+	if err != nil {
+		return nil, errorf("malformed claims: %s", err)
+	}
+
+	err = v.verifyClaims(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+func (v *Verifier) verifySignature(algorithm, keyID string, signingInput, signature []byte) error {
+
+	switch algorithm {
+	case HS256, RS256, ES256:
+	default:
+		return errorf("unsupported algorithm '%s'", algorithm)
+	}
+
+	if v.Keys == nil {
+		return errorf("no key found for algorithm '%s'", algorithm)
+	}
+
+	candidates := v.Keys.candidates(keyID, algorithm)
+	if len(candidates) == 0 {
+		return errorf("no key found for algorithm '%s' and key id '%s'", algorithm, keyID)
+	}
+
+	digest := sha256.Sum256(signingInput)
+
+	for _, candidate := range candidates {
+		if verify(candidate, signingInput, digest[:], signature) {
+			return nil
+		}
+	}
+
+	return errorf("invalid signature")
+}
+
+func verify(key Key, signingInput, digest, signature []byte) bool {
+	switch key.Algorithm {
+	case HS256:
+		mac := hmac.New(sha256.New, key.key.([]byte))
+		mac.Write(signingInput) // nolint
+		return hmac.Equal(signature, mac.Sum(nil))
+	case RS256:
+		return rsa.VerifyPKCS1v15(key.key.(*rsa.PublicKey), crypto.SHA256, digest, signature) == nil
+	case ES256:
+		if len(signature) != 64 {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		return ecdsa.Verify(key.key.(*ecdsa.PublicKey), digest, r, s)
+	default:
+		return false
+	}
+}
+
+func (v *Verifier) verifyClaims(claims Claims) error {
+
+	now := time.Now()
+	if v.Now != nil {
+		now = v.Now()
+	}
+
+	if exp, ok := claims["exp"]; ok {
+		expiresAt, err := numericDate(exp)
+		if err != nil {
+			return errorf("invalid 'exp' claim: %s", err)
+		}
+		if !now.Before(expiresAt.Add(v.Leeway)) {
+			return errorf("token is expired")
+		}
+	}
+
+	if nbf, ok := claims["nbf"]; ok {
+		notBefore, err := numericDate(nbf)
+		if err != nil {
+			return errorf("invalid 'nbf' claim: %s", err)
+		}
+		if now.Add(v.Leeway).Before(notBefore) {
+			return errorf("token is not valid yet")
+		}
+	}
+
+	if v.Issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != v.Issuer {
+			return errorf("invalid issuer '%s'", iss)
+		}
+	}
+
+	if v.Audience != "" && !containsAudience(claims["aud"], v.Audience) {
+		return errorf("token is not intended for audience '%s'", v.Audience)
+	}
+
+	return nil
+}
+
+func numericDate(value interface{}) (time.Time, error) {
+	number, ok := value.(json.Number)
+	if !ok {
+		return time.Time{}, fmt.Errorf("not a number")
+	}
+	seconds, err := number.Float64()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(0, int64(seconds*float64(time.Second))), nil
+}
+
+func containsAudience(aud interface{}, audience string) bool {
+	switch aud := aud.(type) {
+	case string:
+		return aud == audience
+	case []interface{}:
+		for i := range aud {
+			if value, ok := aud[i].(string); ok && value == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func decodeSegment(segment []byte, v interface{}) error {
+	data := make([]byte, base64.RawURLEncoding.DecodedLen(len(segment)))
+	n, err := base64.RawURLEncoding.Decode(data, segment)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data[:n]))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
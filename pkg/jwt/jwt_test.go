@@ -0,0 +1,329 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	hmacSecret = []byte("secret")
+	rsaKey     = mustRSAKey()
+	ecdsaKey   = mustECDSAKey()
+	now        = time.Unix(1500000000, 0)
+)
+
+func TestVerifier(t *testing.T) {
+
+	keys := NewKeySet()
+	keys.AddHMACKey("hmac", hmacSecret)
+	if err := keys.AddPublicKey("rsa", &rsaKey.PublicKey); err != nil {
+		panic(err)
+	}
+	if err := keys.AddPublicKey("ecdsa", &ecdsaKey.PublicKey); err != nil {
+		panic(err)
+	}
+
+	newVerifier := func() *Verifier {
+		verifier := NewVerifier(keys)
+		verifier.Now = func() time.Time {
+			return now
+		}
+		return verifier
+	}
+
+	run := func(verifier *Verifier, token, wantClaims string) {
+		claims, err := verifier.Verify([]byte(token))
+		if err != nil {
+			panic(err)
+		}
+		got, err := json.Marshal(claims)
+		if err != nil {
+			panic(err)
+		}
+		if wantClaims != string(got) {
+			panic(fmt.Errorf("want claims: %s, got: %s", wantClaims, string(got)))
+		}
+	}
+
+	runErr := func(verifier *Verifier, token, wantErr string) {
+		_, err := verifier.Verify([]byte(token))
+		if err == nil {
+			panic(fmt.Errorf("want err for token: %s", token))
+		}
+		if !strings.Contains(err.Error(), wantErr) {
+			panic(fmt.Errorf("want err containing: %s, got: %s", wantErr, err.Error()))
+		}
+		if _, ok := err.(Error); !ok {
+			panic(fmt.Errorf("want err of type Error, got: %T", err))
+		}
+	}
+
+	t.Run("HS256", func(t *testing.T) {
+		run(newVerifier(), sign(HS256, "hmac", `{"sub":"jsmith"}`), `{"sub":"jsmith"}`)
+	})
+	t.Run("RS256", func(t *testing.T) {
+		run(newVerifier(), sign(RS256, "rsa", `{"sub":"jsmith"}`), `{"sub":"jsmith"}`)
+	})
+	t.Run("ES256", func(t *testing.T) {
+		run(newVerifier(), sign(ES256, "ecdsa", `{"sub":"jsmith"}`), `{"sub":"jsmith"}`)
+	})
+	t.Run("without key id", func(t *testing.T) {
+		run(newVerifier(), sign(ES256, "", `{"sub":"jsmith"}`), `{"sub":"jsmith"}`)
+	})
+	t.Run("invalid signature", func(t *testing.T) {
+		token := sign(HS256, "hmac", `{"sub":"jsmith"}`)
+		tampered := strings.Split(token, ".")
+		tampered[1] = base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"admin"}`))
+		runErr(newVerifier(), strings.Join(tampered, "."), "invalid signature")
+	})
+	t.Run("unknown key id", func(t *testing.T) {
+		runErr(newVerifier(), sign(RS256, "other", `{}`), "no key found for algorithm 'RS256' and key id 'other'")
+	})
+	t.Run("unsupported algorithms", func(t *testing.T) {
+		header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`))
+		claims := base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"admin"}`))
+		runErr(newVerifier(), header+"."+claims+".", "unsupported algorithm 'none'")
+	})
+	t.Run("algorithm must match the key", func(t *testing.T) {
+		// a HS256 token signed with the public RSA key must not be accepted
+		publicKey, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+		if err != nil {
+			panic(err)
+		}
+		runErr(newVerifier(), signHMAC(publicKey, "rsa", `{"sub":"admin"}`), "no key found for algorithm 'HS256' and key id 'rsa'")
+	})
+	t.Run("malformed tokens", func(t *testing.T) {
+		runErr(newVerifier(), "abc", "malformed token")
+		runErr(newVerifier(), "a.b.c", "malformed header")
+		token := strings.Split(sign(HS256, "hmac", `{}`), ".")
+		runErr(newVerifier(), token[0]+"."+token[1]+".!", "malformed signature")
+		runErr(newVerifier(), sign(HS256, "hmac", `[]`), "malformed claims")
+	})
+	t.Run("expiration", func(t *testing.T) {
+		run(newVerifier(), sign(HS256, "hmac", `{"exp":1500000001}`), `{"exp":1500000001}`)
+		runErr(newVerifier(), sign(HS256, "hmac", `{"exp":1500000000}`), "token is expired")
+		runErr(newVerifier(), sign(HS256, "hmac", `{"exp":"tomorrow"}`), "invalid 'exp' claim")
+
+		verifier := newVerifier()
+		verifier.Leeway = time.Minute
+		run(verifier, sign(HS256, "hmac", `{"exp":1499999990}`), `{"exp":1499999990}`)
+	})
+	t.Run("not before", func(t *testing.T) {
+		run(newVerifier(), sign(HS256, "hmac", `{"nbf":1500000000}`), `{"nbf":1500000000}`)
+		runErr(newVerifier(), sign(HS256, "hmac", `{"nbf":1500000001}`), "token is not valid yet")
+	})
+	t.Run("issuer and audience", func(t *testing.T) {
+		verifier := newVerifier()
+		verifier.Issuer = "https://issuer.example.org"
+		verifier.Audience = "api"
+		run(verifier, sign(HS256, "hmac", `{"aud":"api","iss":"https://issuer.example.org"}`), `{"aud":"api","iss":"https://issuer.example.org"}`)
+		run(verifier, sign(HS256, "hmac", `{"aud":["web","api"],"iss":"https://issuer.example.org"}`), `{"aud":["web","api"],"iss":"https://issuer.example.org"}`)
+		runErr(verifier, sign(HS256, "hmac", `{"aud":"api","iss":"https://other.example.org"}`), "invalid issuer 'https://other.example.org'")
+		runErr(verifier, sign(HS256, "hmac", `{"aud":["web"],"iss":"https://issuer.example.org"}`), "token is not intended for audience 'api'")
+	})
+	t.Run("authorization header", func(t *testing.T) {
+		verifier := newVerifier()
+		claims, err := verifier.VerifyAuthorizationHeader([]byte("Bearer " + sign(HS256, "hmac", `{"sub":"jsmith"}`)))
+		if err != nil {
+			panic(err)
+		}
+		if claims["sub"] != "jsmith" {
+			panic(fmt.Errorf("want sub: jsmith, got: %v", claims["sub"]))
+		}
+		_, err = verifier.VerifyAuthorizationHeader([]byte("Basic dXNlcjpwYXNz"))
+		if err == nil {
+			panic("want err for basic auth")
+		}
+	})
+}
+
+func TestClaims_Value(t *testing.T) {
+	claims := Claims{
+		"sub": "jsmith",
+		"realm_access": map[string]interface{}{
+			"roles": []interface{}{"admin"},
+		},
+	}
+
+	run := func(path string, want interface{}, wantOk bool) {
+		got, ok := claims.Value(path)
+		if ok != wantOk || fmt.Sprint(got) != fmt.Sprint(want) {
+			panic(fmt.Errorf("want: %v (%t), got: %v (%t)", want, wantOk, got, ok))
+		}
+	}
+
+	run("sub", "jsmith", true)
+	run("realm_access.roles", []interface{}{"admin"}, true)
+	run("realm_access.groups", nil, false)
+	run("sub.name", nil, false)
+}
+
+func TestKeySet(t *testing.T) {
+
+	verify := func(keys *KeySet, token string) {
+		verifier := NewVerifier(keys)
+		_, err := verifier.Verify([]byte(token))
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	t.Run("jwks file", func(t *testing.T) {
+		jwks := fmt.Sprintf(`{"keys":[
+			{"kty":"RSA","kid":"rsa","use":"sig","n":"%s","e":"%s"},
+			{"kty":"EC","kid":"ecdsa","crv":"P-256","x":"%s","y":"%s"},
+			{"kty":"oct","kid":"hmac","k":"%s"},
+			{"kty":"RSA","kid":"encryption","use":"enc","n":"","e":""}
+		]}`,
+			encodeBigInt(rsaKey.N), encodeBigInt(big.NewInt(int64(rsaKey.E))),
+			encodeBigInt(ecdsaKey.X), encodeBigInt(ecdsaKey.Y),
+			base64.RawURLEncoding.EncodeToString(hmacSecret),
+		)
+
+		dir, err := ioutil.TempDir("", "jwks")
+		if err != nil {
+			panic(err)
+		}
+		defer os.RemoveAll(dir)
+
+		path := filepath.Join(dir, "jwks.json")
+		err = ioutil.WriteFile(path, []byte(jwks), 0600)
+		if err != nil {
+			panic(err)
+		}
+
+		keys := NewKeySet()
+		err = keys.LoadJWKSFile(path)
+		if err != nil {
+			panic(err)
+		}
+
+		verify(keys, sign(RS256, "rsa", `{}`))
+		verify(keys, sign(ES256, "ecdsa", `{}`))
+		verify(keys, sign(HS256, "hmac", `{}`))
+	})
+	t.Run("pem", func(t *testing.T) {
+		der, err := x509.MarshalPKIXPublicKey(&ecdsaKey.PublicKey)
+		if err != nil {
+			panic(err)
+		}
+		keys := NewKeySet()
+		err = keys.AddPublicKeyPEM("", pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+		if err != nil {
+			panic(err)
+		}
+		verify(keys, sign(ES256, "ecdsa", `{}`))
+	})
+	t.Run("invalid keys", func(t *testing.T) {
+		runErr := func(jwks string) {
+			err := NewKeySet().AddJWKS([]byte(jwks))
+			if err == nil {
+				panic(fmt.Errorf("want err for jwks: %s", jwks))
+			}
+		}
+		runErr(`{"keys":`)
+		runErr(`{"keys":[{"kty":"OKP"}]}`)
+		runErr(`{"keys":[{"kty":"EC","crv":"P-384","x":"AQ","y":"AQ"}]}`)
+		runErr(`{"keys":[{"kty":"EC","crv":"P-256","x":"AQ","y":"AQ"}]}`)
+		runErr(`{"keys":[{"kty":"RSA","n":"","e":"AQAB"}]}`)
+
+		p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+		if err != nil {
+			panic(err)
+		}
+		if NewKeySet().AddPublicKey("", &p384.PublicKey) == nil {
+			panic("want err for P-384 key")
+		}
+		if NewKeySet().AddPublicKeyPEM("", []byte("not a key")) == nil {
+			panic("want err for invalid PEM")
+		}
+		err = NewKeySet().LoadJWKSFile("does/not/exist.json")
+		if err == nil {
+			panic("want err for missing file")
+		}
+	})
+}
+
+// sign creates a token with the test key for the algorithm
+func sign(algorithm, keyID, claims string) string {
+
+	if algorithm == HS256 {
+		return signHMAC(hmacSecret, keyID, claims)
+	}
+
+	signingInput := signingInput(algorithm, keyID, claims)
+	digest := sha256.Sum256([]byte(signingInput))
+
+	var signature []byte
+
+	switch algorithm {
+	case RS256:
+		var err error
+		signature, err = rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+		if err != nil {
+			panic(err)
+		}
+	case ES256:
+		r, s, err := ecdsa.Sign(rand.Reader, ecdsaKey, digest[:])
+		if err != nil {
+			panic(err)
+		}
+		signature = make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func signHMAC(secret []byte, keyID, claims string) string {
+	signingInput := signingInput(HS256, keyID, claims)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signingInput))
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func signingInput(algorithm, keyID, claims string) string {
+	header := fmt.Sprintf(`{"alg":"%s","typ":"JWT"}`, algorithm)
+	if keyID != "" {
+		header = fmt.Sprintf(`{"alg":"%s","kid":"%s","typ":"JWT"}`, algorithm, keyID)
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(header)) + "." + base64.RawURLEncoding.EncodeToString([]byte(claims))
+}
+
+func encodeBigInt(value *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(value.Bytes())
+}
+
+func mustRSAKey() *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
+
+func mustECDSAKey() *ecdsa.PrivateKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
@@ -0,0 +1,193 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+)
+
+// Key is a key to verify tokens with
+type Key struct {
+	// ID is matched against the 'kid' header of tokens, keys without ID are tried for all tokens
+	ID string
+	// Algorithm is the algorithm the key is used with: HS256, RS256 or ES256
+	Algorithm string
+	key       interface{}
+}
+
+// KeySet is a set of keys, e.g. the current and the previous key during a key rotation
+type KeySet struct {
+	keys []Key
+}
+
+func NewKeySet() *KeySet {
+	return &KeySet{}
+}
+
+// AddHMACKey adds a shared secret to verify HS256 tokens
+func (k *KeySet) AddHMACKey(id string, secret []byte) {
+	k.keys = append(k.keys, Key{
+		ID:        id,
+		Algorithm: HS256,
+		key:       secret,
+	})
+}
+
+// AddPublicKey adds a RSA public key to verify RS256 tokens or a P-256 ECDSA public key to verify ES256 tokens
+func (k *KeySet) AddPublicKey(id string, key crypto.PublicKey) error {
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		k.keys = append(k.keys, Key{ID: id, Algorithm: RS256, key: key})
+	case *ecdsa.PublicKey:
+		if key.Curve != elliptic.P256() {
+			return fmt.Errorf("AddPublicKey: unsupported curve %s", key.Curve.Params().Name)
+		}
+		k.keys = append(k.keys, Key{ID: id, Algorithm: ES256, key: key})
+	default:
+		return fmt.Errorf("AddPublicKey: unsupported key type %T", key)
+	}
+	return nil
+}
+
+// AddPublicKeyPEM adds a PEM encoded PKIX public key, see AddPublicKey
+func (k *KeySet) AddPublicKeyPEM(id string, data []byte) error {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return fmt.Errorf("AddPublicKeyPEM: no PEM block found")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return fmt.Errorf("AddPublicKeyPEM: %s", err)
+	}
+	return k.AddPublicKey(id, key)
+}
+
+// AddJWKS adds all keys of a json web key set as specified in https://tools.ietf.org/html/rfc7517
+// RSA, P-256 EC and symmetric (oct) keys are supported, keys for other uses than signatures are skipped
+func (k *KeySet) AddJWKS(data []byte) error {
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+
+	err := json.Unmarshal(data, &set)
+	if err != nil {
+		return fmt.Errorf("AddJWKS: %s", err)
+	}
+
+	for i := range set.Keys {
+		err = k.addJSONWebKey(set.Keys[i])
+		if err != nil {
+			return fmt.Errorf("AddJWKS: key %d: %s", i, err)
+		}
+	}
+
+	return nil
+}
+
+// LoadJWKSFile adds the keys of a json web key set file, see AddJWKS
+func (k *KeySet) LoadJWKSFile(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("LoadJWKSFile: %s", err)
+	}
+	return k.AddJWKS(data)
+}
+
+// candidates returns the keys to try for a token with the given key id and algorithm
+func (k *KeySet) candidates(id, algorithm string) []Key {
+	candidates := make([]Key, 0, len(k.keys))
+	for i := range k.keys {
+		if k.keys[i].Algorithm != algorithm {
+			continue
+		}
+		if id != "" && k.keys[i].ID != "" && k.keys[i].ID != id {
+			continue
+		}
+		candidates = append(candidates, k.keys[i])
+	}
+	return candidates
+}
+
+type jsonWebKey struct {
+	KeyType   string `json:"kty"`
+	ID        string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	// RSA
+	N string `json:"n"`
+	E string `json:"e"`
+	// EC
+	Curve string `json:"crv"`
+	X     string `json:"x"`
+	Y     string `json:"y"`
+	// oct
+	K string `json:"k"`
+}
+
+func (k *KeySet) addJSONWebKey(key jsonWebKey) error {
+
+	if key.Use != "" && key.Use != "sig" {
+		return nil
+	}
+
+	switch key.KeyType {
+	case "RSA":
+		n, err := decodeBigInt(key.N)
+		if err != nil {
+			return err
+		}
+		e, err := decodeBigInt(key.E)
+		if err != nil {
+			return err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return fmt.Errorf("invalid exponent")
+		}
+		return k.AddPublicKey(key.ID, &rsa.PublicKey{N: n, E: int(e.Int64())})
+	case "EC":
+		if key.Curve != "P-256" {
+			return fmt.Errorf("unsupported curve %s", key.Curve)
+		}
+		x, err := decodeBigInt(key.X)
+		if err != nil {
+			return err
+		}
+		y, err := decodeBigInt(key.Y)
+		if err != nil {
+			return err
+		}
+		if !elliptic.P256().IsOnCurve(x, y) {
+			return fmt.Errorf("point is not on curve")
+		}
+		return k.AddPublicKey(key.ID, &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y})
+	case "oct":
+		secret, err := base64.RawURLEncoding.DecodeString(key.K)
+		if err != nil {
+			return err
+		}
+		k.AddHMACKey(key.ID, secret)
+		return nil
+	default:
+		return fmt.Errorf("unsupported key type %s", key.KeyType)
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty value")
+	}
+	return new(big.Int).SetBytes(data), nil
+}
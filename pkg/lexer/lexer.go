@@ -141,7 +141,7 @@ func (l *Lexer) Read() (tok token.Token) {
 		return
 	}
 
-	if runeIsDigit(next) {
+	if runeIsDigit(next) || next == runes.NEGATIVESIGN && runeIsDigit(l.peekRune(false)) {
 		l.readDigit(&tok)
 		return
 	}
@@ -208,7 +208,7 @@ func (l *Lexer) keywordFromRune(r byte) keyword.Keyword {
 		return keyword.DOT
 	}
 
-	if runeIsDigit(r) {
+	if runeIsDigit(r) || r == runes.NEGATIVESIGN && l.peekIsNegativeNumber() {
 		if l.peekIsFloat() {
 			return keyword.FLOAT
 		}
@@ -224,6 +224,9 @@ func (l *Lexer) peekIsFloat() (isFloat bool) {
 	var peeked byte
 
 	start := l.inputPosition + l.peekWhitespaceLength()
+	if start < len(l.input) && l.input[start] == runes.NEGATIVESIGN {
+		start++
+	}
 
 	for i := start; i < len(l.input); i++ {
 
@@ -243,6 +246,12 @@ func (l *Lexer) peekIsFloat() (isFloat bool) {
 	return hasDot
 }
 
+// peekIsNegativeNumber reports whether the next sequence is a negative sign followed by a digit
+func (l *Lexer) peekIsNegativeNumber() bool {
+	start := l.inputPosition + l.peekWhitespaceLength()
+	return start+1 < len(l.input) && l.input[start] == runes.NEGATIVESIGN && runeIsDigit(l.input[start+1])
+}
+
 func (l *Lexer) matchSingleRuneToken(r byte, tok *token.Token) bool {
 
 	switch r {
@@ -150,6 +150,12 @@ func TestLexer_Peek_Read(t *testing.T) {
 	t.Run("read integer with comma", func(t *testing.T) {
 		run("1337,", mustPeekAndRead(keyword.INTEGER, "1337"))
 	})
+	t.Run("read negative integer", func(t *testing.T) {
+		run("-1337", mustPeekAndRead(keyword.INTEGER, "-1337"))
+	})
+	t.Run("read negative float", func(t *testing.T) {
+		run("-13.37", mustPeekAndRead(keyword.FLOAT, "-13.37"))
+	})
 	t.Run("read float", func(t *testing.T) {
 		run("13.37", mustPeekAndRead(keyword.FLOAT, "13.37"))
 	})
@@ -20,6 +20,7 @@ var (
 	EQUALS         = []byte("=")
 	NEGATIVESIGN   = []byte("-")
 	AND            = []byte("&")
+	COMMENT        = []byte("#")
 
 	BRACKETOPEN        = []byte("(")
 	BRACKETCLOSE       = []byte(")")
@@ -53,7 +54,9 @@ var (
 	MUTATION     = []byte("mutation")
 	SUBSCRIPTION = []byte("subscription")
 	IMPLEMENTS   = []byte("implements")
+	EXTEND       = []byte("extend")
 	ON           = []byte("on")
+	REPEATABLE   = []byte("repeatable")
 	FRAGMENT     = []byte("fragment")
 	NULL         = []byte("null")
 
@@ -41,6 +41,11 @@ func (f *FragmentDefinitionIterable) Value() document.FragmentDefinition {
 	return f.w.l.FragmentDefinition(f.node().Ref)
 }
 
+// Ref returns the reference of the current fragment definition
+func (f *FragmentDefinitionIterable) Ref() int {
+	return f.node().Ref
+}
+
 func (w *Walker) FragmentDefinitionIterable() FragmentDefinitionIterable {
 	return FragmentDefinitionIterable{
 		Iterable: w.newIterable(w.c.fragmentDefinitions),
@@ -69,6 +74,11 @@ func (o *OperationDefinitionIterable) Value() document.OperationDefinition {
 	return o.w.l.OperationDefinition(o.node().Ref)
 }
 
+// Ref returns the reference of the current operation definition
+func (o *OperationDefinitionIterable) Ref() int {
+	return o.node().Ref
+}
+
 func (w *Walker) OperationDefinitionIterable() OperationDefinitionIterable {
 	return OperationDefinitionIterable{
 		Iterable: w.newIterable(w.c.operationDefinition),
@@ -165,7 +175,10 @@ func (w *Walker) TypeSystemDefinitionOrderedRootNodes() TypeSystemDefinitionOrde
 	sort.Slice(refs, func(i, j int) bool {
 		left := refs[i]
 		right := refs[j]
-		return w.nodes[left].Position.LineStart < w.nodes[right].Position.LineStart
+		if w.nodes[left].Position.LineStart != w.nodes[right].Position.LineStart {
+			return w.nodes[left].Position.LineStart < w.nodes[right].Position.LineStart
+		}
+		return w.nodes[left].Position.CharStart < w.nodes[right].Position.CharStart
 	})
 
 	return TypeSystemDefinitionOrderedRootNodes{
@@ -47,6 +47,42 @@ func (l *Lookup) OperationDefinitions() document.OperationDefinitions {
 	return l.p.ParsedDefinitions.OperationDefinitions
 }
 
+// SchemaDefinition returns the first schema definition of the type system definition
+func (l *Lookup) SchemaDefinition() (document.SchemaDefinition, bool) {
+	if len(l.p.ParsedDefinitions.SchemaDefinitions) == 0 {
+		return document.SchemaDefinition{}, false
+	}
+	return l.p.ParsedDefinitions.SchemaDefinitions[0], true
+}
+
+func (l *Lookup) ObjectTypeDefinitions() document.ObjectTypeDefinitions {
+	return l.p.ParsedDefinitions.ObjectTypeDefinitions
+}
+
+func (l *Lookup) InterfaceTypeDefinitions() document.InterfaceTypeDefinitions {
+	return l.p.ParsedDefinitions.InterfaceTypeDefinitions
+}
+
+func (l *Lookup) UnionTypeDefinitions() document.UnionTypeDefinitions {
+	return l.p.ParsedDefinitions.UnionTypeDefinitions
+}
+
+func (l *Lookup) EnumTypeDefinitions() []document.EnumTypeDefinition {
+	return l.p.ParsedDefinitions.EnumTypeDefinitions
+}
+
+func (l *Lookup) InputObjectTypeDefinitions() document.InputObjectTypeDefinitions {
+	return l.p.ParsedDefinitions.InputObjectTypeDefinitions
+}
+
+func (l *Lookup) ScalarTypeDefinitions() document.ScalarTypeDefinitions {
+	return l.p.ParsedDefinitions.ScalarTypeDefinitions
+}
+
+func (l *Lookup) DirectiveDefinitions() document.DirectiveDefinitions {
+	return l.p.ParsedDefinitions.DirectiveDefinitions
+}
+
 func (l *Lookup) ByteSlice(reference document.ByteSliceReference) document.ByteSlice {
 	return l.p.ByteSlice(reference)
 }
@@ -674,6 +710,15 @@ func (l *Lookup) ArgumentsDefinition(i int) document.ArgumentsDefinition {
 	return l.p.ParsedDefinitions.ArgumentsDefinitions[i]
 }
 
+func (l *Lookup) InputFieldsDefinition(i int) document.InputFieldsDefinition {
+	if i == -1 {
+		return document.InputFieldsDefinition{
+			InputValueDefinitions: document.NewInputValueDefinitions(-1),
+		}
+	}
+	return l.p.ParsedDefinitions.InputFieldsDefinitions[i]
+}
+
 func (l *Lookup) InputValueDefinitionByNameFromDefinitions(name document.ByteSliceReference, definitions document.InputValueDefinitions) (document.InputValueDefinition, bool) {
 
 	for definitions.Next(l.p) {
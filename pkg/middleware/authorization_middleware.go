@@ -0,0 +1,616 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/jensneuse/graphql-go-tools/pkg/document"
+	"github.com/jensneuse/graphql-go-tools/pkg/graphqlerror"
+	"github.com/jensneuse/graphql-go-tools/pkg/lookup"
+	"github.com/jensneuse/graphql-go-tools/pkg/parser"
+	"github.com/jensneuse/graphql-go-tools/pkg/responsewalker"
+	"github.com/tidwall/sjson"
+)
+
+/*
+directive @hasRole(
+	roles: [String!]!
+) on FIELD_DEFINITION
+
+directive @requiresScope(
+	scopes: [String!]!
+) on FIELD_DEFINITION
+*/
+
+/*
+AuthorizationMiddleware restricts the access to fields based on the roles and scopes of the client
+
+example schema:
+
+	type Query {
+		documents: [Document] @requiresScope(scopes: ["documents:read"])
+	}
+
+	type Document {
+		owner: String
+		sensitiveInformation: String @hasRole(roles: ["admin", "owner"])
+	}
+
+@hasRole requires at least one of the roles, @requiresScope requires all of the scopes
+roles and scopes are read from the context, they should be set from the claims of a verified token by RequestConfig.Authentication:
+
+	Authentication: &proxy.Authentication{
+		Verifier:        jwt.NewVerifier(keys),
+		ClaimsToContext: map[string]string{"realm_access.roles": "roles", "scope": "scopes"},
+	}
+
+don't set them with RequestConfig.AddHeadersToContext, headers are sent by the client so any client could claim e.g. the role "admin",
+Authentication clears the context keys of the roles and scopes so that headers of the same name are ignored
+they might be a comma or space separated string, a json array of strings or a slice of strings
+
+unauthorized fields are removed from the request before it's sent to the backend,
+in the response they're set to null and an error with the path of the field is added:
+
+	query myDocuments {
+		documents {
+			owner
+			sensitiveInformation
+		}
+	}
+
+Response for a client with scope "documents:read" but without role:
+
+{"errors":[{"message":"not authorized to access field 'Document.sensitiveInformation'","path":["documents",0,"sensitiveInformation"]}],"data":{"documents":[{"owner":"jsmith","sensitiveInformation":null}]}}
+
+unauthorized fields of a non null type can't be set to null, requests selecting them are rejected
+if RejectUnauthorized is set all requests selecting unauthorized fields are rejected
+rejected requests fail with an AuthorizationError which the proxies answer with status code 403:
+
+{"errors":[{"message":"not authorized to access field 'Document.sensitiveInformation'","path":["documents","sensitiveInformation"],"extensions":{"code":"FORBIDDEN"}}]}
+*/
+type AuthorizationMiddleware struct {
+	// RolesContextKey is the context key of the roles of the client, defaults to DefaultRolesContextKey
+	RolesContextKey string
+	// ScopesContextKey is the context key of the scopes of the client, defaults to DefaultScopesContextKey
+	ScopesContextKey string
+	// RejectUnauthorized rejects requests selecting unauthorized fields instead of removing the fields
+	RejectUnauthorized bool
+
+	// pruned are the fields replaced during OnRequest with their error message
+	// it's keyed by the parser because the middleware is shared by all invokers
+	mux    sync.Mutex
+	pruned map[*parser.Parser]map[int]string
+}
+
+const (
+	// DefaultRolesContextKey is the default context key of the roles of the client
+	DefaultRolesContextKey = "roles"
+	// DefaultScopesContextKey is the default context key of the scopes of the client
+	DefaultScopesContextKey = "scopes"
+)
+
+var authorizationMiddlewareSchemaExtension = []byte(`
+directive @hasRole(
+	roles: [String!]!
+) on FIELD_DEFINITION
+directive @requiresScope(
+	scopes: [String!]!
+) on FIELD_DEFINITION`)
+
+var (
+	hasRoleDirectiveName       = []byte("hasRole")
+	requiresScopeDirectiveName = []byte("requiresScope")
+	rolesArgumentName          = []byte("roles")
+	scopesArgumentName         = []byte("scopes")
+)
+
+func (a *AuthorizationMiddleware) PrepareSchema(ctx context.Context, l *lookup.Lookup, w *lookup.Walker, parser *parser.Parser, mod *parser.ManualAstMod) error {
+	return parser.ExtendTypeSystemDefinition(authorizationMiddlewareSchemaExtension)
+}
+
+func (a *AuthorizationMiddleware) OnRequest(ctx context.Context, l *lookup.Lookup, w *lookup.Walker, parser *parser.Parser, mod *parser.ManualAstMod) error {
+
+	a.setPruned(parser, nil)
+
+	authorizer := fieldAuthorizer{
+		l:      l,
+		mod:    mod,
+		roles:  claimsFromContext(ctx, a.contextKey(a.RolesContextKey, DefaultRolesContextKey)),
+		scopes: claimsFromContext(ctx, a.contextKey(a.ScopesContextKey, DefaultScopesContextKey)),
+		reject: a.RejectUnauthorized,
+		pruned: map[int]string{},
+	}
+
+	err := authorizer.authorize(w)
+	if err != nil {
+		return err
+	}
+
+	if len(authorizer.pruned) != 0 {
+		a.setPruned(parser, authorizer.pruned)
+	}
+
+	return nil
+}
+
+func (a *AuthorizationMiddleware) OnResponse(ctx context.Context, response *[]byte, l *lookup.Lookup, w *lookup.Walker, parser *parser.Parser, mod *parser.ManualAstMod) (err error) {
+
+	pruned := a.getPruned(parser)
+	if len(pruned) == 0 {
+		return nil
+	}
+
+	operationName := ""
+	if request, ok := GraphQLRequestFromContext(ctx); ok {
+		operationName = request.OperationName
+	}
+
+	editor := responsewalker.NewEditor()
+	walker := responsewalker.New()
+	walker.SetInput(l)
+
+	var errors [][]byte
+
+	err = walker.Walk(operationName, *response, func(field responsewalker.Field) error {
+		message, ok := pruned[field.Ref]
+		if !ok {
+			return nil
+		}
+		editor.Replace(field.Offset, field.Value, []byte("null"))
+		graphqlError, err := json.Marshal(authorizationError{
+			Message: message,
+			Path:    responsePath(field.Path),
+		})
+		if err != nil {
+			return err
+		}
+		errors = append(errors, graphqlError)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	*response, err = editor.Apply(*response)
+	if err != nil {
+		return err
+	}
+
+	for i := range errors {
+		*response, err = sjson.SetRawBytes(*response, "errors.-1", errors[i])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (a *AuthorizationMiddleware) contextKey(key, defaultKey string) string {
+	if key == "" {
+		return defaultKey
+	}
+	return key
+}
+
+func (a *AuthorizationMiddleware) setPruned(p *parser.Parser, pruned map[int]string) {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+	if pruned == nil {
+		delete(a.pruned, p)
+		return
+	}
+	if a.pruned == nil {
+		a.pruned = map[*parser.Parser]map[int]string{}
+	}
+	a.pruned[p] = pruned
+}
+
+func (a *AuthorizationMiddleware) getPruned(p *parser.Parser) map[int]string {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+	return a.pruned[p]
+}
+
+type authorizationError struct {
+	Message string        `json:"message"`
+	Path    []interface{} `json:"path"`
+}
+
+// AuthorizationError rejects requests selecting unauthorized fields
+type AuthorizationError struct {
+	Message string
+	// Path are the response keys from the operation to the field,
+	// it's empty for fields of fragment definitions because they might be spread anywhere
+	Path []string
+}
+
+func (e AuthorizationError) Error() string {
+	return "AuthorizationMiddleware: " + e.Message
+}
+
+func (e AuthorizationError) StatusCode() int {
+	return http.StatusForbidden
+}
+
+// Response returns the graphql response for the error, e.g.:
+// {"errors":[{"message":"not authorized to access field 'Document.classification'","path":["documents","classification"],"extensions":{"code":"FORBIDDEN"}}]}
+func (e AuthorizationError) Response() []byte {
+	var path []interface{}
+	for i := range e.Path {
+		path = append(path, e.Path[i])
+	}
+	return graphqlerror.Response(graphqlerror.Error{Message: e.Message, Path: path, Extensions: graphqlerror.Extensions{Code: "FORBIDDEN"}})
+}
+
+// responsePath converts a response walker path, e.g. 'data.documents.0.owner', to a graphql error path
+func responsePath(path string) []interface{} {
+	elements := strings.Split(strings.TrimPrefix(path, "data."), ".")
+	out := make([]interface{}, len(elements))
+	for i := range elements {
+		if index, err := strconv.Atoi(elements[i]); err == nil {
+			out[i] = index
+			continue
+		}
+		out[i] = elements[i]
+	}
+	return out
+}
+
+// claimsFromContext returns the roles or scopes stored in the context
+func claimsFromContext(ctx context.Context, key string) []string {
+
+	if ctx == nil {
+		return nil
+	}
+
+	switch value := ctx.Value(key).(type) {
+	case []string:
+		return value
+	case []interface{}:
+		claims := make([]string, 0, len(value))
+		for i := range value {
+			if claim, ok := value[i].(string); ok {
+				claims = append(claims, claim)
+			}
+		}
+		return claims
+	case []byte:
+		return parseClaims(string(value))
+	case string:
+		return parseClaims(value)
+	default:
+		return nil
+	}
+}
+
+func parseClaims(value string) []string {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "[") {
+		var claims []string
+		if err := json.Unmarshal([]byte(value), &claims); err == nil {
+			return claims
+		}
+	}
+	return strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '"'
+	})
+}
+
+// fieldAuthorizer replaces unauthorized fields in the executable definition
+type fieldAuthorizer struct {
+	l      *lookup.Lookup
+	mod    *parser.ManualAstMod
+	roles  []string
+	scopes []string
+	reject bool
+	pruned map[int]string
+
+	typeNameRef document.ByteSliceReference
+	// path are the response keys of the fields enclosing the current selection set, nil inside fragment definitions
+	path []string
+}
+
+func (f *fieldAuthorizer) authorize(w *lookup.Walker) error {
+
+	usedBefore := f.usedVariables(w)
+
+	var err error
+	f.typeNameRef, _, err = f.mod.PutLiteralBytes([]byte("__typename"))
+	if err != nil {
+		return err
+	}
+
+	operations := f.l.OperationDefinitions()
+	for i := range operations {
+		f.path = make([]string, 0, 8)
+		err := f.selectionSet(operations[i].SelectionSet, operationRootTypeName(f.l, operations[i]))
+		if err != nil {
+			return err
+		}
+	}
+
+	fragments := f.l.FragmentDefinitions()
+	f.path = nil
+	for i := range fragments {
+		err := f.selectionSet(fragments[i].SelectionSet, f.l.Type(fragments[i].TypeCondition).Name)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(f.pruned) == 0 {
+		return nil
+	}
+
+	// variables which were only used by removed fields must be removed as well to keep the request valid
+	usedAfter := f.usedVariables(w)
+	for i := range operations {
+		variables := f.l.VariableDefinitionIterator(operations[i].VariableDefinitions)
+		for variables.Next() {
+			variable, ref := variables.Value()
+			name := string(f.l.ByteSlice(variable.Variable))
+			if usedBefore[name] && !usedAfter[name] {
+				f.mod.DeleteVariableDefinitionFromOperation(ref, i)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (f *fieldAuthorizer) selectionSet(setRef int, typeName document.ByteSliceReference) error {
+
+	set := f.l.SelectionSet(setRef)
+
+	for _, fieldRef := range set.Fields {
+		field := f.l.Field(fieldRef)
+		if f.l.ByteSliceReferenceContentsEquals(field.Name, f.typeNameRef) {
+			continue
+		}
+
+		definition, authorized := f.fieldDefinition(typeName, field.Name)
+		if !authorized {
+			err := f.prune(fieldRef, setRef, field, definition, typeName)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		if field.SelectionSet == -1 {
+			continue
+		}
+
+		if f.path != nil {
+			f.path = append(f.path, f.responseKey(field))
+		}
+		err := f.selectionSet(field.SelectionSet, f.l.UnwrappedNamedType(f.l.Type(definition.Type)).Name)
+		if err != nil {
+			return err
+		}
+		if f.path != nil {
+			f.path = f.path[:len(f.path)-1]
+		}
+	}
+
+	for _, inlineFragmentRef := range set.InlineFragments {
+		inlineFragment := f.l.InlineFragment(inlineFragmentRef)
+		fragmentTypeName := typeName
+		if inlineFragment.TypeCondition != -1 {
+			fragmentTypeName = f.l.Type(inlineFragment.TypeCondition).Name
+		}
+		err := f.selectionSet(inlineFragment.SelectionSet, fragmentTypeName)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// operationRootTypeName returns the name of the root type of an operation, the schema definition might be omitted
+func operationRootTypeName(l *lookup.Lookup, operation document.OperationDefinition) document.ByteSliceReference {
+
+	var rootType document.ObjectTypeDefinition
+	var ok bool
+
+	switch operation.OperationType {
+	case document.OperationTypeMutation:
+		rootType, ok = l.MutationObjectTypeDefinition()
+	case document.OperationTypeSubscription:
+		rootType, ok = l.SubscriptionObjectTypeDefinition()
+	default:
+		rootType, ok = l.QueryObjectTypeDefinition()
+	}
+
+	if !ok {
+		return document.ByteSliceReference{}
+	}
+
+	return rootType.Name
+}
+
+// prune replaces the field with __typename aliased to the response key of the field
+// this keeps the selection set valid and the value is set to null in the response
+func (f *fieldAuthorizer) prune(fieldRef, setRef int, field document.Field, definition document.FieldDefinition, typeName document.ByteSliceReference) error {
+
+	message := fmt.Sprintf("not authorized to access field '%s.%s'", string(f.l.ByteSlice(typeName)), string(f.l.ByteSlice(field.Name)))
+
+	if f.reject {
+		return f.error(message, field)
+	}
+
+	if f.l.Type(definition.Type).Kind == document.TypeKindNON_NULL {
+		return f.error(message+" of non null type", field)
+	}
+
+	replacement := f.mod.PutField(document.Field{
+		Alias:        f.responseKeyRef(field),
+		Name:         f.typeNameRef,
+		ArgumentSet:  -1,
+		DirectiveSet: field.DirectiveSet,
+		SelectionSet: -1,
+	})
+
+	f.mod.ReplaceFieldInSelectionSet(fieldRef, replacement, setRef)
+	f.pruned[replacement] = message
+
+	return nil
+}
+
+func (f *fieldAuthorizer) error(message string, field document.Field) AuthorizationError {
+	var path []string
+	if f.path != nil {
+		path = append(append(path, f.path...), f.responseKey(field))
+	}
+	return AuthorizationError{
+		Message: message,
+		Path:    path,
+	}
+}
+
+// responseKeyRef returns the alias of the field or its name if there's none
+func (f *fieldAuthorizer) responseKeyRef(field document.Field) document.ByteSliceReference {
+	if field.Alias.Length() == 0 {
+		return field.Name
+	}
+	return field.Alias
+}
+
+func (f *fieldAuthorizer) responseKey(field document.Field) string {
+	return string(f.l.ByteSlice(f.responseKeyRef(field)))
+}
+
+// fieldDefinition returns the field definition on typeName and whether the client is authorized to access it
+// for abstract types the field definitions of all possible types must authorize the client
+func (f *fieldAuthorizer) fieldDefinition(typeName, fieldName document.ByteSliceReference) (definition document.FieldDefinition, authorized bool) {
+
+	definition, _ = f.l.FieldDefinitionByNameFromDefinitions(f.l.FieldsDefinitionFromNamedType(typeName), fieldName)
+
+	possibleTypeNames := make([]document.ByteSliceReference, 0, 8)
+	f.l.PossibleSelectionTypes(typeName, &possibleTypeNames)
+
+	for i := range possibleTypeNames {
+		possibleDefinition, ok := f.l.FieldDefinitionByNameFromDefinitions(f.l.FieldsDefinitionFromNamedType(possibleTypeNames[i]), fieldName)
+		if ok && !f.isAuthorized(possibleDefinition) {
+			return definition, false
+		}
+	}
+
+	return definition, true
+}
+
+func (f *fieldAuthorizer) isAuthorized(definition document.FieldDefinition) bool {
+
+	directives := f.l.DirectiveIterable(f.l.DirectiveSet(definition.DirectiveSet))
+	for directives.Next() {
+		directive, _ := directives.Value()
+		directiveName := f.l.ByteSlice(directive.Name)
+		switch {
+		case bytes.Equal(directiveName, hasRoleDirectiveName):
+			if !containsAny(f.roles, f.directiveArgument(directive, rolesArgumentName)) {
+				return false
+			}
+		case bytes.Equal(directiveName, requiresScopeDirectiveName):
+			if !containsAll(f.scopes, f.directiveArgument(directive, scopesArgumentName)) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// directiveArgument returns the strings of a [String!]! argument
+func (f *fieldAuthorizer) directiveArgument(directive document.Directive, name []byte) []string {
+
+	var values []string
+
+	args := f.l.ArgumentsIterable(f.l.ArgumentSet(directive.ArgumentSet))
+	for args.Next() {
+		arg, _ := args.Value()
+		if !bytes.Equal(f.l.ByteSlice(arg.Name), name) {
+			continue
+		}
+		value := f.l.Value(arg.Value)
+		switch value.ValueType {
+		case document.ValueTypeString:
+			values = append(values, string(f.l.ByteSlice(value.Raw)))
+		case document.ValueTypeList:
+			for _, itemRef := range f.l.ListValue(value.Reference) {
+				item := f.l.Value(itemRef)
+				if item.ValueType == document.ValueTypeString {
+					values = append(values, string(f.l.ByteSlice(item.Raw)))
+				}
+			}
+		}
+	}
+
+	return values
+}
+
+// usedVariables returns the names of all variables used as arguments in the executable definition
+func (f *fieldAuthorizer) usedVariables(w *lookup.Walker) map[string]bool {
+
+	used := map[string]bool{}
+
+	w.SetLookup(f.l)
+	w.WalkExecutable()
+
+	sets := w.ArgumentSetIterable()
+	for sets.Next() {
+		set, _ := sets.Value()
+		args := f.l.ArgumentsIterable(set)
+		for args.Next() {
+			arg, _ := args.Value()
+			f.collectVariables(arg.Value, used)
+		}
+	}
+
+	return used
+}
+
+func (f *fieldAuthorizer) collectVariables(valueRef int, used map[string]bool) {
+	value := f.l.Value(valueRef)
+	switch value.ValueType {
+	case document.ValueTypeVariable:
+		used[string(f.l.ByteSlice(value.Raw))] = true
+	case document.ValueTypeList:
+		for _, itemRef := range f.l.ListValue(value.Reference) {
+			f.collectVariables(itemRef, used)
+		}
+	case document.ValueTypeObject:
+		for _, fieldRef := range f.l.ObjectValue(value.Reference) {
+			f.collectVariables(f.l.ObjectField(fieldRef).Value, used)
+		}
+	}
+}
+
+// containsAny returns true if claims contain one of the required values
+func containsAny(claims, required []string) bool {
+	for i := range required {
+		for j := range claims {
+			if claims[j] == required[i] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// containsAll returns true if claims contain all required values
+func containsAll(claims, required []string) bool {
+	for i := range required {
+		if !containsAny(claims, required[i:i+1]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,196 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestAuthorizationMiddleware(t *testing.T) {
+
+	claims := func(roles, scopes interface{}) context.Context {
+		ctx := context.Background()
+		if roles != nil {
+			ctx = context.WithValue(ctx, "roles", roles)
+		}
+		if scopes != nil {
+			ctx = context.WithValue(ctx, "scopes", scopes)
+		}
+		return ctx
+	}
+
+	run := func(middleware *AuthorizationMiddleware, ctx context.Context, query, want string) {
+		got, err := InvokeMiddleware(middleware, ctx, authorizationMiddlewareSchema, query)
+		if err != nil {
+			panic(err)
+		}
+		if want != got {
+			panic(fmt.Errorf("want:\n%s\ngot:\n%s", want, got))
+		}
+	}
+
+	runErr := func(middleware *AuthorizationMiddleware, ctx context.Context, query, wantErr string) {
+		_, err := InvokeMiddleware(middleware, ctx, authorizationMiddlewareSchema, query)
+		if err == nil {
+			panic(fmt.Errorf("want err for query: %s", query))
+		}
+		if !strings.Contains(err.Error(), wantErr) {
+			panic(fmt.Errorf("want err containing: %s, got: %s", wantErr, err.Error()))
+		}
+	}
+
+	t.Run("authorized", func(t *testing.T) {
+		run(&AuthorizationMiddleware{}, claims("owner", "documents:read"),
+			`query myDocuments {documents {owner sensitiveInformation}}`,
+			`query myDocuments {documents {owner sensitiveInformation}}`)
+	})
+	t.Run("missing role", func(t *testing.T) {
+		run(&AuthorizationMiddleware{}, claims("reader", "documents:read"),
+			`query myDocuments {documents {owner sensitiveInformation}}`,
+			`query myDocuments {documents {owner sensitiveInformation:__typename}}`)
+	})
+	t.Run("missing scope", func(t *testing.T) {
+		run(&AuthorizationMiddleware{}, claims("admin", "documents:write"),
+			`query myDocuments {documents {owner} version}`,
+			`query myDocuments {documents:__typename version}`)
+	})
+	t.Run("all scopes are required", func(t *testing.T) {
+		run(&AuthorizationMiddleware{}, claims(nil, "documents:read"),
+			`{documents {history}}`,
+			`{documents {history:__typename}}`)
+		run(&AuthorizationMiddleware{}, claims(nil, "documents:read documents:history"),
+			`{documents {history}}`,
+			`{documents {history}}`)
+	})
+	t.Run("aliases and directives are kept", func(t *testing.T) {
+		run(&AuthorizationMiddleware{}, claims(nil, "documents:read"),
+			`query q($include: Boolean!) {documents {secret: sensitiveInformation @include(if: $include)}}`,
+			`query q($include:Boolean!) {documents {secret:__typename @include(if:$include)}}`)
+	})
+	t.Run("variables only used by removed fields are removed", func(t *testing.T) {
+		run(&AuthorizationMiddleware{}, claims(nil, "documents:read"),
+			`query q($id: ID, $unused: Int) {documents {sensitiveInformation(id: $id)}}`,
+			`query q($unused:Int) {documents {sensitiveInformation:__typename}}`)
+		run(&AuthorizationMiddleware{}, claims(nil, "documents:read"),
+			`query q($id: ID) {documents {owner(id: $id) sensitiveInformation(id: $id)}}`,
+			`query q($id:ID) {documents {owner(id:$id) sensitiveInformation:__typename}}`)
+	})
+	t.Run("fragments", func(t *testing.T) {
+		run(&AuthorizationMiddleware{}, claims(nil, "documents:read"),
+			`query q {documents {...documentFields ... on Document {sensitiveInformation}}} fragment documentFields on Document {owner sensitiveInformation}`,
+			"query q {documents {...documentFields ...on Document{sensitiveInformation:__typename}}}\nfragment documentFields on Document {owner sensitiveInformation:__typename}")
+	})
+	t.Run("fields of interfaces are checked on all implementations", func(t *testing.T) {
+		run(&AuthorizationMiddleware{}, claims(nil, nil),
+			`{nodes {id secret}}`,
+			`{nodes {id secret:__typename}}`)
+		run(&AuthorizationMiddleware{}, claims("admin", nil),
+			`{nodes {id secret}}`,
+			`{nodes {id secret}}`)
+	})
+	t.Run("claim formats", func(t *testing.T) {
+		query := `{documents {sensitiveInformation}}`
+		run(&AuthorizationMiddleware{}, claims([]byte("reader,owner"), []byte("documents:read")), query, query)
+		run(&AuthorizationMiddleware{}, claims(`["owner"]`, `["documents:read"]`), query, query)
+		run(&AuthorizationMiddleware{}, claims([]string{"owner"}, []string{"documents:read"}), query, query)
+		run(&AuthorizationMiddleware{}, claims([]interface{}{"owner"}, []interface{}{"documents:read"}), query, query)
+		run(&AuthorizationMiddleware{}, nil, query, `{documents:__typename}`)
+	})
+	t.Run("custom context keys", func(t *testing.T) {
+		ctx := context.WithValue(context.WithValue(context.Background(), "x-roles", "owner"), "x-scopes", "documents:read")
+		query := `{documents {sensitiveInformation}}`
+		run(&AuthorizationMiddleware{RolesContextKey: "x-roles", ScopesContextKey: "x-scopes"}, ctx, query, query)
+	})
+	t.Run("reject unauthorized", func(t *testing.T) {
+		runErr(&AuthorizationMiddleware{RejectUnauthorized: true}, claims(nil, "documents:read"),
+			`{documents {owner sensitiveInformation}}`,
+			"AuthorizationMiddleware: not authorized to access field 'Document.sensitiveInformation'")
+	})
+	t.Run("unauthorized non null field", func(t *testing.T) {
+		runErr(&AuthorizationMiddleware{}, claims(nil, "documents:read"),
+			`{documents {classification}}`,
+			"not authorized to access field 'Document.classification' of non null type")
+	})
+	t.Run("rejected fields carry their path", func(t *testing.T) {
+		runPath := func(query string, wantPath []string) {
+			_, err := InvokeMiddleware(&AuthorizationMiddleware{RejectUnauthorized: true}, claims(nil, "documents:read"), authorizationMiddlewareSchema, query)
+			authorizationErr, ok := err.(AuthorizationError)
+			if !ok {
+				panic(fmt.Errorf("want AuthorizationError, got: %v", err))
+			}
+			if fmt.Sprint(wantPath) != fmt.Sprint(authorizationErr.Path) {
+				panic(fmt.Errorf("want path: %v, got: %v", wantPath, authorizationErr.Path))
+			}
+		}
+		runPath(`{documents {owner sensitiveInformation}}`, []string{"documents", "sensitiveInformation"})
+		runPath(`{docs: documents {... on Document {secret: sensitiveInformation}}}`, []string{"docs", "secret"})
+		runPath(`{documents {...documentFields}} fragment documentFields on Document {sensitiveInformation}`, nil)
+	})
+}
+
+func TestAuthorizationMiddleware_OnResponse(t *testing.T) {
+
+	run := func(ctx context.Context, query, response, want string) {
+		got, err := InvokeMiddlewareOnResponse(&AuthorizationMiddleware{}, ctx, authorizationMiddlewareSchema, query, response)
+		if err != nil {
+			panic(err)
+		}
+		if want != got {
+			panic(fmt.Errorf("want:\n%s\ngot:\n%s", want, got))
+		}
+	}
+
+	ctx := context.WithValue(context.Background(), "scopes", "documents:read")
+
+	t.Run("unauthorized fields are set to null", func(t *testing.T) {
+		run(ctx, `query myDocuments {documents {owner secret: sensitiveInformation}}`,
+			`{"data":{"documents":[{"owner":"jsmith","secret":"Document"},{"owner":"jdoe","secret":"Document"}]}}`,
+			`{"errors":[`+
+				`{"message":"not authorized to access field 'Document.sensitiveInformation'","path":["documents",0,"secret"]},`+
+				`{"message":"not authorized to access field 'Document.sensitiveInformation'","path":["documents",1,"secret"]}],`+
+				`"data":{"documents":[{"owner":"jsmith","secret":null},{"owner":"jdoe","secret":null}]}}`)
+	})
+	t.Run("errors are appended to existing errors", func(t *testing.T) {
+		run(ctx, `{version documents {sensitiveInformation}}`,
+			`{"data":{"version":null,"documents":[{"sensitiveInformation":"Document"}]},"errors":[{"message":"failed","path":["version"]}]}`,
+			`{"data":{"version":null,"documents":[{"sensitiveInformation":null}]},"errors":[{"message":"failed","path":["version"]},`+
+				`{"message":"not authorized to access field 'Document.sensitiveInformation'","path":["documents",0,"sensitiveInformation"]}]}`)
+	})
+	t.Run("root fields", func(t *testing.T) {
+		run(nil, `{documents {owner}}`,
+			`{"data":{"documents":"Query"}}`,
+			`{"errors":[{"message":"not authorized to access field 'Query.documents'","path":["documents"]}],"data":{"documents":null}}`)
+	})
+	t.Run("authorized response is unchanged", func(t *testing.T) {
+		run(ctx, `{documents {owner}}`,
+			`{"data":{"documents":[{"owner":"jsmith"}]}}`,
+			`{"data":{"documents":[{"owner":"jsmith"}]}}`)
+	})
+}
+
+const authorizationMiddlewareSchema = `
+schema {
+	query: Query
+}
+
+type Query {
+	documents: [Document] @requiresScope(scopes: ["documents:read"])
+	nodes: [Node]
+	version: String
+}
+
+interface Node {
+	id: ID
+	secret: String
+}
+
+type Document implements Node {
+	id: ID
+	secret: String @hasRole(roles: ["admin"])
+	owner(id: ID): String
+	sensitiveInformation(id: ID): String @hasRole(roles: ["admin", "owner"])
+	history: [String] @requiresScope(scopes: ["documents:read", "documents:history"])
+	classification: String! @hasRole(roles: ["admin"])
+}
+`
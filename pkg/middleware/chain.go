@@ -0,0 +1,206 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/jensneuse/graphql-go-tools/pkg/document"
+	"github.com/jensneuse/graphql-go-tools/pkg/lookup"
+	"github.com/jensneuse/graphql-go-tools/pkg/parser"
+)
+
+// Phase orders the middlewares of a Chain, middlewares of an earlier phase handle the request first
+// and the response last because OnResponse is invoked in reverse order
+type Phase int
+
+const (
+	// PhaseNormalize maps the request of the client to the backend schema, e.g. the SchemaVisibilityMiddleware
+	PhaseNormalize Phase = iota
+	// PhaseAdmission rejects requests before they get processed any further, e.g. the RateLimitMiddleware
+	PhaseAdmission
+	// PhaseRewrite rewrites the request, e.g. the AuthorizationMiddleware
+	// it's the phase of all middlewares which don't implement PhasedMiddleware
+	PhaseRewrite
+	// PhaseValidation checks the request of the client before it's resolved or sent to the backend, e.g. the ValidationMiddleware
+	PhaseValidation
+	// PhaseEnrich adds what only the backend schema defines to validated requests, e.g. the ContextMiddleware adding arguments from the context
+	PhaseEnrich
+	// PhaseResolve answers valid requests without the backend, e.g. the IntrospectionMiddleware or the ResponseCacheMiddleware
+	PhaseResolve
+)
+
+// PhasedMiddleware might be implemented by middlewares which belong to a phase other than PhaseRewrite
+type PhasedMiddleware interface {
+	Phase() Phase
+}
+
+// PhaseOf returns the phase of a middleware
+func PhaseOf(middleware GraphqlMiddleware) Phase {
+	if phased, ok := middleware.(PhasedMiddleware); ok {
+		return phased.Phase()
+	}
+	return PhaseRewrite
+}
+
+/*
+Chain orders middlewares by their phase, middlewares of the same phase keep their order
+
+	Chain(&IntrospectionMiddleware{}, &ContextMiddleware{}, &ValidationMiddleware{})
+
+returns the ValidationMiddleware (PhaseValidation), the ContextMiddleware (PhaseEnrich) and the IntrospectionMiddleware (PhaseResolve),
+use InPhase to move a middleware to another phase
+*/
+func Chain(middleWares ...GraphqlMiddleware) []GraphqlMiddleware {
+	chain := make([]GraphqlMiddleware, len(middleWares))
+	copy(chain, middleWares)
+	sort.SliceStable(chain, func(i, j int) bool {
+		return PhaseOf(chain[i]) < PhaseOf(chain[j])
+	})
+	return chain
+}
+
+// InPhase returns the middleware assigned to phase
+func InPhase(phase Phase, middleware GraphqlMiddleware) GraphqlMiddleware {
+	return &phasedMiddleware{
+		GraphqlMiddleware: middleware,
+		phase:             phase,
+	}
+}
+
+type phasedMiddleware struct {
+	GraphqlMiddleware
+	phase Phase
+}
+
+func (p *phasedMiddleware) Phase() Phase {
+	return p.phase
+}
+
+func (p *phasedMiddleware) ResolveRequest(ctx context.Context, out io.Writer, l *lookup.Lookup, w *lookup.Walker, parser *parser.Parser, mod *parser.ManualAstMod) (resolved bool, err error) {
+	return resolveRequest(p.GraphqlMiddleware, ctx, out, l, w, parser, mod)
+}
+
+// Operation is the operation of a request which gets passed to a Predicate
+// Type is OperationTypeUnknown if the request doesn't select an operation, e.g. because the operation name is missing
+type Operation struct {
+	Name string
+	Type document.OperationType
+}
+
+// Predicate decides if a conditional middleware handles a request, see When
+type Predicate func(ctx context.Context, operation Operation) bool
+
+// OnOperationTypes returns a predicate matching operations of one of the types, e.g. document.OperationTypeMutation
+func OnOperationTypes(types ...document.OperationType) Predicate {
+	return func(ctx context.Context, operation Operation) bool {
+		for _, operationType := range types {
+			if operation.Type == operationType {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// OnOperationNames returns a predicate matching operations with one of the names
+func OnOperationNames(names ...string) Predicate {
+	return func(ctx context.Context, operation Operation) bool {
+		for _, name := range names {
+			if operation.Name == name {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+/*
+When returns a middleware which only handles requests matching the predicate
+
+	When(OnOperationTypes(document.OperationTypeMutation), &RateLimitMiddleware{...})
+
+limits mutations only, the predicate is evaluated once per request before OnRequest,
+the schema is prepared for all requests so that it doesn't depend on the request
+*/
+func When(predicate Predicate, middleware GraphqlMiddleware) GraphqlMiddleware {
+	return &conditionalMiddleware{
+		middleware: middleware,
+		predicate:  predicate,
+	}
+}
+
+type conditionalMiddleware struct {
+	middleware GraphqlMiddleware
+	predicate  Predicate
+
+	mux    sync.Mutex
+	active map[*parser.Parser]bool
+}
+
+func (c *conditionalMiddleware) Phase() Phase {
+	return PhaseOf(c.middleware)
+}
+
+func (c *conditionalMiddleware) PrepareSchema(ctx context.Context, l *lookup.Lookup, w *lookup.Walker, parser *parser.Parser, mod *parser.ManualAstMod) error {
+	return c.middleware.PrepareSchema(ctx, l, w, parser, mod)
+}
+
+func (c *conditionalMiddleware) OnRequest(ctx context.Context, l *lookup.Lookup, w *lookup.Walker, parser *parser.Parser, mod *parser.ManualAstMod) error {
+
+	var operation Operation
+	if request, ok := GraphQLRequestFromContext(ctx); ok {
+		operation.Name = request.OperationName
+	}
+	if definition, ok := selectedOperation(l, operation.Name); ok {
+		operation.Name = string(l.ByteSlice(definition.Name))
+		operation.Type = definition.OperationType
+	}
+
+	active := c.predicate(ctx, operation)
+	c.setActive(parser, active)
+	if !active {
+		return nil
+	}
+
+	return c.middleware.OnRequest(ctx, l, w, parser, mod)
+}
+
+func (c *conditionalMiddleware) ResolveRequest(ctx context.Context, out io.Writer, l *lookup.Lookup, w *lookup.Walker, parser *parser.Parser, mod *parser.ManualAstMod) (resolved bool, err error) {
+	if !c.isActive(parser) {
+		return false, nil
+	}
+	return resolveRequest(c.middleware, ctx, out, l, w, parser, mod)
+}
+
+func (c *conditionalMiddleware) OnResponse(ctx context.Context, response *[]byte, l *lookup.Lookup, w *lookup.Walker, parser *parser.Parser, mod *parser.ManualAstMod) error {
+	if !c.isActive(parser) {
+		return nil
+	}
+	return c.middleware.OnResponse(ctx, response, l, w, parser, mod)
+}
+
+func (c *conditionalMiddleware) setActive(p *parser.Parser, active bool) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if c.active == nil {
+		c.active = map[*parser.Parser]bool{}
+	}
+	c.active[p] = active
+}
+
+func (c *conditionalMiddleware) isActive(p *parser.Parser) bool {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.active[p]
+}
+
+// resolveRequest forwards to the wrapped middleware if it's a RequestResolver
+func resolveRequest(middleware GraphqlMiddleware, ctx context.Context, out io.Writer, l *lookup.Lookup, w *lookup.Walker, parser *parser.Parser, mod *parser.ManualAstMod) (resolved bool, err error) {
+	resolver, ok := middleware.(RequestResolver)
+	if !ok {
+		return false, nil
+	}
+	return resolver.ResolveRequest(ctx, out, l, w, parser, mod)
+}
@@ -0,0 +1,289 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/jensneuse/graphql-go-tools/pkg/document"
+	"github.com/jensneuse/graphql-go-tools/pkg/validation"
+	"github.com/jensneuse/graphql-go-tools/pkg/validator"
+)
+
+func TestChain(t *testing.T) {
+
+	names := func(middleWares []GraphqlMiddleware) string {
+		out := ""
+		for _, middleware := range middleWares {
+			switch m := middleware.(type) {
+			case *recordingMiddleware:
+				out += m.name + ","
+			case *phasedMiddleware:
+				out += m.GraphqlMiddleware.(*recordingMiddleware).name + ","
+			default:
+				out += fmt.Sprintf("%T,", middleware)
+			}
+		}
+		return out
+	}
+
+	run := func(want string, middleWares ...GraphqlMiddleware) {
+		got := names(Chain(middleWares...))
+		if want != got {
+			panic(fmt.Errorf("want: %s, got: %s", want, got))
+		}
+	}
+
+	t.Run("builtin middlewares are ordered by their phase", func(t *testing.T) {
+		run("*middleware.SchemaVisibilityMiddleware,*middleware.RateLimitMiddleware,*middleware.AuthorizationMiddleware,"+
+			"*middleware.ValidationMiddleware,*middleware.DeprecationMiddleware,*middleware.ContextMiddleware,*middleware.IntrospectionMiddleware,*middleware.ResponseCacheMiddleware,",
+			&ValidationMiddleware{},
+			&ContextMiddleware{},
+			&IntrospectionMiddleware{},
+			&RateLimitMiddleware{},
+			&AuthorizationMiddleware{},
+			&DeprecationMiddleware{},
+			&ResponseCacheMiddleware{},
+			&SchemaVisibilityMiddleware{},
+		)
+	})
+	t.Run("middlewares of the same phase keep their order", func(t *testing.T) {
+		run("a,b,c,", &recordingMiddleware{name: "a"}, &recordingMiddleware{name: "b"}, &recordingMiddleware{name: "c"})
+	})
+	t.Run("InPhase moves a middleware to another phase", func(t *testing.T) {
+		run("c,a,b,",
+			&recordingMiddleware{name: "a"},
+			InPhase(PhaseValidation, &recordingMiddleware{name: "b"}),
+			InPhase(PhaseNormalize, &recordingMiddleware{name: "c"}),
+		)
+	})
+	t.Run("conditional middlewares keep their phase", func(t *testing.T) {
+		if phase := PhaseOf(When(OnOperationNames("q"), &ValidationMiddleware{})); phase != PhaseValidation {
+			panic(fmt.Errorf("want phase: %d, got: %d", PhaseValidation, phase))
+		}
+	})
+}
+
+func TestChain_ValidatesResolvedRequests(t *testing.T) {
+
+	run := func(query string, rules *validator.Registry) (string, error) {
+
+		invoker := NewInvoker(Chain(&IntrospectionMiddleware{}, &ValidationMiddleware{Rules: rules})...)
+		err := invoker.SetSchema([]byte(chainTestSchema))
+		if err != nil {
+			panic(err)
+		}
+
+		err = invoker.InvokeMiddleWares(context.Background(), []byte(query))
+		if err != nil {
+			return "", err
+		}
+
+		resolved, ok := invoker.ResolvedResponse()
+		if !ok {
+			panic(fmt.Errorf("want resolved response for query: %s", query))
+		}
+
+		return string(resolved), nil
+	}
+
+	noIntrospection := validator.DefaultRegistry()
+	err := noIntrospection.Enable(validation.NoIntrospection.String())
+	if err != nil {
+		panic(err)
+	}
+
+	t.Run("introspection queries are validated before they're answered", func(t *testing.T) {
+		query := `{__schema {queryType {name}} __type(name: "Document") {fields {name}}}`
+
+		got, err := run(query, nil)
+		if err != nil {
+			panic(err)
+		}
+		want := `{"data":{"__schema":{"queryType":{"name":"Query"}},"__type":{"fields":[{"name":"owner"}]}}}`
+		if want != got {
+			panic(fmt.Errorf("want:\n%s\ngot:\n%s", want, got))
+		}
+
+		_, err = run(query, noIntrospection)
+		if err == nil || !strings.Contains(err.Error(), "NoIntrospection") {
+			panic(fmt.Errorf("want NoIntrospection violation, got: %v", err))
+		}
+	})
+	t.Run("introspection fields are not part of the query type", func(t *testing.T) {
+		got, err := run(`{__type(name: "Query") {fields {name}}}`, nil)
+		if err != nil {
+			panic(err)
+		}
+		want := `{"data":{"__type":{"fields":[{"name":"documents"}]}}}`
+		if want != got {
+			panic(fmt.Errorf("want:\n%s\ngot:\n%s", want, got))
+		}
+	})
+	t.Run("invalid introspection queries are rejected", func(t *testing.T) {
+		_, err := run(`{__type {name}}`, nil)
+		if err == nil {
+			panic(fmt.Errorf("want missing argument 'name' to be rejected"))
+		}
+	})
+}
+
+func TestWhen(t *testing.T) {
+
+	run := func(query, operationName, want string, middleWares ...GraphqlMiddleware) {
+
+		ctx := WithGraphQLRequest(context.Background(), &GraphQLRequest{OperationName: operationName})
+
+		invoker := NewInvoker(middleWares...)
+		err := invoker.SetSchema([]byte(chainTestSchema))
+		if err != nil {
+			panic(err)
+		}
+
+		err = invoker.InvokeMiddleWares(ctx, []byte(query))
+		if err != nil {
+			panic(err)
+		}
+
+		out := []byte("backend")
+		if resolved, ok := invoker.ResolvedResponse(); ok {
+			out = append([]byte{}, resolved...)
+		}
+
+		err = invoker.InvokeMiddleWaresOnResponse(ctx, &out)
+		if err != nil {
+			panic(err)
+		}
+
+		if want != string(out) {
+			panic(fmt.Errorf("want:\n%s\ngot:\n%s", want, string(out)))
+		}
+	}
+
+	onMutations := OnOperationTypes(document.OperationTypeMutation)
+
+	t.Run("active on matching operation types", func(t *testing.T) {
+		run(`mutation m {deleteDocument}`, "", "backend,b,a",
+			&recordingMiddleware{name: "a"},
+			When(onMutations, &recordingMiddleware{name: "b"}),
+		)
+	})
+	t.Run("inactive on other operation types", func(t *testing.T) {
+		run(`query q {documents {owner}}`, "", "backend,a",
+			&recordingMiddleware{name: "a"},
+			When(onMutations, &recordingMiddleware{name: "b"}),
+		)
+	})
+	t.Run("inactive middlewares don't resolve requests", func(t *testing.T) {
+		run(`query q {documents {owner}}`, "", "backend,c,a",
+			&recordingMiddleware{name: "a"},
+			When(onMutations, &recordingMiddleware{name: "b", resolve: true}),
+			&recordingMiddleware{name: "c"},
+		)
+		run(`mutation m {deleteDocument}`, "", "b,b,a",
+			&recordingMiddleware{name: "a"},
+			When(onMutations, &recordingMiddleware{name: "b", resolve: true}),
+			&recordingMiddleware{name: "c"},
+		)
+	})
+	t.Run("operation names select the operation", func(t *testing.T) {
+		query := `query q {documents {owner}} mutation m {deleteDocument}`
+		run(query, "m", "backend,b", When(onMutations, &recordingMiddleware{name: "b"}))
+		run(query, "q", "backend", When(onMutations, &recordingMiddleware{name: "b"}))
+		run(query, "q", "backend,b", When(OnOperationNames("p", "q"), &recordingMiddleware{name: "b"}))
+		run(query, "m", "backend", When(OnOperationNames("p", "q"), &recordingMiddleware{name: "b"}))
+	})
+	t.Run("shared middlewares are evaluated per request", func(t *testing.T) {
+		conditional := When(onMutations, &recordingMiddleware{name: "b"})
+		run(`mutation m {deleteDocument}`, "", "backend,b", conditional)
+		run(`query q {documents {owner}}`, "", "backend", conditional)
+	})
+	t.Run("phased middlewares resolve requests", func(t *testing.T) {
+		run(`query q {documents {owner}}`, "", "b,b",
+			InPhase(PhaseResolve, &recordingMiddleware{name: "b", resolve: true}),
+			&recordingMiddleware{name: "c"},
+		)
+	})
+}
+
+func TestInvokerPool_Free(t *testing.T) {
+
+	pool := NewInvokerPool(1, &recordingMiddleware{name: "a"})
+
+	run := func(want string, middleWares ...GraphqlMiddleware) {
+		index, invoker := pool.Get()
+		defer pool.Free(index)
+		if middleWares != nil {
+			invoker.SetMiddleWares(middleWares...)
+		}
+		err := invoker.SetSchema([]byte(chainTestSchema))
+		if err != nil {
+			panic(err)
+		}
+		err = invoker.InvokeMiddleWares(context.Background(), []byte(`{documents {owner}}`))
+		if err != nil {
+			panic(err)
+		}
+		out := []byte("backend")
+		err = invoker.InvokeMiddleWaresOnResponse(context.Background(), &out)
+		if err != nil {
+			panic(err)
+		}
+		if want != string(out) {
+			panic(fmt.Errorf("want: %s, got: %s", want, string(out)))
+		}
+	}
+
+	run("backend,a")
+	run("backend,c,b", &recordingMiddleware{name: "b"}, &recordingMiddleware{name: "c"})
+	run("backend,a")
+}
+
+const chainTestSchema = `
+schema {
+	query: Query
+	mutation: Mutation
+}
+
+type Query {
+	documents: [Document]
+}
+
+type Mutation {
+	deleteDocument: Boolean
+}
+
+type Document {
+	owner: String
+}
+`
+
+func TestInvokerPool_Get(t *testing.T) {
+
+	pool := NewInvokerPool(2)
+
+	indexes := make([]int, 0, 5)
+	invokers := map[*Invoker]bool{}
+	for j := 0; j < 5; j++ {
+		index, invoker := pool.Get()
+		indexes = append(indexes, index)
+		invokers[invoker] = true
+	}
+	if len(invokers) != 5 {
+		t.Fatalf("want 5 distinct invokers during the burst, got: %d", len(invokers))
+	}
+
+	for _, index := range indexes {
+		pool.Free(index)
+	}
+
+	if len(pool.invokers) != 2 || len(pool.index) != 2 {
+		t.Fatalf("want the pool to keep 2 invokers after the burst, got: %d (%d idle)", len(pool.invokers), len(pool.index))
+	}
+	for j := 0; j < 2; j++ {
+		if index, _ := pool.Get(); index == temporaryInvokerIndex {
+			t.Fatal("want an invoker of the pool")
+		}
+	}
+}
@@ -3,11 +3,13 @@ package middleware
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/jensneuse/graphql-go-tools/pkg/document"
 	"github.com/jensneuse/graphql-go-tools/pkg/lexing/literal"
 	"github.com/jensneuse/graphql-go-tools/pkg/lookup"
 	"github.com/jensneuse/graphql-go-tools/pkg/parser"
+	"github.com/jensneuse/graphql-go-tools/pkg/printer"
 	"strings"
 )
 
@@ -15,6 +17,8 @@ import (
 directive @addArgumentFromContext(
 	name: String!
 	contextKey: String!
+	argumentType: String
+	variableName: String
 ) on FIELD_DEFINITION
 */
 
@@ -23,28 +27,47 @@ ContextMiddleware does rewrite graphql requests based on schema annotations usin
 
 example schema:
 
-type Query {
-	documents: [Document] @addArgumentFromContext(name: "user",contextKey: "user")
-}
+	type Query {
+		documents: [Document] @addArgumentFromContext(name: "user",contextKey: "user")
+	}
 
 given there's an object with key "user" and value "jsmith@example.org" in the context
 
 original Request:
 
-query myDocuments {
-	documents {
-		sensitiveInformation
+	query myDocuments {
+		documents {
+			sensitiveInformation
+		}
 	}
-}
 
 Request after rewriting:
 
-query myDocuments {
-	documents(user: "jsmith@example.org") {
-		sensitiveInformation
+	query myDocuments {
+		documents(user: "jsmith@example.org") {
+			sensitiveInformation
+		}
+	}
+
+Without further configuration the value is added as a String.
+If the field declares the argument or the directive sets argumentType, e.g. argumentType: "[Role!]!",
+the context value gets coerced to this type. String context values which are valid json get decoded first,
+so "3" becomes an Int, "true" a Boolean and `["ADMIN"]` a list of enum values.
+
+A name containing dots adds the value to a field of an input object,
+e.g. name: "filter.owner" together with argumentType: "DocumentFilter" adds documents(filter: {owner: "jsmith@example.org"}),
+fields of the filter sent by the client are kept.
+
+If variableName is set the value is sent as a variable instead of inlining it into the query:
+
+	query myDocuments($user: String) {
+		documents(user: $user) {
+			sensitiveInformation
+		}
 	}
-}
 
+Arguments declared on the field can be marked @internal, see SchemaVisibilityMiddleware,
+so that they're not part of the public schema and can't be set by the client.
 */
 type ContextMiddleware struct {
 }
@@ -53,6 +76,8 @@ var contextMiddlewareSchemaExtension = []byte(`
 directive @addArgumentFromContext(
 	name: String!
 	contextKey: String!
+	argumentType: String
+	variableName: String
 ) on FIELD_DEFINITION`)
 
 func (a *ContextMiddleware) PrepareSchema(ctx context.Context, l *lookup.Lookup, w *lookup.Walker, parser *parser.Parser, mod *parser.ManualAstMod) error {
@@ -62,6 +87,11 @@ func (a *ContextMiddleware) PrepareSchema(ctx context.Context, l *lookup.Lookup,
 	return err
 }
 
+// Phase returns PhaseEnrich so that requests get validated before arguments which aren't part of the public schema are added
+func (a *ContextMiddleware) Phase() Phase {
+	return PhaseEnrich
+}
+
 func (a *ContextMiddleware) OnResponse(ctx context.Context, response *[]byte, l *lookup.Lookup, w *lookup.Walker, parser *parser.Parser, mod *parser.ManualAstMod) (err error) {
 	return nil
 }
@@ -70,6 +100,11 @@ type ContextRewriteConfig struct {
 	fieldName               document.ByteSliceReference
 	argumentName            document.ByteSliceReference
 	argumentValueContextKey document.ByteSlice
+	// argumentPath are the input object fields below the argument, e.g. [owner] for name: "filter.owner"
+	argumentPath []string
+	// argumentType is the type of the argument, -1 if unknown
+	argumentType int
+	variableName string
 }
 
 func (a *ContextMiddleware) OnRequest(ctx context.Context, l *lookup.Lookup, w *lookup.Walker, parser *parser.Parser, mod *parser.ManualAstMod) error {
@@ -92,6 +127,16 @@ func (a *ContextMiddleware) OnRequest(ctx context.Context, l *lookup.Lookup, w *
 		return err
 	}
 
+	argumentTypeLiteral, _, err := mod.PutLiteralBytes([]byte("argumentType"))
+	if err != nil {
+		return err
+	}
+
+	variableNameLiteral, _, err := mod.PutLiteralBytes([]byte("variableName"))
+	if err != nil {
+		return err
+	}
+
 	typeNamesAndFieldNamesWithDirective := make(map[string][]ContextRewriteConfig)
 
 	fields := w.FieldsContainingDirectiveIterator(addArgumentFromContextDirectiveName)
@@ -103,9 +148,12 @@ func (a *ContextMiddleware) OnRequest(ctx context.Context, l *lookup.Lookup, w *
 		objectTypeDefinition := l.ObjectTypeDefinition(objectTypeDefinitionRef)
 
 		rewriteConfig := ContextRewriteConfig{
-			fieldName: fieldDefinition.Name,
+			fieldName:    fieldDefinition.Name,
+			argumentType: -1,
 		}
 
+		var argumentType []byte
+
 		argSet := l.ArgumentSet(directive.ArgumentSet)
 		args := l.ArgumentsIterable(argSet)
 		for args.Next() {
@@ -116,7 +164,36 @@ func (a *ContextMiddleware) OnRequest(ctx context.Context, l *lookup.Lookup, w *
 			} else if l.ByteSliceReferenceContentsEquals(arg.Name, contextKeyLiteral) {
 				value := l.Value(arg.Value)
 				rewriteConfig.argumentValueContextKey = l.ByteSlice(value.Raw)
+			} else if l.ByteSliceReferenceContentsEquals(arg.Name, argumentTypeLiteral) {
+				argumentType = l.ByteSlice(l.Value(arg.Value).Raw)
+			} else if l.ByteSliceReferenceContentsEquals(arg.Name, variableNameLiteral) {
+				rewriteConfig.variableName = string(l.ByteSlice(l.Value(arg.Value).Raw))
+			}
+		}
+
+		if name := l.ByteSlice(rewriteConfig.argumentName); bytes.Contains(name, literal.DOT) {
+			path := strings.Split(string(name), ".")
+			rewriteConfig.argumentName, _, err = mod.PutLiteralString(path[0])
+			if err != nil {
+				return err
+			}
+			rewriteConfig.argumentPath = path[1:]
+		}
+
+		if len(argumentType) != 0 {
+			rewriteConfig.argumentType, err = mod.PutType(argumentType)
+			if err != nil {
+				return err
+			}
+		} else if inputValue, ok := l.InputValueDefinitionByNameFromDefinitions(rewriteConfig.argumentName, l.ArgumentsDefinition(fieldDefinition.ArgumentsDefinition).InputValueDefinitions); ok {
+			rewriteConfig.argumentType = inputValue.Type
+		} else if rewriteConfig.variableName != "" {
+			rewriteConfig.argumentType, err = mod.PutType(literal.STRING)
+			if err != nil {
+				return err
 			}
+		} else if len(rewriteConfig.argumentPath) != 0 {
+			return fmt.Errorf("OnRequest: the type of argument '%s' of field '%s' is unknown, set argumentType to add a value to one of its fields", string(l.ByteSlice(rewriteConfig.argumentName)), string(l.ByteSlice(fieldDefinition.Name)))
 		}
 
 		typeNamesAndFieldNamesWithDirective[string(l.ByteSlice(objectTypeDefinition.Name))] = append(typeNamesAndFieldNamesWithDirective[string(l.ByteSlice(objectTypeDefinition.Name))], rewriteConfig)
@@ -125,9 +202,20 @@ func (a *ContextMiddleware) OnRequest(ctx context.Context, l *lookup.Lookup, w *
 	w.SetLookup(l)
 	w.WalkExecutable()
 
+	rewriter := contextArgumentRewriter{
+		ctx:       ctx,
+		l:         l,
+		w:         w,
+		parser:    parser,
+		mod:       mod,
+		variables: map[string]string{},
+	}
+
+	rewritten := map[rewrittenField]bool{}
+
 	selectionSets := w.SelectionSetIterable()
 	for selectionSets.Next() {
-		set, _, _, parent := selectionSets.Value()
+		set, nodeRef, _, parent := selectionSets.Value()
 		typeName := w.SelectionSetTypeName(set, parent)
 		fieldsWithDirective, ok := typeNamesAndFieldNamesWithDirective[string(l.ByteSlice(typeName))]
 		if !ok {
@@ -137,51 +225,33 @@ func (a *ContextMiddleware) OnRequest(ctx context.Context, l *lookup.Lookup, w *
 		fields := l.SelectionSetCollectedFields(set, typeName)
 		for fields.Next() {
 			fieldRef, field := fields.Value()
-			for _, i := range fieldsWithDirective {
+			for configRef, i := range fieldsWithDirective {
 				if l.ByteSliceReferenceContentsEquals(i.fieldName, field.Name) {
 
+					// fields of fragments are collected more than once
+					rewrite := rewrittenField{fieldRef: fieldRef, typeName: string(l.ByteSlice(typeName)), config: configRef}
+					if rewritten[rewrite] {
+						continue
+					}
+					rewritten[rewrite] = true
+
 					argumentValue := ctx.Value(string(i.argumentValueContextKey))
 					if argumentValue == nil {
 						return fmt.Errorf("OnRequest: No value for key: %s (did you forget to configure setting the 'contextKeys' configuration which enables loading variables from the header into the context values?)", string(i.argumentValueContextKey))
 					}
 
-					var argByteSliceRef document.ByteSliceReference
-					var argNameRef int
+					var valueRef int
 					var err error
 
-					switch argumentValue := argumentValue.(type) {
-					case string:
-						if !strings.HasPrefix(argumentValue, "\"") {
-							argumentValue = "\"" + argumentValue
-						}
-						if !strings.HasSuffix(argumentValue, "\"") {
-							argumentValue = argumentValue + "\""
-						}
-						argByteSliceRef, argNameRef, err = mod.PutLiteralString(argumentValue)
-						if err != nil {
-							return err
-						}
-					case []byte:
-						if !bytes.HasPrefix(argumentValue, literal.QUOTE) {
-							argumentValue = append(literal.QUOTE, argumentValue...)
-						}
-						if !bytes.HasSuffix(argumentValue, literal.QUOTE) {
-							argumentValue = append(argumentValue, literal.QUOTE...)
-						}
-						argByteSliceRef, argNameRef, err = mod.PutLiteralBytes(argumentValue)
-						if err != nil {
-							return err
-						}
+					if i.argumentType == -1 {
+						valueRef, err = rewriter.stringValue(argumentValue, i)
+					} else {
+						valueRef, err = rewriter.typedValue(argumentValue, i, field, nodeRef)
 					}
-
-					val := document.Value{
-						ValueType: document.ValueTypeString,
-						Raw:       argByteSliceRef,
-						Reference: argNameRef,
+					if err != nil {
+						return err
 					}
 
-					valueRef := mod.PutValue(val)
-
 					arg := document.Argument{
 						Name:  i.argumentName,
 						Value: valueRef,
@@ -197,3 +267,325 @@ func (a *ContextMiddleware) OnRequest(ctx context.Context, l *lookup.Lookup, w *
 
 	return nil
 }
+
+type rewrittenField struct {
+	fieldRef int
+	typeName string
+	config   int
+}
+
+// contextArgumentRewriter creates the argument values for one request
+type contextArgumentRewriter struct {
+	ctx    context.Context
+	l      *lookup.Lookup
+	w      *lookup.Walker
+	parser *parser.Parser
+	mod    *parser.ManualAstMod
+	// variables are the json values of the variables added so far, keyed by name
+	variables map[string]string
+	// definedVariables are the variable definitions added so far, keyed by operation and name
+	definedVariables map[int]map[string]bool
+}
+
+// stringValue adds the value as a String literal, string values are expected to be escaped already
+func (c *contextArgumentRewriter) stringValue(argumentValue interface{}, config ContextRewriteConfig) (int, error) {
+
+	var argByteSliceRef document.ByteSliceReference
+	var argNameRef int
+	var err error
+
+	switch argumentValue := argumentValue.(type) {
+	case string:
+		if !strings.HasPrefix(argumentValue, "\"") {
+			argumentValue = "\"" + argumentValue
+		}
+		if !strings.HasSuffix(argumentValue, "\"") {
+			argumentValue = argumentValue + "\""
+		}
+		argByteSliceRef, argNameRef, err = c.mod.PutLiteralString(argumentValue)
+		if err != nil {
+			return -1, err
+		}
+	case []byte:
+		if !bytes.HasPrefix(argumentValue, literal.QUOTE) {
+			argumentValue = append(literal.QUOTE, argumentValue...)
+		}
+		if !bytes.HasSuffix(argumentValue, literal.QUOTE) {
+			argumentValue = append(argumentValue, literal.QUOTE...)
+		}
+		argByteSliceRef, argNameRef, err = c.mod.PutLiteralBytes(argumentValue)
+		if err != nil {
+			return -1, err
+		}
+	default:
+		return -1, fmt.Errorf("OnRequest: value of type %T for key: %s can only be added to arguments of known type, set argumentType", argumentValue, string(config.argumentValueContextKey))
+	}
+
+	val := document.Value{
+		ValueType: document.ValueTypeString,
+		Raw:       argByteSliceRef,
+		Reference: argNameRef,
+	}
+
+	return c.mod.PutValue(val), nil
+}
+
+// typedValue coerces the context value to the type of the argument
+// for nested arguments the value is merged into the input object sent by the client
+// with a variableName the context value is replaced with the variable, selectionSetNode is the walker node of the selection set containing the field
+func (c *contextArgumentRewriter) typedValue(argumentValue interface{}, config ContextRewriteConfig, field document.Field, selectionSetNode int) (int, error) {
+
+	fieldType, err := c.inputFieldType(config.argumentType, config.argumentPath)
+	if err != nil {
+		return -1, fmt.Errorf("OnRequest: key: %s: %s", string(config.argumentValueContextKey), err)
+	}
+
+	valueRef, err := c.mod.PutDecodedJSONValue(decodeContextValue(argumentValue, c.isStringType(fieldType)), fieldType)
+	if err != nil {
+		return -1, fmt.Errorf("OnRequest: key: %s: %s", string(config.argumentValueContextKey), err)
+	}
+
+	if config.variableName != "" {
+		valueRef, err = c.variableValue(valueRef, fieldType, config, selectionSetNode)
+		if err != nil {
+			return -1, err
+		}
+	}
+
+	if len(config.argumentPath) == 0 {
+		return valueRef, nil
+	}
+
+	existingValueRef := -1
+	if field.ArgumentSet != -1 {
+		if argument, ok := c.l.ArgumentByIndexAndName(c.l.ArgumentSet(field.ArgumentSet), config.argumentName); ok {
+			existingValueRef = argument.Value
+		}
+	}
+
+	valueRef, err = c.mergeIntoObject(existingValueRef, config.argumentPath, valueRef)
+	if err != nil {
+		return -1, fmt.Errorf("OnRequest: key: %s: argument '%s': %s", string(config.argumentValueContextKey), string(c.l.ByteSlice(config.argumentName)), err)
+	}
+
+	return valueRef, nil
+}
+
+// mergeIntoObject returns a copy of the object value objectRef with valueRef set at path
+// objects missing on the path get created, other fields set by the client are kept
+func (c *contextArgumentRewriter) mergeIntoObject(objectRef int, path []string, valueRef int) (int, error) {
+
+	if len(path) == 0 {
+		return valueRef, nil
+	}
+
+	var fields document.ObjectValue
+	if objectRef != -1 {
+		object := c.l.Value(objectRef)
+		switch object.ValueType {
+		case document.ValueTypeObject:
+			fields = c.l.ObjectValue(object.Reference)
+		case document.ValueTypeNull:
+		default:
+			return -1, fmt.Errorf("must be an input object literal to set field '%s'", path[0])
+		}
+	}
+
+	merged := make(document.ObjectValue, 0, len(fields)+1)
+	childRef := -1
+	for _, ref := range fields {
+		if string(c.l.ByteSlice(c.l.ObjectField(ref).Name)) == path[0] {
+			childRef = c.l.ObjectField(ref).Value
+			continue
+		}
+		merged = append(merged, ref)
+	}
+
+	childRef, err := c.mergeIntoObject(childRef, path[1:], valueRef)
+	if err != nil {
+		return -1, err
+	}
+
+	name, _, err := c.mod.PutLiteralString(path[0])
+	if err != nil {
+		return -1, err
+	}
+
+	merged = append(merged, c.mod.PutObjectField(document.ObjectField{
+		Name:  name,
+		Value: childRef,
+	}))
+
+	return c.mod.PutValue(document.Value{
+		ValueType: document.ValueTypeObject,
+		Reference: c.mod.PutObjectValue(merged),
+	}), nil
+}
+
+// variableValue moves the value into the variables of the request and defines the variable in all operations using the field
+func (c *contextArgumentRewriter) variableValue(valueRef, variableType int, config ContextRewriteConfig, selectionSetNode int) (int, error) {
+
+	request, ok := GraphQLRequestFromContext(c.ctx)
+	if !ok {
+		return -1, fmt.Errorf("OnRequest: the graphql request is missing in the context, it's required to set variable $%s", config.variableName)
+	}
+
+	valueJSON := bytes.Buffer{}
+	astPrinter := printer.New()
+	astPrinter.SetInput(c.parser, c.l, c.w)
+	err := astPrinter.PrintValueJSON(&valueJSON, valueRef)
+	if err != nil {
+		return -1, err
+	}
+
+	if previous, ok := c.variables[config.variableName]; ok && previous != valueJSON.String() {
+		return -1, fmt.Errorf("OnRequest: conflicting values for variable $%s", config.variableName)
+	}
+	c.variables[config.variableName] = valueJSON.String()
+
+	variableName, _, err := c.mod.PutLiteralString(config.variableName)
+	if err != nil {
+		return -1, err
+	}
+
+	operations := c.w.NodeUsageInOperationsIterator(selectionSetNode)
+	for operations.Next() {
+		err = c.defineVariable(operations.Value(), variableName, variableType, config)
+		if err != nil {
+			return -1, err
+		}
+	}
+
+	var variableValue interface{}
+	decoder := json.NewDecoder(&valueJSON)
+	decoder.UseNumber()
+	err = decoder.Decode(&variableValue)
+	if err != nil {
+		return -1, err
+	}
+
+	if request.Variables == nil {
+		request.Variables = map[string]interface{}{}
+	}
+	request.Variables[config.variableName] = variableValue
+
+	return c.mod.PutValue(document.Value{
+		ValueType: document.ValueTypeVariable,
+		Raw:       variableName,
+	}), nil
+}
+
+func (c *contextArgumentRewriter) defineVariable(operationRef int, variableName document.ByteSliceReference, variableType int, config ContextRewriteConfig) error {
+
+	if c.definedVariables == nil {
+		c.definedVariables = map[int]map[string]bool{}
+	}
+	if c.definedVariables[operationRef][config.variableName] {
+		return nil
+	}
+
+	operation := c.l.OperationDefinition(operationRef)
+	if _, exists := c.l.VariableDefinition(variableName, operation.VariableDefinitions); exists {
+		return fmt.Errorf("OnRequest: variable $%s is reserved for values from the context and must not be defined by the client", config.variableName)
+	}
+
+	variableDefinitionRef := c.mod.PutVariableDefinition(document.VariableDefinition{
+		Variable:     variableName,
+		Type:         variableType,
+		DefaultValue: -1,
+	})
+	c.mod.AppendVariableDefinitionToOperation(variableDefinitionRef, operationRef)
+
+	if c.definedVariables[operationRef] == nil {
+		c.definedVariables[operationRef] = map[string]bool{}
+	}
+	c.definedVariables[operationRef][config.variableName] = true
+	return nil
+}
+
+// inputFieldType returns the type of the input object field at path below the argument type
+func (c *contextArgumentRewriter) inputFieldType(argumentType int, path []string) (int, error) {
+
+	fieldType := argumentType
+
+	for _, name := range path {
+		typeName := c.l.UnwrappedNamedType(c.l.Type(fieldType)).Name
+		definition, ok := c.l.InputObjectTypeDefinitionByName(typeName)
+		if !ok {
+			return -1, fmt.Errorf("can't add field '%s' to '%s' which is not an input object", name, string(c.l.ByteSlice(typeName)))
+		}
+
+		found := false
+		inputValues := c.l.InputFieldsDefinition(definition.InputFieldsDefinition).InputValueDefinitions
+		for inputValues.Next(c.parser) {
+			inputValue, _ := inputValues.Value()
+			if string(c.l.ByteSlice(inputValue.Name)) == name {
+				fieldType = inputValue.Type
+				found = true
+				break
+			}
+		}
+		if !found {
+			return -1, fmt.Errorf("input object '%s' has no field '%s'", string(c.l.ByteSlice(typeName)), name)
+		}
+	}
+
+	return fieldType, nil
+}
+
+// isStringType reports whether a type is a String, an ID or a custom scalar which are all sent as strings as is
+func (c *contextArgumentRewriter) isStringType(typeRef int) bool {
+	documentType := c.l.Type(typeRef)
+	if documentType.Kind == document.TypeKindNON_NULL {
+		documentType = c.l.Type(documentType.OfType)
+	}
+	if documentType.Kind != document.TypeKindNAMED {
+		return false
+	}
+	name := c.l.ByteSlice(documentType.Name)
+	if bytes.Equal(name, literal.STRING) || bytes.Equal(name, literal.ID) {
+		return true
+	}
+	for _, definition := range c.parser.ParsedDefinitions.ScalarTypeDefinitions {
+		if bytes.Equal(c.l.ByteSlice(definition.Name), name) {
+			return !bytes.Equal(name, literal.INT) && !bytes.Equal(name, literal.FLOAT) && !bytes.Equal(name, literal.BOOLEAN)
+		}
+	}
+	return false
+}
+
+// decodeContextValue converts a context value into a json value
+// strings which are valid json get decoded unless the type is a string type and the decoded value isn't a string
+func decodeContextValue(value interface{}, isStringType bool) interface{} {
+
+	var raw []byte
+
+	switch value := value.(type) {
+	case string:
+		raw = []byte(value)
+	case []byte:
+		raw = value
+	case []string:
+		items := make([]interface{}, len(value))
+		for i := range value {
+			items[i] = value[i]
+		}
+		return items
+	default:
+		return value
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	var decoded interface{}
+	if err := decoder.Decode(&decoded); err != nil || decoder.More() {
+		return string(raw)
+	}
+
+	if _, isString := decoded.(string); isStringType && !isString {
+		return string(raw)
+	}
+
+	return decoded
+}
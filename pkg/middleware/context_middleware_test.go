@@ -2,8 +2,10 @@ package middleware
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/jensneuse/graphql-go-tools/pkg/testhelper"
+	"strings"
 	"testing"
 )
 
@@ -123,6 +125,147 @@ func TestContextMiddleware(t *testing.T) {
 	})
 }
 
+func TestContextMiddleware_TypedValues(t *testing.T) {
+
+	schema := func(queryFields string) string {
+		return contextMiddlewareTypedSchema + "\ntype Query {\n" + queryFields + "\n}\n"
+	}
+
+	run := func(queryFields string, contextValue interface{}, query, want string) {
+		ctx := context.WithValue(context.Background(), "value", contextValue)
+		got, err := InvokeMiddleware(&ContextMiddleware{}, ctx, schema(queryFields), query)
+		if err != nil {
+			panic(err)
+		}
+		if want != got {
+			panic(fmt.Errorf("want:\n%s\ngot:\n%s", want, got))
+		}
+	}
+
+	runVariables := func(queryFields string, contextValue interface{}, query, want, wantVariables string) {
+		request := &GraphQLRequest{
+			Variables: map[string]interface{}{"first": 10},
+		}
+		ctx := WithGraphQLRequest(context.WithValue(context.Background(), "value", contextValue), request)
+		got, err := InvokeMiddleware(&ContextMiddleware{}, ctx, schema(queryFields), query)
+		if err != nil {
+			panic(err)
+		}
+		if want != got {
+			panic(fmt.Errorf("want:\n%s\ngot:\n%s", want, got))
+		}
+		variables, err := json.Marshal(request.Variables)
+		if err != nil {
+			panic(err)
+		}
+		if wantVariables != string(variables) {
+			panic(fmt.Errorf("want variables: %s, got: %s", wantVariables, string(variables)))
+		}
+	}
+
+	runErr := func(queryFields string, contextValue interface{}, query, wantErr string) {
+		ctx := context.WithValue(context.Background(), "value", contextValue)
+		_, err := InvokeMiddleware(&ContextMiddleware{}, ctx, schema(queryFields), query)
+		if err == nil {
+			panic(fmt.Errorf("want err for query: %s", query))
+		}
+		if !strings.Contains(err.Error(), wantErr) {
+			panic(fmt.Errorf("want err containing: %s, got: %s", wantErr, err.Error()))
+		}
+	}
+
+	t.Run("argument type from directive", func(t *testing.T) {
+		field := `documents: [Document] @addArgumentFromContext(name: "level", contextKey: "value", argumentType: "Int!")`
+		run(field, "3", `{documents {owner}}`, `{documents(level:3) {owner}}`)
+		run(field, 3, `{documents {owner}}`, `{documents(level:3) {owner}}`)
+		runErr(field, "three", `{documents {owner}}`, "must be an Int")
+	})
+	t.Run("argument type from field definition", func(t *testing.T) {
+		run(`documents(archived: Boolean): [Document] @addArgumentFromContext(name: "archived", contextKey: "value")`,
+			[]byte("true"), `{documents {owner}}`, `{documents(archived:true) {owner}}`)
+		run(`documents(id: ID): [Document] @addArgumentFromContext(name: "id", contextKey: "value")`,
+			"123", `{documents {owner}}`, `{documents(id:"123") {owner}}`)
+		run(`documents(owner: String): [Document] @addArgumentFromContext(name: "owner", contextKey: "value")`,
+			`jsmith "the admin"`, `{documents {owner}}`, `{documents(owner:"jsmith \"the admin\"") {owner}}`)
+	})
+	t.Run("enums and lists", func(t *testing.T) {
+		field := `documents(roles: [Role!]): [Document] @addArgumentFromContext(name: "roles", contextKey: "value")`
+		run(field, `["ADMIN","READER"]`, `{documents {owner}}`, `{documents(roles:[ADMIN,READER]) {owner}}`)
+		run(field, "READER", `{documents {owner}}`, `{documents(roles:[READER]) {owner}}`)
+		run(field, []string{"ADMIN"}, `{documents {owner}}`, `{documents(roles:[ADMIN]) {owner}}`)
+		runErr(field, "OWNER", `{documents {owner}}`, "must be a value of enum Role")
+	})
+	t.Run("input objects", func(t *testing.T) {
+		run(`documents(filter: DocumentFilter): [Document] @addArgumentFromContext(name: "filter", contextKey: "value")`,
+			`{"owner":"jsmith","level":2}`, `{documents {owner}}`, `{documents(filter:{level:2,owner:"jsmith"}) {owner}}`)
+		run(`documents(filter: DocumentFilter): [Document] @addArgumentFromContext(name: "filter", contextKey: "value")`,
+			map[string]interface{}{"roles": []interface{}{"ADMIN"}}, `{documents {owner}}`, `{documents(filter:{roles:[ADMIN]}) {owner}}`)
+	})
+	t.Run("nested input fields", func(t *testing.T) {
+		field := `documents(filter: DocumentFilter): [Document] @addArgumentFromContext(name: "filter.nested.owner", contextKey: "value")`
+		run(field, "jsmith", `{documents {owner}}`, `{documents(filter:{nested:{owner:"jsmith"}}) {owner}}`)
+		run(field, "jsmith", `{documents(filter: {level: 2, nested: {owner: "admin", level: 1}}) {owner}}`,
+			`{documents(filter:{level:2,nested:{level:1,owner:"jsmith"}}) {owner}}`)
+		run(field, "jsmith", `{documents(filter: null) {owner}}`, `{documents(filter:{nested:{owner:"jsmith"}}) {owner}}`)
+		run(`documents: [Document] @addArgumentFromContext(name: "filter.level", contextKey: "value", argumentType: "DocumentFilter!")`,
+			"2", `{documents {owner}}`, `{documents(filter:{level:2}) {owner}}`)
+		runErr(field, "jsmith", `query q($filter: DocumentFilter) {documents(filter: $filter) {owner}}`, "must be an input object literal")
+		runErr(`documents: [Document] @addArgumentFromContext(name: "filter.owner", contextKey: "value")`,
+			"jsmith", `{documents {owner}}`, "the type of argument 'filter' of field 'documents' is unknown")
+		runErr(`documents(filter: DocumentFilter): [Document] @addArgumentFromContext(name: "filter.author", contextKey: "value")`,
+			"jsmith", `{documents {owner}}`, "input object 'DocumentFilter' has no field 'author'")
+	})
+	t.Run("variables", func(t *testing.T) {
+		runVariables(`documents(roles: [Role!]): [Document] @addArgumentFromContext(name: "roles", contextKey: "value", variableName: "roles")`,
+			"ADMIN", `query q {documents {owner} ...documentFields} fragment documentFields on Query {documents {owner}}`,
+			"query q($roles:[Role!]) {documents(roles:$roles) {owner} ...documentFields}\nfragment documentFields on Query {documents(roles:$roles) {owner}}",
+			`{"first":10,"roles":["ADMIN"]}`)
+		runVariables(`documents: [Document] @addArgumentFromContext(name: "owner", contextKey: "value", variableName: "owner")`,
+			"jsmith", `{documents {owner}}`,
+			`query($owner:String) {documents(owner:$owner) {owner}}`,
+			`{"first":10,"owner":"jsmith"}`)
+		runVariables(`documents(filter: DocumentFilter): [Document] @addArgumentFromContext(name: "filter.owner", contextKey: "value", variableName: "owner")`,
+			"jsmith", `query q($level: Int) {documents(filter: {level: $level}) {owner}}`,
+			`query q($level:Int $owner:String) {documents(filter:{level:$level,owner:$owner}) {owner}}`,
+			`{"first":10,"owner":"jsmith"}`)
+	})
+	t.Run("reject client defined variables", func(t *testing.T) {
+		field := `documents: [Document] @addArgumentFromContext(name: "owner", contextKey: "value", variableName: "owner")`
+		ctx := WithGraphQLRequest(context.WithValue(context.Background(), "value", "jsmith"), &GraphQLRequest{})
+		_, err := InvokeMiddleware(&ContextMiddleware{}, ctx, schema(field), `query q($owner: String) {documents(owner: $owner) {owner}}`)
+		if err == nil || !strings.Contains(err.Error(), "variable $owner is reserved") {
+			panic(fmt.Errorf("want err for client defined variable, got: %v", err))
+		}
+		runErr(field, "jsmith", `{documents {owner}}`, "the graphql request is missing in the context")
+	})
+	t.Run("untyped values must be strings", func(t *testing.T) {
+		runErr(`documents: [Document] @addArgumentFromContext(name: "level", contextKey: "value")`,
+			3, `{documents {owner}}`, "can only be added to arguments of known type")
+	})
+}
+
+const contextMiddlewareTypedSchema = `
+schema {
+	query: Query
+}
+
+enum Role {
+	ADMIN
+	READER
+}
+
+input DocumentFilter {
+	owner: String
+	roles: [Role!]
+	level: Int
+	nested: DocumentFilter
+}
+
+type Document {
+	owner: String
+}
+`
+
 const publicSchema = `
 schema {
 	query: Query
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"context"
+	"github.com/jensneuse/graphql-go-tools/pkg/deprecation"
+	"github.com/jensneuse/graphql-go-tools/pkg/lookup"
+	"github.com/jensneuse/graphql-go-tools/pkg/parser"
+)
+
+// DeprecationMiddleware reports the usage of deprecated fields, arguments, input fields and enum values
+// this can be used to track down the clients still using members which should be removed from the schema
+// to reject requests using deprecated members enable the NoDeprecatedUsage rule of the ValidationMiddleware instead
+type DeprecationMiddleware struct {
+	// OnDeprecatedUsage gets called for each request using deprecated members with all usages of the request
+	OnDeprecatedUsage func(ctx context.Context, usages []deprecation.Usage)
+}
+
+func (d *DeprecationMiddleware) PrepareSchema(ctx context.Context, l *lookup.Lookup, w *lookup.Walker, parser *parser.Parser, mod *parser.ManualAstMod) error {
+	return nil
+}
+
+// Phase returns PhaseValidation so that deprecated usages are reported for the final request
+func (d *DeprecationMiddleware) Phase() Phase {
+	return PhaseValidation
+}
+
+func (d *DeprecationMiddleware) OnRequest(ctx context.Context, l *lookup.Lookup, w *lookup.Walker, parser *parser.Parser, mod *parser.ManualAstMod) error {
+
+	if d.OnDeprecatedUsage == nil {
+		return nil
+	}
+
+	reporter := deprecation.New()
+	reporter.SetInput(l)
+
+	usages := reporter.Report()
+	if len(usages) != 0 {
+		d.OnDeprecatedUsage(ctx, usages)
+	}
+
+	return nil
+}
+
+func (d *DeprecationMiddleware) OnResponse(ctx context.Context, response *[]byte, l *lookup.Lookup, w *lookup.Walker, parser *parser.Parser, mod *parser.ManualAstMod) (err error) {
+	return nil
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"context"
+	"github.com/jensneuse/graphql-go-tools/pkg/deprecation"
+	"testing"
+)
+
+func TestDeprecationMiddleware(t *testing.T) {
+
+	run := func(query string, wantCoordinates ...string) {
+		var got []deprecation.Usage
+		middleware := &DeprecationMiddleware{
+			OnDeprecatedUsage: func(ctx context.Context, usages []deprecation.Usage) {
+				got = append(got, usages...)
+			},
+		}
+
+		result, err := InvokeMiddleware(middleware, context.Background(), deprecationMiddlewareSchema, query)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if result != query {
+			t.Fatalf("want unmodified query: %s, got: %s", query, result)
+		}
+
+		if len(got) != len(wantCoordinates) {
+			t.Fatalf("want %d usages, got: %+v", len(wantCoordinates), got)
+		}
+		for i := range wantCoordinates {
+			if got[i].Coordinate != wantCoordinates[i] {
+				t.Fatalf("want usage of: %s, got: %s", wantCoordinates[i], got[i].Coordinate)
+			}
+		}
+	}
+
+	t.Run("without deprecated usage", func(t *testing.T) {
+		run(`query myDocuments {documents {owner}}`)
+	})
+	t.Run("with deprecated usage", func(t *testing.T) {
+		run(`query myDocuments {documents {owner ownerName}}`, "Document.ownerName")
+	})
+}
+
+const deprecationMiddlewareSchema = `
+schema {
+	query: Query
+}
+
+type Query {
+	documents: [Document]
+}
+
+type Document {
+	owner: String
+	ownerName: String @deprecated(reason: "use owner")
+}
+`
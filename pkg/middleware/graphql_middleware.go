@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/jensneuse/graphql-go-tools/pkg/lookup"
 	"github.com/jensneuse/graphql-go-tools/pkg/parser"
+	"io"
 )
 
 // GraphqlMiddleware is the interface to be implemented when writing middlewares
@@ -20,3 +21,10 @@ type GraphqlMiddleware interface {
 	// this can be used to transform the response before sending the result back to the client
 	OnResponse(ctx context.Context, response *[]byte, l *lookup.Lookup, w *lookup.Walker, parser *parser.Parser, mod *parser.ManualAstMod) (err error)
 }
+
+// RequestResolver might be implemented by middlewares which are able to answer a request without the backend
+// ResolveRequest gets called after OnRequest of the same middleware
+// if it returns resolved the response written to out is sent to the client and the remaining middlewares are skipped
+type RequestResolver interface {
+	ResolveRequest(ctx context.Context, out io.Writer, l *lookup.Lookup, w *lookup.Walker, parser *parser.Parser, mod *parser.ManualAstMod) (resolved bool, err error)
+}
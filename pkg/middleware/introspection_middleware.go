@@ -0,0 +1,868 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/jensneuse/graphql-go-tools/pkg/document"
+	"github.com/jensneuse/graphql-go-tools/pkg/introspection"
+	"github.com/jensneuse/graphql-go-tools/pkg/introspection/generator"
+	"github.com/jensneuse/graphql-go-tools/pkg/lookup"
+	"github.com/jensneuse/graphql-go-tools/pkg/parser"
+	"github.com/jensneuse/graphql-go-tools/pkg/transform"
+)
+
+// IntrospectionMiddleware answers introspection queries from the schema of the proxy instead of sending them to the backend
+// this keeps the private schema of the backend, e.g. arguments added by the ContextMiddleware, hidden from the clients
+// query operations selecting nothing but __schema, __type and __typename are resolved, all other operations are passed on
+// the middleware must be placed after the ValidationMiddleware so that rules like NoIntrospection or DepthLimit apply to introspection queries
+// the schema must contain the built-in scalars, e.g. added by the PrepareSchema step of the ValidationMiddleware
+type IntrospectionMiddleware struct {
+}
+
+// introspectionSchemaExtension are the types of the introspection system as specified in:
+// http://spec.graphql.org/October2021/#sec-Schema-Introspection
+var introspectionSchemaExtension = []byte(`
+type __Schema {
+	description: String
+	types: [__Type!]!
+	queryType: __Type!
+	mutationType: __Type
+	subscriptionType: __Type
+	directives: [__Directive!]!
+}
+type __Type {
+	kind: __TypeKind!
+	name: String
+	description: String
+	fields(includeDeprecated: Boolean = false): [__Field!]
+	interfaces: [__Type!]
+	possibleTypes: [__Type!]
+	enumValues(includeDeprecated: Boolean = false): [__EnumValue!]
+	inputFields(includeDeprecated: Boolean = false): [__InputValue!]
+	ofType: __Type
+	specifiedByURL: String
+}
+type __Field {
+	name: String!
+	description: String
+	args(includeDeprecated: Boolean = false): [__InputValue!]!
+	type: __Type!
+	isDeprecated: Boolean!
+	deprecationReason: String
+}
+type __InputValue {
+	name: String!
+	description: String
+	type: __Type!
+	defaultValue: String
+	isDeprecated: Boolean!
+	deprecationReason: String
+}
+type __EnumValue {
+	name: String!
+	description: String
+	isDeprecated: Boolean!
+	deprecationReason: String
+}
+enum __TypeKind {
+	SCALAR
+	OBJECT
+	INTERFACE
+	UNION
+	ENUM
+	INPUT_OBJECT
+	LIST
+	NON_NULL
+}
+type __Directive {
+	name: String!
+	description: String
+	locations: [__DirectiveLocation!]!
+	args(includeDeprecated: Boolean = false): [__InputValue!]!
+	isRepeatable: Boolean!
+}
+enum __DirectiveLocation {
+	QUERY
+	MUTATION
+	SUBSCRIPTION
+	FIELD
+	FRAGMENT_DEFINITION
+	FRAGMENT_SPREAD
+	INLINE_FRAGMENT
+	SCHEMA
+	SCALAR
+	OBJECT
+	FIELD_DEFINITION
+	ARGUMENT_DEFINITION
+	INTERFACE
+	UNION
+	ENUM
+	ENUM_VALUE
+	INPUT_OBJECT
+	INPUT_FIELD_DEFINITION
+	VARIABLE_DEFINITION
+}
+`)
+
+var (
+	schemaFieldName      = []byte("__schema")
+	typeFieldName        = []byte("__type")
+	typeNameFieldName    = []byte("__typename")
+	skipDirectiveName    = []byte("skip")
+	includeDirectiveName = []byte("include")
+)
+
+// introspectionRootFields are the implicit fields of the query type as specified in:
+// http://spec.graphql.org/October2021/#sec-Schema-Introspection
+var introspectionRootFields = []byte(`{
+	__schema: __Schema!
+	__type(name: String!): __Type
+}`)
+
+// PrepareSchema adds the introspection types to the schema so that they're part of the introspection response
+// the introspection fields get added to the query type so that introspection queries pass the validation
+func (i *IntrospectionMiddleware) PrepareSchema(ctx context.Context, l *lookup.Lookup, w *lookup.Walker, parser *parser.Parser, mod *parser.ManualAstMod) error {
+
+	err := parser.ExtendTypeSystemDefinition(introspectionSchemaExtension)
+	if err != nil {
+		return err
+	}
+
+	queryTypeName := []byte("Query")
+	if definition, ok := l.SchemaDefinition(); ok && definition.Query.Length() != 0 {
+		queryTypeName = l.ByteSlice(definition.Query)
+	}
+
+	objectTypeDefinitions := l.ObjectTypeDefinitions()
+	for ref := range objectTypeDefinitions {
+		if bytes.Equal(l.ByteSlice(objectTypeDefinitions[ref].Name), queryTypeName) {
+			return parser.ExtendObjectTypeDefinition(ref, introspectionRootFields)
+		}
+	}
+
+	return nil
+}
+
+// Phase returns PhaseResolve so that introspection queries get validated before they're answered
+func (i *IntrospectionMiddleware) Phase() Phase {
+	return PhaseResolve
+}
+
+func (i *IntrospectionMiddleware) OnRequest(ctx context.Context, l *lookup.Lookup, w *lookup.Walker, parser *parser.Parser, mod *parser.ManualAstMod) error {
+	return nil
+}
+
+// ResolveRequest writes the response of introspection queries
+// the operation name and variables are taken from the request set with WithGraphQLRequest
+func (i *IntrospectionMiddleware) ResolveRequest(ctx context.Context, out io.Writer, l *lookup.Lookup, w *lookup.Walker, parser *parser.Parser, mod *parser.ManualAstMod) (resolved bool, err error) {
+
+	var operationName string
+	var variables map[string]interface{}
+	if request, ok := GraphQLRequestFromContext(ctx); ok {
+		operationName, variables = request.OperationName, request.Variables
+	}
+
+	operation, ok := selectedOperation(l, operationName)
+	if !ok || operation.OperationType != document.OperationTypeQuery {
+		return false, nil
+	}
+
+	resolver := introspectionResolver{
+		l:                   l,
+		variables:           variables,
+		variableDefinitions: operation.VariableDefinitions,
+		queryTypeName:       "Query",
+	}
+
+	if definition, ok := l.SchemaDefinition(); ok && definition.Query.Length() != 0 {
+		resolver.queryTypeName = string(l.ByteSlice(definition.Query))
+	}
+
+	if !resolver.isIntrospectionOperation(operation) {
+		return false, nil
+	}
+
+	var response introspection.Response
+	gen := generator.New()
+	gen.SetInput(l)
+	err = gen.Generate(&response)
+	if err != nil {
+		return false, err
+	}
+
+	// hidden, internal and renamed elements of the SchemaVisibilityMiddleware are answered with the public schema
+	newSchemaVisibility(l).publicIntrospectionSchema(&response.Data.Schema)
+
+	resolver.schema = response.Data.Schema
+	resolver.types = make(map[string]int, len(resolver.schema.Types))
+	for j := range resolver.schema.Types {
+		resolver.types[resolver.schema.Types[j].Name] = j
+	}
+
+	resolver.out.WriteString(`{"data":`)
+	err = resolver.resolveQuery([]int{operation.SelectionSet})
+	if err != nil {
+		return false, err
+	}
+	resolver.out.WriteString(`}`)
+
+	_, err = resolver.out.WriteTo(out)
+	return err == nil, err
+}
+
+func (i *IntrospectionMiddleware) OnResponse(ctx context.Context, response *[]byte, l *lookup.Lookup, w *lookup.Walker, parser *parser.Parser, mod *parser.ManualAstMod) (err error) {
+	return nil
+}
+
+// selectedOperation returns the operation with the given name
+// operationName might be empty if the executable definition contains exactly one operation
+func selectedOperation(l *lookup.Lookup, operationName string) (document.OperationDefinition, bool) {
+
+	operations := l.OperationDefinitions()
+
+	if operationName == "" {
+		if len(operations) != 1 {
+			return document.OperationDefinition{}, false
+		}
+		return operations[0], true
+	}
+
+	for i := range operations {
+		if string(l.ByteSlice(operations[i].Name)) == operationName {
+			return operations[i], true
+		}
+	}
+
+	return document.OperationDefinition{}, false
+}
+
+// collectedField is a field of the response, the selection sets of all fields with the same response key get merged
+type collectedField struct {
+	responseKey string
+	field       document.Field
+	sets        []int
+}
+
+// introspectionResolver resolves the selection sets of an introspection query against the generated schema
+type introspectionResolver struct {
+	l                   *lookup.Lookup
+	out                 bytes.Buffer
+	schema              introspection.Schema
+	types               map[string]int
+	queryTypeName       string
+	variables           map[string]interface{}
+	variableDefinitions []int
+	fragments           []document.ByteSliceReference
+}
+
+// isIntrospectionOperation returns true if the operation selects at least one field and only introspection fields
+func (r *introspectionResolver) isIntrospectionOperation(operation document.OperationDefinition) bool {
+
+	fields, err := r.collectFields(r.queryTypeName, []int{operation.SelectionSet})
+	if err != nil || len(fields) == 0 {
+		return false
+	}
+
+	for _, field := range fields {
+		name := r.l.ByteSlice(field.field.Name)
+		if !bytes.Equal(name, schemaFieldName) && !bytes.Equal(name, typeFieldName) && !bytes.Equal(name, typeNameFieldName) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// collectFields returns the fields of the selection sets which apply to the type
+// fields skipped by @skip or @include are omitted
+func (r *introspectionResolver) collectFields(typeName string, sets []int) (fields []collectedField, err error) {
+	for _, set := range sets {
+		fields, err = r.collectSelectionSetFields(typeName, set, fields)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return fields, nil
+}
+
+func (r *introspectionResolver) collectSelectionSetFields(typeName string, ref int, fields []collectedField) ([]collectedField, error) {
+
+	set := r.l.SelectionSet(ref)
+
+	for _, fieldRef := range set.Fields {
+		field := r.l.Field(fieldRef)
+		included, err := r.included(field.DirectiveSet)
+		if err != nil {
+			return nil, err
+		}
+		if !included {
+			continue
+		}
+
+		responseKey := field.Name
+		if field.Alias.Length() != 0 {
+			responseKey = field.Alias
+		}
+
+		fields = appendCollectedField(fields, string(r.l.ByteSlice(responseKey)), field)
+	}
+
+	for _, inlineFragmentRef := range set.InlineFragments {
+		inlineFragment := r.l.InlineFragment(inlineFragmentRef)
+		if inlineFragment.TypeCondition != -1 && string(r.l.ByteSlice(r.l.Type(inlineFragment.TypeCondition).Name)) != typeName {
+			continue
+		}
+		included, err := r.included(inlineFragment.DirectiveSet)
+		if err != nil {
+			return nil, err
+		}
+		if !included {
+			continue
+		}
+		fields, err = r.collectSelectionSetFields(typeName, inlineFragment.SelectionSet, fields)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, spreadRef := range set.FragmentSpreads {
+		spread := r.l.FragmentSpread(spreadRef)
+		if r.l.ByteSliceReferencesContainName(r.fragments, spread.FragmentName) {
+			continue // cyclic fragment spreads are rejected by validation
+		}
+		fragment, _, ok := r.l.FragmentDefinitionByName(spread.FragmentName)
+		if !ok {
+			return nil, fmt.Errorf("IntrospectionMiddleware: fragment '%s' is not defined", string(r.l.ByteSlice(spread.FragmentName)))
+		}
+		if string(r.l.ByteSlice(r.l.Type(fragment.TypeCondition).Name)) != typeName {
+			continue
+		}
+		included, err := r.included(spread.DirectiveSet)
+		if err != nil {
+			return nil, err
+		}
+		if !included {
+			continue
+		}
+		r.fragments = append(r.fragments, spread.FragmentName)
+		fields, err = r.collectSelectionSetFields(typeName, fragment.SelectionSet, fields)
+		r.fragments = r.fragments[:len(r.fragments)-1]
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return fields, nil
+}
+
+func appendCollectedField(fields []collectedField, responseKey string, field document.Field) []collectedField {
+	for i := range fields {
+		if fields[i].responseKey == responseKey {
+			if field.SelectionSet != -1 {
+				fields[i].sets = append(fields[i].sets, field.SelectionSet)
+			}
+			return fields
+		}
+	}
+
+	collected := collectedField{
+		responseKey: responseKey,
+		field:       field,
+	}
+	if field.SelectionSet != -1 {
+		collected.sets = []int{field.SelectionSet}
+	}
+
+	return append(fields, collected)
+}
+
+// included evaluates the @skip and @include directives of the directive set
+func (r *introspectionResolver) included(directiveSet int) (bool, error) {
+
+	directives := r.l.DirectiveIterable(r.l.DirectiveSet(directiveSet))
+	for directives.Next() {
+		directive, _ := directives.Value()
+		name := r.l.ByteSlice(directive.Name)
+		skip := bytes.Equal(name, skipDirectiveName)
+		if !skip && !bytes.Equal(name, includeDirectiveName) {
+			continue
+		}
+
+		value, ok := r.argument(directive.ArgumentSet, "if")
+		if !ok {
+			return false, fmt.Errorf("IntrospectionMiddleware: argument 'if' of directive '@%s' is missing", string(name))
+		}
+		condition, err := r.booleanValue(value)
+		if err != nil {
+			return false, err
+		}
+
+		if condition == skip {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// argument returns the value ref of the argument with the given name
+func (r *introspectionResolver) argument(argumentSet int, name string) (int, bool) {
+	args := r.l.ArgumentsIterable(r.l.ArgumentSet(argumentSet))
+	for args.Next() {
+		arg, _ := args.Value()
+		if string(r.l.ByteSlice(arg.Name)) == name {
+			return arg.Value, true
+		}
+	}
+	return -1, false
+}
+
+// variableValue returns the provided value of a variable or its default value
+func (r *introspectionResolver) variableValue(ref int) (value interface{}, defaultValue int, ok bool) {
+
+	name := r.l.ByteSliceReference(r.l.Value(ref).Reference)
+	if value, ok := r.variables[string(r.l.ByteSlice(name))]; ok {
+		return value, -1, true
+	}
+
+	definition, ok := r.l.VariableDefinition(name, r.variableDefinitions)
+	if !ok || definition.DefaultValue == -1 {
+		return nil, -1, false
+	}
+
+	return nil, definition.DefaultValue, true
+}
+
+func (r *introspectionResolver) booleanValue(ref int) (bool, error) {
+
+	value := r.l.Value(ref)
+
+	switch value.ValueType {
+	case document.ValueTypeBoolean:
+		return value.Reference == 1, nil
+	case document.ValueTypeVariable:
+		variable, defaultValue, ok := r.variableValue(ref)
+		if ok && defaultValue != -1 {
+			return r.booleanValue(defaultValue)
+		}
+		if boolean, isBoolean := variable.(bool); ok && isBoolean {
+			return boolean, nil
+		}
+	}
+
+	return false, fmt.Errorf("IntrospectionMiddleware: value '%s' is not a boolean", string(r.l.ByteSlice(value.Raw)))
+}
+
+func (r *introspectionResolver) stringValue(ref int) (string, error) {
+
+	value := r.l.Value(ref)
+
+	switch value.ValueType {
+	case document.ValueTypeString:
+		return string(transform.UnescapeString(r.l.ByteSlice(value.Raw))), nil
+	case document.ValueTypeVariable:
+		variable, defaultValue, ok := r.variableValue(ref)
+		if ok && defaultValue != -1 {
+			return r.stringValue(defaultValue)
+		}
+		if str, isString := variable.(string); ok && isString {
+			return str, nil
+		}
+	}
+
+	return "", fmt.Errorf("IntrospectionMiddleware: value '%s' is not a string", string(r.l.ByteSlice(value.Raw)))
+}
+
+// includeDeprecated returns the value of the includeDeprecated argument, it defaults to false
+func (r *introspectionResolver) includeDeprecated(field document.Field) (bool, error) {
+	value, ok := r.argument(field.ArgumentSet, "includeDeprecated")
+	if !ok {
+		return false, nil
+	}
+	return r.booleanValue(value)
+}
+
+// resolveObject writes the fields selected on an object of the introspection system
+// resolve writes the value of all fields except __typename
+func (r *introspectionResolver) resolveObject(typeName string, sets []int, resolve func(name string, field collectedField) error) error {
+
+	fields, err := r.collectFields(typeName, sets)
+	if err != nil {
+		return err
+	}
+
+	r.out.WriteByte('{')
+	for i, field := range fields {
+		if i != 0 {
+			r.out.WriteByte(',')
+		}
+		r.writeJSON(field.responseKey)
+		r.out.WriteByte(':')
+
+		name := string(r.l.ByteSlice(field.field.Name))
+		if name == string(typeNameFieldName) {
+			r.writeJSON(typeName)
+			continue
+		}
+
+		err = resolve(name, field)
+		if err != nil {
+			return err
+		}
+	}
+	r.out.WriteByte('}')
+
+	return nil
+}
+
+func (r *introspectionResolver) resolveQuery(sets []int) error {
+	return r.resolveObject(r.queryTypeName, sets, func(name string, field collectedField) error {
+		switch name {
+		case "__schema":
+			return r.resolveSchema(field.sets)
+		case "__type":
+			value, ok := r.argument(field.field.ArgumentSet, "name")
+			if !ok {
+				return fmt.Errorf("IntrospectionMiddleware: argument 'name' of field '__type' is missing")
+			}
+			typeName, err := r.stringValue(value)
+			if err != nil {
+				return err
+			}
+			return r.resolveNamedType(field.sets, typeName)
+		default:
+			return r.unknownField(r.queryTypeName, name)
+		}
+	})
+}
+
+func (r *introspectionResolver) resolveSchema(sets []int) error {
+	return r.resolveObject("__Schema", sets, func(name string, field collectedField) error {
+		switch name {
+		case "types":
+			r.out.WriteByte('[')
+			for i := range r.schema.Types {
+				if i != 0 {
+					r.out.WriteByte(',')
+				}
+				err := r.resolveFullType(field.sets, &r.schema.Types[i])
+				if err != nil {
+					return err
+				}
+			}
+			r.out.WriteByte(']')
+			return nil
+		case "queryType":
+			return r.resolveTypeName(field.sets, r.schema.QueryType)
+		case "mutationType":
+			return r.resolveTypeName(field.sets, r.schema.MutationType)
+		case "subscriptionType":
+			return r.resolveTypeName(field.sets, r.schema.SubscriptionType)
+		case "description":
+			r.out.WriteString("null")
+			return nil
+		case "directives":
+			r.out.WriteByte('[')
+			for i := range r.schema.Directives {
+				if i != 0 {
+					r.out.WriteByte(',')
+				}
+				err := r.resolveDirective(field.sets, &r.schema.Directives[i])
+				if err != nil {
+					return err
+				}
+			}
+			r.out.WriteByte(']')
+			return nil
+		default:
+			return r.unknownField("__Schema", name)
+		}
+	})
+}
+
+func (r *introspectionResolver) resolveTypeName(sets []int, typeName *introspection.TypeName) error {
+	if typeName == nil {
+		r.out.WriteString("null")
+		return nil
+	}
+	return r.resolveNamedType(sets, typeName.Name)
+}
+
+// resolveNamedType writes the type with the given name or null if the type doesn't exist
+func (r *introspectionResolver) resolveNamedType(sets []int, name string) error {
+	i, ok := r.types[name]
+	if !ok {
+		r.out.WriteString("null")
+		return nil
+	}
+	return r.resolveFullType(sets, &r.schema.Types[i])
+}
+
+// resolveTypeRef writes a type reference, wrapping types resolve their ofType, named types resolve to the full type
+func (r *introspectionResolver) resolveTypeRef(sets []int, typeRef *introspection.TypeRef) error {
+
+	if typeRef == nil {
+		r.out.WriteString("null")
+		return nil
+	}
+
+	if typeRef.Kind != introspection.LIST && typeRef.Kind != introspection.NON_NULL {
+		if typeRef.Name == nil {
+			r.out.WriteString("null")
+			return nil
+		}
+		return r.resolveNamedType(sets, *typeRef.Name)
+	}
+
+	return r.resolveObject("__Type", sets, func(name string, field collectedField) error {
+		switch name {
+		case "kind":
+			r.writeJSON(typeRef.Kind)
+		case "ofType":
+			return r.resolveTypeRef(field.sets, typeRef.OfType)
+		case "name", "description", "fields", "interfaces", "possibleTypes", "enumValues", "inputFields", "specifiedByURL":
+			r.out.WriteString("null")
+		default:
+			return r.unknownField("__Type", name)
+		}
+		return nil
+	})
+}
+
+func (r *introspectionResolver) resolveFullType(sets []int, fullType *introspection.FullType) error {
+	return r.resolveObject("__Type", sets, func(name string, field collectedField) error {
+		switch name {
+		case "kind":
+			r.writeJSON(fullType.Kind)
+		case "name":
+			r.writeJSON(fullType.Name)
+		case "description":
+			r.writeDescription(fullType.Description)
+		case "fields":
+			if fullType.Fields == nil {
+				r.out.WriteString("null")
+				return nil
+			}
+			includeDeprecated, err := r.includeDeprecated(field.field)
+			if err != nil {
+				return err
+			}
+			r.out.WriteByte('[')
+			var written bool
+			for i := range fullType.Fields {
+				if fullType.Fields[i].IsDeprecated && !includeDeprecated {
+					continue
+				}
+				if written {
+					r.out.WriteByte(',')
+				}
+				err = r.resolveField(field.sets, &fullType.Fields[i])
+				if err != nil {
+					return err
+				}
+				written = true
+			}
+			r.out.WriteByte(']')
+		case "interfaces":
+			return r.resolveTypeRefs(field.sets, fullType.Interfaces)
+		case "possibleTypes":
+			return r.resolveTypeRefs(field.sets, fullType.PossibleTypes)
+		case "enumValues":
+			if fullType.EnumValues == nil {
+				r.out.WriteString("null")
+				return nil
+			}
+			includeDeprecated, err := r.includeDeprecated(field.field)
+			if err != nil {
+				return err
+			}
+			r.out.WriteByte('[')
+			var written bool
+			for i := range fullType.EnumValues {
+				if fullType.EnumValues[i].IsDeprecated && !includeDeprecated {
+					continue
+				}
+				if written {
+					r.out.WriteByte(',')
+				}
+				err = r.resolveEnumValue(field.sets, &fullType.EnumValues[i])
+				if err != nil {
+					return err
+				}
+				written = true
+			}
+			r.out.WriteByte(']')
+		case "inputFields":
+			return r.resolveInputValues(field, fullType.InputFields)
+		case "ofType":
+			r.out.WriteString("null")
+		case "specifiedByURL":
+			r.writeJSON(fullType.SpecifiedByURL)
+		default:
+			return r.unknownField("__Type", name)
+		}
+		return nil
+	})
+}
+
+func (r *introspectionResolver) resolveTypeRefs(sets []int, typeRefs []introspection.TypeRef) error {
+	if typeRefs == nil {
+		r.out.WriteString("null")
+		return nil
+	}
+	r.out.WriteByte('[')
+	for i := range typeRefs {
+		if i != 0 {
+			r.out.WriteByte(',')
+		}
+		err := r.resolveTypeRef(sets, &typeRefs[i])
+		if err != nil {
+			return err
+		}
+	}
+	r.out.WriteByte(']')
+	return nil
+}
+
+func (r *introspectionResolver) resolveField(sets []int, field *introspection.Field) error {
+	return r.resolveObject("__Field", sets, func(name string, selection collectedField) error {
+		switch name {
+		case "name":
+			r.writeJSON(field.Name)
+		case "description":
+			r.writeDescription(field.Description)
+		case "args":
+			return r.resolveInputValues(selection, field.Args)
+		case "type":
+			return r.resolveTypeRef(selection.sets, &field.Type)
+		case "isDeprecated":
+			r.writeJSON(field.IsDeprecated)
+		case "deprecationReason":
+			r.writeDeprecationReason(field.IsDeprecated, field.DeprecationReason)
+		default:
+			return r.unknownField("__Field", name)
+		}
+		return nil
+	})
+}
+
+// resolveInputValues writes the arguments or input fields selected by the field
+// deprecated input values are omitted unless the includeDeprecated argument of the field is true
+func (r *introspectionResolver) resolveInputValues(field collectedField, inputValues []introspection.InputValue) error {
+	if inputValues == nil {
+		r.out.WriteString("null")
+		return nil
+	}
+	includeDeprecated, err := r.includeDeprecated(field.field)
+	if err != nil {
+		return err
+	}
+	r.out.WriteByte('[')
+	var written bool
+	for i := range inputValues {
+		if inputValues[i].IsDeprecated && !includeDeprecated {
+			continue
+		}
+		if written {
+			r.out.WriteByte(',')
+		}
+		err = r.resolveInputValue(field.sets, &inputValues[i])
+		if err != nil {
+			return err
+		}
+		written = true
+	}
+	r.out.WriteByte(']')
+	return nil
+}
+
+func (r *introspectionResolver) resolveInputValue(sets []int, inputValue *introspection.InputValue) error {
+	return r.resolveObject("__InputValue", sets, func(name string, field collectedField) error {
+		switch name {
+		case "name":
+			r.writeJSON(inputValue.Name)
+		case "description":
+			r.writeDescription(inputValue.Description)
+		case "type":
+			return r.resolveTypeRef(field.sets, &inputValue.Type)
+		case "defaultValue":
+			r.writeJSON(inputValue.DefaultValue)
+		case "isDeprecated":
+			r.writeJSON(inputValue.IsDeprecated)
+		case "deprecationReason":
+			r.writeDeprecationReason(inputValue.IsDeprecated, inputValue.DeprecationReason)
+		default:
+			return r.unknownField("__InputValue", name)
+		}
+		return nil
+	})
+}
+
+func (r *introspectionResolver) resolveEnumValue(sets []int, enumValue *introspection.EnumValue) error {
+	return r.resolveObject("__EnumValue", sets, func(name string, field collectedField) error {
+		switch name {
+		case "name":
+			r.writeJSON(enumValue.Name)
+		case "description":
+			r.writeDescription(enumValue.Description)
+		case "isDeprecated":
+			r.writeJSON(enumValue.IsDeprecated)
+		case "deprecationReason":
+			r.writeDeprecationReason(enumValue.IsDeprecated, enumValue.DeprecationReason)
+		default:
+			return r.unknownField("__EnumValue", name)
+		}
+		return nil
+	})
+}
+
+func (r *introspectionResolver) resolveDirective(sets []int, directive *introspection.Directive) error {
+	return r.resolveObject("__Directive", sets, func(name string, field collectedField) error {
+		switch name {
+		case "name":
+			r.writeJSON(directive.Name)
+		case "description":
+			r.writeDescription(directive.Description)
+		case "locations":
+			r.writeJSON(directive.Locations)
+		case "args":
+			return r.resolveInputValues(field, directive.Args)
+		case "isRepeatable":
+			r.writeJSON(directive.IsRepeatable)
+		default:
+			return r.unknownField("__Directive", name)
+		}
+		return nil
+	})
+}
+
+func (r *introspectionResolver) unknownField(typeName, fieldName string) error {
+	return fmt.Errorf("IntrospectionMiddleware: field '%s' is not defined on type '%s'", fieldName, typeName)
+}
+
+// writeDescription writes empty descriptions as null
+func (r *introspectionResolver) writeDescription(description string) {
+	if description == "" {
+		r.out.WriteString("null")
+		return
+	}
+	r.writeJSON(description)
+}
+
+func (r *introspectionResolver) writeDeprecationReason(isDeprecated bool, reason string) {
+	if !isDeprecated {
+		r.out.WriteString("null")
+		return
+	}
+	r.writeJSON(reason)
+}
+
+// writeJSON writes strings, booleans, string slices and type kinds which can't fail to marshal
+func (r *introspectionResolver) writeJSON(v interface{}) {
+	data, _ := json.Marshal(v)
+	r.out.Write(data)
+}
@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+)
+
+func TestIntrospectionMiddleware(t *testing.T) {
+
+	run := func(ctx context.Context, query, want string) {
+		got, err := InvokeMiddleware(&IntrospectionMiddleware{}, ctx, introspectionMiddlewareSchema, query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want != got {
+			t.Fatalf("want:\n%s\ngot:\n%s", want, got)
+		}
+	}
+
+	runErr := func(query string) {
+		_, err := InvokeMiddleware(&IntrospectionMiddleware{}, context.Background(), introspectionMiddlewareSchema, query)
+		if err == nil {
+			t.Fatal("want err")
+		}
+	}
+
+	t.Run("root operation types", func(t *testing.T) {
+		run(nil, `{__schema {queryType {name} mutationType {name} subscriptionType {name}}}`,
+			`{"data":{"__schema":{"queryType":{"name":"Query"},"mutationType":null,"subscriptionType":null}}}`)
+	})
+	t.Run("types contain the introspection types", func(t *testing.T) {
+		run(nil, `{__schema {types {name}}}`,
+			`{"data":{"__schema":{"types":[{"name":"Query"},{"name":"Document"},{"name":"Status"},{"name":"String"},{"name":"Boolean"},{"name":"DateTime"},`+
+				`{"name":"__Schema"},{"name":"__Type"},{"name":"__Field"},{"name":"__InputValue"},{"name":"__EnumValue"},{"name":"__TypeKind"},{"name":"__Directive"},{"name":"__DirectiveLocation"}]}}}`)
+	})
+	t.Run("type by name", func(t *testing.T) {
+		run(nil, `{__type(name: "Query") {kind name description fields {name args {name type {kind name ofType {kind name}} defaultValue} type {kind name ofType {kind name ofType {kind name}}}}}}`,
+			`{"data":{"__type":{"kind":"OBJECT","name":"Query","description":"the root type","fields":[`+
+				`{"name":"documents","args":[{"name":"status","type":{"kind":"ENUM","name":"Status","ofType":null},"defaultValue":"PUBLISHED"}],"type":{"kind":"NON_NULL","name":null,"ofType":{"kind":"LIST","name":null,"ofType":{"kind":"OBJECT","name":"Document"}}}},`+
+				`{"name":"events","args":[{"name":"after","type":{"kind":"SCALAR","name":"DateTime","ofType":null},"defaultValue":null}],"type":{"kind":"LIST","name":null,"ofType":{"kind":"SCALAR","name":"String","ofType":null}}}]}}}`)
+	})
+	t.Run("unknown type", func(t *testing.T) {
+		run(nil, `{__type(name: "Unknown") {name}}`, `{"data":{"__type":null}}`)
+	})
+	t.Run("type name from variable", func(t *testing.T) {
+		ctx := WithGraphQLRequest(context.Background(), &GraphQLRequest{
+			Variables: map[string]interface{}{"name": "Document"},
+		})
+		run(ctx, `query typeByName($name: String!) {__type(name: $name) {name}}`, `{"data":{"__type":{"name":"Document"}}}`)
+	})
+	t.Run("type name from variable default value", func(t *testing.T) {
+		run(nil, `query typeByName($name: String = "Status") {__type(name: $name) {name}}`, `{"data":{"__type":{"name":"Status"}}}`)
+	})
+	t.Run("include deprecated", func(t *testing.T) {
+		run(nil, `{document: __type(name: "Document") {fields {name isDeprecated deprecationReason}} status: __type(name: "Status") {enumValues(includeDeprecated: true) {name isDeprecated deprecationReason}}}`,
+			`{"data":{"document":{"fields":[{"name":"owner","isDeprecated":false,"deprecationReason":null}]},`+
+				`"status":{"enumValues":[{"name":"DRAFT","isDeprecated":false,"deprecationReason":null},{"name":"PUBLISHED","isDeprecated":false,"deprecationReason":null},{"name":"ARCHIVED","isDeprecated":true,"deprecationReason":"use PUBLISHED"}]}}}`)
+	})
+	t.Run("directives", func(t *testing.T) {
+		run(nil, `{__schema {directives {name locations args {name defaultValue}}}}`,
+			`{"data":{"__schema":{"directives":[{"name":"deprecated","locations":["FIELD_DEFINITION","ARGUMENT_DEFINITION","ENUM_VALUE"],"args":[{"name":"reason","defaultValue":"\"No longer supported\""}]},`+
+				`{"name":"tag","locations":["FIELD_DEFINITION","OBJECT"],"args":[{"name":"name","defaultValue":null}]}]}}}`)
+	})
+	t.Run("october 2021 fields", func(t *testing.T) {
+		run(nil, `{__schema {description directives {name isRepeatable}} dateTime: __type(name: "DateTime") {specifiedByURL} string: __type(name: "String") {specifiedByURL} query: __type(name: "Query") {fields {name args {name} allArgs: args(includeDeprecated: true) {name isDeprecated deprecationReason}}}}`,
+			`{"data":{"__schema":{"description":null,"directives":[{"name":"deprecated","isRepeatable":false},{"name":"tag","isRepeatable":true}]},`+
+				`"dateTime":{"specifiedByURL":"https://tools.ietf.org/html/rfc3339"},"string":{"specifiedByURL":null},"query":{"fields":[`+
+				`{"name":"documents","args":[{"name":"status"}],"allArgs":[{"name":"status","isDeprecated":false,"deprecationReason":null}]},`+
+				`{"name":"events","args":[{"name":"after"}],"allArgs":[{"name":"since","isDeprecated":true,"deprecationReason":"use after"},{"name":"after","isDeprecated":false,"deprecationReason":null}]}]}}}`)
+	})
+	t.Run("typename", func(t *testing.T) {
+		run(nil, `{__typename __schema {__typename queryType {__typename}}}`,
+			`{"data":{"__typename":"Query","__schema":{"__typename":"__Schema","queryType":{"__typename":"__Type"}}}}`)
+	})
+	t.Run("fragments", func(t *testing.T) {
+		run(nil, `query IntrospectionQuery {__schema {...schemaFields ... on __Schema {queryType {name}} ... on Query {types {name}}}} fragment schemaFields on __Schema {queryType {kind}}`,
+			`{"data":{"__schema":{"queryType":{"name":"Query","kind":"OBJECT"}}}}`)
+	})
+	t.Run("skip and include", func(t *testing.T) {
+		ctx := WithGraphQLRequest(context.Background(), &GraphQLRequest{
+			Variables: map[string]interface{}{"skip": true},
+		})
+		run(ctx, `query skipped($skip: Boolean!) {__schema {queryType @skip(if: $skip) {name} mutationType @include(if: false) {name} subscriptionType @include(if: true) {name}}}`,
+			`{"data":{"__schema":{"subscriptionType":null}}}`)
+	})
+	t.Run("selected operation", func(t *testing.T) {
+		ctx := WithGraphQLRequest(context.Background(), &GraphQLRequest{
+			OperationName: "introspection",
+		})
+		run(ctx, `query documents {documents {owner}} query introspection {__typename}`, `{"data":{"__typename":"Query"}}`)
+	})
+	t.Run("non introspection operations are passed on", func(t *testing.T) {
+		run(nil, `query documents {__typename documents {owner}}`, `query documents {__typename documents {owner}}`)
+	})
+	t.Run("unknown field", func(t *testing.T) {
+		runErr(`{__schema {foo}}`)
+	})
+	t.Run("undefined fragment", func(t *testing.T) {
+		runErr(`{__schema {queryType {...undefined}}}`)
+	})
+}
+
+const introspectionMiddlewareSchema = `
+schema {
+	query: Query
+}
+
+"the root type"
+type Query {
+	documents(status: Status = PUBLISHED): [Document]!
+	events(since: DateTime @deprecated(reason: "use after"), after: DateTime): [String]
+}
+
+type Document {
+	owner: String
+	sensitiveInformation: String @deprecated
+}
+
+enum Status {
+	DRAFT
+	PUBLISHED
+	ARCHIVED @deprecated(reason: "use PUBLISHED")
+}
+
+scalar String
+scalar Boolean
+scalar DateTime @specifiedBy(url: "https://tools.ietf.org/html/rfc3339")
+
+directive @deprecated(
+	reason: String = "No longer supported"
+) on FIELD_DEFINITION | ARGUMENT_DEFINITION | ENUM_VALUE
+
+directive @tag(name: String!) repeatable on FIELD_DEFINITION | OBJECT
+`
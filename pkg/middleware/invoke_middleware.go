@@ -8,6 +8,7 @@ import (
 // InvokeMiddleware is a one off middleware invocation helper
 // This should only be used for testing as it's a waste of resources
 // It makes use of panics to don't use this in production!
+// If the middleware resolves the request the result is the response instead of the rewritten request
 func InvokeMiddleware(middleware GraphqlMiddleware, ctx context.Context, schema, request string) (result string, err error) {
 
 	invoker := NewInvoker(middleware)
@@ -21,6 +22,10 @@ func InvokeMiddleware(middleware GraphqlMiddleware, ctx context.Context, schema,
 		return
 	}
 
+	if response, resolved := invoker.ResolvedResponse(); resolved {
+		return string(response), err
+	}
+
 	buff := bytes.Buffer{}
 	err = invoker.RewriteRequest(&buff)
 	if err != nil {
@@ -29,3 +34,27 @@ func InvokeMiddleware(middleware GraphqlMiddleware, ctx context.Context, schema,
 
 	return buff.String(), err
 }
+
+// InvokeMiddlewareOnResponse is a one off helper to test the response phase of a middleware
+// the request is invoked first so that the middleware has access to the request AST, the result is the rewritten response
+func InvokeMiddlewareOnResponse(middleware GraphqlMiddleware, ctx context.Context, schema, request, response string) (result string, err error) {
+
+	invoker := NewInvoker(middleware)
+	err = invoker.SetSchema([]byte(schema))
+	if err != nil {
+		return
+	}
+
+	err = invoker.InvokeMiddleWares(ctx, []byte(request))
+	if err != nil {
+		return
+	}
+
+	out := []byte(response)
+	err = invoker.InvokeMiddleWaresOnResponse(ctx, &out)
+	if err != nil {
+		return
+	}
+
+	return string(out), err
+}
@@ -1,7 +1,10 @@
 package middleware
 
 import (
+	"bytes"
 	"context"
+	"github.com/jensneuse/graphql-go-tools/pkg/coercion"
+	"github.com/jensneuse/graphql-go-tools/pkg/cost"
 	"github.com/jensneuse/graphql-go-tools/pkg/lookup"
 	"github.com/jensneuse/graphql-go-tools/pkg/parser"
 	"github.com/jensneuse/graphql-go-tools/pkg/printer"
@@ -12,6 +15,36 @@ type GraphQLRequest struct {
 	OperationName string                 `json:"operationName,omitempty"`
 	Query         string                 `json:"query"`
 	Variables     map[string]interface{} `json:"variables,omitempty"`
+	// Extensions are handled by the proxy and not sent to the backend
+	Extensions *GraphQLRequestExtensions `json:"extensions,omitempty"`
+}
+
+// GraphQLRequestExtensions are the extensions of a request known to the proxy
+type GraphQLRequestExtensions struct {
+	PersistedQuery *PersistedQueryExtension `json:"persistedQuery,omitempty"`
+}
+
+// PersistedQueryExtension identifies a query by its hash instead of sending the query, see package persistedquery
+type PersistedQueryExtension struct {
+	Version    int    `json:"version"`
+	Sha256Hash string `json:"sha256Hash"`
+}
+
+type graphQLRequestContextKey struct{}
+
+// WithGraphQLRequest returns a context which makes the request available to the middlewares
+// this gives middlewares access to the operation name and the variables of the request
+func WithGraphQLRequest(ctx context.Context, request *GraphQLRequest) context.Context {
+	return context.WithValue(ctx, graphQLRequestContextKey{}, request)
+}
+
+// GraphQLRequestFromContext returns the request set with WithGraphQLRequest
+func GraphQLRequestFromContext(ctx context.Context) (*GraphQLRequest, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	request, ok := ctx.Value(graphQLRequestContextKey{}).(*GraphQLRequest)
+	return request, ok && request != nil
 }
 
 type Invoker struct {
@@ -21,6 +54,12 @@ type Invoker struct {
 	walk        *lookup.Walker
 	mod         *parser.ManualAstMod
 	astPrint    *printer.Printer
+	coerce      *coercion.Coercer
+	cost        *cost.Calculator
+	response    bytes.Buffer
+	resolved    bool
+	// invoked is the number of middlewares whose OnRequest got invoked for the current request
+	invoked int
 }
 
 func NewInvoker(middleWares ...GraphqlMiddleware) *Invoker {
@@ -35,16 +74,27 @@ func NewInvoker(middleWares ...GraphqlMiddleware) *Invoker {
 		walk:        walk,
 		mod:         parser.NewManualAstMod(parse),
 		astPrint:    astPrint,
+		coerce:      coercion.New(),
+		cost:        cost.New(),
 		middleWares: middleWares,
 	}
 }
 
+// SetMiddleWares replaces the middlewares invoked for the following requests, e.g. with the middlewares of a RequestConfig
+func (i *Invoker) SetMiddleWares(middleWares ...GraphqlMiddleware) {
+	i.middleWares = middleWares
+}
+
 func (i *Invoker) SetSchema(schema []byte) error {
 	return i.parse.ParseTypeSystemDefinition(schema)
 }
 
 func (i *Invoker) InvokeMiddleWares(ctx context.Context, request []byte) (err error) {
 
+	i.response.Reset()
+	i.resolved = false
+	i.invoked = 0
+
 	err = i.middlewaresPrepareSchema(ctx)
 	if err != nil {
 		return err
@@ -60,12 +110,51 @@ func (i *Invoker) InvokeMiddleWares(ctx context.Context, request []byte) (err er
 	return i.middlewaresOnRequest(ctx)
 }
 
+// ResolvedResponse returns the response written by a RequestResolver
+// if resolved is true the request must not be sent to the backend
+// the response is only valid until the next invocation
+func (i *Invoker) ResolvedResponse() (response []byte, resolved bool) {
+	return i.response.Bytes(), i.resolved
+}
+
+// InvokeMiddleWaresOnResponse runs the OnResponse handlers over the buffered response in reverse order
+// it has to be called after InvokeMiddleWares so that the middlewares have access to the AST of the request
+// if a middleware resolved the request only the middlewares up to the resolving one get invoked
+func (i *Invoker) InvokeMiddleWaresOnResponse(ctx context.Context, response *[]byte) error {
+	for j := i.invoked - 1; j >= 0; j-- {
+		i.walk.SetLookup(i.look)
+		err := i.middleWares[j].OnResponse(ctx, response, i.look, i.walk, i.parse, i.mod)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (i *Invoker) RewriteRequest(w io.Writer) error {
 	i.walk.SetLookup(i.look)
 	i.walk.WalkExecutable()
 	return i.astPrint.PrintExecutableSchema(w)
 }
 
+// CoerceVariables validates and coerces the variables of a request against the variable definitions of the operation
+// it has to be called after InvokeMiddleWares so that the executable definition is already parsed and validated
+// scalarCoercers (optional) are used to coerce custom scalar values
+func (i *Invoker) CoerceVariables(operationName string, variables map[string]interface{}, scalarCoercers map[string]coercion.ScalarCoercer) (map[string]interface{}, error) {
+	i.coerce.SetInput(i.look)
+	i.coerce.SetScalarCoercers(scalarCoercers)
+	return i.coerce.CoerceVariableValues(operationName, variables)
+}
+
+// Cost computes the static cost of the operation with the given name, e.g. for rate limiting
+// it has to be called after InvokeMiddleWares so that the executable definition is already parsed
+// variables are used to resolve multiplier arguments, see package cost for details
+func (i *Invoker) Cost(operationName string, variables map[string]interface{}) (int, error) {
+	i.cost.SetInput(i.look)
+	i.cost.SetVariables(variables)
+	return i.cost.Cost(operationName)
+}
+
 func (i *Invoker) middlewaresPrepareSchema(ctx context.Context) error {
 	for j := range i.middleWares {
 		err := i.middleWares[j].PrepareSchema(ctx, i.look, i.walk, i.parse, i.mod)
@@ -78,10 +167,23 @@ func (i *Invoker) middlewaresPrepareSchema(ctx context.Context) error {
 
 func (i *Invoker) middlewaresOnRequest(ctx context.Context) error {
 	for j := range i.middleWares {
+		i.invoked = j + 1
 		err := i.middleWares[j].OnRequest(ctx, i.look, i.walk, i.parse, i.mod)
 		if err != nil {
 			return err
 		}
+		resolver, ok := i.middleWares[j].(RequestResolver)
+		if !ok {
+			continue
+		}
+		i.resolved, err = resolver.ResolveRequest(ctx, &i.response, i.look, i.walk, i.parse, i.mod)
+		if err != nil {
+			return err
+		}
+		if i.resolved {
+			return nil
+		}
+		i.response.Reset()
 	}
 	return nil
 }
@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jensneuse/graphql-go-tools/pkg/lookup"
+	"github.com/jensneuse/graphql-go-tools/pkg/parser"
+	"io"
+	"testing"
+)
+
+// recordingMiddleware appends its name to the response and optionally resolves the request
+type recordingMiddleware struct {
+	name    string
+	resolve bool
+	err     error
+}
+
+func (r *recordingMiddleware) PrepareSchema(ctx context.Context, l *lookup.Lookup, w *lookup.Walker, parser *parser.Parser, mod *parser.ManualAstMod) error {
+	return nil
+}
+
+func (r *recordingMiddleware) OnRequest(ctx context.Context, l *lookup.Lookup, w *lookup.Walker, parser *parser.Parser, mod *parser.ManualAstMod) error {
+	return nil
+}
+
+func (r *recordingMiddleware) ResolveRequest(ctx context.Context, out io.Writer, l *lookup.Lookup, w *lookup.Walker, parser *parser.Parser, mod *parser.ManualAstMod) (resolved bool, err error) {
+	if !r.resolve {
+		return false, nil
+	}
+	_, err = out.Write([]byte(r.name))
+	return true, err
+}
+
+func (r *recordingMiddleware) OnResponse(ctx context.Context, response *[]byte, l *lookup.Lookup, w *lookup.Walker, parser *parser.Parser, mod *parser.ManualAstMod) (err error) {
+	if r.err != nil {
+		return r.err
+	}
+	// the request AST must still be available during the response phase
+	if len(parser.ParsedDefinitions.OperationDefinitions) == 0 {
+		return fmt.Errorf("%s: missing operation definition", r.name)
+	}
+	*response = append(*response, []byte(","+r.name)...)
+	return nil
+}
+
+func TestInvoker_InvokeMiddleWaresOnResponse(t *testing.T) {
+
+	run := func(response string, want string, wantErr bool, middleWares ...GraphqlMiddleware) {
+
+		invoker := NewInvoker(middleWares...)
+		err := invoker.SetSchema([]byte(invokerTestSchema))
+		if err != nil {
+			panic(err)
+		}
+
+		err = invoker.InvokeMiddleWares(context.Background(), []byte(`query q {documents {owner}}`))
+		if err != nil {
+			panic(err)
+		}
+
+		if resolved, ok := invoker.ResolvedResponse(); ok {
+			response = string(resolved)
+		}
+
+		out := []byte(response)
+		err = invoker.InvokeMiddleWaresOnResponse(context.Background(), &out)
+		if wantErr {
+			if err == nil {
+				panic("want err")
+			}
+			return
+		}
+		if err != nil {
+			panic(err)
+		}
+
+		if want != string(out) {
+			panic(fmt.Errorf("want:\n%s\ngot:\n%s", want, string(out)))
+		}
+	}
+
+	t.Run("reverse order", func(t *testing.T) {
+		run("backend", "backend,c,b,a", false,
+			&recordingMiddleware{name: "a"},
+			&recordingMiddleware{name: "b"},
+			&recordingMiddleware{name: "c"},
+		)
+	})
+	t.Run("resolved request skips the middlewares after the resolver", func(t *testing.T) {
+		run("backend", "b,b,a", false,
+			&recordingMiddleware{name: "a"},
+			&recordingMiddleware{name: "b", resolve: true},
+			&recordingMiddleware{name: "c"},
+		)
+	})
+	t.Run("error stops the response phase", func(t *testing.T) {
+		run("backend", "", true,
+			&recordingMiddleware{name: "a"},
+			&recordingMiddleware{name: "b", err: errors.New("failing")},
+		)
+	})
+	t.Run("without middlewares", func(t *testing.T) {
+		run("backend", "backend", false)
+	})
+	t.Run("builtin middlewares pass the response through", func(t *testing.T) {
+		run("backend", "backend", false,
+			&ContextMiddleware{},
+			&DeprecationMiddleware{},
+			&ValidationMiddleware{},
+		)
+	})
+}
+
+func TestInvokeMiddlewareOnResponse(t *testing.T) {
+	got, err := InvokeMiddlewareOnResponse(&recordingMiddleware{name: "a"}, context.Background(), invokerTestSchema, `{documents {owner}}`, "backend")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "backend,a" {
+		t.Fatalf("want: backend,a, got: %s", got)
+	}
+}
+
+const invokerTestSchema = `
+schema {
+	query: Query
+}
+
+type Query {
+	documents: [Document]
+}
+
+type Document {
+	owner: String
+}
+`
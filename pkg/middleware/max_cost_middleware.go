@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/jensneuse/graphql-go-tools/pkg/cost"
+	"github.com/jensneuse/graphql-go-tools/pkg/lookup"
+	"github.com/jensneuse/graphql-go-tools/pkg/parser"
+)
+
+/*
+MaxCostMiddleware rejects requests with a static cost greater than MaxCost, see package cost for the cost analysis
+
+unlike the cost.MaxCost validation rule it computes the cost with the variables of the request:
+
+	query users($first: Int) {
+		users(first: $first) { name }
+	}
+
+costs 1 + 100 * 1 with the variables {"first": 100}, multiplier variables which can't be resolved count as cost.Calculator.MaxMultiplier
+
+rejected requests fail with cost.ErrMaxCostExceeded which the proxies answer with a graphql error and status code 400
+*/
+type MaxCostMiddleware struct {
+	// MaxCost is the maximum cost of a request
+	MaxCost int
+}
+
+func (m *MaxCostMiddleware) PrepareSchema(ctx context.Context, l *lookup.Lookup, w *lookup.Walker, parser *parser.Parser, mod *parser.ManualAstMod) error {
+	return parser.ExtendTypeSystemDefinition([]byte(cost.DirectiveDefinition))
+}
+
+// Phase returns PhaseAdmission so that rejected requests aren't processed any further
+func (m *MaxCostMiddleware) Phase() Phase {
+	return PhaseAdmission
+}
+
+func (m *MaxCostMiddleware) OnRequest(ctx context.Context, l *lookup.Lookup, w *lookup.Walker, parser *parser.Parser, mod *parser.ManualAstMod) error {
+
+	var request GraphQLRequest
+	if graphqlRequest, ok := GraphQLRequestFromContext(ctx); ok {
+		request = *graphqlRequest
+	}
+
+	calculator := cost.New()
+	calculator.SetInput(l)
+	calculator.SetVariables(request.Variables)
+	requestCost, err := calculator.Cost(request.OperationName)
+	if err != nil {
+		return err
+	}
+
+	if requestCost > m.MaxCost {
+		return cost.ErrMaxCostExceeded
+	}
+
+	return nil
+}
+
+func (m *MaxCostMiddleware) OnResponse(ctx context.Context, response *[]byte, l *lookup.Lookup, w *lookup.Walker, parser *parser.Parser, mod *parser.ManualAstMod) error {
+	return nil
+}
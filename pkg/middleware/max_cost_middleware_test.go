@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/jensneuse/graphql-go-tools/pkg/cost"
+)
+
+func TestMaxCostMiddleware(t *testing.T) {
+
+	middleware := &MaxCostMiddleware{MaxCost: 30}
+
+	variables := func(variables map[string]interface{}) context.Context {
+		return WithGraphQLRequest(context.Background(), &GraphQLRequest{OperationName: "documents", Variables: variables})
+	}
+
+	run := func(ctx context.Context, query string) {
+		_, err := InvokeMiddleware(middleware, ctx, rateLimitMiddlewareSchema, query)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	runErr := func(ctx context.Context, query string) {
+		_, err := InvokeMiddleware(middleware, ctx, rateLimitMiddlewareSchema, query)
+		if err != cost.ErrMaxCostExceeded {
+			panic(fmt.Errorf("want err: %+v, got: %+v", cost.ErrMaxCostExceeded, err))
+		}
+	}
+
+	query := `query documents($first: Int) {documents(first: $first) {owner}}`
+
+	t.Run("within max cost", func(t *testing.T) {
+		run(context.Background(), `{documents(first: 10) {owner}}`)
+	})
+	t.Run("exceeds max cost", func(t *testing.T) {
+		runErr(context.Background(), `{documents(first: 100) {owner}}`)
+	})
+	t.Run("variable within max cost", func(t *testing.T) {
+		run(variables(map[string]interface{}{"first": 10}), query)
+	})
+	t.Run("variable exceeds max cost", func(t *testing.T) {
+		runErr(variables(map[string]interface{}{"first": 1000000}), query)
+	})
+	t.Run("missing variable counts as max multiplier", func(t *testing.T) {
+		runErr(variables(nil), query)
+	})
+}
@@ -1,12 +1,22 @@
 package middleware
 
+// InvokerPool hands out invokers for the requests of a proxy
+// a request holds its invoker until the response is written because the response phase needs the request AST,
+// if all invokers are in use the pool hands out temporary invokers so that concurrent requests never wait for each other
 type InvokerPool struct {
-	index    chan int
-	invokers []*Invoker
+	index       chan int
+	invokers    []*Invoker
+	middleWares []GraphqlMiddleware
 }
 
+// temporaryInvokerIndex is the index of invokers created because all invokers of the pool were in use
+const temporaryInvokerIndex = -1
+
+// NewInvokerPool returns a pool keeping size invokers, it should be sized to the usual number of concurrent requests
 func NewInvokerPool(size int, middleWares ...GraphqlMiddleware) *InvokerPool {
-	pool := &InvokerPool{}
+	pool := &InvokerPool{
+		middleWares: middleWares,
+	}
 	pool.index = make(chan int, size)
 	pool.invokers = make([]*Invoker, size)
 	for i := 0; i < size; i++ {
@@ -17,12 +27,22 @@ func NewInvokerPool(size int, middleWares ...GraphqlMiddleware) *InvokerPool {
 	return pool
 }
 
+// Get returns an idle invoker of the pool or a temporary invoker if all invokers are in use
 func (i *InvokerPool) Get() (index int, invoker *Invoker) {
-	index = <-i.index
-	invoker = i.invokers[index]
-	return
+	select {
+	case index = <-i.index:
+		return index, i.invokers[index]
+	default:
+		return temporaryInvokerIndex, NewInvoker(i.middleWares...)
+	}
 }
 
+// Free returns the invoker to the pool, middlewares set with Invoker.SetMiddleWares are reset to the ones of the pool
+// temporary invokers are dropped so that the pool doesn't keep more than size invokers after a burst of requests
 func (i *InvokerPool) Free(index int) {
+	if index == temporaryInvokerIndex {
+		return
+	}
+	i.invokers[index].SetMiddleWares(i.middleWares...)
 	i.index <- index
 }
@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+
+	"github.com/jensneuse/graphql-go-tools/pkg/cost"
+	"github.com/jensneuse/graphql-go-tools/pkg/lookup"
+	"github.com/jensneuse/graphql-go-tools/pkg/parser"
+	"github.com/jensneuse/graphql-go-tools/pkg/ratelimit"
+)
+
+/*
+RateLimitMiddleware limits the requests of each client with a token bucket, see package ratelimit
+
+clients are identified by the context value of ContextKey, e.g. an api key header added with AddHeadersToContext or a claim,
+requests without this value are rejected with status code 401 unless AnonymousLimit is set,
+anonymous requests then share a single bucket of their own so that they can't exhaust the bucket of a client
+
+each request takes a single token, if CostBased is set it takes the static cost of its operation instead:
+
+	type Query {
+		documents(first: Int): [Document] @cost(weight: 2, multipliers: ["first"])
+	}
+
+with a Limit of {Capacity: 100, Period: time.Minute} a client might send the query '{documents(first: 10) {owner}}'
+with a cost of 12 eight times a minute, see package cost for the cost analysis
+
+rejected requests fail with a ratelimit.Error which the proxies answer with a graphql error, status code 429
+and a Retry-After header, requests costing more than the capacity of a bucket are rejected with status code 400
+
+the middleware should be placed before the middlewares resolving requests, e.g. the IntrospectionMiddleware
+or the ResponseCacheMiddleware, so that resolved requests take tokens as well
+Chain places it accordingly (PhaseAdmission), use When to limit e.g. mutations only
+*/
+type RateLimitMiddleware struct {
+	// Store (optional) defaults to a ratelimit.Local store for DefaultRateLimitClients clients
+	Store ratelimit.Store
+	// Limit is the token bucket of each client
+	Limit ratelimit.Limit
+	// ContextKey is the context key identifying the client, e.g. the api key or the user id
+	ContextKey string
+	// AnonymousLimit (optional) is the token bucket shared by all requests without client, they're rejected if it's not set
+	AnonymousLimit ratelimit.Limit
+	// CostBased makes requests take the cost of their operation instead of a single token
+	CostBased bool
+
+	mux sync.Mutex
+}
+
+// DefaultRateLimitClients is the number of clients of the default store of the RateLimitMiddleware
+const DefaultRateLimitClients = 1024
+
+func (r *RateLimitMiddleware) PrepareSchema(ctx context.Context, l *lookup.Lookup, w *lookup.Walker, parser *parser.Parser, mod *parser.ManualAstMod) error {
+	if !r.CostBased {
+		return nil
+	}
+	return parser.ExtendTypeSystemDefinition([]byte(cost.DirectiveDefinition))
+}
+
+// Phase returns PhaseAdmission so that rejected requests aren't processed any further
+func (r *RateLimitMiddleware) Phase() Phase {
+	return PhaseAdmission
+}
+
+func (r *RateLimitMiddleware) OnRequest(ctx context.Context, l *lookup.Lookup, w *lookup.Walker, parser *parser.Parser, mod *parser.ManualAstMod) error {
+
+	tokens := 1
+	if r.CostBased {
+		var err error
+		tokens, err = r.cost(ctx, l)
+		if err != nil {
+			return err
+		}
+	}
+
+	key, limit := r.bucket(ctx)
+	if key == "" {
+		return ratelimit.ErrMissingClient
+	}
+
+	if tokens > limit.Capacity {
+		return ratelimit.ErrCostExceedsLimit
+	}
+
+	result, err := r.store().Take(ctx, key, tokens, limit)
+	if err != nil {
+		return err
+	}
+
+	if !result.Allowed {
+		return ratelimit.ErrRateLimited.WithRetryAfter(result.RetryAfter)
+	}
+
+	return nil
+}
+
+func (r *RateLimitMiddleware) OnResponse(ctx context.Context, response *[]byte, l *lookup.Lookup, w *lookup.Walker, parser *parser.Parser, mod *parser.ManualAstMod) error {
+	return nil
+}
+
+// bucket returns the store key and the limit of the client of the request
+// clients and anonymous requests use distinct key prefixes so that no client can use the anonymous bucket,
+// the key is empty if the request has no client and AnonymousLimit isn't set
+func (r *RateLimitMiddleware) bucket(ctx context.Context) (string, ratelimit.Limit) {
+	if client := contextValueString(ctx, r.ContextKey); client != "" {
+		return "client:" + client, r.Limit
+	}
+	if r.AnonymousLimit.Valid() {
+		return "anonymous", r.AnonymousLimit
+	}
+	return "", ratelimit.Limit{}
+}
+
+// cost computes the cost of the requested operation with the variables of the request
+func (r *RateLimitMiddleware) cost(ctx context.Context, l *lookup.Lookup) (int, error) {
+
+	var request GraphQLRequest
+	if graphqlRequest, ok := GraphQLRequestFromContext(ctx); ok {
+		request = *graphqlRequest
+	}
+
+	calculator := cost.New()
+	calculator.SetInput(l)
+	calculator.SetVariables(request.Variables)
+	return calculator.Cost(request.OperationName)
+}
+
+func (r *RateLimitMiddleware) store() ratelimit.Store {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if r.Store == nil {
+		r.Store = ratelimit.NewLocal(DefaultRateLimitClients)
+	}
+	return r.Store
+}
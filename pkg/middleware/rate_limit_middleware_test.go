@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jensneuse/graphql-go-tools/pkg/ratelimit"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+
+	now := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	newMiddleware := func(capacity int, costBased bool) *RateLimitMiddleware {
+		store := ratelimit.NewLocal(10)
+		store.Now = func() time.Time {
+			return now
+		}
+		return &RateLimitMiddleware{
+			Store:      store,
+			Limit:      ratelimit.Limit{Capacity: capacity, Period: 10 * time.Second},
+			ContextKey: "apiKey",
+			CostBased:  costBased,
+		}
+	}
+
+	run := func(middleware *RateLimitMiddleware, ctx context.Context, query string) {
+		_, err := InvokeMiddleware(middleware, ctx, rateLimitMiddlewareSchema, query)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	runErr := func(middleware *RateLimitMiddleware, ctx context.Context, query string, wantErr ratelimit.Error) {
+		_, err := InvokeMiddleware(middleware, ctx, rateLimitMiddlewareSchema, query)
+		if err != wantErr {
+			panic(fmt.Errorf("want err: %+v, got: %+v", wantErr, err))
+		}
+	}
+
+	apiKey := func(key string) context.Context {
+		return context.WithValue(context.Background(), "apiKey", []byte(key))
+	}
+
+	t.Run("requests take a single token", func(t *testing.T) {
+		middleware := newMiddleware(2, false)
+		run(middleware, apiKey("a"), `{documents(first: 10) {owner}}`)
+		run(middleware, apiKey("a"), `{documents(first: 10) {owner}}`)
+		runErr(middleware, apiKey("a"), `{documents {owner}}`, ratelimit.ErrRateLimited.WithRetryAfter(5*time.Second))
+		now = now.Add(5 * time.Second)
+		run(middleware, apiKey("a"), `{documents {owner}}`)
+	})
+	t.Run("clients are limited independently", func(t *testing.T) {
+		middleware := newMiddleware(1, false)
+		run(middleware, apiKey("a"), `{documents {owner}}`)
+		run(middleware, apiKey("b"), `{documents {owner}}`)
+		runErr(middleware, apiKey("a"), `{documents {owner}}`, ratelimit.ErrRateLimited.WithRetryAfter(10*time.Second))
+	})
+	t.Run("requests without client are rejected", func(t *testing.T) {
+		middleware := newMiddleware(1, false)
+		runErr(middleware, nil, `{documents {owner}}`, ratelimit.ErrMissingClient)
+		runErr(middleware, context.Background(), `{documents {owner}}`, ratelimit.ErrMissingClient)
+		runErr(middleware, apiKey(""), `{documents {owner}}`, ratelimit.ErrMissingClient)
+	})
+	t.Run("requests without client share the anonymous bucket", func(t *testing.T) {
+		middleware := newMiddleware(1, false)
+		middleware.AnonymousLimit = ratelimit.Limit{Capacity: 2, Period: 10 * time.Second}
+		run(middleware, nil, `{documents {owner}}`)
+		run(middleware, context.Background(), `{documents {owner}}`)
+		runErr(middleware, context.Background(), `{documents {owner}}`, ratelimit.ErrRateLimited.WithRetryAfter(5*time.Second))
+		run(middleware, apiKey("anonymous"), `{documents {owner}}`)
+		runErr(middleware, apiKey("anonymous"), `{documents {owner}}`, ratelimit.ErrRateLimited.WithRetryAfter(10*time.Second))
+	})
+	t.Run("cost based requests take their cost", func(t *testing.T) {
+		middleware := newMiddleware(30, true)
+		run(middleware, apiKey("a"), `{documents(first: 10) {owner}}`)
+		run(middleware, apiKey("a"), `{documents(first: 10) {owner}}`)
+		runErr(middleware, apiKey("a"), `{documents(first: 10) {owner}}`, ratelimit.ErrRateLimited.WithRetryAfter(2*time.Second))
+		run(middleware, apiKey("a"), `{documents(first: 2) {owner}}`)
+	})
+	t.Run("cost based requests use the variables", func(t *testing.T) {
+		middleware := newMiddleware(30, true)
+		query := `query documents($first: Int) {documents(first: $first) {owner}}`
+		first := func(first int) context.Context {
+			return WithGraphQLRequest(apiKey("a"), &GraphQLRequest{OperationName: "documents", Variables: map[string]interface{}{"first": first}})
+		}
+		run(middleware, first(19), query)
+		runErr(middleware, first(19), query, ratelimit.ErrRateLimited.WithRetryAfter(4*time.Second))
+		run(middleware, first(5), query)
+	})
+	t.Run("requests costing more than the capacity", func(t *testing.T) {
+		middleware := newMiddleware(10, true)
+		runErr(middleware, apiKey("a"), `{documents(first: 10) {owner}}`, ratelimit.ErrCostExceedsLimit)
+		run(middleware, apiKey("a"), `{documents(first: 8) {owner}}`)
+	})
+}
+
+const rateLimitMiddlewareSchema = `
+schema {
+	query: Query
+}
+
+type Query {
+	documents(first: Int): [Document] @cost(weight: 2, multipliers: ["first"])
+}
+
+type Document {
+	owner: String
+}
+`
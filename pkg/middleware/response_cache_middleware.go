@@ -0,0 +1,401 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jensneuse/graphql-go-tools/pkg/document"
+	"github.com/jensneuse/graphql-go-tools/pkg/lookup"
+	"github.com/jensneuse/graphql-go-tools/pkg/parser"
+	"github.com/jensneuse/graphql-go-tools/pkg/printer"
+	"github.com/jensneuse/graphql-go-tools/pkg/responsecache"
+	"github.com/tidwall/gjson"
+)
+
+/*
+enum CacheControlScope {
+	PUBLIC
+	PRIVATE
+}
+
+directive @cacheControl(
+	maxAge: Int
+	scope: CacheControlScope
+) on FIELD_DEFINITION | OBJECT | INTERFACE | UNION
+*/
+
+/*
+ResponseCacheMiddleware caches the responses of query operations
+
+example schema:
+
+	type Query {
+		documents: [Document] @cacheControl(maxAge: 60)
+		document(id: String): Document
+		me: User @cacheControl(maxAge: 10, scope: PRIVATE)
+	}
+
+	type Document @cacheControl(maxAge: 30) {
+		owner: String
+		sensitiveInformation: String @cacheControl(maxAge: 5)
+	}
+
+the max age of a response is the minimum max age of all selected fields:
+a field without maxAge takes the maxAge of the type it returns, e.g. 'documents' is 60 and 'document' is 30
+root fields and fields returning objects, interfaces or unions without maxAge default to DefaultMaxAge,
+all other fields don't restrict the max age
+
+	{documents {owner}}                      max age 60
+	{document {owner}}                       max age 30
+	{documents {owner sensitiveInformation}} max age 5
+
+responses with a max age of 0 and responses containing errors are not cached
+
+the cache key is the normalized request after the previous middlewares rewrote it, the operation name, the variables
+and the values of ContextKeys, therefore the middleware should be placed after the middlewares which rewrite the request,
+e.g. the ContextMiddleware or the AuthorizationMiddleware, and after the ValidationMiddleware so that invalid requests never hit the cache
+Chain places it accordingly (PhaseResolve)
+
+responses are private if one of the selected fields or types has scope PRIVATE,
+private responses are only cached together with the value of SessionContextKey, without session they're not cached
+*/
+type ResponseCacheMiddleware struct {
+	// Store (optional) defaults to a responsecache.LRU of DefaultCacheCapacity responses
+	Store responsecache.Store
+	// DefaultMaxAge is the max age of root fields and fields returning composite types without @cacheControl
+	DefaultMaxAge time.Duration
+	// ContextKeys are the context values which are part of the cache key, e.g. the locale of the client
+	ContextKeys []string
+	// SessionContextKey is the context key identifying the client, e.g. the user id, it's required to cache private responses
+	SessionContextKey string
+
+	mux     sync.Mutex
+	pending map[*parser.Parser]pendingResponse
+}
+
+// DefaultCacheCapacity is the capacity of the default store of the ResponseCacheMiddleware
+const DefaultCacheCapacity = 1024
+
+// pendingResponse is a cache miss which gets stored once the response arrives
+type pendingResponse struct {
+	key string
+	ttl time.Duration
+}
+
+var responseCacheMiddlewareSchemaExtension = []byte(`
+enum CacheControlScope {
+	PUBLIC
+	PRIVATE
+}
+directive @cacheControl(
+	maxAge: Int
+	scope: CacheControlScope
+) on FIELD_DEFINITION | OBJECT | INTERFACE | UNION`)
+
+var (
+	cacheControlDirectiveName = []byte("cacheControl")
+	maxAgeArgumentName        = []byte("maxAge")
+	scopeArgumentName         = []byte("scope")
+	privateScope              = []byte("PRIVATE")
+)
+
+func (r *ResponseCacheMiddleware) PrepareSchema(ctx context.Context, l *lookup.Lookup, w *lookup.Walker, parser *parser.Parser, mod *parser.ManualAstMod) error {
+	return parser.ExtendTypeSystemDefinition(responseCacheMiddlewareSchemaExtension)
+}
+
+// Phase returns PhaseResolve so that only valid requests are answered from the cache
+func (r *ResponseCacheMiddleware) Phase() Phase {
+	return PhaseResolve
+}
+
+func (r *ResponseCacheMiddleware) OnRequest(ctx context.Context, l *lookup.Lookup, w *lookup.Walker, parser *parser.Parser, mod *parser.ManualAstMod) error {
+	r.setPending(parser, nil)
+	return nil
+}
+
+// ResolveRequest answers the request from the cache, on a cache miss the response is stored by OnResponse
+func (r *ResponseCacheMiddleware) ResolveRequest(ctx context.Context, out io.Writer, l *lookup.Lookup, w *lookup.Walker, parser *parser.Parser, mod *parser.ManualAstMod) (resolved bool, err error) {
+
+	var request GraphQLRequest
+	if graphqlRequest, ok := GraphQLRequestFromContext(ctx); ok {
+		request = *graphqlRequest
+	}
+
+	operation, ok := selectedOperation(l, request.OperationName)
+	if !ok || operation.OperationType != document.OperationTypeQuery {
+		return false, nil
+	}
+
+	calculator := cachePolicyCalculator{
+		l:             l,
+		defaultMaxAge: r.DefaultMaxAge,
+	}
+	calculator.selectionSet(operation.SelectionSet, operationRootTypeName(l, operation), false)
+
+	policy := calculator.policy
+	if !policy.restricted || policy.maxAge <= 0 {
+		return false, nil
+	}
+
+	session := ""
+	if policy.private {
+		session = contextValueString(ctx, r.SessionContextKey)
+		if r.SessionContextKey == "" || session == "" {
+			return false, nil
+		}
+	}
+
+	key, err := r.cacheKey(ctx, l, w, parser, request, session)
+	if err != nil {
+		return false, err
+	}
+
+	response, ok, err := r.store().Get(ctx, key)
+	if err != nil {
+		return false, err
+	}
+
+	if ok {
+		_, err = out.Write(response)
+		return err == nil, err
+	}
+
+	r.setPending(parser, &pendingResponse{key: key, ttl: policy.maxAge})
+	return false, nil
+}
+
+// OnResponse stores the response of a cache miss
+func (r *ResponseCacheMiddleware) OnResponse(ctx context.Context, response *[]byte, l *lookup.Lookup, w *lookup.Walker, parser *parser.Parser, mod *parser.ManualAstMod) (err error) {
+
+	pending := r.getPending(parser)
+	if pending == nil {
+		return nil
+	}
+	r.setPending(parser, nil)
+
+	if !gjson.ValidBytes(*response) || gjson.GetBytes(*response, "errors").Exists() {
+		return nil
+	}
+
+	return r.store().Set(ctx, pending.key, *response, pending.ttl)
+}
+
+// cacheKey hashes the normalized request together with the context values
+func (r *ResponseCacheMiddleware) cacheKey(ctx context.Context, l *lookup.Lookup, w *lookup.Walker, parser *parser.Parser, request GraphQLRequest, session string) (string, error) {
+
+	hash := sha256.New()
+
+	w.SetLookup(l)
+	w.WalkExecutable()
+	astPrinter := printer.New()
+	astPrinter.SetInput(parser, l, w)
+	err := astPrinter.PrintExecutableSchema(hash)
+	if err != nil {
+		return "", err
+	}
+
+	// json encodes the keys of maps in sorted order
+	variables, err := json.Marshal(request.Variables)
+	if err != nil {
+		return "", err
+	}
+
+	parts := []string{request.OperationName, string(variables), session}
+	for _, key := range r.ContextKeys {
+		parts = append(parts, contextValueString(ctx, key))
+	}
+
+	for _, part := range parts {
+		// the length prefix keeps the parts from being ambiguous
+		hash.Write([]byte("\n" + strconv.Itoa(len(part)) + ":" + part))
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func (r *ResponseCacheMiddleware) store() responsecache.Store {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if r.Store == nil {
+		r.Store = responsecache.NewLRU(DefaultCacheCapacity)
+	}
+	return r.Store
+}
+
+func (r *ResponseCacheMiddleware) setPending(p *parser.Parser, pending *pendingResponse) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if pending == nil {
+		delete(r.pending, p)
+		return
+	}
+	if r.pending == nil {
+		r.pending = map[*parser.Parser]pendingResponse{}
+	}
+	r.pending[p] = *pending
+}
+
+func (r *ResponseCacheMiddleware) getPending(p *parser.Parser) *pendingResponse {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	pending, ok := r.pending[p]
+	if !ok {
+		return nil
+	}
+	return &pending
+}
+
+// contextValueString returns a context value as string, values other than strings and byte slices are json encoded
+func contextValueString(ctx context.Context, key string) string {
+	if ctx == nil || key == "" {
+		return ""
+	}
+	switch value := ctx.Value(key).(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case []byte:
+		return string(value)
+	default:
+		encoded, _ := json.Marshal(value)
+		return string(encoded)
+	}
+}
+
+// cachePolicy is the max age and scope of a response
+type cachePolicy struct {
+	maxAge time.Duration
+	// restricted is false if no selected field restricts the max age, e.g. for introspection queries
+	restricted bool
+	private    bool
+}
+
+func (c *cachePolicy) restrict(maxAge time.Duration) {
+	if !c.restricted || maxAge < c.maxAge {
+		c.maxAge = maxAge
+	}
+	c.restricted = true
+}
+
+// cachePolicyCalculator computes the cache policy of an operation from the @cacheControl directives of the selected fields
+type cachePolicyCalculator struct {
+	l             *lookup.Lookup
+	defaultMaxAge time.Duration
+	policy        cachePolicy
+	fragments     []document.ByteSliceReference
+}
+
+func (c *cachePolicyCalculator) selectionSet(setRef int, typeName document.ByteSliceReference, nested bool) {
+
+	set := c.l.SelectionSet(setRef)
+
+	for _, fieldRef := range set.Fields {
+		field := c.l.Field(fieldRef)
+		if bytes.HasPrefix(c.l.ByteSlice(field.Name), []byte("__")) {
+			continue
+		}
+
+		definition, ok := c.l.FieldDefinitionByNameFromDefinitions(c.l.FieldsDefinitionFromNamedType(typeName), field.Name)
+		if !ok {
+			continue
+		}
+
+		fieldTypeName := c.l.UnwrappedNamedType(c.l.Type(definition.Type)).Name
+		composite := !c.l.IsLeafNode(fieldTypeName)
+
+		maxAge, hasMaxAge, private := c.cacheControl(definition.DirectiveSet)
+		if composite {
+			typeMaxAge, typeHasMaxAge, typePrivate := c.cacheControl(c.typeDirectiveSet(fieldTypeName))
+			if !hasMaxAge {
+				maxAge, hasMaxAge = typeMaxAge, typeHasMaxAge
+			}
+			private = private || typePrivate
+		}
+
+		switch {
+		case hasMaxAge:
+			c.policy.restrict(maxAge)
+		case composite || !nested:
+			c.policy.restrict(c.defaultMaxAge)
+		}
+
+		c.policy.private = c.policy.private || private
+
+		if field.SelectionSet != -1 {
+			c.selectionSet(field.SelectionSet, fieldTypeName, true)
+		}
+	}
+
+	for _, inlineFragmentRef := range set.InlineFragments {
+		inlineFragment := c.l.InlineFragment(inlineFragmentRef)
+		fragmentTypeName := typeName
+		if inlineFragment.TypeCondition != -1 {
+			fragmentTypeName = c.l.Type(inlineFragment.TypeCondition).Name
+		}
+		c.selectionSet(inlineFragment.SelectionSet, fragmentTypeName, nested)
+	}
+
+	for _, spreadRef := range set.FragmentSpreads {
+		spread := c.l.FragmentSpread(spreadRef)
+		if c.l.ByteSliceReferencesContainName(c.fragments, spread.FragmentName) {
+			continue // cyclic fragment spreads are rejected by validation
+		}
+		fragment, _, ok := c.l.FragmentDefinitionByName(spread.FragmentName)
+		if !ok {
+			continue
+		}
+		c.fragments = append(c.fragments, spread.FragmentName)
+		c.selectionSet(fragment.SelectionSet, c.l.Type(fragment.TypeCondition).Name, nested)
+		c.fragments = c.fragments[:len(c.fragments)-1]
+	}
+}
+
+// typeDirectiveSet returns the directive set of an object, interface or union type
+func (c *cachePolicyCalculator) typeDirectiveSet(typeName document.ByteSliceReference) int {
+	if definition, ok := c.l.ObjectTypeDefinitionByName(typeName); ok {
+		return definition.DirectiveSet
+	}
+	if definition, ok := c.l.InterfaceTypeDefinitionByName(typeName); ok {
+		return definition.DirectiveSet
+	}
+	if definition, ok := c.l.UnionTypeDefinitionByName(typeName); ok {
+		return definition.DirectiveSet
+	}
+	return -1
+}
+
+// cacheControl reads the arguments of the @cacheControl directive inside the directive set
+func (c *cachePolicyCalculator) cacheControl(directiveSet int) (maxAge time.Duration, hasMaxAge, private bool) {
+
+	directives := c.l.DirectiveIterable(c.l.DirectiveSet(directiveSet))
+	for directives.Next() {
+		directive, _ := directives.Value()
+		if !bytes.Equal(c.l.ByteSlice(directive.Name), cacheControlDirectiveName) {
+			continue
+		}
+		args := c.l.ArgumentsIterable(c.l.ArgumentSet(directive.ArgumentSet))
+		for args.Next() {
+			arg, _ := args.Value()
+			value := c.l.Value(arg.Value)
+			switch {
+			case bytes.Equal(c.l.ByteSlice(arg.Name), maxAgeArgumentName) && value.ValueType == document.ValueTypeInt:
+				seconds, err := strconv.Atoi(string(c.l.ByteSlice(value.Raw)))
+				if err == nil {
+					maxAge, hasMaxAge = time.Duration(seconds)*time.Second, true
+				}
+			case bytes.Equal(c.l.ByteSlice(arg.Name), scopeArgumentName) && value.ValueType == document.ValueTypeEnum:
+				private = bytes.Equal(c.l.ByteSlice(value.Raw), privateScope)
+			}
+		}
+	}
+
+	return
+}
@@ -0,0 +1,205 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jensneuse/graphql-go-tools/pkg/responsecache"
+)
+
+func TestResponseCacheMiddleware(t *testing.T) {
+
+	now := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	newMiddleware := func() *ResponseCacheMiddleware {
+		store := responsecache.NewLRU(10)
+		store.Now = func() time.Time {
+			return now
+		}
+		return &ResponseCacheMiddleware{
+			Store:             store,
+			ContextKeys:       []string{"locale"},
+			SessionContextKey: "user",
+		}
+	}
+
+	// invoke runs the request through the middleware, backendResponse is used on cache misses
+	invoke := func(middleware *ResponseCacheMiddleware, ctx context.Context, query, backendResponse string) (response string, resolved bool) {
+		invoker := NewInvoker(middleware, &ValidationMiddleware{})
+		err := invoker.SetSchema([]byte(responseCacheMiddlewareSchema))
+		if err != nil {
+			panic(err)
+		}
+		err = invoker.InvokeMiddleWares(ctx, []byte(query))
+		if err != nil {
+			panic(err)
+		}
+		cached, resolved := invoker.ResolvedResponse()
+		got := []byte(backendResponse)
+		if resolved {
+			got = append([]byte{}, cached...)
+		}
+		err = invoker.InvokeMiddleWaresOnResponse(ctx, &got)
+		if err != nil {
+			panic(err)
+		}
+		return string(got), resolved
+	}
+
+	hit := func(middleware *ResponseCacheMiddleware, ctx context.Context, query, want string) {
+		got, resolved := invoke(middleware, ctx, query, `{"data":"backend"}`)
+		if !resolved {
+			panic(fmt.Errorf("want cache hit for query: %s", query))
+		}
+		if want != got {
+			panic(fmt.Errorf("want:\n%s\ngot:\n%s", want, got))
+		}
+	}
+
+	miss := func(middleware *ResponseCacheMiddleware, ctx context.Context, query, backendResponse string) {
+		_, resolved := invoke(middleware, ctx, query, backendResponse)
+		if resolved {
+			panic(fmt.Errorf("want cache miss for query: %s", query))
+		}
+	}
+
+	withValues := func(values map[string]string) context.Context {
+		ctx := context.Background()
+		for key, value := range values {
+			ctx = context.WithValue(ctx, key, []byte(value))
+		}
+		return ctx
+	}
+
+	t.Run("public responses are cached", func(t *testing.T) {
+		middleware := newMiddleware()
+		miss(middleware, nil, `{documents {owner}}`, `{"data":{"documents":[]}}`)
+		hit(middleware, nil, `{documents {owner}}`, `{"data":{"documents":[]}}`)
+	})
+	t.Run("normalized queries share the cache entry", func(t *testing.T) {
+		middleware := newMiddleware()
+		miss(middleware, nil, `{documents {owner}}`, `{"data":{"documents":[]}}`)
+		hit(middleware, nil, `query {
+			documents {
+				owner
+			}
+		}`, `{"data":{"documents":[]}}`)
+	})
+	t.Run("max age is the minimum of the selected fields", func(t *testing.T) {
+		middleware := newMiddleware()
+		miss(middleware, nil, `{documents {owner sensitiveInformation}}`, `{"data":{"documents":[]}}`)
+		now = now.Add(4 * time.Second)
+		hit(middleware, nil, `{documents {owner sensitiveInformation}}`, `{"data":{"documents":[]}}`)
+		now = now.Add(2 * time.Second)
+		miss(middleware, nil, `{documents {owner sensitiveInformation}}`, `{"data":{"documents":[]}}`)
+	})
+	t.Run("fragments are part of the max age", func(t *testing.T) {
+		middleware := newMiddleware()
+		miss(middleware, nil, `{documents {...documentFields}} fragment documentFields on Document {sensitiveInformation}`, `{"data":{"documents":[]}}`)
+		now = now.Add(6 * time.Second)
+		miss(middleware, nil, `{documents {...documentFields}} fragment documentFields on Document {sensitiveInformation}`, `{"data":{"documents":[]}}`)
+	})
+	t.Run("type hints apply to fields without hints", func(t *testing.T) {
+		middleware := newMiddleware()
+		miss(middleware, nil, `{document(id: "1") {owner}}`, `{"data":{"document":null}}`)
+		now = now.Add(29 * time.Second)
+		hit(middleware, nil, `{document(id: "1") {owner}}`, `{"data":{"document":null}}`)
+		now = now.Add(2 * time.Second)
+		miss(middleware, nil, `{document(id: "1") {owner}}`, `{"data":{"document":null}}`)
+	})
+	t.Run("fields without max age default to DefaultMaxAge", func(t *testing.T) {
+		middleware := newMiddleware()
+		miss(middleware, nil, `{uncached}`, `{"data":{"uncached":"a"}}`)
+		miss(middleware, nil, `{uncached}`, `{"data":{"uncached":"a"}}`)
+		middleware.DefaultMaxAge = time.Minute
+		miss(middleware, nil, `{uncached}`, `{"data":{"uncached":"a"}}`)
+		hit(middleware, nil, `{uncached}`, `{"data":{"uncached":"a"}}`)
+	})
+	t.Run("variables and context keys are part of the key", func(t *testing.T) {
+		middleware := newMiddleware()
+		query := `query documents($limit: Int) {documents(limit: $limit) {owner}}`
+		limit := func(limit int, locale string) context.Context {
+			return WithGraphQLRequest(withValues(map[string]string{"locale": locale}), &GraphQLRequest{
+				OperationName: "documents",
+				Variables:     map[string]interface{}{"limit": limit},
+			})
+		}
+		miss(middleware, limit(1, "en"), query, `{"data":{"documents":[1]}}`)
+		miss(middleware, limit(2, "en"), query, `{"data":{"documents":[2]}}`)
+		miss(middleware, limit(1, "de"), query, `{"data":{"documents":[3]}}`)
+		hit(middleware, limit(1, "en"), query, `{"data":{"documents":[1]}}`)
+		hit(middleware, limit(1, "de"), query, `{"data":{"documents":[3]}}`)
+	})
+	t.Run("private responses are cached per session", func(t *testing.T) {
+		middleware := newMiddleware()
+		miss(middleware, withValues(map[string]string{"user": "jens"}), `{me {name}}`, `{"data":{"me":{"name":"jens"}}}`)
+		miss(middleware, withValues(map[string]string{"user": "jannik"}), `{me {name}}`, `{"data":{"me":{"name":"jannik"}}}`)
+		hit(middleware, withValues(map[string]string{"user": "jens"}), `{me {name}}`, `{"data":{"me":{"name":"jens"}}}`)
+	})
+	t.Run("private responses are not cached without session", func(t *testing.T) {
+		middleware := newMiddleware()
+		miss(middleware, nil, `{me {name}}`, `{"data":{"me":null}}`)
+		miss(middleware, nil, `{me {name}}`, `{"data":{"me":null}}`)
+	})
+	t.Run("private types make the response private", func(t *testing.T) {
+		middleware := newMiddleware()
+		miss(middleware, nil, `{documents {owner} currentUser {name}}`, `{"data":{"documents":[],"currentUser":null}}`)
+		miss(middleware, nil, `{documents {owner} currentUser {name}}`, `{"data":{"documents":[],"currentUser":null}}`)
+	})
+	t.Run("responses with errors are not cached", func(t *testing.T) {
+		middleware := newMiddleware()
+		miss(middleware, nil, `{documents {owner}}`, `{"data":null,"errors":[{"message":"unavailable"}]}`)
+		miss(middleware, nil, `{documents {owner}}`, `{"data":{"documents":[]}}`)
+		hit(middleware, nil, `{documents {owner}}`, `{"data":{"documents":[]}}`)
+	})
+	t.Run("invalid responses are not cached", func(t *testing.T) {
+		middleware := newMiddleware()
+		miss(middleware, nil, `{documents {owner}}`, `Bad Gateway`)
+		miss(middleware, nil, `{documents {owner}}`, `{"data":{"documents":[]}}`)
+	})
+	t.Run("mutations are not cached", func(t *testing.T) {
+		middleware := newMiddleware()
+		miss(middleware, nil, `mutation {deleteDocument}`, `{"data":{"deleteDocument":true}}`)
+		miss(middleware, nil, `mutation {deleteDocument}`, `{"data":{"deleteDocument":true}}`)
+	})
+	t.Run("max age 0 is not cached", func(t *testing.T) {
+		middleware := newMiddleware()
+		miss(middleware, nil, `{documents {owner} live}`, `{"data":{"documents":[],"live":1}}`)
+		miss(middleware, nil, `{documents {owner} live}`, `{"data":{"documents":[],"live":1}}`)
+	})
+	t.Run("default store", func(t *testing.T) {
+		middleware := &ResponseCacheMiddleware{}
+		miss(middleware, nil, `{documents {owner}}`, `{"data":{"documents":[]}}`)
+		hit(middleware, nil, `{documents {owner}}`, `{"data":{"documents":[]}}`)
+	})
+}
+
+const responseCacheMiddlewareSchema = `
+schema {
+	query: Query
+	mutation: Mutation
+}
+
+type Query {
+	documents(limit: Int): [Document] @cacheControl(maxAge: 60)
+	document(id: String): Document
+	me: User @cacheControl(maxAge: 10, scope: PRIVATE)
+	currentUser: User @cacheControl(maxAge: 10)
+	uncached: String
+	live: Int @cacheControl(maxAge: 0)
+}
+
+type Mutation {
+	deleteDocument: Boolean @cacheControl(maxAge: 60)
+}
+
+type Document @cacheControl(maxAge: 30) {
+	owner: String
+	sensitiveInformation: String @cacheControl(maxAge: 5)
+}
+
+type User @cacheControl(scope: PRIVATE) {
+	name: String
+}
+`
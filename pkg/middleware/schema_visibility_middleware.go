@@ -0,0 +1,778 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/jensneuse/graphql-go-tools/pkg/document"
+	"github.com/jensneuse/graphql-go-tools/pkg/introspection"
+	"github.com/jensneuse/graphql-go-tools/pkg/introspection/generator"
+	"github.com/jensneuse/graphql-go-tools/pkg/lookup"
+	"github.com/jensneuse/graphql-go-tools/pkg/parser"
+	"github.com/jensneuse/graphql-go-tools/pkg/responsewalker"
+)
+
+/*
+directive @hide on FIELD_DEFINITION | ARGUMENT_DEFINITION | INPUT_FIELD_DEFINITION | ENUM_VALUE
+
+directive @internal on FIELD_DEFINITION | ARGUMENT_DEFINITION | INPUT_FIELD_DEFINITION | ENUM_VALUE
+
+directive @rename(
+	to: String!
+) on FIELD_DEFINITION | ARGUMENT_DEFINITION | OBJECT | INTERFACE | UNION | ENUM | INPUT_OBJECT | SCALAR
+*/
+
+/*
+SchemaVisibilityMiddleware derives the public schema of the proxy from the annotated schema of the backend
+
+example schema:
+
+	type Query {
+		documents(user: String @internal): [Document] @rename(to: "allDocuments")
+	}
+
+	type Document @rename(to: "File") {
+		owner: String
+		legacyId: Int @hide
+		sensitiveInformation(user: String @internal): String
+	}
+
+public schema:
+
+	type Query {
+		allDocuments: [File]
+	}
+
+	type File {
+		owner: String
+		sensitiveInformation: String
+	}
+
+@hide removes an element from the public schema but clients which know it are still allowed to use it
+@internal removes an element from the public schema and rejects requests using it,
+this way the arguments added by the ContextMiddleware can't be set by the client
+@rename sets the name of a field, an argument or a type in the public schema, the backend name is rejected
+
+incoming requests are rewritten to the names of the backend, renamed fields get aliased so that the response keys stay public:
+
+	{allDocuments {... on File {owner}}}
+
+is sent to the backend as:
+
+	{allDocuments: documents {... on Document {owner}}}
+
+__typename values of renamed types are rewritten in the response, the IntrospectionMiddleware answers with the public schema
+
+the middleware has to be the first middleware so that all other middlewares see the request with the names of the backend
+PublicSchema writes the public schema, e.g. to publish it to the clients
+*/
+type SchemaVisibilityMiddleware struct {
+}
+
+var schemaVisibilityMiddlewareSchemaExtension = []byte(`
+directive @hide on FIELD_DEFINITION | ARGUMENT_DEFINITION | INPUT_FIELD_DEFINITION | ENUM_VALUE
+directive @internal on FIELD_DEFINITION | ARGUMENT_DEFINITION | INPUT_FIELD_DEFINITION | ENUM_VALUE
+directive @rename(
+	to: String!
+) on FIELD_DEFINITION | ARGUMENT_DEFINITION | OBJECT | INTERFACE | UNION | ENUM | INPUT_OBJECT | SCALAR`)
+
+var (
+	hideDirectiveName     = []byte("hide")
+	internalDirectiveName = []byte("internal")
+	renameDirectiveName   = []byte("rename")
+	toArgumentName        = []byte("to")
+)
+
+func (s *SchemaVisibilityMiddleware) PrepareSchema(ctx context.Context, l *lookup.Lookup, w *lookup.Walker, parser *parser.Parser, mod *parser.ManualAstMod) error {
+	return parser.ExtendTypeSystemDefinition(schemaVisibilityMiddlewareSchemaExtension)
+}
+
+// Phase returns PhaseNormalize so that all other middlewares handle the names of the backend schema
+func (s *SchemaVisibilityMiddleware) Phase() Phase {
+	return PhaseNormalize
+}
+
+// OnRequest rewrites the request from the names of the public schema to the names of the backend
+func (s *SchemaVisibilityMiddleware) OnRequest(ctx context.Context, l *lookup.Lookup, w *lookup.Walker, parser *parser.Parser, mod *parser.ManualAstMod) error {
+
+	visibility := newSchemaVisibility(l)
+	if visibility.isEmpty() {
+		return nil
+	}
+
+	rewriter := visibilityRewriter{
+		l:          l,
+		mod:        mod,
+		visibility: visibility,
+	}
+
+	if request, ok := GraphQLRequestFromContext(ctx); ok {
+		rewriter.variables = request.Variables
+	}
+
+	return rewriter.rewrite()
+}
+
+// OnResponse rewrites the __typename values of renamed types to their public names
+func (s *SchemaVisibilityMiddleware) OnResponse(ctx context.Context, response *[]byte, l *lookup.Lookup, w *lookup.Walker, parser *parser.Parser, mod *parser.ManualAstMod) (err error) {
+
+	visibility := newSchemaVisibility(l)
+	if len(visibility.publicTypeNames) == 0 || !selectsTypeName(parser) {
+		return nil
+	}
+
+	operationName := ""
+	if request, ok := GraphQLRequestFromContext(ctx); ok {
+		operationName = request.OperationName
+	}
+
+	editor := responsewalker.NewEditor()
+	walker := responsewalker.New()
+	walker.SetInput(l)
+
+	err = walker.Walk(operationName, *response, func(field responsewalker.Field) error {
+		if field.HasDefinition || !bytes.Equal(l.ByteSlice(l.Field(field.Ref).Name), typeNameFieldName) {
+			return nil
+		}
+		var typeName string
+		if json.Unmarshal(field.Value, &typeName) != nil {
+			return nil
+		}
+		publicName, ok := visibility.publicTypeNames[typeName]
+		if !ok {
+			return nil
+		}
+		value, err := json.Marshal(publicName)
+		if err != nil {
+			return err
+		}
+		editor.Replace(field.Offset, field.Value, value)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	*response, err = editor.Apply(*response)
+	return err
+}
+
+// selectsTypeName reports whether the executable definition contains a __typename field
+func selectsTypeName(p *parser.Parser) bool {
+	for _, field := range p.ParsedDefinitions.Fields {
+		if bytes.Equal(p.ByteSlice(field.Name), typeNameFieldName) {
+			return true
+		}
+	}
+	return false
+}
+
+// PublicSchema writes the public schema of an annotated backend schema in the schema definition language
+func PublicSchema(schema []byte, out io.Writer) error {
+
+	p := parser.NewParser()
+	err := p.ParseTypeSystemDefinition(schema)
+	if err != nil {
+		return err
+	}
+
+	err = p.ExtendTypeSystemDefinition(validationMiddlewareSchemaExtension)
+	if err != nil {
+		return err
+	}
+
+	err = p.ExtendTypeSystemDefinition(schemaVisibilityMiddlewareSchemaExtension)
+	if err != nil {
+		return err
+	}
+
+	l := lookup.New(p)
+
+	var response introspection.Response
+	gen := generator.New()
+	gen.SetInput(l)
+	err = gen.Generate(&response)
+	if err != nil {
+		return err
+	}
+
+	newSchemaVisibility(l).publicIntrospectionSchema(&response.Data.Schema)
+
+	return introspection.WriteSchemaDefinition(out, &response.Data.Schema)
+}
+
+// visibility is the visibility of a field, an argument, an input field or an enum value
+type visibility struct {
+	// publicName is the name in the public schema, it's empty if the element isn't renamed
+	publicName string
+	hidden     bool
+	internal   bool
+}
+
+// schemaVisibility holds the visibility directives of a schema
+type schemaVisibility struct {
+	// elements are keyed by the schema coordinate of the element using the backend names,
+	// e.g. 'Query.documents', 'Query.documents(user:)', 'DocumentFilter.owner' or 'Role.ADMIN'
+	elements map[string]visibility
+	// publicTypeNames maps the backend names of renamed types to their public names
+	publicTypeNames map[string]string
+	// backendTypeNames maps the public names of renamed types to the backend names
+	backendTypeNames map[string]document.ByteSliceReference
+}
+
+func newSchemaVisibility(l *lookup.Lookup) *schemaVisibility {
+
+	s := &schemaVisibility{
+		elements:         map[string]visibility{},
+		publicTypeNames:  map[string]string{},
+		backendTypeNames: map[string]document.ByteSliceReference{},
+	}
+
+	for _, definition := range l.ObjectTypeDefinitions() {
+		s.addType(l, definition.Name, definition.DirectiveSet)
+		s.addFields(l, definition.Name, definition.FieldsDefinition)
+	}
+	for _, definition := range l.InterfaceTypeDefinitions() {
+		s.addType(l, definition.Name, definition.DirectiveSet)
+		s.addFields(l, definition.Name, definition.FieldsDefinition)
+	}
+	for _, definition := range l.UnionTypeDefinitions() {
+		s.addType(l, definition.Name, definition.DirectiveSet)
+	}
+	for _, definition := range l.ScalarTypeDefinitions() {
+		s.addType(l, definition.Name, definition.DirectiveSet)
+	}
+	for _, definition := range l.EnumTypeDefinitions() {
+		s.addType(l, definition.Name, definition.DirectiveSet)
+		enumValues := definition.EnumValuesDefinition
+		for enumValues.Next(l) {
+			enumValue, _ := enumValues.Value()
+			s.add(l, string(l.ByteSlice(definition.Name))+"."+string(l.ByteSlice(enumValue.EnumValue)), enumValue.DirectiveSet)
+		}
+	}
+	for _, definition := range l.InputObjectTypeDefinitions() {
+		s.addType(l, definition.Name, definition.DirectiveSet)
+		s.addInputValues(l, string(l.ByteSlice(definition.Name))+".", "", l.InputFieldsDefinition(definition.InputFieldsDefinition).InputValueDefinitions)
+	}
+
+	return s
+}
+
+func (s *schemaVisibility) isEmpty() bool {
+	return len(s.elements) == 0 && len(s.publicTypeNames) == 0
+}
+
+func (s *schemaVisibility) addType(l *lookup.Lookup, name document.ByteSliceReference, directiveSet int) {
+	v, ok := directiveVisibility(l, directiveSet)
+	if !ok || v.publicName == "" {
+		return
+	}
+	s.publicTypeNames[string(l.ByteSlice(name))] = v.publicName
+	s.backendTypeNames[v.publicName] = name
+}
+
+func (s *schemaVisibility) addFields(l *lookup.Lookup, typeName document.ByteSliceReference, fields document.FieldDefinitions) {
+	for fields.Next(l) {
+		field, _ := fields.Value()
+		coordinate := string(l.ByteSlice(typeName)) + "." + string(l.ByteSlice(field.Name))
+		s.add(l, coordinate, field.DirectiveSet)
+		s.addInputValues(l, coordinate+"(", ":)", l.ArgumentsDefinition(field.ArgumentsDefinition).InputValueDefinitions)
+	}
+}
+
+func (s *schemaVisibility) addInputValues(l *lookup.Lookup, prefix, suffix string, inputValues document.InputValueDefinitions) {
+	for inputValues.Next(l) {
+		inputValue, _ := inputValues.Value()
+		s.add(l, prefix+string(l.ByteSlice(inputValue.Name))+suffix, inputValue.DirectiveSet)
+	}
+}
+
+func (s *schemaVisibility) add(l *lookup.Lookup, coordinate string, directiveSet int) {
+	if v, ok := directiveVisibility(l, directiveSet); ok {
+		s.elements[coordinate] = v
+	}
+}
+
+// directiveVisibility reads the visibility directives of a directive set, ok is false if there are none
+func directiveVisibility(l *lookup.Lookup, directiveSet int) (v visibility, ok bool) {
+
+	directives := l.DirectiveIterable(l.DirectiveSet(directiveSet))
+	for directives.Next() {
+		directive, _ := directives.Value()
+		directiveName := l.ByteSlice(directive.Name)
+		switch {
+		case bytes.Equal(directiveName, hideDirectiveName):
+			v.hidden, ok = true, true
+		case bytes.Equal(directiveName, internalDirectiveName):
+			v.internal, ok = true, true
+		case bytes.Equal(directiveName, renameDirectiveName):
+			args := l.ArgumentsIterable(l.ArgumentSet(directive.ArgumentSet))
+			for args.Next() {
+				arg, _ := args.Value()
+				value := l.Value(arg.Value)
+				if bytes.Equal(l.ByteSlice(arg.Name), toArgumentName) && value.ValueType == document.ValueTypeString {
+					v.publicName, ok = string(l.ByteSlice(value.Raw)), true
+				}
+			}
+		}
+	}
+
+	return
+}
+
+// element returns the visibility of the element at the coordinate
+func (s *schemaVisibility) element(coordinate string) visibility {
+	return s.elements[coordinate]
+}
+
+// publicName returns the public name of an element with the given backend name
+func (s *schemaVisibility) publicName(coordinate, name string) string {
+	if v := s.elements[coordinate]; v.publicName != "" {
+		return v.publicName
+	}
+	return name
+}
+
+// publicTypeName returns the public name of a type
+func (s *schemaVisibility) publicTypeName(name string) string {
+	if publicName, ok := s.publicTypeNames[name]; ok {
+		return publicName
+	}
+	return name
+}
+
+// isVisible reports whether an element is part of the public schema
+func (s *schemaVisibility) isVisible(coordinate string) bool {
+	v := s.elements[coordinate]
+	return !v.hidden && !v.internal
+}
+
+// publicIntrospectionSchema removes hidden and internal elements from the generated schema and applies the public names
+func (s *schemaVisibility) publicIntrospectionSchema(schema *introspection.Schema) {
+
+	if s.isEmpty() {
+		return
+	}
+
+	for _, typeName := range []*introspection.TypeName{schema.QueryType, schema.MutationType, schema.SubscriptionType} {
+		if typeName != nil {
+			typeName.Name = s.publicTypeName(typeName.Name)
+		}
+	}
+
+	for i := range schema.Types {
+		fullType := &schema.Types[i]
+		typeName := fullType.Name
+		fullType.Name = s.publicTypeName(typeName)
+
+		fields := fullType.Fields[:0]
+		for _, field := range fullType.Fields {
+			coordinate := typeName + "." + field.Name
+			if !s.isVisible(coordinate) {
+				continue
+			}
+			field.Name = s.publicName(coordinate, field.Name)
+			field.Args = s.publicInputValues(coordinate+"(", ":)", field.Args)
+			s.publicTypeRef(&field.Type)
+			fields = append(fields, field)
+		}
+		fullType.Fields = fields
+
+		fullType.InputFields = s.publicInputValues(typeName+".", "", fullType.InputFields)
+
+		enumValues := fullType.EnumValues[:0]
+		for _, enumValue := range fullType.EnumValues {
+			if s.isVisible(typeName + "." + enumValue.Name) {
+				enumValues = append(enumValues, enumValue)
+			}
+		}
+		fullType.EnumValues = enumValues
+
+		for j := range fullType.Interfaces {
+			s.publicTypeRef(&fullType.Interfaces[j])
+		}
+		for j := range fullType.PossibleTypes {
+			s.publicTypeRef(&fullType.PossibleTypes[j])
+		}
+	}
+
+	directives := schema.Directives[:0]
+	for _, directive := range schema.Directives {
+		switch directive.Name {
+		case string(hideDirectiveName), string(internalDirectiveName), string(renameDirectiveName):
+			continue
+		}
+		for j := range directive.Args {
+			s.publicTypeRef(&directive.Args[j].Type)
+		}
+		directives = append(directives, directive)
+	}
+	schema.Directives = directives
+}
+
+func (s *schemaVisibility) publicInputValues(prefix, suffix string, inputValues []introspection.InputValue) []introspection.InputValue {
+	public := inputValues[:0]
+	for _, inputValue := range inputValues {
+		coordinate := prefix + inputValue.Name + suffix
+		if !s.isVisible(coordinate) {
+			continue
+		}
+		inputValue.Name = s.publicName(coordinate, inputValue.Name)
+		s.publicTypeRef(&inputValue.Type)
+		public = append(public, inputValue)
+	}
+	return public
+}
+
+func (s *schemaVisibility) publicTypeRef(typeRef *introspection.TypeRef) {
+	for ; typeRef != nil; typeRef = typeRef.OfType {
+		if typeRef.Name != nil {
+			name := s.publicTypeName(*typeRef.Name)
+			typeRef.Name = &name
+		}
+	}
+}
+
+// visibilityRewriter rewrites the executable definition from the public names to the names of the backend
+// each selection set is visited exactly once as fragment definitions are rewritten on their own
+type visibilityRewriter struct {
+	l          *lookup.Lookup
+	mod        *parser.ManualAstMod
+	visibility *schemaVisibility
+	variables  map[string]interface{}
+}
+
+func (r *visibilityRewriter) rewrite() error {
+
+	operations := r.l.OperationDefinitions()
+	for i := range operations {
+		variables := r.l.VariableDefinitionIterator(operations[i].VariableDefinitions)
+		for variables.Next() {
+			variable, _ := variables.Value()
+			err := r.variableDefinition(variable)
+			if err != nil {
+				return err
+			}
+		}
+		err := r.selectionSet(operations[i].SelectionSet, operationRootTypeName(r.l, operations[i]))
+		if err != nil {
+			return err
+		}
+	}
+
+	fragments := r.l.FragmentDefinitions()
+	for i := range fragments {
+		typeName, err := r.typeCondition(fragments[i].TypeCondition)
+		if err != nil {
+			return err
+		}
+		err = r.selectionSet(fragments[i].SelectionSet, typeName)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// typeCondition maps a named type of the request to the backend name and returns it
+func (r *visibilityRewriter) typeCondition(typeRef int) (document.ByteSliceReference, error) {
+
+	name := r.l.Type(typeRef).Name
+	typeName := string(r.l.ByteSlice(name))
+
+	if backendName, ok := r.visibility.backendTypeNames[typeName]; ok {
+		r.mod.SetTypeName(typeRef, backendName)
+		return backendName, nil
+	}
+
+	if _, ok := r.visibility.publicTypeNames[typeName]; ok {
+		return name, fmt.Errorf("SchemaVisibilityMiddleware: type '%s' is not defined", typeName)
+	}
+
+	return name, nil
+}
+
+func (r *visibilityRewriter) variableDefinition(variable document.VariableDefinition) error {
+
+	typeRef := variable.Type
+	for r.l.Type(typeRef).Kind != document.TypeKindNAMED {
+		typeRef = r.l.Type(typeRef).OfType
+	}
+
+	_, err := r.typeCondition(typeRef)
+	if err != nil {
+		return err
+	}
+
+	if variable.DefaultValue != -1 {
+		err = r.value(variable.DefaultValue, variable.Type)
+		if err != nil {
+			return err
+		}
+	}
+
+	if value, ok := r.variables[string(r.l.ByteSlice(variable.Variable))]; ok {
+		return r.variableValue(value, variable.Type)
+	}
+
+	return nil
+}
+
+func (r *visibilityRewriter) selectionSet(setRef int, typeName document.ByteSliceReference) error {
+
+	set := r.l.SelectionSet(setRef)
+
+	for _, fieldRef := range set.Fields {
+		err := r.field(fieldRef, typeName)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, inlineFragmentRef := range set.InlineFragments {
+		inlineFragment := r.l.InlineFragment(inlineFragmentRef)
+		fragmentTypeName := typeName
+		if inlineFragment.TypeCondition != -1 {
+			var err error
+			fragmentTypeName, err = r.typeCondition(inlineFragment.TypeCondition)
+			if err != nil {
+				return err
+			}
+		}
+		err := r.selectionSet(inlineFragment.SelectionSet, fragmentTypeName)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *visibilityRewriter) field(fieldRef int, typeName document.ByteSliceReference) error {
+
+	field := r.l.Field(fieldRef)
+	fieldName := r.l.ByteSlice(field.Name)
+
+	// introspection fields and their selections are part of the introspection system which isn't renamed
+	if bytes.HasPrefix(fieldName, []byte("__")) {
+		return nil
+	}
+
+	definition, coordinate, ok := r.fieldDefinition(typeName, fieldName)
+	if !ok {
+		if r.isBackendName(string(r.l.ByteSlice(typeName)) + "." + string(fieldName)) {
+			return r.undefinedField(typeName, fieldName)
+		}
+		return nil
+	}
+
+	if r.visibility.element(coordinate).internal {
+		return r.undefinedField(typeName, fieldName)
+	}
+
+	if !r.l.ByteSliceReferenceContentsEquals(field.Name, definition.Name) {
+		if field.Alias.Length() == 0 {
+			r.mod.SetFieldAlias(fieldRef, field.Name)
+		}
+		r.mod.SetFieldName(fieldRef, definition.Name)
+	}
+
+	args := r.l.ArgumentsIterable(r.l.ArgumentSet(field.ArgumentSet))
+	for args.Next() {
+		arg, argRef := args.Value()
+		err := r.argument(arg, argRef, definition, coordinate)
+		if err != nil {
+			return err
+		}
+	}
+
+	if field.SelectionSet == -1 {
+		return nil
+	}
+
+	return r.selectionSet(field.SelectionSet, r.l.UnwrappedNamedType(r.l.Type(definition.Type)).Name)
+}
+
+// fieldDefinition returns the field definition with the public name on the type
+// ok is false if the type has no such field, unknown fields are left to the validation
+func (r *visibilityRewriter) fieldDefinition(typeName document.ByteSliceReference, publicName []byte) (definition document.FieldDefinition, coordinate string, ok bool) {
+
+	prefix := string(r.l.ByteSlice(typeName)) + "."
+
+	fields := r.l.FieldsDefinitionFromNamedType(typeName)
+	for fields.Next(r.l) {
+		definition, _ = fields.Value()
+		name := string(r.l.ByteSlice(definition.Name))
+		if r.visibility.publicName(prefix+name, name) == string(publicName) {
+			return definition, prefix + name, true
+		}
+	}
+
+	return definition, "", false
+}
+
+func (r *visibilityRewriter) argument(arg document.Argument, argRef int, field document.FieldDefinition, fieldCoordinate string) error {
+
+	argName := r.l.ByteSlice(arg.Name)
+	undefined := fmt.Errorf("SchemaVisibilityMiddleware: argument '%s' is not defined on field '%s'", string(argName), r.publicCoordinate(fieldCoordinate))
+
+	inputValues := r.l.ArgumentsDefinition(field.ArgumentsDefinition).InputValueDefinitions
+	for inputValues.Next(r.l) {
+		inputValue, _ := inputValues.Value()
+		name := string(r.l.ByteSlice(inputValue.Name))
+		coordinate := fieldCoordinate + "(" + name + ":)"
+		if r.visibility.publicName(coordinate, name) != string(argName) {
+			continue
+		}
+		if r.visibility.element(coordinate).internal {
+			return undefined
+		}
+		if !r.l.ByteSliceReferenceContentsEquals(arg.Name, inputValue.Name) {
+			r.mod.SetArgumentName(argRef, inputValue.Name)
+		}
+		return r.value(arg.Value, inputValue.Type)
+	}
+
+	if r.isBackendName(fieldCoordinate + "(" + string(argName) + ":)") {
+		return undefined
+	}
+
+	return nil
+}
+
+// value rejects internal input fields and enum values inside an argument value
+func (r *visibilityRewriter) value(valueRef, typeRef int) error {
+
+	value := r.l.Value(valueRef)
+	namedType := r.l.UnwrappedNamedType(r.l.Type(typeRef))
+
+	switch value.ValueType {
+	case document.ValueTypeList:
+		itemType := typeRef
+		if listType := r.nullableType(typeRef); listType.Kind == document.TypeKindLIST {
+			itemType = listType.OfType
+		}
+		for _, itemRef := range r.l.ListValue(value.Reference) {
+			err := r.value(itemRef, itemType)
+			if err != nil {
+				return err
+			}
+		}
+	case document.ValueTypeObject:
+		for _, fieldRef := range r.l.ObjectValue(value.Reference) {
+			objectField := r.l.ObjectField(fieldRef)
+			fieldType, ok, err := r.inputField(namedType.Name, string(r.l.ByteSlice(objectField.Name)))
+			if err != nil || !ok {
+				return err
+			}
+			err = r.value(objectField.Value, fieldType)
+			if err != nil {
+				return err
+			}
+		}
+	case document.ValueTypeEnum:
+		return r.enumValue(namedType.Name, string(r.l.ByteSlice(value.Raw)))
+	}
+
+	return nil
+}
+
+// variableValue rejects internal input fields and enum values inside the json value of a variable
+func (r *visibilityRewriter) variableValue(value interface{}, typeRef int) error {
+
+	namedType := r.l.UnwrappedNamedType(r.l.Type(typeRef))
+
+	switch value := value.(type) {
+	case []interface{}:
+		itemType := typeRef
+		if listType := r.nullableType(typeRef); listType.Kind == document.TypeKindLIST {
+			itemType = listType.OfType
+		}
+		for i := range value {
+			err := r.variableValue(value[i], itemType)
+			if err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		for name, fieldValue := range value {
+			fieldType, ok, err := r.inputField(namedType.Name, name)
+			if err != nil || !ok {
+				return err
+			}
+			err = r.variableValue(fieldValue, fieldType)
+			if err != nil {
+				return err
+			}
+		}
+	case string:
+		if _, ok := r.l.EnumTypeDefinitionByName(namedType.Name); ok {
+			return r.enumValue(namedType.Name, value)
+		}
+	}
+
+	return nil
+}
+
+// inputField returns the type of the field of an input object, ok is false for unknown fields
+func (r *visibilityRewriter) inputField(typeName document.ByteSliceReference, name string) (typeRef int, ok bool, err error) {
+
+	definition, exists := r.l.InputObjectTypeDefinitionByName(typeName)
+	if !exists {
+		return -1, false, nil
+	}
+
+	coordinate := string(r.l.ByteSlice(typeName)) + "." + name
+	if r.visibility.element(coordinate).internal {
+		return -1, false, fmt.Errorf("SchemaVisibilityMiddleware: field '%s' is not defined on input type '%s'", name, r.visibility.publicTypeName(string(r.l.ByteSlice(typeName))))
+	}
+
+	inputValues := r.l.InputFieldsDefinition(definition.InputFieldsDefinition).InputValueDefinitions
+	for inputValues.Next(r.l) {
+		inputValue, _ := inputValues.Value()
+		if string(r.l.ByteSlice(inputValue.Name)) == name {
+			return inputValue.Type, true, nil
+		}
+	}
+
+	return -1, false, nil
+}
+
+func (r *visibilityRewriter) enumValue(typeName document.ByteSliceReference, name string) error {
+	if !r.visibility.element(string(r.l.ByteSlice(typeName)) + "." + name).internal {
+		return nil
+	}
+	return fmt.Errorf("SchemaVisibilityMiddleware: value '%s' is not defined on enum '%s'", name, r.visibility.publicTypeName(string(r.l.ByteSlice(typeName))))
+}
+
+func (r *visibilityRewriter) nullableType(typeRef int) document.Type {
+	documentType := r.l.Type(typeRef)
+	if documentType.Kind == document.TypeKindNON_NULL {
+		return r.l.Type(documentType.OfType)
+	}
+	return documentType
+}
+
+// isBackendName reports whether an element is renamed, its backend name must not be used by clients
+func (r *visibilityRewriter) isBackendName(coordinate string) bool {
+	return r.visibility.element(coordinate).publicName != ""
+}
+
+func (r *visibilityRewriter) undefinedField(typeName document.ByteSliceReference, fieldName []byte) error {
+	return fmt.Errorf("SchemaVisibilityMiddleware: field '%s' is not defined on type '%s'", string(fieldName), r.visibility.publicTypeName(string(r.l.ByteSlice(typeName))))
+}
+
+// publicCoordinate converts the coordinate of a field to the public names, e.g. for error messages
+func (r *visibilityRewriter) publicCoordinate(fieldCoordinate string) string {
+	for i := range fieldCoordinate {
+		if fieldCoordinate[i] == '.' {
+			typeName, fieldName := fieldCoordinate[:i], fieldCoordinate[i+1:]
+			return r.visibility.publicTypeName(typeName) + "." + r.visibility.publicName(fieldCoordinate, fieldName)
+		}
+	}
+	return fieldCoordinate
+}
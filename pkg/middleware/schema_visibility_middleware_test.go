@@ -0,0 +1,232 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestSchemaVisibilityMiddleware(t *testing.T) {
+
+	run := func(ctx context.Context, query, want string) {
+		got, err := InvokeMiddleware(&SchemaVisibilityMiddleware{}, ctx, schemaVisibilityMiddlewareSchema, query)
+		if err != nil {
+			panic(err)
+		}
+		if want != got {
+			panic(fmt.Errorf("want:\n%s\ngot:\n%s", want, got))
+		}
+	}
+
+	runErr := func(ctx context.Context, query, wantErr string) {
+		_, err := InvokeMiddleware(&SchemaVisibilityMiddleware{}, ctx, schemaVisibilityMiddlewareSchema, query)
+		if err == nil {
+			panic(fmt.Errorf("want err for query: %s", query))
+		}
+		if !strings.Contains(err.Error(), wantErr) {
+			panic(fmt.Errorf("want err containing: %s, got: %s", wantErr, err.Error()))
+		}
+	}
+
+	variables := func(variables map[string]interface{}) context.Context {
+		return WithGraphQLRequest(context.Background(), &GraphQLRequest{Variables: variables})
+	}
+
+	t.Run("renamed fields are aliased to their public name", func(t *testing.T) {
+		run(nil, `{allDocuments {owner}}`,
+			`{allDocuments:documents {owner}}`)
+	})
+	t.Run("aliases are kept", func(t *testing.T) {
+		run(nil, `{files: allDocuments {owner}}`,
+			`{files:documents {owner}}`)
+	})
+	t.Run("renamed arguments", func(t *testing.T) {
+		run(nil, `{allDocuments(max: 3) {owner}}`,
+			`{allDocuments:documents(limit:3) {owner}}`)
+	})
+	t.Run("renamed types", func(t *testing.T) {
+		run(nil, `query files($filter: FileFilter) {allDocuments(filter: $filter) {...fileFields ... on File {owner}}} fragment fileFields on File {owner}`,
+			`query files($filter:DocumentFilter) {allDocuments:documents(filter:$filter) {...fileFields ...on Document{owner}}}
+fragment fileFields on Document {owner}`)
+	})
+	t.Run("hidden elements can be used", func(t *testing.T) {
+		run(nil, `{allDocuments(filter: {status: LEGACY}) {legacyId}}`,
+			`{allDocuments:documents(filter:{status:LEGACY}) {legacyId}}`)
+	})
+	t.Run("introspection fields are kept", func(t *testing.T) {
+		run(nil, `{__typename allDocuments {__typename} __type(name: "File") {name}}`,
+			`{__typename allDocuments:documents {__typename} __type(name:"File") {name}}`)
+	})
+	t.Run("backend names of renamed elements are rejected", func(t *testing.T) {
+		runErr(nil, `{documents {owner}}`, "field 'documents' is not defined on type 'Query'")
+		runErr(nil, `{allDocuments(limit: 3) {owner}}`, "argument 'limit' is not defined on field 'Query.allDocuments'")
+		runErr(nil, `{allDocuments {... on Document {owner}}}`, "type 'Document' is not defined")
+		runErr(nil, `query files($filter: DocumentFilter) {allDocuments(filter: $filter) {owner}}`, "type 'DocumentFilter' is not defined")
+	})
+	t.Run("internal elements are rejected", func(t *testing.T) {
+		runErr(nil, `{allDocuments {sensitiveInformation}}`, "field 'sensitiveInformation' is not defined on type 'File'")
+		runErr(nil, `{allDocuments(user: "admin") {owner}}`, "argument 'user' is not defined on field 'Query.allDocuments'")
+		runErr(nil, `{allDocuments(filter: {owner: "admin"}) {owner}}`, "field 'owner' is not defined on input type 'FileFilter'")
+		runErr(nil, `{allDocuments(filter: {status: DELETED}) {owner}}`, "value 'DELETED' is not defined on enum 'Status'")
+		runErr(nil, `query files($filter: FileFilter = {owner: "admin"}) {allDocuments(filter: $filter) {owner}}`, "field 'owner' is not defined on input type 'FileFilter'")
+	})
+	t.Run("internal elements in variables are rejected", func(t *testing.T) {
+		query := `query files($filters: [FileFilter]) {allDocuments(filters: $filters) {owner}}`
+		run(variables(map[string]interface{}{"filters": []interface{}{map[string]interface{}{"status": "PUBLISHED"}}}), query,
+			`query files($filters:[DocumentFilter]) {allDocuments:documents(filters:$filters) {owner}}`)
+		runErr(variables(map[string]interface{}{"filters": []interface{}{map[string]interface{}{"owner": "admin"}}}), query,
+			"field 'owner' is not defined on input type 'FileFilter'")
+		runErr(variables(map[string]interface{}{"filters": []interface{}{map[string]interface{}{"status": "DELETED"}}}), query,
+			"value 'DELETED' is not defined on enum 'Status'")
+	})
+	t.Run("schema without visibility directives", func(t *testing.T) {
+		got, err := InvokeMiddleware(&SchemaVisibilityMiddleware{}, nil, authorizationMiddlewareSchema, `{documents {owner}}`)
+		if err != nil {
+			panic(err)
+		}
+		if got != `{documents {owner}}` {
+			panic(fmt.Errorf("want request unchanged, got: %s", got))
+		}
+	})
+}
+
+func TestSchemaVisibilityMiddleware_OnResponse(t *testing.T) {
+
+	run := func(query, response, want string) {
+		got, err := InvokeMiddlewareOnResponse(&SchemaVisibilityMiddleware{}, nil, schemaVisibilityMiddlewareSchema, query, response)
+		if err != nil {
+			panic(err)
+		}
+		if want != got {
+			panic(fmt.Errorf("want:\n%s\ngot:\n%s", want, got))
+		}
+	}
+
+	t.Run("type names are rewritten", func(t *testing.T) {
+		run(`{allDocuments {__typename kind: __typename owner}}`,
+			`{"data":{"allDocuments":[{"__typename":"Document","kind":"Document","owner":"Document"}]}}`,
+			`{"data":{"allDocuments":[{"__typename":"File","kind":"File","owner":"Document"}]}}`)
+	})
+	t.Run("type names of types which aren't renamed are kept", func(t *testing.T) {
+		run(`{__typename allDocuments {owner}}`,
+			`{"data":{"__typename":"Query","allDocuments":null}}`,
+			`{"data":{"__typename":"Query","allDocuments":null}}`)
+	})
+	t.Run("responses are only walked if the request selects __typename", func(t *testing.T) {
+		run(`{allDocuments {owner}}`, `Bad Gateway`, `Bad Gateway`)
+	})
+}
+
+func TestSchemaVisibilityMiddleware_Introspection(t *testing.T) {
+
+	run := func(query, want string) {
+		invoker := NewInvoker(&SchemaVisibilityMiddleware{}, &IntrospectionMiddleware{}, &ValidationMiddleware{})
+		err := invoker.SetSchema([]byte(schemaVisibilityMiddlewareSchema))
+		if err != nil {
+			panic(err)
+		}
+		err = invoker.InvokeMiddleWares(nil, []byte(query))
+		if err != nil {
+			panic(err)
+		}
+		response, resolved := invoker.ResolvedResponse()
+		if !resolved {
+			panic(fmt.Errorf("want resolved query: %s", query))
+		}
+		got := append([]byte{}, response...)
+		err = invoker.InvokeMiddleWaresOnResponse(nil, &got)
+		if err != nil {
+			panic(err)
+		}
+		if want != string(got) {
+			panic(fmt.Errorf("want:\n%s\ngot:\n%s", want, string(got)))
+		}
+	}
+
+	t.Run("public types", func(t *testing.T) {
+		run(`{query: __type(name: "Query") {fields {name args {name type {name ofType {name}}} type {name ofType {name}}}} document: __type(name: "Document") {name}}`,
+			`{"data":{"query":{"fields":[{"name":"allDocuments","args":[{"name":"max","type":{"name":"Int","ofType":null}},{"name":"filter","type":{"name":"FileFilter","ofType":null}},{"name":"filters","type":{"name":null,"ofType":{"name":"FileFilter"}}}],`+
+				`"type":{"name":null,"ofType":{"name":"File"}}}]},"document":null}}`)
+	})
+	t.Run("hidden and internal elements are removed", func(t *testing.T) {
+		run(`{file: __type(name: "File") {name fields {name}} filter: __type(name: "FileFilter") {inputFields {name}} status: __type(name: "Status") {enumValues {name}}}`,
+			`{"data":{"file":{"name":"File","fields":[{"name":"owner"}]},"filter":{"inputFields":[{"name":"status"}]},"status":{"enumValues":[{"name":"PUBLISHED"}]}}}`)
+	})
+	t.Run("visibility directives are removed", func(t *testing.T) {
+		run(`{__typename __schema {queryType {name} directives {name}}}`,
+			`{"data":{"__typename":"Query","__schema":{"queryType":{"name":"Query"},"directives":[{"name":"include"},{"name":"skip"},{"name":"deprecated"},{"name":"specifiedBy"}]}}}`)
+	})
+}
+
+func TestPublicSchema(t *testing.T) {
+
+	out := bytes.Buffer{}
+	err := PublicSchema([]byte(schemaVisibilityMiddlewareSchema), &out)
+	if err != nil {
+		panic(err)
+	}
+
+	want := `schema {
+	query: Query
+}
+
+type Query {
+	allDocuments(
+		max: Int
+		filter: FileFilter
+		filters: [FileFilter]
+	): [File]
+}
+
+type File {
+	owner: String
+}
+
+input FileFilter {
+	status: Status
+}
+
+enum Status {
+	PUBLISHED
+}
+`
+	// the built-in scalars and directives follow the types of the schema
+	got := out.String()
+	if !strings.HasPrefix(got, want) {
+		panic(fmt.Errorf("want:\n%s\ngot:\n%s", want, got))
+	}
+	for _, directive := range []string{"@hide", "@internal", "@rename"} {
+		if strings.Contains(got, directive) {
+			panic(fmt.Errorf("want public schema without %s, got:\n%s", directive, got))
+		}
+	}
+}
+
+const schemaVisibilityMiddlewareSchema = `
+schema {
+	query: Query
+}
+
+type Query {
+	documents(user: String @internal, limit: Int @rename(to: "max"), filter: DocumentFilter, filters: [DocumentFilter]): [Document] @rename(to: "allDocuments")
+}
+
+type Document @rename(to: "File") {
+	owner: String
+	legacyId: Int @hide
+	sensitiveInformation: String @internal
+}
+
+input DocumentFilter @rename(to: "FileFilter") {
+	owner: String @internal
+	status: Status
+}
+
+enum Status {
+	PUBLISHED
+	LEGACY @hide
+	DELETED @internal
+}
+`
@@ -1,8 +1,10 @@
 package middleware
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"github.com/jensneuse/graphql-go-tools/pkg/document"
 	"github.com/jensneuse/graphql-go-tools/pkg/lookup"
 	"github.com/jensneuse/graphql-go-tools/pkg/parser"
 	"github.com/jensneuse/graphql-go-tools/pkg/validator"
@@ -10,8 +12,23 @@ import (
 
 // ValidationMiddleware is a middleware which validates the input Query against the Schema definition
 type ValidationMiddleware struct {
+	// Rules is the rule set used to validate requests, if nil validator.DefaultRegistry() will be used
+	// the rule set can be overridden per request using WithValidationRules
+	Rules *validator.Registry
+	// OnWarnings (optional) gets called with the violations of all rules configured with validator.SeverityWarning
+	OnWarnings func(ctx context.Context, warnings []validator.Violation)
 }
 
+type validationRulesContextKey struct{}
+
+// WithValidationRules returns a context which makes the ValidationMiddleware use the provided rule set
+// for the request instead of the rule set configured on the middleware
+func WithValidationRules(ctx context.Context, rules *validator.Registry) context.Context {
+	return context.WithValue(ctx, validationRulesContextKey{}, rules)
+}
+
+var defaultValidationRules = validator.DefaultRegistry()
+
 var validationMiddlewareSchemaExtension = []byte(`
 scalar Int
 scalar Float
@@ -26,7 +43,10 @@ directive @skip(
 ) on FIELD | FRAGMENT_SPREAD | INLINE_FRAGMENT
 directive @deprecated(
 	reason: String = "No longer supported"
-) on FIELD_DEFINITION | ENUM_VALUE
+) on FIELD_DEFINITION | ARGUMENT_DEFINITION | INPUT_FIELD_DEFINITION | ENUM_VALUE
+directive @specifiedBy(
+	url: String!
+) on SCALAR
 `)
 
 // PrepareSchema adds the base scalar and directive types to the schema so that the user doesn't have to add them
@@ -38,6 +58,11 @@ func (v *ValidationMiddleware) PrepareSchema(ctx context.Context, l *lookup.Look
 	return err
 }
 
+// Phase returns PhaseValidation so that requests get validated before they're resolved or sent to the backend
+func (v *ValidationMiddleware) Phase() Phase {
+	return PhaseValidation
+}
+
 func (v *ValidationMiddleware) OnRequest(ctx context.Context, l *lookup.Lookup, w *lookup.Walker, parser *parser.Parser, mod *parser.ManualAstMod) error {
 
 	w.SetLookup(l)
@@ -46,14 +71,46 @@ func (v *ValidationMiddleware) OnRequest(ctx context.Context, l *lookup.Lookup,
 	valid := validator.New()
 	valid.SetInput(l, w)
 
-	result := valid.ValidateExecutableDefinition(validator.DefaultExecutionRules)
-	if result.Valid {
+	report := valid.ValidateExecutableDefinitionWithRegistry(v.rules(ctx))
+	if len(report.Warnings) != 0 && v.OnWarnings != nil {
+		v.OnWarnings(ctx, report.Warnings)
+	}
+
+	if report.Valid() {
 		return nil
 	}
 
-	return fmt.Errorf("ValidationMiddleware: Invalid Request: RuleName: %s, Description: %s", result.RuleName.String(), result.Description.String())
+	violation := report.Errors[0]
+	if len(violation.Result.Meta.SubjectPath) != 0 {
+		return fmt.Errorf("ValidationMiddleware: Invalid Request: RuleName: %s, Description: %s, Path: %s", violation.RuleName, violation.Result.Description.String(), subjectPath(l, violation.Result.Meta.SubjectPath))
+	}
+
+	return fmt.Errorf("ValidationMiddleware: Invalid Request: RuleName: %s, Description: %s", violation.RuleName, violation.Result.Description.String())
+}
+
+func subjectPath(l *lookup.Lookup, path []document.ByteSliceReference) string {
+	buff := bytes.Buffer{}
+	for i := range path {
+		if i != 0 {
+			buff.WriteByte('.')
+		}
+		buff.Write(l.ByteSlice(path[i]))
+	}
+	return buff.String()
+}
+
+func (v *ValidationMiddleware) rules(ctx context.Context) *validator.Registry {
+	if ctx != nil {
+		if rules, ok := ctx.Value(validationRulesContextKey{}).(*validator.Registry); ok && rules != nil {
+			return rules
+		}
+	}
+	if v.Rules != nil {
+		return v.Rules
+	}
+	return defaultValidationRules
 }
 
 func (v *ValidationMiddleware) OnResponse(ctx context.Context, response *[]byte, l *lookup.Lookup, w *lookup.Walker, parser *parser.Parser, mod *parser.ManualAstMod) (err error) {
-	panic("implement me")
+	return nil
 }
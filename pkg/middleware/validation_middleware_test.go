@@ -1,6 +1,11 @@
 package middleware
 
 import (
+	"context"
+	"github.com/jensneuse/graphql-go-tools/pkg/lookup"
+	"github.com/jensneuse/graphql-go-tools/pkg/validation"
+	"github.com/jensneuse/graphql-go-tools/pkg/validation/rules/execution"
+	"github.com/jensneuse/graphql-go-tools/pkg/validator"
 	"testing"
 )
 
@@ -19,6 +24,76 @@ func TestValidationMiddleware(t *testing.T) {
 			t.Fatal("want err")
 		}
 	})
+	t.Run("rules configured on the middleware", func(t *testing.T) {
+		query := `query myDocuments {documents {sensitiveInformation}}`
+		_, err := InvokeMiddleware(&ValidationMiddleware{Rules: noSensitiveInformationRules(validator.SeverityError)}, nil, validationMiddlewarePublicSchema, query)
+		if err == nil {
+			t.Fatal("want err")
+		}
+		want := "ValidationMiddleware: Invalid Request: RuleName: NoSensitiveInformation, Description: NoDescription"
+		if err.Error() != want {
+			t.Fatalf("want err: %s, got: %s", want, err.Error())
+		}
+	})
+	t.Run("rules from context override the middleware rules", func(t *testing.T) {
+		query := `query myDocuments {documents {sensitiveInformation}}`
+		ctx := WithValidationRules(context.Background(), validator.DefaultRegistry())
+		_, err := InvokeMiddleware(&ValidationMiddleware{Rules: noSensitiveInformationRules(validator.SeverityError)}, ctx, validationMiddlewarePublicSchema, query)
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+	t.Run("depth limit", func(t *testing.T) {
+		rules := validator.DefaultRegistry()
+		err := rules.Register(validation.DepthLimit.String(), execution.DepthLimit(1, true), validator.SeverityError)
+		if err != nil {
+			t.Fatal(err)
+		}
+		query := `query myDocuments {documents {sensitiveInformation}}`
+		_, err = InvokeMiddleware(&ValidationMiddleware{Rules: rules}, nil, validationMiddlewarePublicSchema, query)
+		if err == nil {
+			t.Fatal("want err")
+		}
+		want := "ValidationMiddleware: Invalid Request: RuleName: DepthLimit, Description: MaxDepthExceeded, Path: documents.sensitiveInformation"
+		if err.Error() != want {
+			t.Fatalf("want err: %s, got: %s", want, err.Error())
+		}
+	})
+	t.Run("warnings", func(t *testing.T) {
+		var warnings []validator.Violation
+		middleware := &ValidationMiddleware{
+			Rules: noSensitiveInformationRules(validator.SeverityWarning),
+			OnWarnings: func(ctx context.Context, violations []validator.Violation) {
+				warnings = append(warnings, violations...)
+			},
+		}
+		query := `query myDocuments {documents {sensitiveInformation}}`
+		_, err := InvokeMiddleware(middleware, nil, validationMiddlewarePublicSchema, query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(warnings) != 1 || warnings[0].RuleName != "NoSensitiveInformation" {
+			t.Fatalf("want NoSensitiveInformation warning, got: %+v", warnings)
+		}
+	})
+}
+
+func noSensitiveInformationRules(severity validator.Severity) *validator.Registry {
+	rules := validator.DefaultRegistry()
+	err := rules.Register("NoSensitiveInformation", func(l *lookup.Lookup, w *lookup.Walker) validation.Result {
+		fields := w.FieldsIterable()
+		for fields.Next() {
+			field, _, _ := fields.Value()
+			if string(l.ByteSlice(field.Name)) == "sensitiveInformation" {
+				return validation.Invalid(validation.NoRule, validation.NoDescription, field.Position, field.Name)
+			}
+		}
+		return validation.Valid()
+	}, severity)
+	if err != nil {
+		panic(err)
+	}
+	return rules
 }
 
 const validationMiddlewarePublicSchema = `
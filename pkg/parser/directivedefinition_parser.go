@@ -1,8 +1,11 @@
 package parser
 
 import (
+	"bytes"
+
 	"github.com/jensneuse/graphql-go-tools/pkg/document"
 	"github.com/jensneuse/graphql-go-tools/pkg/lexing/keyword"
+	"github.com/jensneuse/graphql-go-tools/pkg/lexing/literal"
 	"github.com/jensneuse/graphql-go-tools/pkg/lexing/token"
 )
 
@@ -26,6 +29,7 @@ func (p *Parser) parseDirectiveDefinition(hasDescription, isExtend bool, descrip
 	var definition document.DirectiveDefinition
 	definition.DirectiveLocations = p.IndexPoolGet()
 	definition.Name = directiveIdent.Literal
+	definition.ArgumentsDefinition = -1
 	definition.IsExtend = isExtend
 
 	if hasDescription {
@@ -40,6 +44,13 @@ func (p *Parser) parseDirectiveDefinition(hasDescription, isExtend bool, descrip
 		return err
 	}
 
+	if repeatable, matches := p.peekExpectSwallow(keyword.IDENT); matches {
+		if !bytes.Equal(p.ByteSlice(repeatable.Literal), literal.REPEATABLE) {
+			return newErrInvalidType(repeatable.TextPosition, "parseDirectiveDefinition", string(literal.REPEATABLE), string(p.ByteSlice(repeatable.Literal)))
+		}
+		definition.IsRepeatable = true
+	}
+
 	_, err = p.readExpect(keyword.ON, "parseDirectiveDefinition")
 	if err != nil {
 		return err
@@ -56,6 +56,38 @@ func TestParser_parseDirectiveDefinition(t *testing.T) {
 			),
 		)
 	})
+	t.Run("repeatable", func(t *testing.T) {
+		run("directive @ somewhere(inputValue: Int) repeatable on QUERY",
+			mustParseDirectiveDefinition(
+				node(
+					hasName("somewhere"),
+					isRepeatable(true),
+					hasDirectiveLocations(document.DirectiveLocationQUERY),
+				),
+			),
+		)
+	})
+	t.Run("not repeatable", func(t *testing.T) {
+		run("directive @ somewhere on QUERY",
+			mustParseDirectiveDefinition(
+				node(
+					hasName("somewhere"),
+					isRepeatable(false),
+				),
+			),
+		)
+	})
+	t.Run("invalid repeatable", func(t *testing.T) {
+		run("directive @ somewhere repeat on QUERY",
+			mustPanic(
+				mustParseDirectiveDefinition(
+					node(
+						hasName("somewhere"),
+					),
+				),
+			),
+		)
+	})
 	t.Run("multiple locations", func(t *testing.T) {
 		run("directive @ somewhere on QUERY |\nMUTATION",
 			mustParseDirectiveDefinition(
@@ -49,6 +49,8 @@ func (p *Parser) parseAnonymousOperation(executableDefinition *document.Executab
 		return err
 	}
 
+	operationDefinition.Position = p.ParsedDefinitions.SelectionSets[operationDefinition.SelectionSet].Position
+
 	executableDefinition.OperationDefinitions =
 		append(executableDefinition.OperationDefinitions, p.putOperationDefinition(operationDefinition))
 
@@ -9,7 +9,7 @@ import (
 func (p *Parser) parseFieldDefinitions() (fieldDefinitions document.FieldDefinitions, err error) {
 
 	if hasOpen := p.peekExpect(keyword.CURLYBRACKETOPEN, true); !hasOpen {
-		return
+		return document.NewFieldDefinitions(-1), err
 	}
 
 	var hasDescription bool
@@ -29,7 +29,8 @@ func (p *Parser) parseFieldDefinitions() (fieldDefinitions document.FieldDefinit
 		case keyword.CURLYBRACKETCLOSE:
 			p.l.Read()
 			return document.NewFieldDefinitions(nextRef), err
-		case keyword.IDENT, keyword.TYPE, keyword.MUTATION:
+		case keyword.IDENT, keyword.TYPE, keyword.MUTATION, keyword.QUERY, keyword.SUBSCRIPTION, keyword.INPUT, keyword.ENUM, keyword.SCALAR,
+			keyword.INTERFACE, keyword.UNION, keyword.SCHEMA, keyword.DIRECTIVE, keyword.FRAGMENT, keyword.IMPLEMENTS, keyword.EXTEND, keyword.ON:
 
 			fieldIdent := p.l.Read()
 			definition := p.makeFieldDefinition()
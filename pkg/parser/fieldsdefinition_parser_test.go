@@ -51,6 +51,20 @@ func TestParser_parseFieldsDefinition(t *testing.T) {
 				),
 			))
 	})
+	t.Run("keywords as field names", func(t *testing.T) {
+		run(`{
+					input: String
+					query: Int
+				}`,
+			mustParseFieldsDefinition(
+				node(
+					hasName("query"),
+				),
+				node(
+					hasName("input"),
+				),
+			))
+	})
 	t.Run("with description", func(t *testing.T) {
 		run(`{
 					"describes the name"
@@ -8,7 +8,7 @@ import (
 func (p *Parser) parseImplementsInterfaces() (implementsInterfaces document.ByteSliceReferences, err error) {
 
 	if implements := p.peekExpect(keyword.IMPLEMENTS, true); !implements {
-		return
+		return document.NewByteSliceReferences(-1), err
 	}
 
 	nextRef := -1
@@ -26,7 +26,8 @@ func (p *Parser) parseInputValueDefinitions(closeKeyword keyword.Keyword) (defin
 			quote := p.l.Read()
 			description = quote
 			hasDescription = true
-		case keyword.IDENT, keyword.TYPE, keyword.MUTATION:
+		case keyword.IDENT, keyword.TYPE, keyword.MUTATION, keyword.QUERY, keyword.SUBSCRIPTION, keyword.INPUT, keyword.ENUM, keyword.SCALAR,
+			keyword.INTERFACE, keyword.UNION, keyword.SCHEMA, keyword.DIRECTIVE, keyword.FRAGMENT, keyword.IMPLEMENTS, keyword.EXTEND, keyword.ON:
 			ident := p.l.Read()
 			definition := p.makeInputValueDefinition()
 
@@ -26,6 +26,18 @@ func TestParser_parseInputValueDefinitions(t *testing.T) {
 			),
 		)
 	})
+	t.Run("keyword as name", func(t *testing.T) {
+		run("input: String",
+			mustParseInputValueDefinitions(
+				node(
+					hasName("input"),
+					nodeType(
+						hasTypeName("String"),
+					),
+				),
+			),
+		)
+	})
 	t.Run("with default", func(t *testing.T) {
 		run("inputValue: Int = 2",
 			mustParseInputValueDefinitions(
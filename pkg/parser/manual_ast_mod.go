@@ -2,6 +2,7 @@ package parser
 
 import (
 	"bytes"
+	"fmt"
 	"github.com/jensneuse/graphql-go-tools/pkg/document"
 	"github.com/jensneuse/graphql-go-tools/pkg/lexing/keyword"
 )
@@ -63,6 +64,98 @@ func (m *ManualAstMod) DeleteFieldFromSelectionSet(fieldRef, setRef int) {
 	}
 }
 
+// ReplaceFieldInSelectionSet replaces the field oldRef with newRef keeping the order of the selection set
+func (m *ManualAstMod) ReplaceFieldInSelectionSet(oldRef, newRef, setRef int) {
+	for i, j := range m.p.ParsedDefinitions.SelectionSets[setRef].Fields {
+		if oldRef == j {
+			m.p.ParsedDefinitions.SelectionSets[setRef].Fields[i] = newRef
+			return
+		}
+	}
+}
+
+// DeleteVariableDefinitionFromOperation removes a variable definition, e.g. after deleting the last field using it
+func (m *ManualAstMod) DeleteVariableDefinitionFromOperation(variableDefinitionRef, operationRef int) {
+	definitions := m.p.ParsedDefinitions.OperationDefinitions[operationRef].VariableDefinitions
+	for i, j := range definitions {
+		if variableDefinitionRef == j {
+			m.p.ParsedDefinitions.OperationDefinitions[operationRef].VariableDefinitions = append(definitions[:i], definitions[i+1:]...)
+			return
+		}
+	}
+}
+
+// PutObjectField puts an object field into the ast, use PutObjectValue to create an object value containing it
+func (m *ManualAstMod) PutObjectField(field document.ObjectField) int {
+	return m.p.putObjectField(field)
+}
+
+// PutObjectValue puts an object value consisting of object field refs into the ast, the returned ref is the value reference of an object value
+func (m *ManualAstMod) PutObjectValue(value document.ObjectValue) int {
+	m.p.ParsedDefinitions.ObjectValues = append(m.p.ParsedDefinitions.ObjectValues, value)
+	return len(m.p.ParsedDefinitions.ObjectValues) - 1
+}
+
+// PutVariableDefinition puts a variable definition into the ast, use AppendVariableDefinitionToOperation to make use of it
+func (m *ManualAstMod) PutVariableDefinition(definition document.VariableDefinition) int {
+	return m.p.putVariableDefinition(definition)
+}
+
+// AppendVariableDefinitionToOperation adds a variable definition to the variable definitions of an operation
+func (m *ManualAstMod) AppendVariableDefinitionToOperation(variableDefinitionRef, operationRef int) {
+	definitions := m.p.ParsedDefinitions.OperationDefinitions[operationRef].VariableDefinitions
+	// copy the definitions as the slice might share its backing array with the index pool
+	updated := make([]int, len(definitions), len(definitions)+1)
+	copy(updated, definitions)
+	m.p.ParsedDefinitions.OperationDefinitions[operationRef].VariableDefinitions = append(updated, variableDefinitionRef)
+}
+
+// PutType parses a type reference like "[String!]!" and puts it into the ast
+func (m *ManualAstMod) PutType(typeReference []byte) (ref int, err error) {
+
+	for {
+		if m.p.l.Read().Keyword == keyword.EOF {
+			break
+		}
+	}
+
+	err = m.p.l.AppendBytes(typeReference)
+	if err != nil {
+		return -1, err
+	}
+
+	err = m.p.parseType(&ref)
+	if err != nil {
+		return -1, err
+	}
+
+	if m.p.l.Peek(true) != keyword.EOF {
+		return -1, fmt.Errorf("PutType: unexpected input after type reference '%s'", string(typeReference))
+	}
+
+	return ref, nil
+}
+
+// SetFieldName sets the name of a field, e.g. to map the name of the field in a public schema to the backend schema
+func (m *ManualAstMod) SetFieldName(fieldRef int, name document.ByteSliceReference) {
+	m.p.ParsedDefinitions.Fields[fieldRef].Name = name
+}
+
+// SetFieldAlias sets the alias of a field which is the key of the field in the response
+func (m *ManualAstMod) SetFieldAlias(fieldRef int, alias document.ByteSliceReference) {
+	m.p.ParsedDefinitions.Fields[fieldRef].Alias = alias
+}
+
+// SetArgumentName sets the name of an argument
+func (m *ManualAstMod) SetArgumentName(argumentRef int, name document.ByteSliceReference) {
+	m.p.ParsedDefinitions.Arguments[argumentRef].Name = name
+}
+
+// SetTypeName sets the name of a named type, e.g. the type condition of a fragment
+func (m *ManualAstMod) SetTypeName(typeRef int, name document.ByteSliceReference) {
+	m.p.ParsedDefinitions.Types[typeRef].Name = name
+}
+
 func (m *ManualAstMod) AppendFieldToSelectionSet(fieldRef, setRef int) {
 	m.p.ParsedDefinitions.SelectionSets[setRef].Fields = append(m.p.ParsedDefinitions.SelectionSets[setRef].Fields, fieldRef)
 }
@@ -0,0 +1,366 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/jensneuse/graphql-go-tools/pkg/document"
+	"github.com/jensneuse/graphql-go-tools/pkg/transform"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PutJSONValue converts a json encoded value, e.g. the value of a variable, into a value of the input type typeRef
+// and puts it into the ast, the returned ref can be used as the value of an argument
+// the value gets coerced according to: http://facebook.github.io/graphql/draft/#sec-Input-Values
+func (m *ManualAstMod) PutJSONValue(data []byte, typeRef int) (ref int, err error) {
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	err = decoder.Decode(&value)
+	if err != nil {
+		return -1, fmt.Errorf("PutJSONValue: %s", err)
+	}
+
+	return m.PutDecodedJSONValue(value, typeRef)
+}
+
+// PutDecodedJSONValue works like PutJSONValue for values decoded by encoding/json
+// numbers might be json.Number, float64 or any integer type
+func (m *ManualAstMod) PutDecodedJSONValue(value interface{}, typeRef int) (ref int, err error) {
+	return m.putJSONValue(value, typeRef, "value")
+}
+
+func (m *ManualAstMod) putJSONValue(value interface{}, typeRef int, path string) (int, error) {
+
+	valueType := m.p.ParsedDefinitions.Types[typeRef]
+
+	switch valueType.Kind {
+	case document.TypeKindNON_NULL:
+		if value == nil {
+			return -1, fmt.Errorf("PutJSONValue: %s must not be null", path)
+		}
+		return m.putJSONValue(value, valueType.OfType, path)
+	case document.TypeKindLIST:
+		if value == nil {
+			return m.PutValue(document.Value{ValueType: document.ValueTypeNull}), nil
+		}
+		items, ok := value.([]interface{})
+		if !ok {
+			items = []interface{}{value} // a single item gets coerced into a list of one item
+		}
+		list := make(document.ListValue, 0, len(items))
+		for i, item := range items {
+			itemRef, err := m.putJSONValue(item, valueType.OfType, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return -1, err
+			}
+			list = append(list, itemRef)
+		}
+		return m.PutValue(document.Value{ValueType: document.ValueTypeList, Reference: m.p.putListValue(list)}), nil
+	}
+
+	if value == nil {
+		return m.PutValue(document.Value{ValueType: document.ValueTypeNull}), nil
+	}
+
+	typeName := string(m.p.ByteSlice(valueType.Name))
+
+	switch typeName {
+	case "Int":
+		integer, ok := jsonInt(value)
+		if !ok {
+			return -1, fmt.Errorf("PutJSONValue: %s must be an Int, got: %v", path, value)
+		}
+		return m.putJSONNumber(integer, path)
+	case "Float":
+		number, ok := jsonNumber(value)
+		if !ok {
+			return -1, fmt.Errorf("PutJSONValue: %s must be a Float, got: %v", path, value)
+		}
+		return m.putJSONNumber(number, path)
+	case "String":
+		if _, ok := value.(string); !ok {
+			return -1, fmt.Errorf("PutJSONValue: %s must be a String, got: %v", path, value)
+		}
+		return m.putUntypedJSONValue(value, path)
+	case "Boolean":
+		if _, ok := value.(bool); !ok {
+			return -1, fmt.Errorf("PutJSONValue: %s must be a Boolean, got: %v", path, value)
+		}
+		return m.putUntypedJSONValue(value, path)
+	case "ID":
+		if integer, ok := jsonInt(value); ok {
+			return m.putJSONNumber(integer, path)
+		}
+		if _, ok := value.(string); !ok {
+			return -1, fmt.Errorf("PutJSONValue: %s must be an ID, got: %v", path, value)
+		}
+		return m.putUntypedJSONValue(value, path)
+	}
+
+	if enumValues, ok := m.enumValues(valueType.Name); ok {
+		enumValue, ok := value.(string)
+		if !ok || !enumValues[enumValue] {
+			return -1, fmt.Errorf("PutJSONValue: %s must be a value of enum %s, got: %v", path, typeName, value)
+		}
+		return m.putLiteralValue(document.ValueTypeEnum, []byte(enumValue))
+	}
+
+	if inputFields, ok := m.inputFields(valueType.Name); ok {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return -1, fmt.Errorf("PutJSONValue: %s must be an input object %s, got: %v", path, typeName, value)
+		}
+		for name := range object {
+			if _, ok := inputFields[name]; !ok {
+				return -1, fmt.Errorf("PutJSONValue: %s contains field %s which is not defined on %s", path, name, typeName)
+			}
+		}
+		for name, inputField := range inputFields {
+			fieldType := m.p.ParsedDefinitions.Types[inputField.Type]
+			if _, ok := object[name]; !ok && fieldType.Kind == document.TypeKindNON_NULL && inputField.DefaultValue == -1 {
+				return -1, fmt.Errorf("PutJSONValue: %s is missing the required field %s of %s", path, name, typeName)
+			}
+		}
+		return m.putJSONObject(object, path, func(name string) int {
+			return inputFields[name].Type
+		})
+	}
+
+	if m.isScalar(valueType.Name) {
+		return m.putUntypedJSONValue(value, path)
+	}
+
+	return -1, fmt.Errorf("PutJSONValue: %s has the unknown type %s", path, typeName)
+}
+
+// putUntypedJSONValue converts a value without knowing its input type, this is the case for custom scalars
+func (m *ManualAstMod) putUntypedJSONValue(value interface{}, path string) (int, error) {
+
+	if number, ok := jsonNumber(value); ok {
+		return m.putJSONNumber(number, path)
+	}
+
+	switch value := value.(type) {
+	case nil:
+		return m.PutValue(document.Value{ValueType: document.ValueTypeNull}), nil
+	case bool:
+		literal, reference := "false", 0
+		if value {
+			literal, reference = "true", 1
+		}
+		raw, _, err := m.PutLiteralString(literal)
+		if err != nil {
+			return -1, err
+		}
+		return m.PutValue(document.Value{ValueType: document.ValueTypeBoolean, Raw: raw, Reference: reference}), nil
+	case string:
+		quoted := make([]byte, 0, len(value)+2)
+		quoted = append(quoted, '"')
+		quoted = append(quoted, transform.EscapeString([]byte(value))...)
+		quoted = append(quoted, '"')
+		return m.putLiteralValue(document.ValueTypeString, quoted)
+	case []interface{}:
+		list := make(document.ListValue, 0, len(value))
+		for i, item := range value {
+			itemRef, err := m.putUntypedJSONValue(item, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return -1, err
+			}
+			list = append(list, itemRef)
+		}
+		return m.PutValue(document.Value{ValueType: document.ValueTypeList, Reference: m.p.putListValue(list)}), nil
+	case map[string]interface{}:
+		return m.putJSONObject(value, path, nil)
+	default:
+		return -1, fmt.Errorf("PutJSONValue: %s has the unsupported type %T", path, value)
+	}
+}
+
+// putJSONObject puts the fields of an object sorted by name, fieldType returns the input type of a field
+// the fields are converted untyped if fieldType is nil
+func (m *ManualAstMod) putJSONObject(object map[string]interface{}, path string, fieldType func(name string) int) (int, error) {
+
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	objectValue := make(document.ObjectValue, 0, len(names))
+	for _, name := range names {
+
+		if !isName(name) {
+			return -1, fmt.Errorf("PutJSONValue: %s contains the invalid field name '%s'", path, name)
+		}
+		fieldName, _, err := m.PutLiteralString(name)
+		if err != nil {
+			return -1, err
+		}
+
+		var fieldValue int
+		if fieldType != nil {
+			fieldValue, err = m.putJSONValue(object[name], fieldType(name), path+"."+name)
+		} else {
+			fieldValue, err = m.putUntypedJSONValue(object[name], path+"."+name)
+		}
+		if err != nil {
+			return -1, err
+		}
+
+		objectValue = append(objectValue, m.p.putObjectField(document.ObjectField{
+			Name:  fieldName,
+			Value: fieldValue,
+		}))
+	}
+
+	m.p.ParsedDefinitions.ObjectValues = append(m.p.ParsedDefinitions.ObjectValues, objectValue)
+	return m.PutValue(document.Value{ValueType: document.ValueTypeObject, Reference: len(m.p.ParsedDefinitions.ObjectValues) - 1}), nil
+}
+
+// putJSONNumber puts a number as Int if it's integral and fits into 32 bit, as Float otherwise
+// graphql floats may have an exponent but the lexer doesn't support it, so floats are always written as decimals
+// numbers beyond the 32 bit floats of the parser are rejected, which also keeps the written out decimals short
+func (m *ManualAstMod) putJSONNumber(number string, path string) (int, error) {
+
+	float, err := strconv.ParseFloat(number, 64)
+	if err != nil || math.IsInf(float, 0) {
+		return -1, fmt.Errorf("PutJSONValue: %s is an invalid number: %s", path, number)
+	}
+	if math.Abs(float) > math.MaxFloat32 || (float != 0 && float32(float) == 0) {
+		return -1, fmt.Errorf("PutJSONValue: %s is out of the range of Float: %s", path, number)
+	}
+
+	if strings.ContainsAny(number, "eE") {
+		number = strconv.FormatFloat(float, 'f', -1, 64)
+	}
+
+	if !strings.Contains(number, ".") {
+		integer, err := strconv.ParseInt(number, 10, 32)
+		if err == nil {
+			raw, _, err := m.PutLiteralString(number)
+			if err != nil {
+				return -1, err
+			}
+			return m.PutValue(document.Value{ValueType: document.ValueTypeInt, Raw: raw, Reference: m.p.putInteger(int32(integer))}), nil
+		}
+		number += ".0"
+	}
+
+	raw, _, err := m.PutLiteralString(number)
+	if err != nil {
+		return -1, err
+	}
+
+	return m.PutValue(document.Value{ValueType: document.ValueTypeFloat, Raw: raw, Reference: m.p.putFloat(float32(float))}), nil
+}
+
+// putLiteralValue puts a string or enum value which references its literal
+func (m *ManualAstMod) putLiteralValue(valueType document.ValueType, literal []byte) (int, error) {
+	raw, ref, err := m.PutLiteralBytes(literal)
+	if err != nil {
+		return -1, err
+	}
+	return m.PutValue(document.Value{ValueType: valueType, Raw: raw, Reference: ref}), nil
+}
+
+// enumValues returns the names of the values of all enum type definitions and extensions of the given name
+func (m *ManualAstMod) enumValues(name document.ByteSliceReference) (map[string]bool, bool) {
+	var values map[string]bool
+	for _, definition := range m.p.ParsedDefinitions.EnumTypeDefinitions {
+		if !bytes.Equal(m.p.ByteSlice(definition.Name), m.p.ByteSlice(name)) {
+			continue
+		}
+		if values == nil {
+			values = map[string]bool{}
+		}
+		enumValues := definition.EnumValuesDefinition
+		for enumValues.Next(m.p) {
+			enumValue, _ := enumValues.Value()
+			values[string(m.p.ByteSlice(enumValue.EnumValue))] = true
+		}
+	}
+	return values, values != nil
+}
+
+// inputFields returns the fields of all input object type definitions and extensions of the given name
+func (m *ManualAstMod) inputFields(name document.ByteSliceReference) (map[string]document.InputValueDefinition, bool) {
+	var fields map[string]document.InputValueDefinition
+	for _, definition := range m.p.ParsedDefinitions.InputObjectTypeDefinitions {
+		if !bytes.Equal(m.p.ByteSlice(definition.Name), m.p.ByteSlice(name)) {
+			continue
+		}
+		if fields == nil {
+			fields = map[string]document.InputValueDefinition{}
+		}
+		if definition.InputFieldsDefinition == -1 {
+			continue
+		}
+		inputValues := m.p.ParsedDefinitions.InputFieldsDefinitions[definition.InputFieldsDefinition].InputValueDefinitions
+		for inputValues.Next(m.p) {
+			inputValue, _ := inputValues.Value()
+			fields[string(m.p.ByteSlice(inputValue.Name))] = inputValue
+		}
+	}
+	return fields, fields != nil
+}
+
+func (m *ManualAstMod) isScalar(name document.ByteSliceReference) bool {
+	for _, definition := range m.p.ParsedDefinitions.ScalarTypeDefinitions {
+		if bytes.Equal(m.p.ByteSlice(definition.Name), m.p.ByteSlice(name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// isName reports whether name is a valid graphql name: /[_A-Za-z][_0-9A-Za-z]*/
+func isName(name string) bool {
+	for i := 0; i < len(name); i++ {
+		switch c := name[i]; {
+		case c == '_', c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		case c >= '0' && c <= '9' && i != 0:
+		default:
+			return false
+		}
+	}
+	return name != ""
+}
+
+// jsonInt returns the literal of a decoded json number if it's an integer which fits into 32 bit
+func jsonInt(value interface{}) (string, bool) {
+	number, ok := jsonNumber(value)
+	if !ok {
+		return "", false
+	}
+	float, err := strconv.ParseFloat(number, 64)
+	if err != nil || float != math.Trunc(float) || float > math.MaxInt32 || float < math.MinInt32 {
+		return "", false
+	}
+	return strconv.FormatInt(int64(float), 10), true
+}
+
+// jsonNumber returns the literal of a decoded json number
+func jsonNumber(value interface{}) (string, bool) {
+	switch number := value.(type) {
+	case json.Number:
+		return number.String(), true
+	case float64:
+		return strconv.FormatFloat(number, 'f', -1, 64), true
+	case float32:
+		return strconv.FormatFloat(float64(number), 'f', -1, 32), true
+	case int:
+		return strconv.FormatInt(int64(number), 10), true
+	case int32:
+		return strconv.FormatInt(int64(number), 10), true
+	case int64:
+		return strconv.FormatInt(number, 10), true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,87 @@
+package parser
+
+import (
+	"fmt"
+	"github.com/jensneuse/graphql-go-tools/pkg/document"
+	"strings"
+	"testing"
+)
+
+func TestManualAstMod_PutJSONValue(t *testing.T) {
+
+	run := func(typeName, json string, want document.ValueType, wantRaw string) {
+		parser := NewParser()
+		err := parser.ParseTypeSystemDefinition([]byte(`
+			type Query { field(arg: ` + typeName + `): String }
+			enum Status { DRAFT }
+			scalar JSON`))
+		if err != nil {
+			panic(err)
+		}
+
+		typeRef := parser.ParsedDefinitions.InputValueDefinitions[0].Type
+
+		ref, err := NewManualAstMod(parser).PutJSONValue([]byte(json), typeRef)
+		if err != nil {
+			panic(err)
+		}
+
+		value := parser.ParsedDefinitions.Values[ref]
+		if value.ValueType != want {
+			panic(fmt.Errorf("want value type %s, got: %s", want, value.ValueType))
+		}
+		if string(parser.ByteSlice(value.Raw)) != wantRaw {
+			panic(fmt.Errorf("want raw '%s', got: '%s'", wantRaw, string(parser.ByteSlice(value.Raw))))
+		}
+	}
+
+	runErr := func(typeName, json string, wantErr string) {
+		parser := NewParser()
+		err := parser.ParseTypeSystemDefinition([]byte(`type Query { field(arg: ` + typeName + `): String }`))
+		if err != nil {
+			panic(err)
+		}
+
+		_, err = NewManualAstMod(parser).PutJSONValue([]byte(json), parser.ParsedDefinitions.InputValueDefinitions[0].Type)
+		if err == nil {
+			panic(fmt.Errorf("want err for: %s", json))
+		}
+		if !strings.Contains(err.Error(), wantErr) {
+			panic(fmt.Errorf("want err containing: %s, got: %s", wantErr, err.Error()))
+		}
+	}
+
+	t.Run("int", func(t *testing.T) {
+		run("Int", "-3", document.ValueTypeInt, "-3")
+	})
+	t.Run("float", func(t *testing.T) {
+		run("Float", "2.5e2", document.ValueTypeInt, "250")
+		run("Float", "2.55e1", document.ValueTypeFloat, "25.5")
+		run("Float", "-1.5e-3", document.ValueTypeFloat, "-0.0015")
+		run("Float", "3e38", document.ValueTypeFloat, "300000000000000000000000000000000000000.0")
+	})
+	t.Run("float out of range", func(t *testing.T) {
+		runErr("Float", "1e300", "out of the range of Float")
+		runErr("Float", "-4e38", "out of the range of Float")
+		runErr("Float", "1e-300", "out of the range of Float")
+		runErr("Float", "1e400", "invalid number")
+	})
+	t.Run("string", func(t *testing.T) {
+		run("String", `"a\"b"`, document.ValueTypeString, `a\"b`)
+	})
+	t.Run("boolean", func(t *testing.T) {
+		run("Boolean!", "true", document.ValueTypeBoolean, "true")
+	})
+	t.Run("enum", func(t *testing.T) {
+		run("Status", `"DRAFT"`, document.ValueTypeEnum, "DRAFT")
+	})
+	t.Run("null", func(t *testing.T) {
+		run("Status", `null`, document.ValueTypeNull, "")
+	})
+	t.Run("list", func(t *testing.T) {
+		run("[Int]", `[1]`, document.ValueTypeList, "")
+	})
+	t.Run("custom scalar", func(t *testing.T) {
+		run("JSON", `{"a":1}`, document.ValueTypeObject, "")
+	})
+}
@@ -1,6 +1,10 @@
 package parser
 
-import "testing"
+import (
+	"fmt"
+	"github.com/jensneuse/graphql-go-tools/pkg/document"
+	"testing"
+)
 
 func TestManualAstMod_PutLiteralBytes(t *testing.T) {
 	parser := NewParser()
@@ -280,3 +284,112 @@ func TestManualAstMod_DeleteFieldFromSelectionSet(t *testing.T) {
 		)
 	})
 }
+
+func TestManualAstMod_ReplaceFieldInSelectionSet(t *testing.T) {
+	parser := NewParser()
+	err := parser.ParseExecutableDefinition([]byte(`query q($a: Int, $b: Int) {first second third}`))
+	if err != nil {
+		panic(err)
+	}
+	mod := NewManualAstMod(parser)
+
+	setRef := parser.ParsedDefinitions.OperationDefinitions[0].SelectionSet
+	fields := parser.ParsedDefinitions.SelectionSets[setRef].Fields
+	second := fields[1]
+	replacement := mod.PutField(parser.ParsedDefinitions.Fields[fields[2]])
+
+	mod.ReplaceFieldInSelectionSet(second, replacement, setRef)
+	mod.ReplaceFieldInSelectionSet(-1, second, setRef) // unknown fields are ignored
+
+	fields = parser.ParsedDefinitions.SelectionSets[setRef].Fields
+	if len(fields) != 3 || fields[1] != replacement {
+		panic("want second field replaced")
+	}
+
+	variables := parser.ParsedDefinitions.OperationDefinitions[0].VariableDefinitions
+	first, last := variables[0], variables[1]
+	mod.DeleteVariableDefinitionFromOperation(first, 0)
+	variables = parser.ParsedDefinitions.OperationDefinitions[0].VariableDefinitions
+	if len(variables) != 1 || variables[0] != last {
+		panic("want first variable definition deleted")
+	}
+}
+
+func TestManualAstMod_PutType(t *testing.T) {
+	parser := NewParser()
+	err := parser.ParseExecutableDefinition([]byte(`query q($a: Int) {documents}`))
+	if err != nil {
+		panic(err)
+	}
+	mod := NewManualAstMod(parser)
+
+	ref, err := mod.PutType([]byte("[String!]!"))
+	if err != nil {
+		panic(err)
+	}
+
+	nonNull := parser.ParsedDefinitions.Types[ref]
+	list := parser.ParsedDefinitions.Types[nonNull.OfType]
+	item := parser.ParsedDefinitions.Types[parser.ParsedDefinitions.Types[list.OfType].OfType]
+	if nonNull.Kind != document.TypeKindNON_NULL || list.Kind != document.TypeKindLIST || string(parser.ByteSlice(item.Name)) != "String" {
+		panic(fmt.Errorf("want [String!]!, got: %+v %+v %+v", nonNull, list, item))
+	}
+
+	_, err = mod.PutType([]byte("String String"))
+	if err == nil {
+		panic("want err for trailing input")
+	}
+	_, err = mod.PutType([]byte("[String"))
+	if err == nil {
+		panic("want err for invalid type")
+	}
+
+	variableDefinition := mod.PutVariableDefinition(document.VariableDefinition{
+		Type:         ref,
+		DefaultValue: -1,
+	})
+	mod.AppendVariableDefinitionToOperation(variableDefinition, 0)
+	variables := parser.ParsedDefinitions.OperationDefinitions[0].VariableDefinitions
+	if len(variables) != 2 || variables[1] != variableDefinition {
+		panic("want variable definition appended")
+	}
+}
+
+func TestManualAstMod_SetNames(t *testing.T) {
+	parser := NewParser()
+	err := parser.ParseExecutableDefinition([]byte(`query q {documents(limit: 3) {... on Document {owner}}}`))
+	if err != nil {
+		panic(err)
+	}
+	mod := NewManualAstMod(parser)
+
+	name, _, err := mod.PutLiteralBytes([]byte("allDocuments"))
+	if err != nil {
+		panic(err)
+	}
+
+	setRef := parser.ParsedDefinitions.OperationDefinitions[0].SelectionSet
+	fieldRef := parser.ParsedDefinitions.SelectionSets[setRef].Fields[0]
+	field := parser.ParsedDefinitions.Fields[fieldRef]
+
+	mod.SetFieldAlias(fieldRef, field.Name)
+	mod.SetFieldName(fieldRef, name)
+	mod.SetArgumentName(parser.ParsedDefinitions.ArgumentSets[field.ArgumentSet][0], name)
+
+	inlineFragmentRef := parser.ParsedDefinitions.SelectionSets[field.SelectionSet].InlineFragments[0]
+	typeRef := parser.ParsedDefinitions.InlineFragments[inlineFragmentRef].TypeCondition
+	mod.SetTypeName(typeRef, name)
+
+	field = parser.ParsedDefinitions.Fields[fieldRef]
+	argument := parser.ParsedDefinitions.Arguments[parser.ParsedDefinitions.ArgumentSets[field.ArgumentSet][0]]
+	got := []string{
+		string(parser.ByteSlice(field.Alias)),
+		string(parser.ByteSlice(field.Name)),
+		string(parser.ByteSlice(argument.Name)),
+		string(parser.ByteSlice(parser.ParsedDefinitions.Types[typeRef].Name)),
+	}
+	want := []string{"documents", "allDocuments", "allDocuments", "allDocuments"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		panic(fmt.Errorf("want: %v, got: %v", want, got))
+	}
+}
@@ -287,6 +287,48 @@ func (p *Parser) ExtendTypeSystemDefinition(input []byte) (err error) {
 	return
 }
 
+// ExtendObjectTypeDefinition parses field definitions like "{ name: String }" and adds them to the fields of an object type definition
+// like ExtendTypeSystemDefinition it must be called before parsing the executable definition
+func (p *Parser) ExtendObjectTypeDefinition(objectTypeRef int, fieldsDefinition []byte) (err error) {
+	err = p.l.ExtendTypeSystemInput(fieldsDefinition)
+	if err != nil {
+		return
+	}
+
+	definitions, err := p.parseFieldDefinitions()
+	if err != nil {
+		return
+	}
+
+	if next := p.l.Peek(true); next != keyword.EOF {
+		return fmt.Errorf("ExtendObjectTypeDefinition: unexpected input after fields definition, got: %s", next)
+	}
+
+	// field definitions are linked in reverse order of their declaration
+	// so the existing fields get linked to the last field of the extension
+	existing := p.ParsedDefinitions.ObjectTypeDefinitions[objectTypeRef].FieldsDefinition
+	head := -1
+	if existing.Next(p) {
+		_, head = existing.Value()
+	}
+
+	first, last := -1, -1
+	for definitions.Next(p) {
+		_, last = definitions.Value()
+		if first == -1 {
+			first = last
+		}
+	}
+
+	if first != -1 {
+		p.ParsedDefinitions.FieldDefinitions[last].NextRef = head
+		p.ParsedDefinitions.ObjectTypeDefinitions[objectTypeRef].FieldsDefinition = document.NewFieldDefinitions(first)
+	}
+
+	p.setCacheStats()
+	return
+}
+
 // ParseExecutableDefinition parses an ExecutableDefinition from an io.Reader
 func (p *Parser) ParseExecutableDefinition(input []byte) (err error) {
 	p.resetExecutableCaches()
@@ -1,37 +1,22 @@
 package parser
 
-import "github.com/jensneuse/graphql-go-tools/pkg/introspection"
+import (
+	"bytes"
 
-func (p *Parser) ParseIntrospectionResponse(response *introspection.Response) (err error) {
-
-	/*mod := NewManualAstMod(p)
-
-	p.resetCaches()
-	p.l.ResetTypeSystemInput()
+	"github.com/jensneuse/graphql-go-tools/pkg/introspection"
+)
 
-	if response.Data.Schema.QueryType != nil {
-		ref, _, err := mod.PutLiteralString(response.Data.Schema.QueryType.Name)
-		if err != nil {
-			return err
-		}
-		//mod.SetQueryTypeName(ref)
-	}
+// ParseIntrospectionResponse populates the type system definition from the response of an introspection query
+// all types, directives, descriptions, default values, deprecations, specifiedBy urls and repeatable directives
+// of the response are part of the resulting AST
+// the response gets transformed into the schema definition language first which is then parsed as usual
+func (p *Parser) ParseIntrospectionResponse(response *introspection.Response) (err error) {
 
-	if response.Data.Schema.MutationType != nil {
-		ref, _, err := mod.PutLiteralString(response.Data.Schema.MutationType.Name)
-		if err != nil {
-			return err
-		}
-		//mod.SetMutationTypeName(ref)
+	buff := bytes.Buffer{}
+	err = introspection.WriteSchemaDefinition(&buff, &response.Data.Schema)
+	if err != nil {
+		return err
 	}
 
-	if response.Data.Schema.SubscriptionType != nil {
-		ref, _, err := mod.PutLiteralString(response.Data.Schema.SubscriptionType.Name)
-		if err != nil {
-			return err
-		}
-		mod.SetSubscriptionTypeName(ref)
-	}*/
-
-	return
+	return p.ParseTypeSystemDefinition(buff.Bytes())
 }
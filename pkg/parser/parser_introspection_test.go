@@ -1,63 +1,587 @@
 package parser
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/jensneuse/graphql-go-tools/pkg/document"
+	"github.com/jensneuse/graphql-go-tools/pkg/introspection"
+	"io/ioutil"
+	"strings"
 	"testing"
 )
 
 func TestParser_ParseIntrospectionResponse(t *testing.T) {
 
-	/*	type check func(p *Parser)
+	type check func(p *Parser)
 
-		run := func(response *introspection.Response, checks ...check) {
-			p := NewParser()
-			err := p.ParseIntrospectionResponse(response)
-			if err != nil {
-				panic(err)
+	run := func(response *introspection.Response, checks ...check) {
+		p := NewParser()
+		err := p.ParseIntrospectionResponse(response)
+		if err != nil {
+			panic(err)
+		}
+
+		for i := range checks {
+			checks[i](p)
+		}
+	}
+
+	mustHaveSchema := func(wantQuery, wantMutation, wantSubscription string) check {
+		return func(p *Parser) {
+			if len(p.ParsedDefinitions.SchemaDefinitions) != 1 {
+				panic(fmt.Errorf("mustHaveSchema: want 1 schema definition, got: %d", len(p.ParsedDefinitions.SchemaDefinitions)))
+			}
+			schema := p.ParsedDefinitions.SchemaDefinitions[0]
+			gotQuery := string(p.ByteSlice(schema.Query))
+			if wantQuery != gotQuery {
+				panic(fmt.Errorf("mustHaveSchema: want(query): %s, got: %s", wantQuery, gotQuery))
+			}
+			gotMutation := string(p.ByteSlice(schema.Mutation))
+			if wantMutation != gotMutation {
+				panic(fmt.Errorf("mustHaveSchema: want(mutation): %s, got: %s", wantMutation, gotMutation))
+			}
+			gotSubscription := string(p.ByteSlice(schema.Subscription))
+			if wantSubscription != gotSubscription {
+				panic(fmt.Errorf("mustHaveSchema: want(subscription): %s, got: %s", wantSubscription, gotSubscription))
 			}
+		}
+	}
 
-			for i := range checks {
-				checks[i](p)
+	mustHaveTypeCounts := func(objects, interfaces, unions, enums, inputObjects, scalars, directives int) check {
+		return func(p *Parser) {
+			want := []int{objects, interfaces, unions, enums, inputObjects, scalars, directives}
+			got := []int{
+				len(p.ParsedDefinitions.ObjectTypeDefinitions),
+				len(p.ParsedDefinitions.InterfaceTypeDefinitions),
+				len(p.ParsedDefinitions.UnionTypeDefinitions),
+				len(p.ParsedDefinitions.EnumTypeDefinitions),
+				len(p.ParsedDefinitions.InputObjectTypeDefinitions),
+				len(p.ParsedDefinitions.ScalarTypeDefinitions),
+				len(p.ParsedDefinitions.DirectiveDefinitions),
+			}
+			if fmt.Sprint(want) != fmt.Sprint(got) {
+				panic(fmt.Errorf("mustHaveTypeCounts: want: %v, got: %v", want, got))
 			}
 		}
+	}
 
-		mustHaveSchema := func(wantQuery, wantMutation, wantSubscription string) check {
-			return func(p *Parser) {
-				if wantQuery != "" {
-					gotQuery := string(p.ByteSlice(p.ParsedDefinitions.TypeSystemDefinition.SchemaDefinition.Query))
-					if wantQuery != gotQuery {
-						panic(fmt.Errorf("mustHaveSchema: want(query): %s, got: %s", wantQuery, gotQuery))
-					}
+	mustHaveObjectType := func(name, wantDescription string, wantInterfaces []string, wantFields ...string) check {
+		return func(p *Parser) {
+			for _, object := range p.ParsedDefinitions.ObjectTypeDefinitions {
+				if string(p.ByteSlice(object.Name)) != name {
+					continue
 				}
-				if wantMutation != "" {
-					gotMutation := string(p.ByteSlice(p.ParsedDefinitions.TypeSystemDefinition.SchemaDefinition.Mutation))
-					if wantMutation != gotMutation {
-						panic(fmt.Errorf("mustHaveSchema: want(mutation): %s, got: %s", wantMutation, gotMutation))
-					}
+				if gotDescription := string(p.ByteSlice(object.Description)); !strings.HasPrefix(gotDescription, wantDescription) {
+					panic(fmt.Errorf("mustHaveObjectType: want(description): %s, got: %s", wantDescription, gotDescription))
 				}
-				if wantSubscription != "" {
-					gotSubscription := string(p.ByteSlice(p.ParsedDefinitions.TypeSystemDefinition.SchemaDefinition.Subscription))
-					if wantSubscription != gotSubscription {
-						panic(fmt.Errorf("mustHaveSchema: want(subscription): %s, got: %s", wantSubscription, gotSubscription))
-					}
+				var gotInterfaces []string
+				interfaces := object.ImplementsInterfaces
+				for interfaces.Next(p) {
+					name, _ := interfaces.Value()
+					gotInterfaces = append([]string{string(p.ByteSlice(name))}, gotInterfaces...)
+				}
+				if fmt.Sprint(wantInterfaces) != fmt.Sprint(gotInterfaces) {
+					panic(fmt.Errorf("mustHaveObjectType: want(interfaces): %v, got: %v", wantInterfaces, gotInterfaces))
+				}
+				gotFields := fieldDefinitionsString(p, object.FieldsDefinition)
+				if fmt.Sprint(wantFields) != fmt.Sprint(gotFields) {
+					panic(fmt.Errorf("mustHaveObjectType: want(fields): %v, got: %v", wantFields, gotFields))
+				}
+				return
+			}
+			panic(fmt.Errorf("mustHaveObjectType: type %s not found", name))
+		}
+	}
+
+	mustHaveUnion := func(name string, wantMembers ...string) check {
+		return func(p *Parser) {
+			for _, union := range p.ParsedDefinitions.UnionTypeDefinitions {
+				if string(p.ByteSlice(union.Name)) != name {
+					continue
+				}
+				var gotMembers []string
+				for _, member := range union.UnionMemberTypes {
+					gotMembers = append(gotMembers, string(p.CachedByteSlice(member)))
+				}
+				if fmt.Sprint(wantMembers) != fmt.Sprint(gotMembers) {
+					panic(fmt.Errorf("mustHaveUnion: want(members): %v, got: %v", wantMembers, gotMembers))
+				}
+				return
+			}
+			panic(fmt.Errorf("mustHaveUnion: union %s not found", name))
+		}
+	}
+
+	mustHaveEnum := func(name string, wantValues ...string) check {
+		return func(p *Parser) {
+			for _, enum := range p.ParsedDefinitions.EnumTypeDefinitions {
+				if string(p.ByteSlice(enum.Name)) != name {
+					continue
+				}
+				var gotValues []string
+				values := enum.EnumValuesDefinition
+				for values.Next(p) {
+					value, _ := values.Value()
+					gotValues = append([]string{string(p.ByteSlice(value.EnumValue)) + directivesString(p, value.DirectiveSet)}, gotValues...)
+				}
+				if fmt.Sprint(wantValues) != fmt.Sprint(gotValues) {
+					panic(fmt.Errorf("mustHaveEnum: want(values): %v, got: %v", wantValues, gotValues))
+				}
+				return
+			}
+			panic(fmt.Errorf("mustHaveEnum: enum %s not found", name))
+		}
+	}
+
+	mustHaveInputObject := func(name string, wantFields ...string) check {
+		return func(p *Parser) {
+			for _, inputObject := range p.ParsedDefinitions.InputObjectTypeDefinitions {
+				if string(p.ByteSlice(inputObject.Name)) != name {
+					continue
+				}
+				gotFields := inputValueDefinitionsString(p, p.ParsedDefinitions.InputFieldsDefinitions[inputObject.InputFieldsDefinition].InputValueDefinitions)
+				if fmt.Sprint(wantFields) != fmt.Sprint(gotFields) {
+					panic(fmt.Errorf("mustHaveInputObject: want(fields): %v, got: %v", wantFields, gotFields))
+				}
+				return
+			}
+			panic(fmt.Errorf("mustHaveInputObject: input %s not found", name))
+		}
+	}
+
+	mustHaveDirective := func(name string, wantLocations ...string) check {
+		return func(p *Parser) {
+			for _, directive := range p.ParsedDefinitions.DirectiveDefinitions {
+				if string(p.ByteSlice(directive.Name)) != name {
+					continue
+				}
+				var gotLocations []string
+				for _, location := range directive.DirectiveLocations {
+					gotLocations = append(gotLocations, document.DirectiveLocation(location).String())
 				}
+				if fmt.Sprint(wantLocations) != fmt.Sprint(gotLocations) {
+					panic(fmt.Errorf("mustHaveDirective: want(locations): %v, got: %v", wantLocations, gotLocations))
+				}
+				return
 			}
+			panic(fmt.Errorf("mustHaveDirective: directive %s not found", name))
+		}
+	}
+
+	mustHaveScalar := func(name, wantDirectives string) check {
+		return func(p *Parser) {
+			for _, scalar := range p.ParsedDefinitions.ScalarTypeDefinitions {
+				if string(p.ByteSlice(scalar.Name)) != name {
+					continue
+				}
+				gotDirectives := directivesString(p, scalar.DirectiveSet)
+				if wantDirectives != gotDirectives {
+					panic(fmt.Errorf("mustHaveScalar: want(directives): %s, got: %s", wantDirectives, gotDirectives))
+				}
+				return
+			}
+			panic(fmt.Errorf("mustHaveScalar: scalar %s not found", name))
+		}
+	}
+
+	mustHaveRepeatableDirective := func(name string, want bool) check {
+		return func(p *Parser) {
+			for _, directive := range p.ParsedDefinitions.DirectiveDefinitions {
+				if string(p.ByteSlice(directive.Name)) != name {
+					continue
+				}
+				if want != directive.IsRepeatable {
+					panic(fmt.Errorf("mustHaveRepeatableDirective: want(repeatable): %t, got: %t", want, directive.IsRepeatable))
+				}
+				return
+			}
+			panic(fmt.Errorf("mustHaveRepeatableDirective: directive %s not found", name))
+		}
+	}
+
+	t.Run("swapi", func(t *testing.T) {
+
+		data, err := ioutil.ReadFile("../introspection/testdata/swapi_introspection_response.json")
+		if err != nil {
+			panic(err)
+		}
+
+		var response introspection.Response
+		err = json.Unmarshal(data, &response)
+		if err != nil {
+			panic(err)
+		}
+
+		run(&response,
+			mustHaveSchema("Query", "Mutation", "Subscription"),
+			mustHaveTypeCounts(50, 1, 0, 17, 54, 6, 3),
+			mustHaveObjectType("__Directive", "A Directive provides a way to describe alternate runtime execution", nil,
+				"name: String!",
+				"description: String",
+				"locations: [__DirectiveLocation!]!",
+				"args: [__InputValue!]!",
+				"onOperation: Boolean! @deprecated(reason: Use `locations`.)",
+				"onFragment: Boolean! @deprecated(reason: Use `locations`.)",
+				"onField: Boolean! @deprecated(reason: Use `locations`.)",
+			),
+			mustHaveObjectType("InvokeFunctionPayload", "", nil,
+				"result: String!",
+				"clientMutationId: String",
+			),
+			mustHaveEnum("PERSON_GENDER", "UNKNOWN", "MALE", "FEMALE", "HERMAPHRODITE"),
+			mustHaveInputObject("InvokeFunctionInput",
+				"name: String!",
+				"input: String!",
+				"clientMutationId: String",
+			),
+			mustHaveDirective("include", "FIELD", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"),
+			mustHaveDirective("skip", "FIELD", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"),
+			mustHaveDirective("deprecated", "ENUM_VALUE", "FIELD_DEFINITION"),
+		)
+	})
+	t.Run("default values", func(t *testing.T) {
+		data, err := ioutil.ReadFile("../introspection/testdata/swapi_introspection_response.json")
+		if err != nil {
+			panic(err)
 		}
 
-		t.Run("simple schema", func(t *testing.T) {
-			run(&introspection.Response{
-				Data: introspection.Data{
-					Schema: introspection.Schema{
-						QueryType: &introspection.TypeName{
+		var response introspection.Response
+		err = json.Unmarshal(data, &response)
+		if err != nil {
+			panic(err)
+		}
+
+		run(&response,
+			func(p *Parser) {
+				for _, object := range p.ParsedDefinitions.ObjectTypeDefinitions {
+					if string(p.ByteSlice(object.Name)) != "__Type" {
+						continue
+					}
+					fields := object.FieldsDefinition
+					for fields.Next(p) {
+						field, _ := fields.Value()
+						if string(p.ByteSlice(field.Name)) != "fields" {
+							continue
+						}
+						args := p.ParsedDefinitions.ArgumentsDefinitions[field.ArgumentsDefinition].InputValueDefinitions
+						got := inputValueDefinitionsString(p, args)
+						want := []string{"includeDeprecated: Boolean = false"}
+						if fmt.Sprint(want) != fmt.Sprint(got) {
+							panic(fmt.Errorf("want: %v, got: %v", want, got))
+						}
+						return
+					}
+				}
+				panic(fmt.Errorf("__Type.fields not found"))
+			},
+		)
+	})
+	t.Run("unions, interfaces and deprecations", func(t *testing.T) {
+		run(&introspection.Response{
+			Data: introspection.Data{
+				Schema: introspection.Schema{
+					QueryType: &introspection.TypeName{
+						Name: "Query",
+					},
+					Types: []introspection.FullType{
+						{
+							Kind: introspection.OBJECT,
 							Name: "Query",
+							Fields: []introspection.Field{
+								{
+									Name: "search",
+									Args: []introspection.InputValue{
+										{
+											Name:         "term",
+											Type:         nonNullTypeRef(introspection.TypeRef{Kind: introspection.SCALAR, Name: stringPointer("String")}),
+											DefaultValue: stringPointer(`"foo"`),
+										},
+									},
+									Type: listTypeRef(introspection.TypeRef{Kind: introspection.UNION, Name: stringPointer("SearchResult")}),
+								},
+								{
+									Name:              "oldSearch",
+									Type:              introspection.TypeRef{Kind: introspection.UNION, Name: stringPointer("SearchResult")},
+									IsDeprecated:      true,
+									DeprecationReason: `use "search" instead`,
+								},
+							},
+						},
+						{
+							Kind:        introspection.OBJECT,
+							Name:        "User",
+							Description: "a \"user\"\nof the system",
+							Interfaces: []introspection.TypeRef{
+								introspection.TypeRef{Kind: introspection.INTERFACE, Name: stringPointer("Node")},
+								introspection.TypeRef{Kind: introspection.INTERFACE, Name: stringPointer("Named")},
+							},
+							Fields: []introspection.Field{
+								{
+									Name: "id",
+									Type: nonNullTypeRef(introspection.TypeRef{Kind: introspection.SCALAR, Name: stringPointer("ID")}),
+								},
+							},
+						},
+						{
+							Kind: introspection.UNION,
+							Name: "SearchResult",
+							PossibleTypes: []introspection.TypeRef{
+								introspection.TypeRef{Kind: introspection.OBJECT, Name: stringPointer("User")},
+								introspection.TypeRef{Kind: introspection.OBJECT, Name: stringPointer("Query")},
+							},
+						},
+						{
+							Kind: introspection.ENUM,
+							Name: "Role",
+							EnumValues: []introspection.EnumValue{
+								{
+									Name: "ADMIN",
+								},
+								{
+									Name:         "ROOT",
+									IsDeprecated: true,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+			mustHaveSchema("Query", "", ""),
+			mustHaveTypeCounts(2, 0, 1, 1, 0, 0, 0),
+			mustHaveObjectType("Query", "", nil,
+				`search(term: String! = "foo"): [SearchResult]`,
+				`oldSearch: SearchResult @deprecated(reason: use \"search\" instead)`,
+			),
+			mustHaveObjectType("User", `a \"user\"\nof the system`, []string{"Node", "Named"},
+				"id: ID!",
+			),
+			mustHaveUnion("SearchResult", "User", "Query"),
+			mustHaveEnum("Role", "ADMIN", "ROOT @deprecated"),
+		)
+	})
+	t.Run("october 2021", func(t *testing.T) {
+
+		data, err := ioutil.ReadFile("../introspection/testdata/october2021_introspection_response.json")
+		if err != nil {
+			panic(err)
+		}
+
+		var response introspection.Response
+		err = introspection.Decode(bytes.NewReader(data), &response)
+		if err != nil {
+			panic(err)
+		}
+
+		run(&response,
+			mustHaveSchema("Query", "", ""),
+			mustHaveTypeCounts(2, 0, 0, 0, 0, 3, 3),
+			mustHaveObjectType("Query", "", nil,
+				"events(after: DateTime, since: DateTime @deprecated(reason: Use `after`.)): [Event!]!",
+			),
+			mustHaveScalar("DateTime", " @specifiedBy(url: https://tools.ietf.org/html/rfc3339)"),
+			mustHaveDirective("tag", "FIELD_DEFINITION", "OBJECT", "VARIABLE_DEFINITION"),
+			mustHaveRepeatableDirective("tag", true),
+		)
+	})
+	t.Run("legacy encoding", func(t *testing.T) {
+
+		data, err := ioutil.ReadFile("../introspection/testdata/legacy_introspection_response.json")
+		if err != nil {
+			panic(err)
+		}
+
+		var response introspection.Response
+		err = introspection.Decode(bytes.NewReader(data), &response)
+		if err != nil {
+			panic(err)
+		}
+
+		run(&response,
+			mustHaveObjectType("Query", "", nil,
+				"hero(episode: Episode): String",
+				"droid: String @deprecated(reason: use hero)",
+			),
+			mustHaveEnum("Episode", "NEWHOPE", "JEDI @deprecated(reason: No longer supported)"),
+		)
+	})
+	t.Run("specifiedBy, repeatable and deprecated arguments", func(t *testing.T) {
+		run(&introspection.Response{
+			Data: introspection.Data{
+				Schema: introspection.Schema{
+					QueryType: &introspection.TypeName{
+						Name: "Query",
+					},
+					Types: []introspection.FullType{
+						{
+							Kind: introspection.OBJECT,
+							Name: "Query",
+							Fields: []introspection.Field{
+								{
+									Name: "events",
+									Args: []introspection.InputValue{
+										{
+											Name:              "since",
+											Type:              introspection.TypeRef{Kind: introspection.SCALAR, Name: stringPointer("DateTime")},
+											IsDeprecated:      true,
+											DeprecationReason: "use after",
+										},
+									},
+									Type: introspection.TypeRef{Kind: introspection.SCALAR, Name: stringPointer("DateTime")},
+								},
+							},
+						},
+						{
+							Kind:           introspection.SCALAR,
+							Name:           "DateTime",
+							SpecifiedByURL: stringPointer("https://tools.ietf.org/html/rfc3339"),
+						},
+					},
+					Directives: []introspection.Directive{
+						{
+							Name:         "tag",
+							Locations:    []string{"FIELD_DEFINITION", "VARIABLE_DEFINITION"},
+							IsRepeatable: true,
 						},
-						MutationType: &introspection.TypeName{
-							Name: "Mutation",
+						{
+							Name:      "specifiedBy",
+							Locations: []string{"SCALAR"},
 						},
-						SubscriptionType: &introspection.TypeName{
-							Name: "Subscription",
+					},
+				},
+			},
+		},
+			mustHaveObjectType("Query", "", nil,
+				`events(since: DateTime @deprecated(reason: use after)): DateTime`,
+			),
+			mustHaveScalar("DateTime", " @specifiedBy(url: https://tools.ietf.org/html/rfc3339)"),
+			mustHaveDirective("tag", "FIELD_DEFINITION", "VARIABLE_DEFINITION"),
+			mustHaveRepeatableDirective("tag", true),
+			mustHaveRepeatableDirective("specifiedBy", false),
+		)
+	})
+	t.Run("missing query type", func(t *testing.T) {
+		p := NewParser()
+		err := p.ParseIntrospectionResponse(&introspection.Response{})
+		if err == nil {
+			panic("want err")
+		}
+	})
+	t.Run("missing name of named type", func(t *testing.T) {
+		p := NewParser()
+		err := p.ParseIntrospectionResponse(&introspection.Response{
+			Data: introspection.Data{
+				Schema: introspection.Schema{
+					QueryType: &introspection.TypeName{
+						Name: "Query",
+					},
+					Types: []introspection.FullType{
+						{
+							Kind: introspection.OBJECT,
+							Name: "Query",
+							Fields: []introspection.Field{
+								{
+									Name: "foo",
+									Type: introspection.TypeRef{Kind: introspection.SCALAR},
+								},
+							},
 						},
 					},
 				},
-			}, mustHaveSchema("Query", "Mutation", "Subscription"))
-		})*/
+			},
+		})
+		if err == nil {
+			panic("want err")
+		}
+	})
+}
+
+// fieldDefinitionsString returns the fields in declaration order
+func fieldDefinitionsString(p *Parser, fields document.FieldDefinitions) (out []string) {
+	for fields.Next(p) {
+		field, _ := fields.Value()
+		var args string
+		if field.ArgumentsDefinition != -1 {
+			args = "(" + strings.Join(inputValueDefinitionsString(p, p.ParsedDefinitions.ArgumentsDefinitions[field.ArgumentsDefinition].InputValueDefinitions), ", ") + ")"
+		}
+		out = append([]string{string(p.ByteSlice(field.Name)) + args + ": " + typeString(p, field.Type) + directivesString(p, field.DirectiveSet)}, out...)
+	}
+	return
+}
+
+// inputValueDefinitionsString returns the input values in declaration order
+func inputValueDefinitionsString(p *Parser, inputValues document.InputValueDefinitions) (out []string) {
+	for inputValues.Next(p) {
+		inputValue, _ := inputValues.Value()
+		var defaultValue string
+		if inputValue.DefaultValue != -1 {
+			value := p.ParsedDefinitions.Values[inputValue.DefaultValue]
+			defaultValue = " = " + string(p.ByteSlice(value.Raw))
+			if value.ValueType == document.ValueTypeString {
+				defaultValue = ` = "` + string(p.ByteSlice(value.Raw)) + `"`
+			}
+		}
+		out = append([]string{string(p.ByteSlice(inputValue.Name)) + ": " + typeString(p, inputValue.Type) + defaultValue + directivesString(p, inputValue.DirectiveSet)}, out...)
+	}
+	return
+}
+
+func typeString(p *Parser, ref int) string {
+	graphqlType := p.ParsedDefinitions.Types[ref]
+	switch graphqlType.Kind {
+	case document.TypeKindNON_NULL:
+		return typeString(p, graphqlType.OfType) + "!"
+	case document.TypeKindLIST:
+		return "[" + typeString(p, graphqlType.OfType) + "]"
+	default:
+		return string(p.ByteSlice(graphqlType.Name))
+	}
+}
+
+func directivesString(p *Parser, ref int) (out string) {
+	if ref == -1 {
+		return
+	}
+	for _, directiveRef := range p.ParsedDefinitions.DirectiveSets[ref] {
+		directive := p.ParsedDefinitions.Directives[directiveRef]
+		out += " @" + string(p.ByteSlice(directive.Name))
+		if directive.ArgumentSet == -1 {
+			continue
+		}
+		for _, argumentRef := range p.ParsedDefinitions.ArgumentSets[directive.ArgumentSet] {
+			argument := p.ParsedDefinitions.Arguments[argumentRef]
+			out += "(" + string(p.ByteSlice(argument.Name)) + ": " + string(p.ByteSlice(p.ParsedDefinitions.Values[argument.Value].Raw)) + ")"
+		}
+	}
+	return
+}
+
+func nonNullTypeRef(ofType introspection.TypeRef) introspection.TypeRef {
+	return introspection.TypeRef{
+		Kind:   introspection.NON_NULL,
+		OfType: &ofType,
+	}
+}
+
+func listTypeRef(ofType introspection.TypeRef) introspection.TypeRef {
+	return introspection.TypeRef{
+		Kind:   introspection.LIST,
+		OfType: &ofType,
+	}
+}
+
+func stringPointer(s string) *string {
+	return &s
+}
+
+func TestParser_ParseIntrospectionQuery(t *testing.T) {
+	p := NewParser()
+	err := p.ParseExecutableDefinition([]byte(introspection.Query))
+	if err != nil {
+		panic(err)
+	}
+	if len(p.ParsedDefinitions.OperationDefinitions) != 1 || len(p.ParsedDefinitions.FragmentDefinitions) != 3 {
+		panic(fmt.Errorf("want 1 operation and 3 fragments, got: %d, %d", len(p.ParsedDefinitions.OperationDefinitions), len(p.ParsedDefinitions.FragmentDefinitions)))
+	}
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/jensneuse/diffview"
 	"github.com/jensneuse/graphql-go-tools/pkg/document"
+	"github.com/jensneuse/graphql-go-tools/pkg/introspection"
 	"github.com/jensneuse/graphql-go-tools/pkg/lexing/position"
 	"github.com/sebdah/goldie"
 	"io/ioutil"
@@ -15,7 +16,7 @@ import (
 func TestParser(t *testing.T) {
 	t.Run("newErrInvalidType", func(t *testing.T) {
 		want := "parser:a:invalidType - expected 'b', got 'c' @ 1:3-2:4"
-		got := newErrInvalidType(position.Position{1, 2, 3, 4}, "a", "b", "c").Error() // nolint
+		got := newErrInvalidType(position.Position{LineStart: 1, LineEnd: 2, CharStart: 3, CharEnd: 4}, "a", "b", "c").Error() // nolint
 
 		if want != got {
 			t.Fatalf("newErrInvalidType: \nwant: %s\ngot: %s", want, got)
@@ -170,21 +171,15 @@ func TestParser_Starwars(t *testing.T) {
 
 func TestParser_IntrospectionQuery(t *testing.T) {
 
-	inputFileName := "./testdata/introspectionquery.graphql"
 	fixtureFileName := "type_system_definition_parsed_introspection"
 
-	inputFileData, err := ioutil.ReadFile(inputFileName)
-	if err != nil {
-		t.Fatal(err)
-	}
-
 	parser := NewParser()
-	err = parser.ParseExecutableDefinition(inputFileData)
+	err := parser.ParseExecutableDefinition([]byte(introspection.Query))
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	err = parser.ParseExecutableDefinition(inputFileData)
+	err = parser.ParseExecutableDefinition([]byte(introspection.Query))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -224,10 +219,7 @@ func BenchmarkParser(b *testing.B) {
 		b.Fatal(err)
 	}
 
-	queryData, err := ioutil.ReadFile("./testdata/introspectionquery.graphql")
-	if err != nil {
-		b.Fatal(err)
-	}
+	queryData := []byte(introspection.Query)
 
 	b.ResetTimer()
 	b.ReportAllocs()
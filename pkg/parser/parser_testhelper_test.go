@@ -171,6 +171,15 @@ func hasDirectiveLocations(locations ...document.DirectiveLocation) rule {
 	}
 }
 
+func isRepeatable(want bool) rule {
+	return func(node document.Node, parser *Parser, ruleIndex, ruleSetIndex int) {
+		got := node.(document.DirectiveDefinition).IsRepeatable
+		if want != got {
+			panic(fmt.Errorf("isRepeatable: want: %t, got: %t [rule: %d, node: %d]", want, got, ruleIndex, ruleSetIndex))
+		}
+	}
+}
+
 func unwrapObjectField(node document.Node, parser *Parser) document.Node {
 	objectField, ok := node.(document.ObjectField)
 	if ok {
@@ -1028,6 +1037,18 @@ func mustExtendTypeSystemDefinition(extension string, rules ruleSet) checkFunc {
 	}
 }
 
+func mustExtendObjectTypeDefinition(objectTypeRef int, fieldsDefinition string, rules ruleSet) checkFunc {
+	return func(parser *Parser, i int) {
+
+		err := parser.ExtendObjectTypeDefinition(objectTypeRef, []byte(fieldsDefinition))
+		if err != nil {
+			panic(err)
+		}
+
+		evalRules(nil, parser, rules, i)
+	}
+}
+
 func mustParseSchemaDefinition(rules ...rule) checkFunc {
 	return func(parser *Parser, i int) {
 
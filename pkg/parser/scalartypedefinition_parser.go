@@ -19,7 +19,7 @@ func (p *Parser) parseScalarTypeDefinition(hasDescription, isExtend bool, descri
 
 	definition := p.makeScalarTypeDefinition()
 	definition.Name = scalar.Literal
-	definition.IsExtend = true
+	definition.IsExtend = isExtend
 
 	if hasDescription {
 		definition.Position.MergeStartIntoStart(description.TextPosition)
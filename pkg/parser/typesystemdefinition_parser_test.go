@@ -413,6 +413,58 @@ func TestParser_parseTypeSystemDefinition(t *testing.T) {
 			))),
 		)
 	})
+	t.Run("extend object type with fields", func(t *testing.T) {
+		run(`type Query { name: String }`,
+			mustParseTypeSystemDefinition(
+				node(),
+			),
+			mustExtendObjectTypeDefinition(0, `{ age: Int __schema: __Schema! }`, node(
+				hasObjectTypeSystemDefinitions(
+					node(
+						hasName("Query"),
+						hasFieldsDefinitions(
+							node(
+								hasName("__schema"),
+							),
+							node(
+								hasName("age"),
+							),
+							node(
+								hasName("name"),
+							),
+						),
+					),
+				),
+			)),
+		)
+	})
+	t.Run("extend object type without fields", func(t *testing.T) {
+		run(`type Query`,
+			mustParseTypeSystemDefinition(
+				node(),
+			),
+			mustExtendObjectTypeDefinition(0, `{ age: Int }`, node(
+				hasObjectTypeSystemDefinitions(
+					node(
+						hasName("Query"),
+						hasFieldsDefinitions(
+							node(
+								hasName("age"),
+							),
+						),
+					),
+				),
+			)),
+		)
+	})
+	t.Run("extend object type with invalid fields should fail", func(t *testing.T) {
+		run(`type Query { name: String }`,
+			mustParseTypeSystemDefinition(
+				node(),
+			),
+			mustPanic(mustExtendObjectTypeDefinition(0, `{ age: Int } scalar JSON`, node())),
+		)
+	})
 	t.Run("extend after setting executable definition should fail reverse", func(t *testing.T) {
 		run(`schema {}`,
 			mustParseTypeSystemDefinition(
@@ -34,6 +34,9 @@ func (p *Parser) parseUnionTypeDefinition(hasDescription, isExtend bool, descrip
 	}
 
 	shouldParseMembers := p.peekExpect(keyword.EQUALS, true)
+	if shouldParseMembers {
+		p.peekExpect(keyword.PIPE, true) // optional leading pipe
+	}
 
 	for shouldParseMembers {
 
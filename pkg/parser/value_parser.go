@@ -47,7 +47,7 @@ func (p *Parser) parseValue() (ref int, err error) {
 		err = p.parsePeekedObjectValue(&value.Reference)
 	default:
 		invalidToken := p.l.Read()
-		err = newErrInvalidType(invalidToken.TextPosition, "parseValue", fmt.Sprintf("%v", parseValuePossibleKeywords), string(invalidToken.Keyword))
+		err = newErrInvalidType(invalidToken.TextPosition, "parseValue", fmt.Sprintf("%v", parseValuePossibleKeywords), invalidToken.Keyword.String())
 		return
 	}
 
@@ -0,0 +1,70 @@
+package persistedquery
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+// LRU is an in-memory store which keeps a limited number of queries, the least recently used query is evicted first
+type LRU struct {
+	capacity int
+	mux      sync.Mutex
+	entries  map[string]*list.Element
+	order    *list.List
+}
+
+type lruEntry struct {
+	hash  string
+	query string
+}
+
+// NewLRU returns a store for up to capacity queries
+func NewLRU(capacity int) *LRU {
+	return &LRU{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element, capacity),
+		order:    list.New(),
+	}
+}
+
+func (l *LRU) Get(ctx context.Context, hash string) (query string, ok bool, err error) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	element, ok := l.entries[hash]
+	if !ok {
+		return "", false, nil
+	}
+
+	l.order.MoveToFront(element)
+	return element.Value.(*lruEntry).query, true, nil
+}
+
+func (l *LRU) Put(ctx context.Context, hash, query string) error {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	if element, ok := l.entries[hash]; ok {
+		element.Value.(*lruEntry).query = query
+		l.order.MoveToFront(element)
+		return nil
+	}
+
+	l.entries[hash] = l.order.PushFront(&lruEntry{hash: hash, query: query})
+
+	for l.order.Len() > l.capacity {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.entries, oldest.Value.(*lruEntry).hash)
+	}
+
+	return nil
+}
+
+// Len returns the number of stored queries
+func (l *LRU) Len() int {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	return l.order.Len()
+}
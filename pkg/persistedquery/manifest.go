@@ -0,0 +1,88 @@
+package persistedquery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+)
+
+// Manifest is a read only store of known queries, e.g. the allow list of the operations of the production clients
+type Manifest struct {
+	queries map[string]string
+}
+
+// apolloManifest is the format of the persisted query manifest generated by Apollo tooling:
+// {"format":"apollo-persisted-query-manifest","version":1,"operations":[{"id":"<sha256>","name":"...","type":"query","body":"..."}]}
+type apolloManifest struct {
+	Format     string `json:"format"`
+	Operations []struct {
+		ID   string `json:"id"`
+		Body string `json:"body"`
+	} `json:"operations"`
+}
+
+func NewManifest() *Manifest {
+	return &Manifest{
+		queries: map[string]string{},
+	}
+}
+
+// Add adds a query to the manifest and returns its hash
+func (m *Manifest) Add(query string) string {
+	hash := Hash(query)
+	m.queries[hash] = query
+	return hash
+}
+
+// ParseManifest parses an Apollo persisted query manifest or a json object of queries keyed by their hash
+// the hashes are verified so that the manifest can't contain queries under a wrong hash
+func ParseManifest(data []byte) (*Manifest, error) {
+
+	var apollo apolloManifest
+	err := json.Unmarshal(data, &apollo)
+	if err != nil {
+		return nil, fmt.Errorf("ParseManifest: %s", err.Error())
+	}
+
+	var queries map[string]string
+	if apollo.Format == "apollo-persisted-query-manifest" {
+		queries = make(map[string]string, len(apollo.Operations))
+		for _, operation := range apollo.Operations {
+			queries[operation.ID] = operation.Body
+		}
+	} else {
+		err = json.Unmarshal(data, &queries)
+		if err != nil {
+			return nil, fmt.Errorf("ParseManifest: %s", err.Error())
+		}
+	}
+
+	manifest := NewManifest()
+	for hash, query := range queries {
+		if manifest.Add(query) != hash {
+			return nil, fmt.Errorf("ParseManifest: hash '%s' doesn't match its query", hash)
+		}
+	}
+
+	return manifest, nil
+}
+
+// LoadManifestFile reads a manifest file, see ParseManifest
+func LoadManifestFile(path string) (*Manifest, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseManifest(data)
+}
+
+func (m *Manifest) Get(ctx context.Context, hash string) (query string, ok bool, err error) {
+	query, ok = m.queries[hash]
+	return query, ok, nil
+}
+
+// Put returns ErrReadOnly, queries must be added before the manifest is used
+func (m *Manifest) Put(ctx context.Context, hash, query string) error {
+	return ErrReadOnly
+}
@@ -0,0 +1,65 @@
+// Package persistedquery implements stores for persisted queries, e.g. Apollo automatic persisted queries (APQ)
+// queries are identified by the hex encoded sha256 hash of the query, see Hash
+package persistedquery
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+
+	"github.com/jensneuse/graphql-go-tools/pkg/graphqlerror"
+)
+
+// Store stores persisted queries by their hash
+type Store interface {
+	// Get returns the query of the hash, ok is false if the hash is unknown
+	Get(ctx context.Context, hash string) (query string, ok bool, err error)
+	// Put stores the query of the hash, read only stores return ErrReadOnly
+	Put(ctx context.Context, hash, query string) error
+}
+
+// ErrReadOnly is returned by stores which don't allow to register queries, e.g. the Manifest
+var ErrReadOnly = errors.New("persistedquery: store is read only")
+
+// Hash returns the hex encoded sha256 hash of a query
+func Hash(query string) string {
+	sum := sha256.Sum256([]byte(query))
+	return hex.EncodeToString(sum[:])
+}
+
+// Error is an error which is answered with a graphql response
+// the messages and codes of ErrNotFound and ErrNotSupported are the ones expected by Apollo clients
+type Error struct {
+	Message string
+	Code    string
+	Status  int
+}
+
+var (
+	// ErrNotFound tells the client to send the query together with its hash
+	ErrNotFound = Error{Message: "PersistedQueryNotFound", Code: "PERSISTED_QUERY_NOT_FOUND", Status: http.StatusOK}
+	// ErrNotSupported tells the client to stop sending hashes
+	ErrNotSupported = Error{Message: "PersistedQueryNotSupported", Code: "PERSISTED_QUERY_NOT_SUPPORTED", Status: http.StatusOK}
+	// ErrHashMismatch rejects queries which don't match the hash they're sent with
+	ErrHashMismatch = Error{Message: "provided sha does not match query", Code: "PERSISTED_QUERY_HASH_MISMATCH", Status: http.StatusBadRequest}
+	// ErrUnsupportedVersion rejects persisted queries of a version other than 1
+	ErrUnsupportedVersion = Error{Message: "unsupported persisted query version", Code: "PERSISTED_QUERY_UNSUPPORTED_VERSION", Status: http.StatusBadRequest}
+	// ErrNotAllowed rejects queries which aren't part of the allow list
+	ErrNotAllowed = Error{Message: "query is not allowed", Code: "PERSISTED_QUERY_NOT_ALLOWED", Status: http.StatusForbidden}
+)
+
+func (e Error) Error() string {
+	return "persistedquery: " + e.Message
+}
+
+func (e Error) StatusCode() int {
+	return e.Status
+}
+
+// Response returns the graphql response for the error, e.g.:
+// {"errors":[{"message":"PersistedQueryNotFound","extensions":{"code":"PERSISTED_QUERY_NOT_FOUND"}}]}
+func (e Error) Response() []byte {
+	return graphqlerror.Response(graphqlerror.Error{Message: e.Message, Extensions: graphqlerror.Extensions{Code: e.Code}})
+}
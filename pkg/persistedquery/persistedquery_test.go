@@ -0,0 +1,127 @@
+package persistedquery
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHash(t *testing.T) {
+	got := Hash("{__typename}")
+	want := "ecf4edb46db40b5132295c0291d62fb65d6759a9eedfa4d5d612dd5ec54a6b38"
+	if got != want {
+		panic(fmt.Errorf("want: %s, got: %s", want, got))
+	}
+}
+
+func TestLRU(t *testing.T) {
+
+	ctx := context.Background()
+	lru := NewLRU(2)
+
+	get := func(hash, want string, wantOk bool) {
+		query, ok, err := lru.Get(ctx, hash)
+		if err != nil {
+			panic(err)
+		}
+		if ok != wantOk || query != want {
+			panic(fmt.Errorf("want: '%s' (%t) for hash %s, got: '%s' (%t)", want, wantOk, hash, query, ok))
+		}
+	}
+
+	_ = lru.Put(ctx, "a", "{a}")
+	_ = lru.Put(ctx, "b", "{b}")
+	get("a", "{a}", true)
+
+	// b is the least recently used query
+	_ = lru.Put(ctx, "c", "{c}")
+	get("b", "", false)
+	get("a", "{a}", true)
+	get("c", "{c}", true)
+
+	_ = lru.Put(ctx, "a", "{updated}")
+	get("a", "{updated}", true)
+
+	if lru.Len() != 2 {
+		panic(fmt.Errorf("want 2 queries, got: %d", lru.Len()))
+	}
+}
+
+func TestManifest(t *testing.T) {
+
+	ctx := context.Background()
+	query := "query documents {documents {owner}}"
+	hash := Hash(query)
+
+	get := func(manifest *Manifest) {
+		got, ok, err := manifest.Get(ctx, hash)
+		if err != nil {
+			panic(err)
+		}
+		if !ok || got != query {
+			panic(fmt.Errorf("want query for hash %s, got: '%s' (%t)", hash, got, ok))
+		}
+		if _, ok, _ := manifest.Get(ctx, Hash("{__typename}")); ok {
+			panic("want unknown query")
+		}
+		if manifest.Put(ctx, Hash("{__typename}"), "{__typename}") != ErrReadOnly {
+			panic("want ErrReadOnly")
+		}
+	}
+
+	t.Run("apollo manifest", func(t *testing.T) {
+		manifest, err := ParseManifest([]byte(fmt.Sprintf(`{"format":"apollo-persisted-query-manifest","version":1,"operations":[{"id":"%s","name":"documents","type":"query","body":"%s"}]}`, hash, query)))
+		if err != nil {
+			panic(err)
+		}
+		get(manifest)
+	})
+	t.Run("queries keyed by hash", func(t *testing.T) {
+		manifest, err := ParseManifest([]byte(fmt.Sprintf(`{"%s":"%s"}`, hash, query)))
+		if err != nil {
+			panic(err)
+		}
+		get(manifest)
+	})
+	t.Run("file", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "persistedquery")
+		if err != nil {
+			panic(err)
+		}
+		defer os.RemoveAll(dir)
+		path := filepath.Join(dir, "manifest.json")
+		err = ioutil.WriteFile(path, []byte(fmt.Sprintf(`{"%s":"%s"}`, hash, query)), 0644)
+		if err != nil {
+			panic(err)
+		}
+		manifest, err := LoadManifestFile(path)
+		if err != nil {
+			panic(err)
+		}
+		get(manifest)
+	})
+	t.Run("added queries", func(t *testing.T) {
+		manifest := NewManifest()
+		if manifest.Add(query) != hash {
+			panic("want hash of the query")
+		}
+		get(manifest)
+	})
+	t.Run("wrong hash", func(t *testing.T) {
+		_, err := ParseManifest([]byte(fmt.Sprintf(`{"%s":"{__typename}"}`, hash)))
+		if err == nil {
+			panic("want err for wrong hash")
+		}
+	})
+}
+
+func TestError_Response(t *testing.T) {
+	got := string(ErrNotFound.Response())
+	want := `{"errors":[{"message":"PersistedQueryNotFound","extensions":{"code":"PERSISTED_QUERY_NOT_FOUND"}}]}`
+	if got != want {
+		panic(fmt.Errorf("want: %s, got: %s", want, got))
+	}
+}
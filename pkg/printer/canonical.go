@@ -0,0 +1,55 @@
+package printer
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/jensneuse/graphql-go-tools/pkg/lookup"
+	"github.com/jensneuse/graphql-go-tools/pkg/parser"
+)
+
+// Normalize prints a schema in the canonical style, two schemas which only differ in the order of
+// their definitions, fields, arguments, enum values, directives or in their formatting result in the same output
+// descriptions and comments get omitted if stripDescriptions is set
+func Normalize(schema []byte, stripDescriptions bool) ([]byte, error) {
+
+	p := parser.NewParser()
+	err := p.ParseTypeSystemDefinition(schema)
+	if err != nil {
+		return nil, fmt.Errorf("Normalize: %s", err)
+	}
+
+	l := lookup.New(p)
+	w := lookup.NewWalker(1024, 8)
+	w.SetLookup(l)
+	w.WalkTypeSystemDefinition()
+
+	style := CanonicalStyle()
+	style.StripDescriptions = stripDescriptions
+
+	printer := New()
+	printer.SetStyle(style)
+	printer.SetInput(p, l, w)
+
+	out := bytes.Buffer{}
+	err = printer.PrintTypeSystemDefinition(&out)
+	if err != nil {
+		return nil, fmt.Errorf("Normalize: %s", err)
+	}
+
+	return out.Bytes(), nil
+}
+
+// Fingerprint returns the hex encoded sha256 hash of the normalized schema
+// it's stable across formatting changes and reordering, e.g. to use it as part of a cache key
+func Fingerprint(schema []byte, stripDescriptions bool) (string, error) {
+
+	normalized, err := Normalize(schema, stripDescriptions)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.Sum256(normalized)
+	return hex.EncodeToString(hash[:]), nil
+}
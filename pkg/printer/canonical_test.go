@@ -0,0 +1,169 @@
+package printer
+
+import (
+	"fmt"
+	"io/ioutil"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+
+	run := func(schema string, stripDescriptions bool, want string) {
+		got, err := Normalize([]byte(schema), stripDescriptions)
+		if err != nil {
+			panic(err)
+		}
+		if want != string(got) {
+			panic(fmt.Errorf("want:\n%s\ngot:\n%s", want, string(got)))
+		}
+	}
+
+	t.Run("sorted", func(t *testing.T) {
+		run(`
+directive @b on FIELD_DEFINITION
+directive @a(y: Int, x: Int) on FIELD_DEFINITION | OBJECT
+union Result = Query | Document
+"the root type"
+type Query implements Node & Entity @b @a(y: 1, x: 2) {
+	documents(status: Status = PUBLISHED, filter: Filter = {owner: "me", limit: 10}): [Document]
+	"the id"
+	id: ID!
+}
+schema { query: Query }
+enum Status { PUBLISHED DRAFT }
+input Filter { owner: String limit: Int }
+`, false, `schema {
+	query: Query
+}
+
+input Filter {
+	limit: Int
+	owner: String
+}
+
+"the root type"
+type Query implements Entity & Node @a(x: 2 y: 1) @b {
+	documents(filter: Filter = {limit: 10, owner: "me"} status: Status = PUBLISHED): [Document]
+	"the id"
+	id: ID!
+}
+
+union Result = Document | Query
+
+enum Status {
+	DRAFT
+	PUBLISHED
+}
+
+directive @a(
+	x: Int
+	y: Int
+) on FIELD_DEFINITION | OBJECT
+
+directive @b on FIELD_DEFINITION
+`)
+	})
+	t.Run("strip descriptions", func(t *testing.T) {
+		run(`
+# a comment
+"""
+the root type
+"""
+type Query {
+	"a field"
+	field("an argument" arg: Int): String
+}
+enum Status {
+	"drafted"
+	DRAFT
+}`, true, `type Query {
+	field(arg: Int): String
+}
+
+enum Status {
+	DRAFT
+}
+`)
+	})
+}
+
+func TestFingerprint(t *testing.T) {
+
+	fingerprint := func(schema string, stripDescriptions bool) string {
+		hash, err := Fingerprint([]byte(schema), stripDescriptions)
+		if err != nil {
+			panic(err)
+		}
+		return hash
+	}
+
+	runEqual := func(left, right string, stripDescriptions bool) {
+		if fingerprint(left, stripDescriptions) != fingerprint(right, stripDescriptions) {
+			panic(fmt.Errorf("want equal fingerprints for:\n%s\nand:\n%s", left, right))
+		}
+	}
+
+	runDifferent := func(left, right string, stripDescriptions bool) {
+		if fingerprint(left, stripDescriptions) == fingerprint(right, stripDescriptions) {
+			panic(fmt.Errorf("want different fingerprints for:\n%s\nand:\n%s", left, right))
+		}
+	}
+
+	t.Run("hex encoded sha256", func(t *testing.T) {
+		hash := fingerprint(`type Query { field: String }`, false)
+		if len(hash) != 64 {
+			panic(fmt.Errorf("want 64 hex characters, got: %s", hash))
+		}
+	})
+	t.Run("formatting", func(t *testing.T) {
+		runEqual(`type Query { field(a: Int, b: [String!]! = ["x", "y"]): String }`, `
+type Query {
+	field(
+		a: Int
+		b: [String!]! = ["x" "y"]
+	): String
+}`, false)
+	})
+	t.Run("order", func(t *testing.T) {
+		runEqual(`
+type Query @b @a { a: String b(y: Int x: Int): Int }
+enum E { A B }
+union U = Query | Other
+type Other { c: String }`, `
+type Other { c: String }
+union U = Other | Query
+enum E { B A }
+type Query @a @b { b(x: Int y: Int): Int a: String }`, false)
+	})
+	t.Run("descriptions", func(t *testing.T) {
+		runDifferent(`"a" type Query { field: String }`, `"b" type Query { field: String }`, false)
+		runEqual(`"a" type Query { field: String }`, `"b" type Query { field: String }`, true)
+		runEqual(`"""
+			a "quoted" description
+		""" type Query { field: String }`, `"a \"quoted\" description" type Query { field: String }`, false)
+	})
+	t.Run("changes", func(t *testing.T) {
+		runDifferent(`type Query { field: String }`, `type Query { field: String! }`, false)
+		runDifferent(`type Query { field: String }`, `type Query { field: String other: Int }`, false)
+		runDifferent(`type Query { field(a: Int = 1): String }`, `type Query { field(a: Int = 2): String }`, false)
+		runDifferent(`type Query { field: String @a }`, `type Query { field: String }`, false)
+		runDifferent(`type Query { field: String }`, `extend type Query { field: String }`, false)
+	})
+	t.Run("big schema", func(t *testing.T) {
+		schema, err := ioutil.ReadFile("../parser/testdata/big_schema.graphql")
+		if err != nil {
+			panic(err)
+		}
+		normalized, err := Normalize(schema, false)
+		if err != nil {
+			panic(err)
+		}
+		runEqual(string(schema), string(normalized), false)
+	})
+	t.Run("invalid schema", func(t *testing.T) {
+		_, err := Fingerprint([]byte(`type Query {`), false)
+		if err == nil {
+			panic("want err")
+		}
+	})
+}
@@ -0,0 +1,76 @@
+package printer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/jensneuse/graphql-go-tools/pkg/document"
+	"github.com/jensneuse/graphql-go-tools/pkg/lexing/literal"
+	"io"
+)
+
+// PrintValueJSON prints a value as json, e.g. to move an inline argument into the variables of a request
+// enums get printed as strings, values containing variables can't be printed
+func (p *Printer) PrintValueJSON(out io.Writer, ref int) error {
+	p.out = out
+	p.err = nil
+	p.printValueJSON(ref)
+	return p.err
+}
+
+func (p *Printer) printValueJSON(ref int) {
+
+	if p.err != nil {
+		return
+	}
+
+	value := p.l.Value(ref)
+
+	switch value.ValueType {
+	case document.ValueTypeBoolean, document.ValueTypeInt, document.ValueTypeFloat:
+		p.write(p.p.ByteSlice(value.Raw))
+	case document.ValueTypeNull:
+		p.write(literal.NULL)
+	case document.ValueTypeString:
+		raw := p.p.ByteSlice(value.Raw)
+		p.printJSONString(descriptionValue(raw, isBlockString(raw)))
+	case document.ValueTypeEnum:
+		p.printJSONString(p.p.ByteSlice(value.Raw))
+	case document.ValueTypeVariable:
+		p.err = fmt.Errorf("PrintValueJSON: variable '$%s' can't be printed as json", string(p.p.ByteSlice(value.Raw)))
+	case document.ValueTypeObject:
+		p.write(literal.CURLYBRACKETOPEN)
+		for i, fieldRef := range p.l.ObjectValue(value.Reference) {
+			if i != 0 {
+				p.write(literal.COMMA)
+			}
+			field := p.l.ObjectField(fieldRef)
+			p.printJSONString(p.p.ByteSlice(field.Name))
+			p.write(literal.COLON)
+			p.printValueJSON(field.Value)
+		}
+		p.write(literal.CURLYBRACKETCLOSE)
+	case document.ValueTypeList:
+		p.write(literal.SQUAREBRACKETOPEN)
+		for i, itemRef := range p.l.ListValue(value.Reference) {
+			if i != 0 {
+				p.write(literal.COMMA)
+			}
+			p.printValueJSON(itemRef)
+		}
+		p.write(literal.SQUAREBRACKETCLOSE)
+	default:
+		p.err = fmt.Errorf("PrintValueJSON: unsupported value type %s", value.ValueType)
+	}
+}
+
+func (p *Printer) printJSONString(value []byte) {
+	buff := bytes.Buffer{}
+	encoder := json.NewEncoder(&buff)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(string(value)); err != nil {
+		p.err = err
+		return
+	}
+	p.write(bytes.TrimSuffix(buff.Bytes(), literal.LINETERMINATOR))
+}
@@ -0,0 +1,272 @@
+package printer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/jensneuse/graphql-go-tools/pkg/lookup"
+	"github.com/jensneuse/graphql-go-tools/pkg/parser"
+	"reflect"
+	"testing"
+)
+
+func TestPrinter_PrintValueJSON(t *testing.T) {
+
+	// parse parses the schema and a query containing the argument,
+	// it returns the value of the argument and the type of its definition
+	parse := func(argument string) (*parser.Parser, *Printer, int, int) {
+
+		p := parser.NewParser()
+		err := p.ParseTypeSystemDefinition([]byte(jsonValueSchema))
+		if err != nil {
+			panic(err)
+		}
+		err = p.ParseExecutableDefinition([]byte(fmt.Sprintf("{field(%s)}", argument)))
+		if err != nil {
+			panic(err)
+		}
+
+		l := lookup.New(p)
+		w := lookup.NewWalker(1024, 8)
+		w.SetLookup(l)
+		w.WalkExecutable()
+
+		printer := New()
+		printer.SetInput(p, l, w)
+
+		arg := p.ParsedDefinitions.Arguments[p.ParsedDefinitions.ArgumentSets[p.ParsedDefinitions.Fields[0].ArgumentSet][0]]
+
+		fieldDefinitions := p.ParsedDefinitions.ObjectTypeDefinitions[0].FieldsDefinition
+		for fieldDefinitions.Next(p) {
+			fieldDefinition, _ := fieldDefinitions.Value()
+			inputValues := p.ParsedDefinitions.ArgumentsDefinitions[fieldDefinition.ArgumentsDefinition].InputValueDefinitions
+			for inputValues.Next(p) {
+				inputValue, _ := inputValues.Value()
+				if bytes.Equal(p.ByteSlice(inputValue.Name), p.ByteSlice(arg.Name)) {
+					return p, printer, arg.Value, inputValue.Type
+				}
+			}
+		}
+
+		panic(fmt.Errorf("no definition for argument: %s", argument))
+	}
+
+	printJSON := func(printer *Printer, ref int) string {
+		out := bytes.Buffer{}
+		err := printer.PrintValueJSON(&out, ref)
+		if err != nil {
+			panic(err)
+		}
+		return out.String()
+	}
+
+	printQuery := func(printer *Printer) string {
+		out := bytes.Buffer{}
+		err := printer.PrintExecutableSchema(&out)
+		if err != nil {
+			panic(err)
+		}
+		return out.String()
+	}
+
+	// run asserts that the argument gets printed as wantJSON
+	// and that converting wantJSON back results in an argument printed as wantArgument
+	run := func(argument, wantJSON, wantArgument string) {
+
+		p, printer, valueRef, typeRef := parse(argument)
+
+		gotJSON := printJSON(printer, valueRef)
+		if wantJSON != gotJSON {
+			panic(fmt.Errorf("want json:\n%s\ngot:\n%s", wantJSON, gotJSON))
+		}
+
+		convertedRef, err := parser.NewManualAstMod(p).PutJSONValue([]byte(gotJSON), typeRef)
+		if err != nil {
+			panic(err)
+		}
+
+		// object fields are sorted by name after the conversion, so the round tripped json is compared decoded
+		roundTripped := printJSON(printer, convertedRef)
+		if !jsonEqual(wantJSON, roundTripped) {
+			panic(fmt.Errorf("want round tripped json:\n%s\ngot:\n%s", wantJSON, roundTripped))
+		}
+
+		p.ParsedDefinitions.Arguments[p.ParsedDefinitions.ArgumentSets[p.ParsedDefinitions.Fields[0].ArgumentSet][0]].Value = convertedRef
+		want := fmt.Sprintf("{field(%s)}", wantArgument)
+		got := printQuery(printer)
+		if want != got {
+			panic(fmt.Errorf("want argument:\n%s\ngot:\n%s", want, got))
+		}
+	}
+
+	runErr := func(argument string) {
+		_, printer, valueRef, _ := parse(argument)
+		err := printer.PrintValueJSON(&bytes.Buffer{}, valueRef)
+		if err == nil {
+			panic(fmt.Errorf("want err for argument: %s", argument))
+		}
+	}
+
+	runPutErr := func(argument, json string) {
+		p, _, _, typeRef := parse(argument)
+		_, err := parser.NewManualAstMod(p).PutJSONValue([]byte(json), typeRef)
+		if err == nil {
+			panic(fmt.Errorf("want err for json: %s", json))
+		}
+	}
+
+	t.Run("int", func(t *testing.T) {
+		run(`integer: 42`, `42`, `integer:42`)
+		run(`integer: -7`, `-7`, `integer:-7`)
+	})
+	t.Run("float", func(t *testing.T) {
+		run(`float: 1.5`, `1.5`, `float:1.5`)
+		run(`float: -0.25`, `-0.25`, `float:-0.25`)
+		run(`float: 2`, `2`, `float:2`)
+	})
+	t.Run("string", func(t *testing.T) {
+		run(`text: "foo"`, `"foo"`, `text:"foo"`)
+		run(`text: ""`, `""`, `text:""`)
+	})
+	t.Run("string with escapes", func(t *testing.T) {
+		run(`text: "a \"quoted\" \\ string\nwith a line break and ä"`, `"a \"quoted\" \\ string\nwith a line break and ä"`, `text:"a \"quoted\" \\ string\nwith a line break and ä"`)
+		run(`text: "<html> & 'quotes' \t tab"`, `"<html> & 'quotes' \t tab"`, `text:"<html> & 'quotes' \t tab"`)
+	})
+	t.Run("block string", func(t *testing.T) {
+		run("text: \"\"\"\n\t\tblock \"quoted\"\n\t\t  string\n\t\"\"\"", `"block \"quoted\"\n  string"`, `text:"block \"quoted\"\n  string"`)
+	})
+	t.Run("boolean", func(t *testing.T) {
+		run(`flag: true`, `true`, `flag:true`)
+		run(`flag: false`, `false`, `flag:false`)
+	})
+	t.Run("id", func(t *testing.T) {
+		run(`id: "abc"`, `"abc"`, `id:"abc"`)
+		run(`id: 123`, `123`, `id:123`)
+	})
+	t.Run("null", func(t *testing.T) {
+		run(`text: null`, `null`, `text:null`)
+		run(`filter: null`, `null`, `filter:null`)
+	})
+	t.Run("enum", func(t *testing.T) {
+		run(`status: PUBLISHED`, `"PUBLISHED"`, `status:PUBLISHED`)
+		run(`status: EXTENDED`, `"EXTENDED"`, `status:EXTENDED`)
+	})
+	t.Run("lists", func(t *testing.T) {
+		run(`statuses: [DRAFT, PUBLISHED]`, `["DRAFT","PUBLISHED"]`, `statuses:[DRAFT,PUBLISHED]`)
+		run(`statuses: []`, `[]`, `statuses:[]`)
+		run(`matrix: [[1, 2], [3], null]`, `[[1,2],[3],null]`, `matrix:[[1,2],[3],null]`)
+	})
+	t.Run("input objects", func(t *testing.T) {
+		run(`filter: {status: DRAFT, limit: 10}`, `{"status":"DRAFT","limit":10}`, `filter:{limit:10,status:DRAFT}`)
+		run(`filter: {status: DRAFT, nested: {text: "foo", statuses: [PUBLISHED]}}`,
+			`{"status":"DRAFT","nested":{"text":"foo","statuses":["PUBLISHED"]}}`,
+			`filter:{nested:{statuses:[PUBLISHED],text:"foo"},status:DRAFT}`)
+		run(`filters: [{status: DRAFT}, {status: PUBLISHED, ratio: 0.5}]`, `[{"status":"DRAFT"},{"status":"PUBLISHED","ratio":0.5}]`,
+			`filters:[{status:DRAFT},{ratio:0.5,status:PUBLISHED}]`)
+	})
+	t.Run("custom scalar", func(t *testing.T) {
+		run(`json: {a: [1, 2.5, "b", true, null], c: {d: ENUM}}`, `{"a":[1,2.5,"b",true,null],"c":{"d":"ENUM"}}`, `json:{a:[1,2.5,"b",true,null],c:{d:"ENUM"}}`)
+	})
+	t.Run("variables can't be printed as json", func(t *testing.T) {
+		runErr(`integer: $var`)
+		runErr(`filter: {status: $status}`)
+	})
+	t.Run("json coercion", func(t *testing.T) {
+
+		convert := func(argument, json, want string) {
+			p, printer, _, typeRef := parse(argument)
+			ref, err := parser.NewManualAstMod(p).PutJSONValue([]byte(json), typeRef)
+			if err != nil {
+				panic(err)
+			}
+			p.ParsedDefinitions.Arguments[p.ParsedDefinitions.ArgumentSets[p.ParsedDefinitions.Fields[0].ArgumentSet][0]].Value = ref
+			got := printQuery(printer)
+			if want != got {
+				panic(fmt.Errorf("want:\n%s\ngot:\n%s", want, got))
+			}
+		}
+
+		convert(`statuses: []`, `"DRAFT"`, `{field(statuses:[DRAFT])}`)
+		convert(`integer: 1`, `1.0`, `{field(integer:1)}`)
+		convert(`float: 1`, `1e3`, `{field(float:1000)}`)
+		convert(`float: 1`, `1.5e-3`, `{field(float:0.0015)}`)
+		convert(`float: 1`, `1e12`, `{field(float:1000000000000.0)}`)
+		convert(`integer: 1`, `2147483647`, `{field(integer:2147483647)}`)
+		convert(`json: 1`, `{"z":1,"a":{"b":[]}}`, `{field(json:{a:{b:[]},z:1})}`)
+	})
+	t.Run("invalid json", func(t *testing.T) {
+		runPutErr(`integer: 1`, `"1"`)
+		runPutErr(`integer: 1`, `1.5`)
+		runPutErr(`integer: 1`, `2147483648`)
+		runPutErr(`float: 1`, `true`)
+		runPutErr(`text: ""`, `1`)
+		runPutErr(`flag: true`, `"true"`)
+		runPutErr(`id: 1`, `1.5`)
+		runPutErr(`status: DRAFT`, `"UNKNOWN"`)
+		runPutErr(`status: DRAFT`, `1`)
+		runPutErr(`required: 1`, `null`)
+		runPutErr(`statuses: []`, `[null]`)
+		runPutErr(`filter: {}`, `{"unknown":1}`)
+		runPutErr(`filter: {}`, `[]`)
+		runPutErr(`filter: {}`, `{"nested":{}}`)
+		runPutErr(`json: 1`, `{"invalid-name":1}`)
+		runPutErr(`json: 1`, `{`)
+	})
+}
+
+func jsonEqual(left, right string) bool {
+	var leftValue, rightValue interface{}
+	if err := json.Unmarshal([]byte(left), &leftValue); err != nil {
+		panic(err)
+	}
+	if err := json.Unmarshal([]byte(right), &rightValue); err != nil {
+		panic(err)
+	}
+	return reflect.DeepEqual(leftValue, rightValue)
+}
+
+const jsonValueSchema = `
+schema {
+	query: Query
+}
+
+type Query {
+	field(
+		integer: Int
+		required: Int!
+		float: Float
+		text: String
+		flag: Boolean
+		id: ID
+		status: Status
+		statuses: [Status!]
+		matrix: [[Int]]
+		filter: Filter
+		filters: [Filter]
+		json: JSON
+	): String
+}
+
+enum Status {
+	DRAFT
+	PUBLISHED
+}
+
+extend enum Status {
+	EXTENDED
+}
+
+input Filter {
+	status: Status!
+	limit: Int = 10
+	ratio: Float
+	nested: Nested
+}
+
+input Nested {
+	text: String!
+	statuses: [Status]
+}
+
+scalar JSON
+`
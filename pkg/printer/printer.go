@@ -4,22 +4,66 @@ import (
 	"bytes"
 	"github.com/jensneuse/graphql-go-tools/pkg/document"
 	"github.com/jensneuse/graphql-go-tools/pkg/lexing/literal"
+	"github.com/jensneuse/graphql-go-tools/pkg/lexing/position"
 	"github.com/jensneuse/graphql-go-tools/pkg/lookup"
 	"github.com/jensneuse/graphql-go-tools/pkg/parser"
 	"github.com/jensneuse/graphql-go-tools/pkg/transform"
 	"io"
+	"sort"
+	"unicode/utf8"
 )
 
 type Printer struct {
-	l   *lookup.Lookup
-	w   *lookup.Walker
-	p   *parser.Parser
-	out io.Writer
-	err error
+	l     *lookup.Lookup
+	w     *lookup.Walker
+	p     *parser.Parser
+	out   io.Writer
+	err   error
+	style Style
+	// indent is written once per indentation level
+	indent []byte
+	// pretty is set according to the layout of the document kind currently printed
+	pretty bool
+	level  int
+	// column is the width of the current line, it's only tracked if lines get wrapped
+	column    int
+	scratch   bytes.Buffer
+	rootNodes []rootNode
+	// executable is set while printing executable definitions, these are always printed in source order
+	executable bool
+	// comments of the executable definition and the index of the next one to print
+	comments    []Comment
+	nextComment int
 }
 
+// Comment is a comment of an executable definition
+// the parser drops comments of executable definitions, use SetComments to print them anyway
+type Comment struct {
+	// Position is the position of the comment in the document
+	Position position.Position
+	// Text is the comment including the leading '#', consecutive comment lines are a single comment
+	Text []byte
+}
+
+type rootNode struct {
+	ref      int
+	kind     lookup.NodeKind
+	position position.Position
+}
+
+type listKind int
+
+const (
+	// argumentList are arguments, arguments definitions and variable definitions
+	argumentList listKind = iota
+	// valueList are list and object values
+	valueList
+)
+
 func New() *Printer {
-	return &Printer{}
+	printer := &Printer{}
+	printer.SetStyle(DefaultStyle())
+	return printer
 }
 
 func (p *Printer) SetInput(parser *parser.Parser, l *lookup.Lookup, w *lookup.Walker) {
@@ -27,6 +71,24 @@ func (p *Printer) SetInput(parser *parser.Parser, l *lookup.Lookup, w *lookup.Wa
 	p.l = l
 	p.w = w
 	p.err = nil
+	p.comments = nil
+}
+
+// SetComments sets the comments of the executable definition of the input, they must be in source order
+// each comment is printed on its own line before the operation, fragment or selection following it,
+// comments at the end of a selection set or the document are printed at its end
+func (p *Printer) SetComments(comments []Comment) {
+	p.comments = comments
+}
+
+// SetStyle configures how the following documents get printed
+func (p *Printer) SetStyle(style Style) {
+	p.style = style
+	if style.IndentWidth > 0 {
+		p.indent = bytes.Repeat(literal.SPACE, style.IndentWidth)
+	} else {
+		p.indent = literal.TAB
+	}
 }
 
 func (p *Printer) write(bytes []byte) {
@@ -34,126 +96,455 @@ func (p *Printer) write(bytes []byte) {
 		return
 	}
 	_, p.err = p.out.Write(bytes)
+	if p.style.MaxLineWidth > 0 {
+		p.trackColumn(bytes)
+	}
+}
+
+func (p *Printer) trackColumn(data []byte) {
+	if i := bytes.LastIndexByte(data, '\n'); i != -1 {
+		p.column = 0
+		data = data[i+1:]
+	}
+	p.column += utf8.RuneCount(data) + bytes.Count(data, literal.TAB)*(tabWidth-1)
+}
+
+// fits reports whether the output of print fits into the current line
+func (p *Printer) fits(print func()) bool {
+	if p.style.MaxLineWidth <= 0 {
+		return true
+	}
+
+	out, column, err := p.out, p.column, p.err
+	p.scratch.Reset()
+	p.out = &p.scratch
+	print()
+	fits := p.err == nil && !bytes.Contains(p.scratch.Bytes(), literal.LINETERMINATOR) && p.column <= p.style.MaxLineWidth
+	p.out, p.column, p.err = out, column, err
+
+	return fits
+}
+
+// newLine starts a new line indented by the current level
+func (p *Printer) newLine() {
+	p.write(literal.LINETERMINATOR)
+	for i := 0; i < p.level; i++ {
+		p.write(p.indent)
+	}
+}
+
+// optionalSpace writes a space in pretty documents only
+func (p *Printer) optionalSpace() {
+	if p.pretty {
+		p.write(literal.SPACE)
+	}
+}
+
+func (p *Printer) withComma(kind listKind) bool {
+	switch p.style.Comma {
+	case CommaSeparate, CommaTrailing:
+		return true
+	case CommaNone:
+		return false
+	default:
+		return kind == valueList
+	}
+}
+
+// writeSeparator separates two items of a list printed on a single line
+func (p *Printer) writeSeparator(kind listKind) {
+	if !p.withComma(kind) {
+		p.write(literal.SPACE)
+		return
+	}
+	p.write(literal.COMMA)
+	p.optionalSpace()
+}
+
+// writeLineSeparator terminates an item of a list printed one item per line
+func (p *Printer) writeLineSeparator(kind listKind, last bool) {
+	if p.style.Comma == CommaTrailing || (!last && p.withComma(kind)) {
+		p.write(literal.COMMA)
+	}
+}
+
+// printList prints count items enclosed by open and close, on a single line or one item per line if wrap is set
+func (p *Printer) printList(kind listKind, count int, open, close []byte, wrap bool, print func(i int)) {
+	p.write(open)
+	if wrap {
+		p.level++
+	}
+	for i := 0; i < count; i++ {
+		if wrap {
+			p.newLine()
+		} else if i != 0 {
+			p.writeSeparator(kind)
+		}
+		print(i)
+		if wrap {
+			p.writeLineSeparator(kind, i == count-1)
+		}
+	}
+	if wrap {
+		p.level--
+		p.newLine()
+	}
+	p.write(close)
+}
+
+// printBlock prints count items enclosed by curly brackets, one item per line in pretty documents
+func (p *Printer) printBlock(count int, print func(i int)) {
+	p.write(literal.CURLYBRACKETOPEN)
+	p.level++
+	for i := 0; i < count; i++ {
+		if p.pretty {
+			p.newLine()
+		} else if i != 0 {
+			p.write(literal.SPACE)
+		}
+		print(i)
+	}
+	p.level--
+	if p.pretty {
+		p.newLine()
+	}
+	p.write(literal.CURLYBRACKETCLOSE)
 }
 
 func (p *Printer) PrintTypeSystemDefinition(out io.Writer) error {
 
 	p.out = out
+	p.pretty = p.style.TypeSystemDefinition != LayoutMinified
+	p.executable = false
+	p.level = 0
+	p.column = 0
 
-	rootNodes := p.w.TypeSystemDefinitionOrderedRootNodes()
-	var addDoubleLineTerminator bool
-	for rootNodes.Next() {
+	for i, node := range p.typeSystemDefinitionRootNodes() {
 
-		if addDoubleLineTerminator {
-			p.write(literal.LINETERMINATOR)
+		if i != 0 {
 			p.write(literal.LINETERMINATOR)
+			if p.pretty {
+				p.write(literal.LINETERMINATOR)
+			}
 		}
 
-		ref, kind := rootNodes.Value()
-		switch kind {
+		switch node.kind {
 		case lookup.SCHEMA:
-			p.PrintSchemaDefinition(ref)
+			p.PrintSchemaDefinition(node.ref)
 		case lookup.OBJECT_TYPE_DEFINITION:
-			p.PrintObjectTypeDefinition(ref)
+			p.PrintObjectTypeDefinition(node.ref)
 		case lookup.ENUM_TYPE_DEFINITION:
-			p.PrintEnumTypeDefinition(ref)
+			p.PrintEnumTypeDefinition(node.ref)
 		case lookup.DIRECTIVE_DEFINITION:
-			p.PrintDirectiveDefinition(ref)
+			p.PrintDirectiveDefinition(node.ref)
 		case lookup.INTERFACE_TYPE_DEFINITION:
-			p.PrintInterfaceTypeDefinition(ref)
+			p.PrintInterfaceTypeDefinition(node.ref)
 		case lookup.SCALAR_TYPE_DEFINITION:
-			p.PrintScalarTypeDefinition(ref)
+			p.PrintScalarTypeDefinition(node.ref)
 		case lookup.UNION_TYPE_DEFINITION:
-			p.PrintUnionTypeDefinition(ref)
+			p.PrintUnionTypeDefinition(node.ref)
 		case lookup.INPUT_OBJECT_TYPE_DEFINITION:
-			p.PrintInputObjectTypeDefinition(ref)
+			p.PrintInputObjectTypeDefinition(node.ref)
 		}
-
-		addDoubleLineTerminator = true
 	}
 
 	p.write(literal.LINETERMINATOR)
 	return p.err
 }
 
+func (p *Printer) typeSystemDefinitionRootNodes() []rootNode {
+
+	p.rootNodes = p.rootNodes[:0]
+	nodes := p.w.TypeSystemDefinitionOrderedRootNodes()
+	for nodes.Next() {
+		ref, kind := nodes.Value()
+		p.rootNodes = append(p.rootNodes, rootNode{ref: ref, kind: kind})
+	}
+
+	if p.style.Order != OrderSorted {
+		return p.rootNodes
+	}
+
+	rank := func(node rootNode) int {
+		switch node.kind {
+		case lookup.SCHEMA:
+			return 0
+		case lookup.DIRECTIVE_DEFINITION:
+			return 2
+		default:
+			return 1
+		}
+	}
+
+	sort.SliceStable(p.rootNodes, func(i, j int) bool {
+		left, right := p.rootNodes[i], p.rootNodes[j]
+		if rank(left) != rank(right) {
+			return rank(left) < rank(right)
+		}
+		return bytes.Compare(p.rootNodeName(left), p.rootNodeName(right)) == -1
+	})
+
+	return p.rootNodes
+}
+
+func (p *Printer) rootNodeName(node rootNode) []byte {
+	definitions := &p.p.ParsedDefinitions
+	switch node.kind {
+	case lookup.OBJECT_TYPE_DEFINITION:
+		return p.p.ByteSlice(definitions.ObjectTypeDefinitions[node.ref].Name)
+	case lookup.ENUM_TYPE_DEFINITION:
+		return p.p.ByteSlice(definitions.EnumTypeDefinitions[node.ref].Name)
+	case lookup.DIRECTIVE_DEFINITION:
+		return p.p.ByteSlice(definitions.DirectiveDefinitions[node.ref].Name)
+	case lookup.INTERFACE_TYPE_DEFINITION:
+		return p.p.ByteSlice(definitions.InterfaceTypeDefinitions[node.ref].Name)
+	case lookup.SCALAR_TYPE_DEFINITION:
+		return p.p.ByteSlice(definitions.ScalarTypeDefinitions[node.ref].Name)
+	case lookup.UNION_TYPE_DEFINITION:
+		return p.p.ByteSlice(definitions.UnionTypeDefinitions[node.ref].Name)
+	case lookup.INPUT_OBJECT_TYPE_DEFINITION:
+		return p.p.ByteSlice(definitions.InputObjectTypeDefinitions[node.ref].Name)
+	default:
+		return nil
+	}
+}
+
+// order brings refs collected from a linked list of the AST into the order of the style
+func (p *Printer) order(refs []int, name func(ref int) []byte) {
+	switch p.style.Order {
+	case OrderSorted:
+		sort.SliceStable(refs, func(i, j int) bool {
+			return bytes.Compare(name(refs[i]), name(refs[j])) == -1
+		})
+	default:
+		// linked lists of the AST are in reverse declaration order
+		for i, j := 0, len(refs)-1; i < j; i, j = i+1, j-1 {
+			refs[i], refs[j] = refs[j], refs[i]
+		}
+	}
+}
+
+func (p *Printer) fieldDefinitions(fields document.FieldDefinitions) (refs []int) {
+	for fields.Next(p.l) {
+		_, ref := fields.Value()
+		refs = append(refs, ref)
+	}
+	p.order(refs, func(ref int) []byte {
+		return p.p.ByteSlice(p.p.ParsedDefinitions.FieldDefinitions[ref].Name)
+	})
+	return
+}
+
+func (p *Printer) inputValueDefinitions(inputValues document.InputValueDefinitions) (refs []int) {
+	for inputValues.Next(p.p) {
+		_, ref := inputValues.Value()
+		refs = append(refs, ref)
+	}
+	p.order(refs, func(ref int) []byte {
+		return p.p.ByteSlice(p.p.ParsedDefinitions.InputValueDefinitions[ref].Name)
+	})
+	return
+}
+
+func (p *Printer) enumValueDefinitions(enumValues document.EnumValueDefinitions) (refs []int) {
+	for enumValues.Next(p.p) {
+		_, ref := enumValues.Value()
+		refs = append(refs, ref)
+	}
+	p.order(refs, func(ref int) []byte {
+		return p.p.ByteSlice(p.p.ParsedDefinitions.EnumValuesDefinitions[ref].EnumValue)
+	})
+	return
+}
+
+func (p *Printer) printExtend(isExtend bool) {
+	if isExtend {
+		p.write(literal.EXTEND)
+		p.write(literal.SPACE)
+	}
+}
+
 func (p *Printer) PrintSchemaDefinition(index int) {
 	definition := p.p.ParsedDefinitions.SchemaDefinitions[index]
-	p.write(literal.SCHEMA)
-	p.write(literal.SPACE)
-	p.write(literal.CURLYBRACKETOPEN)
-	if definition.Query.Length() != 0 {
-		p.write(literal.LINETERMINATOR)
-		p.write(literal.TAB)
-		p.PrintSimpleField(literal.QUERY, definition.Query)
-	}
-	if definition.Mutation.Length() != 0 {
-		p.write(literal.LINETERMINATOR)
-		p.write(literal.TAB)
-		p.PrintSimpleField(literal.MUTATION, definition.Mutation)
+
+	var names [3][]byte
+	var values [3]document.ByteSliceReference
+	count := 0
+	for _, operationType := range []struct {
+		name  []byte
+		value document.ByteSliceReference
+	}{
+		{literal.QUERY, definition.Query},
+		{literal.MUTATION, definition.Mutation},
+		{literal.SUBSCRIPTION, definition.Subscription},
+	} {
+		if operationType.value.Length() == 0 {
+			continue
+		}
+		names[count], values[count] = operationType.name, operationType.value
+		count++
 	}
-	if definition.Subscription.Length() != 0 {
-		p.write(literal.LINETERMINATOR)
-		p.write(literal.TAB)
-		p.PrintSimpleField(literal.SUBSCRIPTION, definition.Subscription)
+
+	p.printExtend(definition.IsExtend)
+	p.write(literal.SCHEMA)
+	if definition.DirectiveSet != -1 {
+		p.write(literal.SPACE)
+		p.printDirectiveSet(definition.DirectiveSet)
 	}
-	p.write(literal.LINETERMINATOR)
-	p.write(literal.CURLYBRACKETCLOSE)
+	p.optionalSpace()
+	p.printBlock(count, func(i int) {
+		p.PrintSimpleField(names[i], values[i])
+	})
 }
 
 func (p *Printer) PrintSimpleField(name []byte, value document.ByteSliceReference) {
 	p.write(name)
 	p.write(literal.COLON)
-	p.write(literal.SPACE)
+	p.optionalSpace()
 	p.write(p.p.ByteSlice(value))
 }
 
-func (p *Printer) PrintDescription(ref document.ByteSliceReference, linePrefix ...[]byte) {
-	if ref.Length() == 0 {
+// PrintDescription prints a description followed by a line break
+// or a space if the description belongs to a definition printed on a single line
+func (p *Printer) PrintDescription(ref document.ByteSliceReference) {
+	p.printDescription(ref, !p.pretty)
+}
+
+func (p *Printer) printDescription(ref document.ByteSliceReference, inline bool) {
+	if ref.Length() == 0 || p.style.StripDescriptions {
 		return
 	}
-	description := p.p.ByteSlice(ref)
-	multiLine := bytes.Contains(description, literal.LINETERMINATOR)
-	if !multiLine {
-		for _, prefix := range linePrefix {
-			p.write(prefix)
-		}
+
+	raw := p.p.ByteSlice(ref)
+	if bytes.HasPrefix(raw, literal.COMMENT) {
+		p.printComment(raw)
+		return
+	}
+
+	blockString := isBlockString(raw)
+	multiLine := bytes.Contains(raw, literal.LINETERMINATOR)
+
+	switch {
+	case inline || p.style.Description == DescriptionSingleLine:
 		p.write(literal.QUOTE)
-		p.write(description)
+		p.write(transform.EscapeString(descriptionValue(raw, blockString)))
 		p.write(literal.QUOTE)
-		p.write(literal.LINETERMINATOR)
+	case p.style.Description == DescriptionBlock:
+		p.printBlockDescription(descriptionValue(raw, blockString))
+	case multiLine:
+		p.printBlockDescription(descriptionValue(raw, blockString))
+	case blockString:
+		p.printBlockString(raw)
+	default:
+		p.write(literal.QUOTE)
+		p.write(raw)
+		p.write(literal.QUOTE)
+	}
+
+	if inline {
+		p.write(literal.SPACE)
 		return
 	}
-	description = transform.TrimWhitespace(description)
-	for _, prefix := range linePrefix {
-		p.write(prefix)
+
+	p.newLine()
+}
+
+// printBlockDescription prints the value of a description as block string indented by the current level
+// a value starting with whitespace starts on the line of the opening quotes as the indentation of the first line would get removed otherwise
+func (p *Printer) printBlockDescription(value []byte) {
+	p.write(literal.QUOTE)
+	p.write(literal.QUOTE)
+	p.write(literal.QUOTE)
+	for i, line := range bytes.Split(value, literal.LINETERMINATOR) {
+		if len(line) == 0 {
+			p.write(literal.LINETERMINATOR)
+			continue
+		}
+		if i != 0 || (line[0] != ' ' && line[0] != '\t') {
+			p.newLine()
+		}
+		p.write(bytes.Replace(line, []byte(`"""`), []byte(`\"""`), -1))
 	}
+	p.newLine()
 	p.write(literal.QUOTE)
 	p.write(literal.QUOTE)
 	p.write(literal.QUOTE)
-	p.write(literal.LINETERMINATOR)
-	for _, prefix := range linePrefix {
-		p.write(prefix)
+}
+
+// printComment prints the lines of a comment the parser stored as a description
+// a comment always ends its line, even in minified documents
+func (p *Printer) printComment(raw []byte) {
+	p.writeComment(raw)
+	if p.pretty {
+		p.newLine()
+		return
 	}
-	p.write(description)
 	p.write(literal.LINETERMINATOR)
-	for _, prefix := range linePrefix {
-		p.write(prefix)
+}
+
+// writeComment writes the lines of a comment without ending the last one
+func (p *Printer) writeComment(raw []byte) {
+	for i, line := range bytes.Split(raw, literal.LINETERMINATOR) {
+		if i != 0 {
+			p.newLine()
+		}
+		p.write(bytes.TrimSpace(line))
 	}
+}
+
+// printBlockString prints the raw content of a block string on a single line
+func (p *Printer) printBlockString(raw []byte) {
+	p.write(literal.QUOTE)
+	p.write(literal.QUOTE)
+	p.write(literal.QUOTE)
+	p.write(raw)
 	p.write(literal.QUOTE)
 	p.write(literal.QUOTE)
 	p.write(literal.QUOTE)
-	p.write(literal.LINETERMINATOR)
+}
+
+// isBlockString reports whether raw string content can only stem from a block string
+// strings can neither span multiple lines nor contain unescaped quotes
+func isBlockString(raw []byte) bool {
+	for i := 0; i < len(raw); i++ {
+		switch raw[i] {
+		case '\\':
+			i++
+		case '"', '\n':
+			return true
+		}
+	}
+	return false
+}
+
+// descriptionValue returns the value of a description as it was written in the document
+func descriptionValue(raw []byte, blockString bool) []byte {
+	if blockString {
+		return transform.BlockStringValue(raw)
+	}
+	return transform.UnescapeString(raw)
 }
 
 func (p *Printer) PrintFieldDefinition(definition document.FieldDefinition) {
-	p.PrintDescription(definition.Description, literal.TAB)
-	p.write(literal.TAB)
+	p.PrintDescription(definition.Description)
+
+	wrap := false
+	if definition.ArgumentsDefinition != -1 && p.pretty {
+		wrap = p.hasDescribedInputValue(definition.ArgumentsDefinition) || !p.fits(func() {
+			p.printFieldDefinition(definition, false)
+		})
+	}
+
+	p.printFieldDefinition(definition, wrap)
+}
+
+func (p *Printer) printFieldDefinition(definition document.FieldDefinition, wrapArguments bool) {
 	p.write(p.p.ByteSlice(definition.Name))
 	if definition.ArgumentsDefinition != -1 {
-		p.PrintArgumentsDefinitionInline(definition.ArgumentsDefinition)
+		p.printArgumentsDefinition(definition.ArgumentsDefinition, wrapArguments)
 	}
 	p.write(literal.COLON)
-	p.write(literal.SPACE)
+	p.optionalSpace()
 	p.PrintType(definition.Type)
 	if definition.DirectiveSet != -1 {
 		p.write(literal.SPACE)
@@ -161,59 +552,60 @@ func (p *Printer) PrintFieldDefinition(definition document.FieldDefinition) {
 	}
 }
 
-func (p *Printer) PrintArgumentsDefinition(ref int) {
-	definition := p.p.ParsedDefinitions.ArgumentsDefinitions[ref]
-	iter := definition.InputValueDefinitions
-	p.write(literal.BRACKETOPEN)
-	for iter.Next(p.p) {
-
-		inputValueDefinition, _ := iter.Value()
-
-		p.write(literal.LINETERMINATOR)
-		p.PrintInputValueDefinition(inputValueDefinition)
+// hasDescribedInputValue reports whether any argument of the arguments definition has a description
+func (p *Printer) hasDescribedInputValue(argumentsDefinition int) bool {
+	if p.style.StripDescriptions {
+		return false
 	}
-	p.write(literal.LINETERMINATOR)
-	p.write(literal.BRACKETCLOSE)
+	inputValues := p.p.ParsedDefinitions.ArgumentsDefinitions[argumentsDefinition].InputValueDefinitions
+	for inputValues.Next(p.p) {
+		inputValue, _ := inputValues.Value()
+		if inputValue.Description.Length() != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// PrintArgumentsDefinition prints the arguments definition one argument per line
+func (p *Printer) PrintArgumentsDefinition(ref int) {
+	p.printArgumentsDefinition(ref, true)
 }
 
+// PrintArgumentsDefinitionInline prints the arguments definition on a single line
 func (p *Printer) PrintArgumentsDefinitionInline(ref int) {
+	p.printArgumentsDefinition(ref, false)
+}
+
+func (p *Printer) printArgumentsDefinition(ref int, wrap bool) {
 	definition := p.p.ParsedDefinitions.ArgumentsDefinitions[ref]
-	p.write(literal.BRACKETOPEN)
-	iter := definition.InputValueDefinitions
-	var addSpace bool
-	for iter.Next(p.p) {
-		if addSpace {
-			p.write(literal.SPACE)
-		}
-		inputValueDefinition, _ := iter.Value()
-		p.PrintInputValueDefinitionInline(inputValueDefinition)
-		addSpace = true
-	}
-	p.write(literal.BRACKETCLOSE)
+	refs := p.inputValueDefinitions(definition.InputValueDefinitions)
+	p.printList(argumentList, len(refs), literal.BRACKETOPEN, literal.BRACKETCLOSE, wrap, func(i int) {
+		inputValueDefinition := p.p.ParsedDefinitions.InputValueDefinitions[refs[i]]
+		p.printInputValueDefinition(inputValueDefinition, !wrap)
+	})
 }
 
+// PrintInputValueDefinition prints the input value definition preceded by its description on a separate line
 func (p *Printer) PrintInputValueDefinition(definition document.InputValueDefinition) {
-	p.PrintDescription(definition.Description, literal.TAB)
-	p.write(literal.TAB)
-	p.write(p.p.ByteSlice(definition.Name))
-	p.write(literal.COLON)
-	p.write(literal.SPACE)
-	p.PrintType(definition.Type)
-	if definition.DirectiveSet != -1 {
-		p.write(literal.SPACE)
-		p.printDirectiveSet(definition.DirectiveSet)
-	}
+	p.printInputValueDefinition(definition, !p.pretty)
 }
 
+// PrintInputValueDefinitionInline prints the input value definition on a single line
 func (p *Printer) PrintInputValueDefinitionInline(definition document.InputValueDefinition) {
+	p.printInputValueDefinition(definition, true)
+}
+
+func (p *Printer) printInputValueDefinition(definition document.InputValueDefinition, inline bool) {
+	p.printDescription(definition.Description, inline)
 	p.write(p.p.ByteSlice(definition.Name))
 	p.write(literal.COLON)
-	p.write(literal.SPACE)
+	p.optionalSpace()
 	p.PrintType(definition.Type)
 	if definition.DefaultValue != -1 {
-		p.write(literal.SPACE)
+		p.optionalSpace()
 		p.write(literal.EQUALS)
-		p.write(literal.SPACE)
+		p.optionalSpace()
 		p.PrintValue(definition.DefaultValue)
 	}
 	if definition.DirectiveSet != -1 {
@@ -240,29 +632,65 @@ func (p *Printer) PrintType(ref int) {
 func (p *Printer) PrintObjectTypeDefinition(ref int) {
 	definition := p.l.ObjectTypeDefinition(ref)
 	p.PrintDescription(definition.Description)
+	p.printExtend(definition.IsExtend)
 	p.write(literal.TYPE)
 	p.write(literal.SPACE)
 	p.write(p.p.ByteSlice(definition.Name))
+	p.printImplementsInterfaces(definition.ImplementsInterfaces)
 	if definition.DirectiveSet != -1 {
 		p.write(literal.SPACE)
 		p.printDirectiveSet(definition.DirectiveSet)
+	}
+	p.printFieldsDefinition(definition.FieldsDefinition)
+}
 
+func (p *Printer) printImplementsInterfaces(interfaces document.ByteSliceReferences) {
+	var names [][]byte
+	for interfaces.Next(p.p) {
+		name, _ := interfaces.Value()
+		names = append(names, p.p.ByteSlice(name))
 	}
-	p.write(literal.SPACE)
-	p.write(literal.CURLYBRACKETOPEN)
-	fields := definition.FieldsDefinition
-	for fields.Next(p.l) {
-		p.write(literal.LINETERMINATOR)
-		field, _ := fields.Value()
-		p.PrintFieldDefinition(field)
+	switch p.style.Order {
+	case OrderSorted:
+		sort.SliceStable(names, func(i, j int) bool {
+			return bytes.Compare(names[i], names[j]) == -1
+		})
+	default:
+		// implemented interfaces are linked in reverse declaration order
+		for i, j := 0, len(names)-1; i < j; i, j = i+1, j-1 {
+			names[i], names[j] = names[j], names[i]
+		}
+	}
+	for i, name := range names {
+		if i == 0 {
+			p.write(literal.SPACE)
+			p.write(literal.IMPLEMENTS)
+			p.write(literal.SPACE)
+		} else {
+			p.optionalSpace()
+			p.write(literal.AND)
+			p.optionalSpace()
+		}
+		p.write(name)
 	}
-	p.write(literal.LINETERMINATOR)
-	p.write(literal.CURLYBRACKETCLOSE)
+}
+
+// printFieldsDefinition prints the fields of an object or interface type definition, nothing if there are none
+func (p *Printer) printFieldsDefinition(fields document.FieldDefinitions) {
+	refs := p.fieldDefinitions(fields)
+	if len(refs) == 0 {
+		return
+	}
+	p.optionalSpace()
+	p.printBlock(len(refs), func(i int) {
+		p.PrintFieldDefinition(p.p.ParsedDefinitions.FieldDefinitions[refs[i]])
+	})
 }
 
 func (p *Printer) PrintEnumTypeDefinition(ref int) {
 	definition := p.p.ParsedDefinitions.EnumTypeDefinitions[ref]
 	p.PrintDescription(definition.Description)
+	p.printExtend(definition.IsExtend)
 	p.write(literal.ENUM)
 	p.write(literal.SPACE)
 	p.write(p.p.ByteSlice(definition.Name))
@@ -270,39 +698,51 @@ func (p *Printer) PrintEnumTypeDefinition(ref int) {
 		p.write(literal.SPACE)
 		p.printDirectiveSet(definition.DirectiveSet)
 	}
-	p.write(literal.SPACE)
-	p.write(literal.CURLYBRACKETOPEN)
-	p.write(literal.LINETERMINATOR)
-	var addLineTerminator bool
-	enums := definition.EnumValuesDefinition
-	for enums.Next(p.p) {
-		if addLineTerminator {
-			p.write(literal.LINETERMINATOR)
-		}
-		enumValue, _ := enums.Value()
-		p.PrintEnumValueDefinition(enumValue)
-		addLineTerminator = true
+	refs := p.enumValueDefinitions(definition.EnumValuesDefinition)
+	if len(refs) == 0 {
+		return
 	}
-	p.write(literal.LINETERMINATOR)
-	p.write(literal.CURLYBRACKETCLOSE)
+	p.optionalSpace()
+	p.printBlock(len(refs), func(i int) {
+		p.PrintEnumValueDefinition(p.p.ParsedDefinitions.EnumValuesDefinitions[refs[i]])
+	})
 }
 
 func (p *Printer) PrintEnumValueDefinition(definition document.EnumValueDefinition) {
-	p.PrintDescription(definition.Description, literal.TAB)
-	p.write(literal.TAB)
+	p.PrintDescription(definition.Description)
 	p.write(p.p.ByteSlice(definition.EnumValue))
+	if definition.DirectiveSet != -1 {
+		p.write(literal.SPACE)
+		p.printDirectiveSet(definition.DirectiveSet)
+	}
 }
 
 func (p *Printer) PrintDirectiveDefinition(ref int) {
 	definition := p.p.ParsedDefinitions.DirectiveDefinitions[ref]
 	p.PrintDescription(definition.Description)
+
+	wrap := false
+	if definition.ArgumentsDefinition != -1 && p.pretty {
+		wrap = p.style.MaxLineWidth <= 0 || !p.fits(func() {
+			p.printDirectiveDefinition(definition, false)
+		})
+	}
+
+	p.printDirectiveDefinition(definition, wrap)
+}
+
+func (p *Printer) printDirectiveDefinition(definition document.DirectiveDefinition, wrapArguments bool) {
+	p.printExtend(definition.IsExtend)
 	p.write(literal.DIRECTIVE)
 	p.write(literal.SPACE)
 	p.write(literal.AT)
 	p.write(p.p.ByteSlice(definition.Name))
-	p.write(literal.SPACE)
 	if definition.ArgumentsDefinition != -1 {
-		p.PrintArgumentsDefinition(definition.ArgumentsDefinition)
+		p.printArgumentsDefinition(definition.ArgumentsDefinition, wrapArguments)
+	}
+	p.write(literal.SPACE)
+	if definition.IsRepeatable {
+		p.write(literal.REPEATABLE)
 		p.write(literal.SPACE)
 	}
 	p.write(literal.ON)
@@ -311,24 +751,20 @@ func (p *Printer) PrintDirectiveDefinition(ref int) {
 }
 
 func (p *Printer) PrintDirectiveLocations(locations []int) {
-	var addPipe bool
-	for _, location := range locations {
-
-		if addPipe {
-			p.write(literal.SPACE)
+	for i, location := range locations {
+		if i != 0 {
+			p.optionalSpace()
 			p.write(literal.PIPE)
-			p.write(literal.SPACE)
+			p.optionalSpace()
 		}
-
 		p.write([]byte(document.DirectiveLocation(location).String()))
-
-		addPipe = true
 	}
 }
 
 func (p *Printer) PrintInterfaceTypeDefinition(ref int) {
 	definition := p.p.ParsedDefinitions.InterfaceTypeDefinitions[ref]
 	p.PrintDescription(definition.Description)
+	p.printExtend(definition.IsExtend)
 	p.write(literal.INTERFACE)
 	p.write(literal.SPACE)
 	p.write(p.p.ByteSlice(definition.Name))
@@ -336,21 +772,13 @@ func (p *Printer) PrintInterfaceTypeDefinition(ref int) {
 		p.write(literal.SPACE)
 		p.printDirectiveSet(definition.DirectiveSet)
 	}
-	p.write(literal.SPACE)
-	p.write(literal.CURLYBRACKETOPEN)
-	fields := definition.FieldsDefinition
-	for fields.Next(p.l) {
-		p.write(literal.LINETERMINATOR)
-		field, _ := fields.Value()
-		p.PrintFieldDefinition(field)
-	}
-	p.write(literal.LINETERMINATOR)
-	p.write(literal.CURLYBRACKETCLOSE)
+	p.printFieldsDefinition(definition.FieldsDefinition)
 }
 
 func (p *Printer) PrintScalarTypeDefinition(ref int) {
 	definition := p.p.ParsedDefinitions.ScalarTypeDefinitions[ref]
 	p.PrintDescription(definition.Description)
+	p.printExtend(definition.IsExtend)
 	p.write(literal.SCALAR)
 	p.write(literal.SPACE)
 	p.write(p.p.ByteSlice(definition.Name))
@@ -363,6 +791,23 @@ func (p *Printer) PrintScalarTypeDefinition(ref int) {
 func (p *Printer) PrintUnionTypeDefinition(ref int) {
 	definition := p.p.ParsedDefinitions.UnionTypeDefinitions[ref]
 	p.PrintDescription(definition.Description)
+
+	members := append([]int(nil), definition.UnionMemberTypes...)
+	if p.style.Order == OrderSorted {
+		sort.SliceStable(members, func(i, j int) bool {
+			return bytes.Compare(p.p.CachedByteSlice(members[i]), p.p.CachedByteSlice(members[j])) == -1
+		})
+	}
+
+	wrap := p.pretty && !p.fits(func() {
+		p.printUnionTypeDefinition(definition, members, false)
+	})
+
+	p.printUnionTypeDefinition(definition, members, wrap)
+}
+
+func (p *Printer) printUnionTypeDefinition(definition document.UnionTypeDefinition, members []int, wrapMembers bool) {
+	p.printExtend(definition.IsExtend)
 	p.write(literal.UNION)
 	p.write(literal.SPACE)
 	p.write(p.p.ByteSlice(definition.Name))
@@ -370,66 +815,149 @@ func (p *Printer) PrintUnionTypeDefinition(ref int) {
 		p.write(literal.SPACE)
 		p.printDirectiveSet(definition.DirectiveSet)
 	}
-	p.write(literal.SPACE)
+	if len(members) == 0 {
+		return
+	}
+	p.optionalSpace()
 	p.write(literal.EQUALS)
-	p.write(literal.SPACE)
-	var addPipe bool
-	for _, memberName := range definition.UnionMemberTypes {
-		if addPipe {
-			p.write(literal.SPACE)
+	if wrapMembers {
+		p.level++
+	}
+	for i, member := range members {
+		switch {
+		case wrapMembers:
+			p.newLine()
 			p.write(literal.PIPE)
 			p.write(literal.SPACE)
+		case i != 0:
+			p.optionalSpace()
+			p.write(literal.PIPE)
+			p.optionalSpace()
+		default:
+			p.optionalSpace()
 		}
-		p.write(p.p.CachedByteSlice(memberName))
-		addPipe = true
+		p.write(p.p.CachedByteSlice(member))
+	}
+	if wrapMembers {
+		p.level--
 	}
 }
 
 func (p *Printer) PrintInputObjectTypeDefinition(ref int) {
 	definition := p.p.ParsedDefinitions.InputObjectTypeDefinitions[ref]
 	p.PrintDescription(definition.Description)
+	p.printExtend(definition.IsExtend)
 	p.write(literal.INPUT)
 	p.write(literal.SPACE)
 	p.write(p.p.ByteSlice(definition.Name))
-	p.write(literal.SPACE)
-	p.write(literal.CURLYBRACKETOPEN)
-	iter := p.p.ParsedDefinitions.InputFieldsDefinitions[definition.InputFieldsDefinition].InputValueDefinitions
-	for iter.Next(p.p) {
-
-		inputValueDefinition, _ := iter.Value()
-
-		p.write(literal.LINETERMINATOR)
-		p.PrintInputValueDefinition(inputValueDefinition)
+	if definition.DirectiveSet != -1 {
+		p.write(literal.SPACE)
+		p.printDirectiveSet(definition.DirectiveSet)
 	}
-	p.write(literal.LINETERMINATOR)
-	p.write(literal.CURLYBRACKETCLOSE)
+	if definition.InputFieldsDefinition == -1 {
+		return
+	}
+	p.optionalSpace()
+	refs := p.inputValueDefinitions(p.p.ParsedDefinitions.InputFieldsDefinitions[definition.InputFieldsDefinition].InputValueDefinitions)
+	p.printBlock(len(refs), func(i int) {
+		p.PrintInputValueDefinition(p.p.ParsedDefinitions.InputValueDefinitions[refs[i]])
+	})
 }
 
 func (p *Printer) PrintExecutableSchema(out io.Writer) error {
 
 	p.out = out
+	p.pretty = p.style.ExecutableDefinition == LayoutPretty
+	p.executable = true
+	p.level = 0
+	p.column = 0
+	p.nextComment = 0
+
+	definitions := p.executableDefinitionRootNodes()
+	for i, definition := range definitions {
+		if i != 0 {
+			p.writeDefinitionSeparator()
+		}
+		p.printCommentsBefore(definition.position)
+		switch definition.kind {
+		case lookup.OPERATION_DEFINITION:
+			p.printOperation(p.l.OperationDefinition(definition.ref))
+		case lookup.FRAGMENT_DEFINITION:
+			p.printFragmentDefinition(p.l.FragmentDefinition(definition.ref))
+		}
+	}
 
-	operations := p.w.OperationDefinitionIterable()
-	for operations.Next() {
-		operation := operations.Value()
-		p.printOperation(operation)
+	if p.nextComment < len(p.comments) {
+		if len(definitions) != 0 {
+			p.writeDefinitionSeparator()
+		}
+		p.printCommentsBefore(position.Position{LineStart: ^uint32(0)})
+		return p.err
 	}
 
-	if p.l.HasOperationDefinitions() && p.l.HasFragmentDefinitions() {
+	if p.pretty && len(definitions) != 0 {
 		p.write(literal.LINETERMINATOR)
 	}
 
+	return p.err
+}
+
+// printCommentsBefore prints the comments preceding the position, each one ends its line
+func (p *Printer) printCommentsBefore(position position.Position) {
+	for p.hasCommentBefore(position) {
+		p.printComment(p.comments[p.nextComment].Text)
+		p.nextComment++
+	}
+}
+
+// hasCommentBefore reports whether the next comment precedes the position
+func (p *Printer) hasCommentBefore(position position.Position) bool {
+	if p.nextComment >= len(p.comments) {
+		return false
+	}
+	comment := p.comments[p.nextComment].Position
+	return comment.LineStart < position.LineStart || comment.LineStart == position.LineStart && comment.CharStart < position.CharStart
+}
+
+// executableDefinitionRootNodes returns all operations and fragments in source order
+func (p *Printer) executableDefinitionRootNodes() []rootNode {
+
+	p.rootNodes = p.rootNodes[:0]
+
+	operations := p.w.OperationDefinitionIterable()
+	for operations.Next() {
+		p.rootNodes = append(p.rootNodes, rootNode{
+			ref:      operations.Ref(),
+			kind:     lookup.OPERATION_DEFINITION,
+			position: operations.Value().Position,
+		})
+	}
+
 	fragments := p.w.FragmentDefinitionIterable()
-	var addNewLine bool
 	for fragments.Next() {
-		if addNewLine {
-			p.write(literal.LINETERMINATOR)
+		p.rootNodes = append(p.rootNodes, rootNode{
+			ref:      fragments.Ref(),
+			kind:     lookup.FRAGMENT_DEFINITION,
+			position: fragments.Value().Position,
+		})
+	}
+
+	sort.SliceStable(p.rootNodes, func(i, j int) bool {
+		left, right := p.rootNodes[i].position, p.rootNodes[j].position
+		if left.LineStart != right.LineStart {
+			return left.LineStart < right.LineStart
 		}
-		fragment := fragments.Value()
-		p.printFragmentDefinition(fragment)
-		addNewLine = true
+		return left.CharStart < right.CharStart
+	})
+
+	return p.rootNodes
+}
+
+func (p *Printer) writeDefinitionSeparator() {
+	p.write(literal.LINETERMINATOR)
+	if p.pretty {
+		p.write(literal.LINETERMINATOR)
 	}
-	return p.err
 }
 
 func (p *Printer) printFragmentDefinition(fragment document.FragmentDefinition) {
@@ -449,108 +977,186 @@ func (p *Printer) printFragmentDefinition(fragment document.FragmentDefinition)
 }
 
 func (p *Printer) printOperation(operation document.OperationDefinition) {
+
+	wrap := false
+	if len(operation.VariableDefinitions) > 0 && p.pretty && !p.fits(func() {
+		p.printOperationHead(operation, false)
+	}) {
+		wrap = true
+	}
+
+	p.printOperationHead(operation, wrap)
+
+	if operation.SelectionSet != -1 {
+		p.printSelectionSet(operation.SelectionSet)
+	}
+}
+
+func (p *Printer) printOperationHead(operation document.OperationDefinition, wrapVariableDefinitions bool) {
 	hasName := operation.Name.Length() != 0
-	p.printOperationType(operation.OperationType, hasName)
+	hasVariableDefinitions := len(operation.VariableDefinitions) > 0
+	hasOperationType := p.printOperationType(operation.OperationType, hasName || hasVariableDefinitions || operation.DirectiveSet != -1)
 	if hasName {
+		p.write(literal.SPACE)
 		p.write(p.p.ByteSlice(operation.Name))
-		if len(operation.VariableDefinitions) > 0 {
-			p.printVariableDefinitions(operation.VariableDefinitions)
+	}
+	if hasVariableDefinitions {
+		if !hasName {
+			p.optionalSpace()
 		}
+		p.printVariableDefinitions(operation.VariableDefinitions, wrapVariableDefinitions)
+	}
+	if hasOperationType {
 		p.write(literal.SPACE)
 	}
 	if operation.DirectiveSet != -1 {
 		p.printDirectiveSet(operation.DirectiveSet)
 		p.write(literal.SPACE)
 	}
-	if operation.SelectionSet != -1 {
-		p.printSelectionSet(operation.SelectionSet)
-	}
 }
 
 func (p *Printer) printDirectiveSet(setRef int) {
-	set := p.l.DirectiveSet(setRef)
-	iter := p.l.DirectiveIterable(set)
-	var addSpace bool
-	for iter.Next() {
-		if addSpace {
+	set := p.sortByName(p.l.DirectiveSet(setRef), func(ref int) []byte {
+		return p.p.ByteSlice(p.l.Directive(ref).Name)
+	})
+	for i, ref := range set {
+		if i != 0 {
 			p.write(literal.SPACE)
 		}
-		directive, _ := iter.Value()
-		p.printDirective(directive)
-		addSpace = true
+		p.printDirective(p.l.Directive(ref))
 	}
 }
 
+// sortByName returns a sorted copy of refs if type system definitions get printed sorted
+// this applies to directives, arguments and object fields which are kept in the order of the AST otherwise
+func (p *Printer) sortByName(refs []int, name func(ref int) []byte) []int {
+	if p.executable || p.style.Order != OrderSorted {
+		return refs
+	}
+	sorted := append([]int(nil), refs...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return bytes.Compare(name(sorted[i]), name(sorted[j])) == -1
+	})
+	return sorted
+}
+
 func (p *Printer) printDirective(directive document.Directive) {
 	p.write(literal.AT)
 	p.write(p.p.ByteSlice(directive.Name))
 	if directive.ArgumentSet != -1 {
-		p.printArgumentSet(directive.ArgumentSet)
+		p.printArgumentSet(directive.ArgumentSet, false)
 	}
 }
 
-func (p *Printer) printOperationType(operationType document.OperationType, hasName bool) {
+// printOperationType prints the operation type, queries use the shorthand if the operation type isn't required
+func (p *Printer) printOperationType(operationType document.OperationType, required bool) bool {
 	switch operationType {
 	case document.OperationTypeQuery:
-		if hasName {
-			p.write(literal.QUERY)
-			p.write(literal.SPACE)
+		if !required {
+			return false
 		}
+		p.write(literal.QUERY)
 	case document.OperationTypeMutation:
 		p.write(literal.MUTATION)
-		p.write(literal.SPACE)
 	case document.OperationTypeSubscription:
 		p.write(literal.SUBSCRIPTION)
-		p.write(literal.SPACE)
+	default:
+		return false
 	}
+	return true
 }
 
 func (p *Printer) printSelectionSet(ref int) {
 
 	p.write(literal.CURLYBRACKETOPEN)
+	p.level++
 
 	set := p.l.SelectionSetContentsIterator(ref)
 	var addSpace bool
 	for set.Next() {
 
-		if addSpace {
+		if p.pretty {
+			p.newLine()
+		} else if addSpace {
 			p.write(literal.SPACE)
 		}
 
 		kind, ref := set.Value()
 		switch kind {
 		case lookup.FIELD:
+			p.printCommentsBefore(p.l.Field(ref).Position)
 			p.printField(ref)
 		case lookup.FRAGMENT_SPREAD:
+			p.printCommentsBefore(p.l.FragmentSpread(ref).Position)
 			p.printFragmentSpread(ref)
 		case lookup.INLINE_FRAGMENT:
+			p.printCommentsBefore(p.l.InlineFragment(ref).Position)
 			p.printInlineFragment(ref)
 		}
 
 		addSpace = true
 	}
 
+	// comments at the end of the selection set are printed before the closing bracket
+	end := p.l.SelectionSet(ref).Position
+	end.LineStart, end.CharStart = end.LineEnd, end.CharEnd
+	if p.pretty {
+		for p.hasCommentBefore(end) {
+			p.newLine()
+			p.writeComment(p.comments[p.nextComment].Text)
+			p.nextComment++
+		}
+	} else if p.hasCommentBefore(end) {
+		if addSpace {
+			p.write(literal.SPACE)
+		}
+		p.printCommentsBefore(end)
+	}
+
+	p.level--
+	if p.pretty {
+		p.newLine()
+	}
 	p.write(literal.CURLYBRACKETCLOSE)
 }
 
 func (p *Printer) printField(ref int) {
 
 	field := p.l.Field(ref)
+
+	wrap := false
+	if field.ArgumentSet != -1 && p.pretty && !p.fits(func() {
+		p.printFieldHead(field, false)
+	}) {
+		wrap = true
+	}
+
+	p.printFieldHead(field, wrap)
+
+	if field.SelectionSet != -1 {
+		p.write(literal.SPACE)
+		p.printSelectionSet(field.SelectionSet)
+	}
+}
+
+func (p *Printer) printFieldHead(field document.Field, wrapArguments bool) {
+
+	if field.Alias.Length() != 0 {
+		p.write(p.p.ByteSlice(field.Alias))
+		p.write(literal.COLON)
+		p.optionalSpace()
+	}
+
 	p.write(p.p.ByteSlice(field.Name))
 
 	if field.ArgumentSet != -1 {
-		p.printArgumentSet(field.ArgumentSet)
+		p.printArgumentSet(field.ArgumentSet, wrapArguments)
 	}
 
 	if field.DirectiveSet != -1 {
 		p.write(literal.SPACE)
 		p.printDirectiveSet(field.DirectiveSet)
 	}
-
-	if field.SelectionSet != -1 {
-		p.write(literal.SPACE)
-		p.printSelectionSet(field.SelectionSet)
-	}
 }
 
 func (p *Printer) printFragmentSpread(ref int) {
@@ -570,6 +1176,7 @@ func (p *Printer) printInlineFragment(ref int) {
 
 	if inline.TypeCondition != -1 {
 		typeCondition := p.l.Type(inline.TypeCondition)
+		p.optionalSpace()
 		p.write(literal.ON)
 		p.write(literal.SPACE)
 		p.write(p.p.ByteSlice(typeCondition.Name))
@@ -580,31 +1187,23 @@ func (p *Printer) printInlineFragment(ref int) {
 		p.printDirectiveSet(inline.DirectiveSet)
 	}
 
+	p.optionalSpace()
 	p.printSelectionSet(inline.SelectionSet)
 }
 
-func (p *Printer) printArgumentSet(ref int) {
-
-	p.write(literal.BRACKETOPEN)
-
-	set := p.l.ArgumentSet(ref)
-	iter := p.l.ArgumentsIterable(set)
-	var addSpace bool
-	for iter.Next() {
-		if addSpace {
-			p.write(literal.SPACE)
-		}
-		argument, _ := iter.Value()
-		p.printArgument(argument)
-		addSpace = true
-	}
-
-	p.write(literal.BRACKETCLOSE)
+func (p *Printer) printArgumentSet(ref int, wrap bool) {
+	set := p.sortByName(p.l.ArgumentSet(ref), func(argument int) []byte {
+		return p.p.ByteSlice(p.l.Argument(argument).Name)
+	})
+	p.printList(argumentList, len(set), literal.BRACKETOPEN, literal.BRACKETCLOSE, wrap, func(i int) {
+		p.printArgument(p.l.Argument(set[i]))
+	})
 }
 
 func (p *Printer) printArgument(arg document.Argument) {
 	p.write(p.p.ByteSlice(arg.Name))
 	p.write(literal.COLON)
+	p.optionalSpace()
 	p.PrintValue(arg.Value)
 }
 
@@ -618,8 +1217,13 @@ func (p *Printer) PrintValue(ref int) {
 	case document.ValueTypeNull:
 		p.write(literal.NULL)
 	case document.ValueTypeString:
+		raw := p.p.ByteSlice(value.Raw)
+		if isBlockString(raw) {
+			p.printBlockString(raw)
+			return
+		}
 		p.write(literal.QUOTE)
-		p.write(p.p.ByteSlice(value.Raw))
+		p.write(raw)
 		p.write(literal.QUOTE)
 	case document.ValueTypeVariable:
 		p.write(literal.DOLLAR)
@@ -632,76 +1236,44 @@ func (p *Printer) PrintValue(ref int) {
 }
 
 func (p *Printer) printObjectValue(ref int) {
-
-	p.write(literal.CURLYBRACKETOPEN)
-
-	objectValue := p.l.ObjectValue(ref)
-	fields := p.l.ObjectFieldsIterator(objectValue)
-	var addComma bool
-	for fields.Next() {
-		if addComma {
-			p.write(literal.COMMA)
-		}
-
-		field, _ := fields.Value()
-		p.printObjectField(field)
-
-		addComma = true
-	}
-
-	p.write(literal.CURLYBRACKETCLOSE)
+	objectValue := p.sortByName(p.l.ObjectValue(ref), func(field int) []byte {
+		return p.p.ByteSlice(p.l.ObjectField(field).Name)
+	})
+	p.printList(valueList, len(objectValue), literal.CURLYBRACKETOPEN, literal.CURLYBRACKETCLOSE, false, func(i int) {
+		p.printObjectField(p.l.ObjectField(objectValue[i]))
+	})
 }
 
 func (p *Printer) printObjectField(field document.ObjectField) {
 	p.write(p.p.ByteSlice(field.Name))
 	p.write(literal.COLON)
+	p.optionalSpace()
 	p.PrintValue(field.Value)
 }
 
 func (p *Printer) printListValue(ref int) {
-
-	p.write(literal.SQUAREBRACKETOPEN)
-
 	list := p.l.ListValue(ref)
-	var addComma bool
-	for _, valueRef := range list {
-
-		if addComma {
-			p.write(literal.COMMA)
-		}
-
-		p.PrintValue(valueRef)
-		addComma = true
-	}
-
-	p.write(literal.SQUAREBRACKETCLOSE)
+	p.printList(valueList, len(list), literal.SQUAREBRACKETOPEN, literal.SQUAREBRACKETCLOSE, false, func(i int) {
+		p.PrintValue(list[i])
+	})
 }
 
 func (p *Printer) printVariableDefinition(definition document.VariableDefinition) {
 	p.write(literal.DOLLAR)
 	p.write(p.p.ByteSlice(definition.Variable))
 	p.write(literal.COLON)
+	p.optionalSpace()
 	p.PrintType(definition.Type)
-}
-
-func (p *Printer) printVariableDefinitions(definitions []int) {
-
-	variableDefinitions := p.l.VariableDefinitionIterator(definitions)
-
-	p.write(literal.BRACKETOPEN)
-
-	prependSpaceBeforeNext := false
-
-	for variableDefinitions.Next() {
-		if prependSpaceBeforeNext {
-			p.write(literal.SPACE)
-		}
-
-		variable, _ := variableDefinitions.Value()
-		p.printVariableDefinition(variable)
-
-		prependSpaceBeforeNext = true
+	if definition.DefaultValue != -1 {
+		p.optionalSpace()
+		p.write(literal.EQUALS)
+		p.optionalSpace()
+		p.PrintValue(definition.DefaultValue)
 	}
+}
 
-	p.write(literal.BRACKETCLOSE)
+func (p *Printer) printVariableDefinitions(refs []int, wrap bool) {
+	p.printList(argumentList, len(refs), literal.BRACKETOPEN, literal.BRACKETCLOSE, wrap, func(i int) {
+		p.printVariableDefinition(p.p.ParsedDefinitions.VariableDefinitions[refs[i]])
+	})
 }
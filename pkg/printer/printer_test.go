@@ -4,10 +4,15 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
+	"github.com/jensneuse/graphql-go-tools/pkg/document"
+	"github.com/jensneuse/graphql-go-tools/pkg/introspection"
+	"github.com/jensneuse/graphql-go-tools/pkg/lexing/position"
 	"github.com/jensneuse/graphql-go-tools/pkg/lookup"
 	"github.com/jensneuse/graphql-go-tools/pkg/parser"
 	"github.com/sebdah/goldie"
 	"io"
+	"io/ioutil"
+	"reflect"
 	"testing"
 )
 
@@ -227,6 +232,446 @@ func TestPrinter_Regression(t *testing.T) {
 	})
 }
 
+func TestPrinter_Coverage(t *testing.T) {
+
+	run := func(style Style, input, want string, typeSystemDefinition bool) {
+		_, got, err := parseAndPrint(style, []byte(input), typeSystemDefinition)
+		if err != nil {
+			panic(err)
+		}
+		if want != string(got) {
+			panic(fmt.Errorf("want:\n\n%s\n\ngot:\n\n%s\n", want, string(got)))
+		}
+	}
+
+	sourceOrder := DefaultStyle()
+	sourceOrder.Order = OrderSource
+
+	pretty := DefaultStyle()
+	pretty.ExecutableDefinition = LayoutPretty
+
+	t.Run("schema extension", func(t *testing.T) {
+		run(sourceOrder, `extend schema @foo { mutation: Mutation }`, `extend schema @foo {
+	mutation: Mutation
+}
+`, true)
+	})
+	t.Run("type extensions", func(t *testing.T) {
+		run(sourceOrder, `
+extend type Query implements Node @key { id: ID! }
+extend type Dog @key
+extend interface Node @foo
+extend union SearchResult @foo = Photo
+extend enum Status @foo { ARCHIVED }
+extend input Filter @foo { status: Status }
+extend scalar Date @foo`, `extend type Query implements Node @key {
+	id: ID!
+}
+
+extend type Dog @key
+
+extend interface Node @foo
+
+extend union SearchResult @foo = Photo
+
+extend enum Status @foo {
+	ARCHIVED
+}
+
+extend input Filter @foo {
+	status: Status
+}
+
+extend scalar Date @foo
+`, true)
+	})
+	t.Run("directives in all positions", func(t *testing.T) {
+		run(sourceOrder, `
+schema @a { query: Query }
+type Query implements A & B @b { field(arg: Int = 1 @c): String @d }
+interface A @e { field: String @f }
+union U @g = A | B
+enum E @h { V @i }
+input I @j { field: Int = 2 @k }
+scalar S @l`, `schema @a {
+	query: Query
+}
+
+type Query implements A & B @b {
+	field(arg: Int = 1 @c): String @d
+}
+
+interface A @e {
+	field: String @f
+}
+
+union U @g = A | B
+
+enum E @h {
+	V @i
+}
+
+input I @j {
+	field: Int = 2 @k
+}
+
+scalar S @l
+`, true)
+	})
+	t.Run("implemented interfaces in declaration order", func(t *testing.T) {
+		run(DefaultStyle(), `type Query implements B & A { b: String a: String }`, `type Query implements B & A {
+	b: String
+	a: String
+}
+`, true)
+	})
+	t.Run("repeatable directive definition", func(t *testing.T) {
+		run(sourceOrder, `directive @tag(name: String!) repeatable on FIELD_DEFINITION | OBJECT
+directive @key repeatable on OBJECT`, `directive @tag(
+	name: String!
+) repeatable on FIELD_DEFINITION | OBJECT
+
+directive @key repeatable on OBJECT
+`, true)
+	})
+	t.Run("comments", func(t *testing.T) {
+		run(sourceOrder, `
+# the query type
+# spans two lines
+type Query {
+	# a field
+	field(
+		# an argument
+		arg: Int): String
+}`, `# the query type
+# spans two lines
+type Query {
+	# a field
+	field(
+		# an argument
+		arg: Int
+	): String
+}
+`, true)
+	})
+	t.Run("described arguments", func(t *testing.T) {
+		run(sourceOrder, `type Query { field("an argument" arg: Int other: String): String }`, `type Query {
+	field(
+		"an argument"
+		arg: Int
+		other: String
+	): String
+}
+`, true)
+	})
+	t.Run("block strings", func(t *testing.T) {
+		run(sourceOrder, `
+"""a "quoted" type"""
+type Query { field(arg: String = """multi
+  line"""): String }`, `"""a "quoted" type"""
+type Query {
+	field(arg: String = """multi
+  line"""): String
+}
+`, true)
+	})
+	t.Run("variable default values", func(t *testing.T) {
+		run(DefaultStyle(), `query Q($a: Int = 1, $b: [String!] = ["x"], $c: Input = {d: true}) {field}`,
+			`query Q($a:Int=1 $b:[String!]=["x"] $c:Input={d:true}) {field}`, false)
+	})
+	t.Run("aliases", func(t *testing.T) {
+		run(DefaultStyle(), `{first: field(id: 1) second: field(id: 2) {alias: name}}`, `{first:field(id:1) second:field(id:2) {alias:name}}`, false)
+		run(pretty, `{first: field(id: 1)}`, "{\n\tfirst: field(id: 1)\n}\n", false)
+	})
+	t.Run("anonymous operations", func(t *testing.T) {
+		run(DefaultStyle(), `query ($id: ID) {field(id: $id)}`, `query($id:ID) {field(id:$id)}`, false)
+		run(DefaultStyle(), `query @live {field}`, `query @live {field}`, false)
+		run(DefaultStyle(), `mutation {field}`, `mutation {field}`, false)
+		run(pretty, `query ($id: ID) @live {field}`, "query ($id: ID) @live {\n\tfield\n}\n", false)
+	})
+	t.Run("source order of operations and fragments", func(t *testing.T) {
+		run(DefaultStyle(), `fragment F on Query {field} query A {...F} query B {...F}`, "fragment F on Query {field}\nquery A {...F}\nquery B {...F}", false)
+	})
+	t.Run("block string arguments", func(t *testing.T) {
+		run(DefaultStyle(), "{field(text: \"\"\"a \"quoted\"\nvalue\"\"\")}", "{field(text:\"\"\"a \"quoted\"\nvalue\"\"\")}", false)
+	})
+}
+
+// TestPrinter_RoundTrip asserts for the fixtures of the repository that parsing a printed document
+// results in the same AST as parsing the original one, for all combinations of styles
+func TestPrinter_RoundTrip(t *testing.T) {
+
+	readFile := func(fileName string) string {
+		data, err := ioutil.ReadFile(fileName)
+		if err != nil {
+			panic(err)
+		}
+		return string(data)
+	}
+
+	typeSystemDefinitions := map[string]string{
+		"starwars":         starwarsSchema,
+		"starwars_file":    readFile("../parser/testdata/starwars.schema.graphql"),
+		"all_constructs":   roundTripTypeSystemDefinition,
+		"extensions":       "extend schema @foo {query: Query}\nextend type Query @bar\nextend scalar Date @baz",
+		"comment_in_input": "type Query {field(\n# comment\narg: Int): String}",
+	}
+
+	executableDefinitions := map[string]string{
+		"introspection":        introspectionQuery,
+		"introspection_query":  introspection.Query,
+		"all_constructs_query": roundTripExecutableDefinition,
+	}
+
+	var styles []Style
+	for _, layout := range []Layout{LayoutPretty, LayoutMinified} {
+		for _, comma := range []CommaPlacement{CommaDefault, CommaNone, CommaSeparate, CommaTrailing} {
+			for _, description := range []DescriptionStyle{DescriptionAuto, DescriptionBlock, DescriptionSingleLine} {
+				for _, indentWidth := range []int{0, 2} {
+					for _, maxLineWidth := range []int{0, 40} {
+						styles = append(styles, Style{
+							IndentWidth:          indentWidth,
+							TypeSystemDefinition: layout,
+							ExecutableDefinition: layout,
+							Description:          description,
+							Comma:                comma,
+							MaxLineWidth:         maxLineWidth,
+							Order:                OrderSource,
+						})
+					}
+				}
+			}
+		}
+	}
+
+	run := func(name, input string, typeSystemDefinition bool, styles []Style) {
+		t.Run(name, func(t *testing.T) {
+			for _, style := range styles {
+				original, printed, err := parseAndPrint(style, []byte(input), typeSystemDefinition)
+				if err != nil {
+					panic(err)
+				}
+
+				reparsed, reprinted, err := parseAndPrint(style, printed, typeSystemDefinition)
+				if err != nil {
+					panic(fmt.Errorf("style: %+v\nprinted document can't be parsed: %s\n\n%s", style, err, string(printed)))
+				}
+
+				err = astEqual(original, reparsed)
+				if err != nil {
+					panic(fmt.Errorf("style: %+v\n%s\n\nprinted:\n\n%s", style, err, string(printed)))
+				}
+
+				if !bytes.Equal(printed, reprinted) {
+					panic(fmt.Errorf("style: %+v\nprinting is not idempotent, want:\n\n%s\n\ngot:\n\n%s", style, string(printed), string(reprinted)))
+				}
+
+				sorted := style
+				sorted.Order = OrderSorted
+				_, printedSorted, err := parseAndPrint(sorted, []byte(input), typeSystemDefinition)
+				if err != nil {
+					panic(err)
+				}
+				_, reprintedSorted, err := parseAndPrint(sorted, printedSorted, typeSystemDefinition)
+				if err != nil {
+					panic(err)
+				}
+				if !bytes.Equal(printedSorted, reprintedSorted) {
+					panic(fmt.Errorf("style: %+v\nsorted printing is not idempotent, want:\n\n%s\n\ngot:\n\n%s", sorted, string(printedSorted), string(reprintedSorted)))
+				}
+			}
+		})
+	}
+
+	// the default style of the fmt command prints in the order of the AST
+	styles = append(styles, DefaultStyle())
+
+	for name, input := range typeSystemDefinitions {
+		run(name, input, true, styles)
+	}
+	for name, input := range executableDefinitions {
+		run(name, input, false, styles)
+	}
+
+	// the big schema takes too long for all combinations
+	pretty, minified := DefaultStyle(), DefaultStyle()
+	pretty.Order, minified.Order = OrderSource, OrderSource
+	minified.TypeSystemDefinition = LayoutMinified
+	run("big_schema", readFile("../parser/testdata/big_schema.graphql"), true, []Style{pretty, minified, DefaultStyle()})
+}
+
+func parseAndPrint(style Style, input []byte, typeSystemDefinition bool) (*parser.Parser, []byte, error) {
+
+	p := parser.NewParser()
+	var err error
+	if typeSystemDefinition {
+		err = p.ParseTypeSystemDefinition(input)
+	} else {
+		err = p.ParseExecutableDefinition(input)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	l := lookup.New(p)
+	w := lookup.NewWalker(1024, 8)
+	w.SetLookup(l)
+
+	printer := New()
+	printer.SetStyle(style)
+	printer.SetInput(p, l, w)
+
+	buff := bytes.Buffer{}
+	if typeSystemDefinition {
+		w.WalkTypeSystemDefinition()
+		err = printer.PrintTypeSystemDefinition(&buff)
+	} else {
+		w.WalkExecutable()
+		err = printer.PrintExecutableSchema(&buff)
+	}
+
+	return p, buff.Bytes(), err
+}
+
+// astEqual compares the parsed definitions of two parsers
+// positions are ignored, byte slice references are compared by their content
+// descriptions are compared by their value as they might be printed in another style
+func astEqual(left, right *parser.Parser) error {
+
+	positionType := reflect.TypeOf(position.Position{})
+	byteSliceReferenceType := reflect.TypeOf(document.ByteSliceReference{})
+
+	description := func(p *parser.Parser, ref document.ByteSliceReference) string {
+		raw := p.ByteSlice(ref)
+		if bytes.HasPrefix(raw, []byte("#")) {
+			lines := bytes.Split(raw, []byte("\n"))
+			for i := range lines {
+				lines[i] = bytes.TrimSpace(lines[i])
+			}
+			return string(bytes.Join(lines, []byte("\n")))
+		}
+		return string(descriptionValue(raw, isBlockString(raw)))
+	}
+
+	var compare func(path string, fieldName string, l, r reflect.Value) error
+	compare = func(path string, fieldName string, l, r reflect.Value) error {
+		switch {
+		case l.Type() == positionType:
+			return nil
+		case l.Type() == byteSliceReferenceType:
+			leftRef, rightRef := l.Interface().(document.ByteSliceReference), r.Interface().(document.ByteSliceReference)
+			if fieldName == "Description" {
+				if description(left, leftRef) != description(right, rightRef) {
+					return fmt.Errorf("%s: want description '%s', got: '%s'", path, description(left, leftRef), description(right, rightRef))
+				}
+				return nil
+			}
+			if !bytes.Equal(left.ByteSlice(leftRef), right.ByteSlice(rightRef)) || leftRef.NextRef != rightRef.NextRef {
+				return fmt.Errorf("%s: want '%s' (next: %d), got: '%s' (next: %d)", path, string(left.ByteSlice(leftRef)), leftRef.NextRef, string(right.ByteSlice(rightRef)), rightRef.NextRef)
+			}
+			return nil
+		}
+
+		switch l.Kind() {
+		case reflect.Struct:
+			for i := 0; i < l.NumField(); i++ {
+				field := l.Type().Field(i)
+				if field.PkgPath != "" {
+					continue
+				}
+				err := compare(path+"."+field.Name, field.Name, l.Field(i), r.Field(i))
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		case reflect.Slice, reflect.Array:
+			if l.Len() != r.Len() {
+				return fmt.Errorf("%s: want length %d, got: %d", path, l.Len(), r.Len())
+			}
+			for i := 0; i < l.Len(); i++ {
+				err := compare(fmt.Sprintf("%s[%d]", path, i), fieldName, l.Index(i), r.Index(i))
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		default:
+			if !reflect.DeepEqual(l.Interface(), r.Interface()) {
+				return fmt.Errorf("%s: want %v, got: %v", path, l.Interface(), r.Interface())
+			}
+			return nil
+		}
+	}
+
+	return compare("ParsedDefinitions", "", reflect.ValueOf(left.ParsedDefinitions), reflect.ValueOf(right.ParsedDefinitions))
+}
+
+const roundTripTypeSystemDefinition = `
+# the schema
+schema @a { query: Query mutation: Mutation }
+extend schema @b { subscription: Subscription }
+
+"""
+the root type
+  with an indented line and a \""" quote
+"""
+type Query implements Node & Entity @c(list: [1, 2], object: {a: "b", c: {d: ENUM}}) {
+	"a single line description with \"quotes\""
+	node(
+		"the id"
+		id: ID!
+		"""a "block" description"""
+		filter: Filter = {status: [DRAFT, PUBLISHED], limit: 10, ratio: 1.5, exact: false, owner: null}
+		text: String = """block
+  default"""
+	): Node @d
+	list(first: Int = 10, after: ID @e): [[Node!]]!
+}
+extend type Query { extended: Boolean }
+type Mutation { mutate: Boolean }
+type Subscription { subscribe: Boolean }
+interface Node @f { id: ID! }
+interface Entity { name: String }
+union SearchResult @g = Query | Mutation
+enum Status @h {
+	"drafted"
+	DRAFT @i
+	# published
+	PUBLISHED
+}
+input Filter @j {
+	status: [Status!] = [DRAFT]
+	limit: Int @k
+	ratio: Float
+	exact: Boolean
+	owner: String
+}
+scalar Date @specifiedBy(url: "https://tools.ietf.org/html/rfc3339")
+"a directive"
+directive @tag(name: String! = "default", values: [String]) repeatable on FIELD_DEFINITION | OBJECT | ARGUMENT_DEFINITION
+directive @a on SCHEMA
+`
+
+const roundTripExecutableDefinition = `
+query Q($id: ID! = "1", $list: [Int] = [1, 2], $object: Input = {a: {b: [true, false, null]}}) @live {
+	alias: node(id: $id) @include(if: true) {
+		id
+		... on Entity @skip(if: false) { name }
+		... @include(if: true) { id }
+		...NodeFields @defer
+	}
+	text(value: """block
+  string""", escaped: "a \"quoted\" string", kind: VALUE, float: 1.5)
+}
+query ($id: ID) { node(id: $id) { id } }
+query @live { id }
+{ id }
+mutation M { mutate(data: {a: 1}) }
+subscription { subscribe }
+fragment NodeFields on Node @foo(bar: "baz") { id ... on Entity { name } }
+`
+
 func BenchmarkPrinter_PrintExecutableSchema(b *testing.B) {
 
 	inputBytes := []byte("{foo bar ...{baz} ...Bal ...on Bar{bat bar} bart assets(first:{foo:\"bar\",baz:1}) assets(first:[1,3,3,7]) assets(first:null)}\nfragment MyFrag on Dog {foo bar}")
@@ -727,3 +1172,388 @@ enum __TypeKind {
 	"Indicates this type is a non-null. 'ofType' is a valid field."
 	NON_NULL
 }`
+
+func TestPrinter_Style(t *testing.T) {
+
+	print := func(style Style, input string, typeSystemDefinition bool) string {
+		p := parser.NewParser()
+		if typeSystemDefinition {
+			if err := p.ParseTypeSystemDefinition([]byte(input)); err != nil {
+				panic(err)
+			}
+		} else {
+			if err := p.ParseExecutableDefinition([]byte(input)); err != nil {
+				panic(err)
+			}
+		}
+
+		l := lookup.New(p)
+		w := lookup.NewWalker(1024, 8)
+		w.SetLookup(l)
+
+		printer := New()
+		printer.SetStyle(style)
+		printer.SetInput(p, l, w)
+
+		buff := bytes.Buffer{}
+		if typeSystemDefinition {
+			w.WalkTypeSystemDefinition()
+			if err := printer.PrintTypeSystemDefinition(&buff); err != nil {
+				panic(err)
+			}
+		} else {
+			w.WalkExecutable()
+			if err := printer.PrintExecutableSchema(&buff); err != nil {
+				panic(err)
+			}
+		}
+
+		return buff.String()
+	}
+
+	run := func(style Style, input, want string, typeSystemDefinition bool) {
+		got := print(style, input, typeSystemDefinition)
+		if want != got {
+			panic(fmt.Errorf("want:\n\n%s\n\ngot:\n\n%s\n", want, got))
+		}
+	}
+
+	runTypeSystemDefinition := func(style Style, input, want string) {
+		run(style, input, want, true)
+	}
+
+	runExecutableDefinition := func(style Style, input, want string) {
+		run(style, input, want, false)
+	}
+
+	with := func(modify func(style *Style)) Style {
+		style := DefaultStyle()
+		modify(&style)
+		return style
+	}
+
+	schema := `
+"documents of a user"
+type Query {
+	"all documents"
+	documents(first: Int = 10, after: ID, filter: Filter = {status: [DRAFT, PUBLISHED]}): [Document] @auth
+	document(id: ID!): Document
+}
+"""
+a document
+  with an indented line
+"""
+type Document {
+	id: ID!
+}
+enum Status {
+	DRAFT
+	PUBLISHED
+}
+input Filter {
+	"the status"
+	status: [Status]
+}
+union Result = Query | Document
+directive @auth(role: String = "admin", scope: String) repeatable on FIELD_DEFINITION | OBJECT`
+
+	t.Run("default", func(t *testing.T) {
+		runTypeSystemDefinition(DefaultStyle(), schema, `"documents of a user"
+type Query {
+	"all documents"
+	documents(first: Int = 10 after: ID filter: Filter = {status: [DRAFT, PUBLISHED]}): [Document] @auth
+	document(id: ID!): Document
+}
+
+"""
+a document
+  with an indented line
+"""
+type Document {
+	id: ID!
+}
+
+enum Status {
+	DRAFT
+	PUBLISHED
+}
+
+input Filter {
+	"the status"
+	status: [Status]
+}
+
+union Result = Query | Document
+
+directive @auth(
+	role: String = "admin"
+	scope: String
+) repeatable on FIELD_DEFINITION | OBJECT
+`)
+	})
+	t.Run("indent width and source order", func(t *testing.T) {
+		runTypeSystemDefinition(with(func(style *Style) {
+			style.IndentWidth = 2
+			style.Order = OrderSource
+			style.Comma = CommaSeparate
+		}), schema, `"documents of a user"
+type Query {
+  "all documents"
+  documents(first: Int = 10, after: ID, filter: Filter = {status: [DRAFT, PUBLISHED]}): [Document] @auth
+  document(id: ID!): Document
+}
+
+"""
+a document
+  with an indented line
+"""
+type Document {
+  id: ID!
+}
+
+enum Status {
+  DRAFT
+  PUBLISHED
+}
+
+input Filter {
+  "the status"
+  status: [Status]
+}
+
+union Result = Query | Document
+
+directive @auth(
+  role: String = "admin",
+  scope: String
+) repeatable on FIELD_DEFINITION | OBJECT
+`)
+	})
+	t.Run("sorted", func(t *testing.T) {
+		runTypeSystemDefinition(with(func(style *Style) {
+			style.Order = OrderSorted
+		}), `
+directive @b on FIELD
+directive @a on FIELD
+union C = Z | A
+type B { b: String a(y: Int x: Int): String }
+enum A { Y X }
+schema { query: B }
+`, `schema {
+	query: B
+}
+
+enum A {
+	X
+	Y
+}
+
+type B {
+	a(x: Int y: Int): String
+	b: String
+}
+
+union C = A | Z
+
+directive @a on FIELD
+
+directive @b on FIELD
+`)
+	})
+	t.Run("minified", func(t *testing.T) {
+		runTypeSystemDefinition(with(func(style *Style) {
+			style.TypeSystemDefinition = LayoutMinified
+			style.Order = OrderSource
+		}), schema, `"documents of a user" type Query{"all documents" documents(first:Int=10 after:ID filter:Filter={status:[DRAFT,PUBLISHED]}):[Document] @auth document(id:ID!):Document}
+"a document\n  with an indented line" type Document{id:ID!}
+enum Status{DRAFT PUBLISHED}
+input Filter{"the status" status:[Status]}
+union Result=Query|Document
+directive @auth(role:String="admin" scope:String) repeatable on FIELD_DEFINITION|OBJECT
+`)
+	})
+	t.Run("minified schema can be parsed", func(t *testing.T) {
+		style := with(func(style *Style) {
+			style.Order = OrderSource
+			style.Description = DescriptionSingleLine
+		})
+		minified := with(func(style *Style) {
+			style.TypeSystemDefinition = LayoutMinified
+			style.Order = OrderSource
+		})
+		want := print(style, schema, true)
+		got := print(style, print(minified, schema, true), true)
+		if want != got {
+			panic(fmt.Errorf("want:\n\n%s\n\ngot:\n\n%s\n", want, got))
+		}
+	})
+	t.Run("block descriptions", func(t *testing.T) {
+		runTypeSystemDefinition(with(func(style *Style) {
+			style.Description = DescriptionBlock
+		}), `
+"a \"quoted\" document"
+type Document {
+	"""
+	the id
+	"""
+	id: ID!
+}`, `"""
+a "quoted" document
+"""
+type Document {
+	"""
+	the id
+	"""
+	id: ID!
+}
+`)
+	})
+	t.Run("single line descriptions", func(t *testing.T) {
+		runTypeSystemDefinition(with(func(style *Style) {
+			style.Description = DescriptionSingleLine
+		}), `
+"""
+a "quoted"
+  document
+"""
+type Document {
+	"the id"
+	id: ID!
+}`, `"a \"quoted\"\n  document"
+type Document {
+	"the id"
+	id: ID!
+}
+`)
+	})
+	t.Run("wrap arguments definitions", func(t *testing.T) {
+		runTypeSystemDefinition(with(func(style *Style) {
+			style.MaxLineWidth = 40
+			style.Order = OrderSource
+			style.Comma = CommaTrailing
+		}), `
+type Query {
+	short(id: ID): String
+	documents(first: Int, after: ID, last: Int): [String]
+}
+directive @short(id: ID) on FIELD
+union Result = Document | Image | Video | Audio | Text`, `type Query {
+	short(id: ID): String
+	documents(
+		first: Int,
+		after: ID,
+		last: Int,
+	): [String]
+}
+
+directive @short(id: ID) on FIELD
+
+union Result =
+	| Document
+	| Image
+	| Video
+	| Audio
+	| Text
+`)
+	})
+
+	query := `query Documents($first: Int, $after: ID) @live {documents(first: $first, after: $after, filter: {status: [DRAFT, PUBLISHED]}) {id ...DocumentFields ... on Document @include(if: true) {title}}} fragment DocumentFields on Document {owner}`
+
+	t.Run("minified executable", func(t *testing.T) {
+		runExecutableDefinition(DefaultStyle(), query, `query Documents($first:Int $after:ID) @live {documents(first:$first after:$after filter:{status:[DRAFT,PUBLISHED]}) {id ...DocumentFields ...on Document @include(if:true){title}}}
+fragment DocumentFields on Document {owner}`)
+	})
+	t.Run("minified executable with commas", func(t *testing.T) {
+		runExecutableDefinition(with(func(style *Style) {
+			style.Comma = CommaSeparate
+		}), `{documents(first:1 after:"a") {id}}`, `{documents(first:1,after:"a") {id}}`)
+	})
+	t.Run("pretty executable", func(t *testing.T) {
+		runExecutableDefinition(with(func(style *Style) {
+			style.ExecutableDefinition = LayoutPretty
+			style.IndentWidth = 2
+			style.Comma = CommaSeparate
+		}), query, `query Documents($first: Int, $after: ID) @live {
+  documents(first: $first, after: $after, filter: {status: [DRAFT, PUBLISHED]}) {
+    id
+    ...DocumentFields
+    ... on Document @include(if: true) {
+      title
+    }
+  }
+}
+
+fragment DocumentFields on Document {
+  owner
+}
+`)
+	})
+	t.Run("pretty executable wraps arguments", func(t *testing.T) {
+		runExecutableDefinition(with(func(style *Style) {
+			style.ExecutableDefinition = LayoutPretty
+			style.MaxLineWidth = 30
+		}), `query Documents($first: Int, $after: ID, $last: Int) {documents(first: $first, after: $after) {id}}`, `query Documents(
+	$first: Int
+	$after: ID
+	$last: Int
+) {
+	documents(
+		first: $first
+		after: $after
+	) {
+		id
+	}
+}
+`)
+	})
+	t.Run("multiple operations", func(t *testing.T) {
+		runExecutableDefinition(DefaultStyle(), `query A {a} query B {b}`, "query A {a}\nquery B {b}")
+	})
+}
+
+func TestPrinter_SetComments(t *testing.T) {
+
+	// the parser drops comments of executable definitions, the input contains spaces in their place
+	input := "        \nquery Q {\n  a\n     \n  b\n     \n}"
+	comments := []Comment{
+		{Position: position.Position{LineStart: 1, CharStart: 1}, Text: []byte("# query")},
+		{Position: position.Position{LineStart: 4, CharStart: 3}, Text: []byte("# b")},
+		{Position: position.Position{LineStart: 6, CharStart: 3}, Text: []byte("# end")},
+	}
+
+	run := func(style Style, want string) {
+		p := parser.NewParser()
+		if err := p.ParseExecutableDefinition([]byte(input)); err != nil {
+			panic(err)
+		}
+
+		l := lookup.New(p)
+		w := lookup.NewWalker(1024, 8)
+		w.SetLookup(l)
+		w.WalkExecutable()
+
+		printer := New()
+		printer.SetStyle(style)
+		printer.SetInput(p, l, w)
+		printer.SetComments(comments)
+
+		buff := bytes.Buffer{}
+		if err := printer.PrintExecutableSchema(&buff); err != nil {
+			panic(err)
+		}
+
+		if want != buff.String() {
+			panic(fmt.Errorf("want:\n\n%s\n\ngot:\n\n%s\n", want, buff.String()))
+		}
+	}
+
+	pretty := DefaultStyle()
+	pretty.ExecutableDefinition = LayoutPretty
+
+	t.Run("pretty", func(t *testing.T) {
+		run(pretty, "# query\nquery Q {\n\ta\n\t# b\n\tb\n\t# end\n}\n")
+	})
+	t.Run("minified", func(t *testing.T) {
+		run(DefaultStyle(), "# query\nquery Q {a # b\nb # end\n}")
+	})
+}
@@ -0,0 +1,103 @@
+package printer
+
+// Layout defines whether a document gets printed for humans or as compact as possible
+type Layout int
+
+const (
+	// LayoutPretty prints every definition, field, enum value and selection on its own line indented by its nesting level
+	LayoutPretty Layout = iota + 1
+	// LayoutMinified prints a document without any optional whitespace, one root definition per line
+	LayoutMinified
+)
+
+// DescriptionStyle defines how descriptions get printed in pretty type system definitions
+type DescriptionStyle int
+
+const (
+	// DescriptionAuto prints single line descriptions as strings and multi line descriptions as block strings
+	DescriptionAuto DescriptionStyle = iota + 1
+	// DescriptionBlock prints all descriptions as block strings
+	DescriptionBlock
+	// DescriptionSingleLine prints all descriptions as strings, line terminators get escaped
+	DescriptionSingleLine
+)
+
+// CommaPlacement defines where the optional commas between arguments, arguments definitions,
+// variable definitions as well as list and object values get printed
+type CommaPlacement int
+
+const (
+	// CommaDefault separates list and object values by commas, everything else by spaces
+	CommaDefault CommaPlacement = iota + 1
+	// CommaNone separates all items by spaces
+	CommaNone
+	// CommaSeparate separates all items by commas
+	CommaSeparate
+	// CommaTrailing separates all items by commas
+	// items printed one per line are all terminated by a comma, including the last one
+	CommaTrailing
+)
+
+// Order defines the order of definitions, fields, arguments, input fields, enum values and union members
+// in printed type system definitions, executable definitions are always printed in source order
+type Order int
+
+const (
+	// OrderSource prints everything in declaration order, so printing a printed document doesn't change it
+	OrderSource Order = iota + 1
+	// OrderSorted prints the schema definition first, followed by all types and then all directive definitions
+	// each group as well as fields, arguments definitions, input fields, enum values and union members are sorted by name,
+	// so are implemented interfaces, directives, arguments and the fields of object values
+	OrderSorted
+)
+
+// tabWidth is the number of columns a tab counts for when wrapping lines
+const tabWidth = 4
+
+// Style configures how the Printer lays out documents
+type Style struct {
+	// IndentWidth is the number of spaces per indentation level, tabs are used if it's 0
+	IndentWidth int
+	// TypeSystemDefinition is the layout of type system definitions
+	TypeSystemDefinition Layout
+	// ExecutableDefinition is the layout of operations and fragments
+	ExecutableDefinition Layout
+	// Description is the style of descriptions in pretty type system definitions
+	// descriptions of minified documents and descriptions of arguments printed on a single line are always strings
+	Description DescriptionStyle
+	// Comma defines where optional commas get printed
+	Comma CommaPlacement
+	// MaxLineWidth is the width at which arguments definitions, arguments, variable definitions and union members
+	// of pretty documents get wrapped one per line, tabs count as 4 columns
+	// 0 disables wrapping, in this case the arguments of directive definitions are always printed one per line
+	MaxLineWidth int
+	// Order is the order of type system definitions
+	Order Order
+	// StripDescriptions omits all descriptions and comments of type system definitions
+	StripDescriptions bool
+}
+
+// DefaultStyle returns the style used by New
+// type system definitions are pretty printed in declaration order indented by tabs,
+// executable definitions are minified
+func DefaultStyle() Style {
+	return Style{
+		TypeSystemDefinition: LayoutPretty,
+		ExecutableDefinition: LayoutMinified,
+		Description:          DescriptionAuto,
+		Comma:                CommaDefault,
+		Order:                OrderSource,
+	}
+}
+
+// CanonicalStyle returns the style of Normalize
+// the output only depends on the meaning of a schema, not on its order, formatting or the escaping of descriptions
+func CanonicalStyle() Style {
+	return Style{
+		TypeSystemDefinition: LayoutPretty,
+		ExecutableDefinition: LayoutPretty,
+		Description:          DescriptionSingleLine,
+		Comma:                CommaDefault,
+		Order:                OrderSorted,
+	}
+}
@@ -0,0 +1,90 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jensneuse/graphql-go-tools/pkg/jwt"
+	"github.com/jensneuse/graphql-go-tools/pkg/middleware"
+)
+
+// Authentication configures the verification of json web tokens sent in the Authorization header
+type Authentication struct {
+	// Verifier verifies the signature and the registered claims of the token against a key set
+	Verifier *jwt.Verifier
+	// ClaimsToContext maps claims (dot separated paths for nested claims) to context keys
+	// e.g. {"sub": "user"} makes the subject available to @addArgumentFromContext(name: "user", contextKey: "user")
+	ClaimsToContext map[string]string
+	// AllowAnonymous lets requests without Authorization header pass, the mapped context keys stay empty
+	AllowAnonymous bool
+	// ClearContextKeys are cleared in addition to the mapped context keys so that a client can't set them with headers,
+	// nil clears the default roles and scopes context keys of the middleware.AuthorizationMiddleware
+	ClearContextKeys []string
+}
+
+var defaultClearContextKeys = []string{middleware.DefaultRolesContextKey, middleware.DefaultScopesContextKey}
+
+// ContextWithClaims verifies the token of the Authorization header value and sets the mapped claims on the context
+// mapped context keys and ClearContextKeys are always overwritten so that a client can't set them with a header of the same name
+// string claims are set as is, all other claims are set as their json representation
+func (a *Authentication) ContextWithClaims(ctx context.Context, authorizationHeader []byte) (context.Context, error) {
+
+	ctx = a.clearContextKeys(ctx)
+
+	if len(authorizationHeader) == 0 {
+		if a.AllowAnonymous {
+			return ctx, nil
+		}
+		return ctx, jwt.Error{Message: "missing authorization header"}
+	}
+
+	if a.Verifier == nil {
+		return ctx, fmt.Errorf("ContextWithClaims: Verifier must not be nil")
+	}
+
+	claims, err := a.Verifier.VerifyAuthorizationHeader(authorizationHeader)
+	if err != nil {
+		return ctx, err
+	}
+
+	for claim, contextKey := range a.ClaimsToContext {
+		value, ok := claims.Value(claim)
+		if !ok || value == nil {
+			continue
+		}
+		contextValue, err := claimContextValue(value)
+		if err != nil {
+			return ctx, err
+		}
+		ctx = context.WithValue(ctx, contextKey, contextValue)
+	}
+
+	return ctx, nil
+}
+
+func (a *Authentication) clearContextKeys(ctx context.Context) context.Context {
+	for _, contextKey := range a.ClaimsToContext {
+		ctx = context.WithValue(ctx, contextKey, nil)
+	}
+	clearContextKeys := a.ClearContextKeys
+	if clearContextKeys == nil {
+		clearContextKeys = defaultClearContextKeys
+	}
+	for _, contextKey := range clearContextKeys {
+		ctx = context.WithValue(ctx, contextKey, nil)
+	}
+	return ctx
+}
+
+func claimContextValue(value interface{}) (string, error) {
+	switch value := value.(type) {
+	case string:
+		return value, nil
+	case json.Number:
+		return value.String(), nil
+	default:
+		data, err := json.Marshal(value)
+		return string(data), err
+	}
+}
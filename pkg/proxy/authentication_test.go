@@ -0,0 +1,111 @@
+package proxy
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jensneuse/graphql-go-tools/pkg/jwt"
+)
+
+func TestAuthentication_ContextWithClaims(t *testing.T) {
+
+	keys := jwt.NewKeySet()
+	keys.AddHMACKey("", []byte("secret"))
+
+	authentication := func(allowAnonymous bool) *Authentication {
+		return &Authentication{
+			Verifier: jwt.NewVerifier(keys),
+			ClaimsToContext: map[string]string{
+				"sub":                "user",
+				"realm_access.roles": "roles",
+				"admin":              "admin",
+				"level":              "level",
+			},
+			AllowAnonymous: allowAnonymous,
+		}
+	}
+
+	// the client tries to set the context keys with headers
+	spoofed := func() context.Context {
+		ctx := context.WithValue(context.WithValue(context.Background(), "user", "admin@example.org"), "roles", "admin")
+		return context.WithValue(context.WithValue(ctx, "scopes", "documents:write"), "tenant", "other")
+	}
+
+	run := func(authentication *Authentication, authorizationHeader string, want map[string]interface{}) {
+		ctx, err := authentication.ContextWithClaims(spoofed(), []byte(authorizationHeader))
+		if err != nil {
+			panic(err)
+		}
+		for key, value := range want {
+			if ctx.Value(key) != value {
+				panic(fmt.Errorf("want value '%v' for key '%s', got: '%v'", value, key, ctx.Value(key)))
+			}
+		}
+	}
+
+	runErr := func(authentication *Authentication, authorizationHeader string, wantErr string) {
+		_, err := authentication.ContextWithClaims(spoofed(), []byte(authorizationHeader))
+		if err == nil {
+			panic(fmt.Errorf("want err for header: %s", authorizationHeader))
+		}
+		if !strings.Contains(err.Error(), wantErr) {
+			panic(fmt.Errorf("want err containing: %s, got: %s", wantErr, err.Error()))
+		}
+		statusCodeErr, ok := err.(StatusCodeError)
+		if !ok || statusCodeErr.StatusCode() != http.StatusUnauthorized {
+			panic(fmt.Errorf("want StatusCodeError with status 401, got: %v", err))
+		}
+	}
+
+	t.Run("claims are added to the context", func(t *testing.T) {
+		run(authentication(false), "Bearer "+hs256Token("secret", `{"sub":"jsmith@example.org","realm_access":{"roles":["reader"]},"admin":false,"level":3}`),
+			map[string]interface{}{
+				"user":  "jsmith@example.org",
+				"roles": `["reader"]`,
+				"admin": "false",
+				"level": "3",
+			})
+	})
+	t.Run("missing claims clear the context key", func(t *testing.T) {
+		run(authentication(false), "Bearer "+hs256Token("secret", `{"sub":"jsmith@example.org"}`),
+			map[string]interface{}{
+				"user":  "jsmith@example.org",
+				"roles": nil,
+			})
+	})
+	t.Run("anonymous", func(t *testing.T) {
+		run(authentication(true), "", map[string]interface{}{
+			"user":  nil,
+			"roles": nil,
+		})
+		runErr(authentication(false), "", "missing authorization header")
+	})
+	t.Run("roles and scopes of the authorization middleware are cleared", func(t *testing.T) {
+		run(&Authentication{AllowAnonymous: true}, "", map[string]interface{}{
+			"roles":  nil,
+			"scopes": nil,
+			"tenant": "other",
+		})
+		run(&Authentication{AllowAnonymous: true, ClearContextKeys: []string{"tenant"}}, "", map[string]interface{}{
+			"roles":  "admin",
+			"tenant": nil,
+		})
+	})
+	t.Run("invalid token", func(t *testing.T) {
+		runErr(authentication(true), "Bearer "+hs256Token("other", `{"sub":"admin@example.org"}`), "invalid signature")
+		runErr(authentication(false), "Basic dXNlcjpwYXNz", "Bearer scheme")
+	})
+}
+
+func hs256Token(secret, claims string) string {
+	signingInput := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`)) + "." + base64.RawURLEncoding.EncodeToString([]byte(claims))
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(signingInput))
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -2,6 +2,9 @@ package proxy
 
 import (
 	"context"
+	"github.com/jensneuse/graphql-go-tools/pkg/coercion"
+	"github.com/jensneuse/graphql-go-tools/pkg/middleware"
+	"github.com/jensneuse/graphql-go-tools/pkg/validator"
 	"net/url"
 )
 
@@ -28,6 +31,23 @@ type RequestConfig struct {
 	// BackendHeaders are headers that should be statically set to backend requests
 	// This could be used to add authentication to securely communicate with the origin server
 	BackendHeaders map[string][]string
+	// CoerceVariables enables validating and coercing the request variables against the variable definitions of the operation
+	// requests with invalid variables will be rejected with status code 400 and the coercion errors before they're sent to the backend
+	CoerceVariables bool
+	// ScalarCoercers are hooks to coerce variable values of custom scalars, keyed by the scalar type name
+	ScalarCoercers map[string]coercion.ScalarCoercer
+	// ValidationRules (optional) overrides the rule set of the ValidationMiddleware for requests using this config
+	// This could be used to e.g. disallow introspection on a public route while keeping it on an internal one
+	ValidationRules *validator.Registry
+	// Authentication (optional) verifies the json web token of the Authorization header and adds its claims to the context
+	// requests with an invalid token are rejected, claims take precedence over headers added with AddHeadersToContext
+	Authentication *Authentication
+	// PersistedQueries (optional) enables requests sending the hash of a persisted query instead of the query
+	PersistedQueries *PersistedQueries
+	// MiddleWares (optional) replace the middlewares of the proxy for requests using this config, e.g. to use other middlewares per tenant
+	// the middlewares are shared by all requests using the config, so GetRequestConfig must not create them per request
+	// use middleware.Chain to order them by their phase
+	MiddleWares []middleware.GraphqlMiddleware
 }
 
 type StaticRequestConfigProvider struct {
@@ -0,0 +1,23 @@
+package proxy
+
+import "net/http"
+
+// StatusCodeError is implemented by errors which should be answered with a status code other than 500
+type StatusCodeError interface {
+	error
+	StatusCode() int
+}
+
+// ResponseError is implemented by errors which should be answered with a graphql response instead of the error message,
+// e.g. {"errors":[{"message":"PersistedQueryNotFound","extensions":{"code":"PERSISTED_QUERY_NOT_FOUND"}}]}
+type ResponseError interface {
+	StatusCodeError
+	Response() []byte
+}
+
+// HeaderError is implemented by errors which add headers to the error response,
+// e.g. the Retry-After header of a ratelimit.Error
+type HeaderError interface {
+	error
+	Header() http.Header
+}
@@ -23,12 +23,13 @@ type Proxy struct {
 type ProxyRequest struct {
 	proxy.Request
 	Proxy *Proxy
+	// invoker is held for the whole request so that the response phase has access to the request AST
+	invoker *middleware.Invoker
 }
 
 func (pr *ProxyRequest) AcceptRequest(buff *bytes.Buffer) error {
 
-	idx, invoker := pr.Proxy.InvokerPool.Get()
-	defer pr.Proxy.InvokerPool.Free(idx)
+	invoker := pr.invoker
 
 	err := invoker.SetSchema(*pr.Config.Schema)
 	if err != nil {
@@ -40,6 +41,19 @@ func (pr *ProxyRequest) AcceptRequest(buff *bytes.Buffer) error {
 		return err
 	}
 
+	if response, resolved := invoker.ResolvedResponse(); resolved {
+		pr.Resolved = true
+		_, err = buff.Write(response)
+		return err
+	}
+
+	if pr.Config.CoerceVariables {
+		pr.GraphQLRequest.Variables, err = invoker.CoerceVariables(pr.GraphQLRequest.OperationName, pr.GraphQLRequest.Variables, pr.Config.ScalarCoercers)
+		if err != nil {
+			return err
+		}
+	}
+
 	err = invoker.RewriteRequest(buff)
 	if err != nil {
 		return err
@@ -84,12 +98,22 @@ func (pr *ProxyRequest) DispatchRequest(buff *bytes.Buffer) (io.ReadCloser, erro
 	return response.Body, nil
 }
 
-func (pr *ProxyRequest) AcceptResponse() {
-	panic("implement me")
+func (pr *ProxyRequest) AcceptResponse(buff *bytes.Buffer) error {
+
+	response := buff.Bytes()
+	err := pr.invoker.InvokeMiddleWaresOnResponse(pr.Context, &response)
+	if err != nil {
+		return err
+	}
+
+	buff.Reset()
+	_, err = buff.Write(response)
+	return err
 }
 
-func (pr *ProxyRequest) DispatchResponse() {
-	panic("implement me")
+func (pr *ProxyRequest) DispatchResponse(buff *bytes.Buffer, w io.Writer) error {
+	_, err := buff.WriteTo(w)
+	return err
 }
 
 func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -103,13 +127,31 @@ func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	idx, invoker := p.InvokerPool.Get()
+	defer p.InvokerPool.Free(idx)
+	if config.MiddleWares != nil {
+		invoker.SetMiddleWares(config.MiddleWares...)
+	}
+
 	pr := ProxyRequest{
-		Proxy: p,
+		Proxy:   p,
+		invoker: invoker,
 	}
 	pr.Config = config
 	pr.RequestURL = *r.URL
 	pr.Body = r.Body
 	pr.Context = p.SetContextValues(r.Context(), r.Header, config.AddHeadersToContext)
+	if config.Authentication != nil {
+		pr.Context, err = config.Authentication.ContextWithClaims(pr.Context, []byte(r.Header.Get("Authorization")))
+		if err != nil {
+			p.BufferPool.Put(buff)
+			p.HandleError(err, w)
+			return
+		}
+	}
+	if config.ValidationRules != nil {
+		pr.Context = middleware.WithValidationRules(pr.Context, config.ValidationRules)
+	}
 
 	err = json.NewDecoder(pr.Body).Decode(&pr.GraphQLRequest)
 	if err != nil {
@@ -118,35 +160,59 @@ func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = pr.AcceptRequest(buff)
-	if err != nil {
-		p.BufferPool.Put(buff)
-		p.HandleError(err, w)
-		return
+	if config.PersistedQueries != nil {
+		err = config.PersistedQueries.ResolveQuery(pr.Context, &pr.GraphQLRequest)
+		if err != nil {
+			p.BufferPool.Put(buff)
+			p.HandleError(err, w)
+			return
+		}
 	}
 
-	responseBody, err := pr.DispatchRequest(buff)
+	pr.Context = middleware.WithGraphQLRequest(pr.Context, &pr.GraphQLRequest)
+
+	err = pr.AcceptRequest(buff)
 	if err != nil {
 		p.BufferPool.Put(buff)
-		r.Body.Close()
 		p.HandleError(err, w)
 		return
 	}
 
-	// todo: implement the OnResponse handlers
+	if !pr.Resolved {
+		responseBody, err := pr.DispatchRequest(buff)
+		if err != nil {
+			p.BufferPool.Put(buff)
+			r.Body.Close()
+			p.HandleError(err, w)
+			return
+		}
+
+		buff.Reset()
+		_, err = buff.ReadFrom(responseBody)
+		responseBody.Close()
+		if err != nil {
+			p.BufferPool.Put(buff)
+			r.Body.Close()
+			p.HandleError(err, w)
+			return
+		}
+	}
 
-	_, err = io.Copy(w, responseBody)
+	err = pr.AcceptResponse(buff)
 	if err != nil {
 		p.BufferPool.Put(buff)
 		r.Body.Close()
-		responseBody.Close()
 		p.HandleError(err, w)
 		return
 	}
 
+	w.Header().Set("Content-Type", "application/json")
+	err = pr.DispatchResponse(buff, w)
 	p.BufferPool.Put(buff)
 	r.Body.Close()
-	responseBody.Close()
+	if err != nil {
+		p.HandleError(err, w)
+	}
 }
 
 func (f *Proxy) SetContextValues(ctx context.Context, header http.Header, addHeaders [][]byte) context.Context {
@@ -161,7 +227,22 @@ func NewDefaultProxy(provider proxy.RequestConfigProvider, middlewares ...middle
 	prx := Proxy{
 		HandleError: func(err error, w http.ResponseWriter) {
 			log.Printf("Error: %v", err)
-			w.WriteHeader(http.StatusInternalServerError)
+			if headerErr, ok := err.(proxy.HeaderError); ok {
+				for key, values := range headerErr.Header() {
+					w.Header()[key] = values
+				}
+			}
+			if responseErr, ok := err.(proxy.ResponseError); ok {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(responseErr.StatusCode())
+				_, _ = w.Write(responseErr.Response())
+				return
+			}
+			statusCode := http.StatusInternalServerError
+			if statusCodeErr, ok := err.(proxy.StatusCodeError); ok {
+				statusCode = statusCodeErr.StatusCode()
+			}
+			w.WriteHeader(statusCode)
 			_, _ = w.Write([]byte(err.Error()))
 		},
 	}
@@ -2,16 +2,28 @@ package http
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
 	"errors"
 	hackmiddleware "github.com/jensneuse/graphql-go-tools/hack/middleware"
+	"github.com/jensneuse/graphql-go-tools/pkg/jwt"
+	"github.com/jensneuse/graphql-go-tools/pkg/lookup"
 	"github.com/jensneuse/graphql-go-tools/pkg/middleware"
+	"github.com/jensneuse/graphql-go-tools/pkg/parser"
+	"github.com/jensneuse/graphql-go-tools/pkg/persistedquery"
 	"github.com/jensneuse/graphql-go-tools/pkg/proxy"
+	"github.com/jensneuse/graphql-go-tools/pkg/ratelimit"
+	"github.com/jensneuse/graphql-go-tools/pkg/validation"
+	"github.com/jensneuse/graphql-go-tools/pkg/validator"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 // ProxyTestCase is a human understandable proxy test
@@ -110,6 +122,74 @@ func TestProxy(t *testing.T) {
 			WantProxyErrorHandlerInvocation: false,
 		})
 	})
+	t.Run("coerce variables", func(t *testing.T) {
+		RunTestCase(t, ProxyTestCase{
+			Schema:                          assetSchema,
+			ClientRequest:                   coerceVariablesInput,
+			ExpectedProxiedRequest:          coerceVariablesOutput,
+			BackendStatusCode:               http.StatusOK,
+			WantClientResponseStatusCode:    http.StatusOK,
+			WantProxyErrorHandlerInvocation: false,
+			RequestConfigProviderFactory:    coerceVariablesRequestConfigProvider,
+		})
+	})
+	t.Run("reject invalid variables", func(t *testing.T) {
+		RunTestCase(t, ProxyTestCase{
+			Schema:                          assetSchema,
+			ClientRequest:                   invalidVariablesInput,
+			WantClientResponseStatusCode:    http.StatusOK,
+			WantProxyErrorHandlerInvocation: true,
+			RequestConfigProviderFactory:    coerceVariablesRequestConfigProvider,
+		})
+	})
+	t.Run("invalid variables respond with 400 and a graphql error", func(t *testing.T) {
+		schema := []byte(assetSchema)
+		prx := NewDefaultProxy(proxy.NewStaticRequestConfigProvider(proxy.RequestConfig{Schema: &schema, CoerceVariables: true}))
+		recorder := httptest.NewRecorder()
+		prx.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(invalidVariablesInput)))
+		if recorder.Code != http.StatusBadRequest {
+			t.Fatalf("want status code: %d, got: %d", http.StatusBadRequest, recorder.Code)
+		}
+		if contentType := recorder.Header().Get("Content-Type"); contentType != "application/json" {
+			t.Fatalf("want Content-Type: application/json, got: %s", contentType)
+		}
+		wantBody := `{"errors":[{"message":"Variable \"$first\" got invalid value \"one\"; Expected type \"Int\".","locations":[{"line":1,"column":19}],"extensions":{"code":"BAD_USER_INPUT"}}]}`
+		if recorder.Body.String() != wantBody {
+			t.Fatalf("want body: %s, got: %s", wantBody, recorder.Body.String())
+		}
+	})
+	t.Run("reject request with default validation rules", func(t *testing.T) {
+		RunTestCase(t, ProxyTestCase{
+			Schema: assetSchema,
+			MiddleWares: []middleware.GraphqlMiddleware{
+				&middleware.ValidationMiddleware{},
+			},
+			ClientRequest:                   unusedVariableInput,
+			WantClientResponseStatusCode:    http.StatusOK,
+			WantProxyErrorHandlerInvocation: true,
+		})
+	})
+	t.Run("validation rules from request config", func(t *testing.T) {
+		RunTestCase(t, ProxyTestCase{
+			Schema: assetSchema,
+			MiddleWares: []middleware.GraphqlMiddleware{
+				&middleware.ValidationMiddleware{},
+			},
+			ClientRequest:                   unusedVariableInput,
+			ExpectedProxiedRequest:          unusedVariableOutput,
+			BackendStatusCode:               http.StatusOK,
+			WantClientResponseStatusCode:    http.StatusOK,
+			WantProxyErrorHandlerInvocation: false,
+			RequestConfigProviderFactory: func(config proxy.RequestConfig) proxy.RequestConfigProvider {
+				config.ValidationRules = validator.DefaultRegistry()
+				err := config.ValidationRules.Disable(validation.AllVariablesUsed.String())
+				if err != nil {
+					t.Fatal(err)
+				}
+				return proxy.NewStaticRequestConfigProvider(config)
+			},
+		})
+	})
 	t.Run("failing request config provider", func(t *testing.T) {
 		RunTestCase(t, ProxyTestCase{
 			Schema:                          assetSchema,
@@ -122,6 +202,284 @@ func TestProxy(t *testing.T) {
 			},
 		})
 	})
+	t.Run("introspection query is answered by the proxy", func(t *testing.T) {
+		RunTestCase(t, ProxyTestCase{
+			Schema: introspectionSchema,
+			MiddleWares: []middleware.GraphqlMiddleware{
+				&middleware.IntrospectionMiddleware{},
+				&middleware.ContextMiddleware{},
+				&middleware.ValidationMiddleware{},
+			},
+			ClientRequest:                   introspectionQuery,
+			WantClientResponseStatusCode:    http.StatusOK,
+			WantClientResponseBody:          introspectionResponse,
+			WantProxyErrorHandlerInvocation: false,
+			BackendOnBeforeRequestMiddleware: func(handler http.Handler) http.Handler {
+				f := func(w http.ResponseWriter, r *http.Request) {
+					t.Fatal("introspection query must not be sent to the backend")
+				}
+				return http.HandlerFunc(f)
+			},
+		})
+	})
+	t.Run("response middlewares rewrite the backend response", func(t *testing.T) {
+		RunTestCase(t, ProxyTestCase{
+			Schema: assetSchema,
+			MiddleWares: []middleware.GraphqlMiddleware{
+				&responseSuffixMiddleware{suffix: "-outer"},
+				&responseSuffixMiddleware{suffix: "-inner"},
+			},
+			ClientRequest:                   assetInput,
+			ExpectedProxiedRequest:          assetInput,
+			BackendStatusCode:               http.StatusOK,
+			BackendResponse:                 "testPayload",
+			WantClientResponseStatusCode:    http.StatusOK,
+			WantClientResponseBody:          "testPayload-inner-outer",
+			WantProxyErrorHandlerInvocation: false,
+		})
+	})
+	t.Run("middlewares from request config", func(t *testing.T) {
+		RunTestCase(t, ProxyTestCase{
+			Schema: assetSchema,
+			MiddleWares: []middleware.GraphqlMiddleware{
+				&responseSuffixMiddleware{suffix: "-proxy"},
+			},
+			ClientRequest:                   assetInput,
+			ExpectedProxiedRequest:          assetInput,
+			BackendStatusCode:               http.StatusOK,
+			BackendResponse:                 "testPayload",
+			WantClientResponseStatusCode:    http.StatusOK,
+			WantClientResponseBody:          "testPayload-inner-outer",
+			WantProxyErrorHandlerInvocation: false,
+			RequestConfigProviderFactory: func(config proxy.RequestConfig) proxy.RequestConfigProvider {
+				config.MiddleWares = middleware.Chain(
+					middleware.InPhase(middleware.PhaseValidation, &responseSuffixMiddleware{suffix: "-inner"}),
+					&responseSuffixMiddleware{suffix: "-outer"},
+				)
+				return proxy.NewStaticRequestConfigProvider(config)
+			},
+		})
+	})
+	t.Run("failing response middleware", func(t *testing.T) {
+		RunTestCase(t, ProxyTestCase{
+			Schema: assetSchema,
+			MiddleWares: []middleware.GraphqlMiddleware{
+				&responseSuffixMiddleware{err: errors.New("failing")},
+			},
+			ClientRequest:                   assetInput,
+			ExpectedProxiedRequest:          assetInput,
+			BackendStatusCode:               http.StatusOK,
+			BackendResponse:                 "testPayload",
+			WantClientResponseStatusCode:    http.StatusOK,
+			WantProxyErrorHandlerInvocation: true,
+		})
+	})
+	t.Run("unauthorized fields are removed and set to null", func(t *testing.T) {
+		RunTestCase(t, ProxyTestCase{
+			Schema: authorizationSchema,
+			MiddleWares: []middleware.GraphqlMiddleware{
+				&middleware.AuthorizationMiddleware{},
+			},
+			ClientRequest:          authorizationQuery,
+			ClientHeaders:          map[string]string{"scopes": "documents:read"},
+			ExpectedProxiedRequest: authorizationProxiedQuery,
+			BackendStatusCode:      http.StatusOK,
+			BackendResponse:        authorizationBackendResponse,
+			WantClientResponseBody: authorizationClientResponse,
+			RequestConfigProviderFactory: func(config proxy.RequestConfig) proxy.RequestConfigProvider {
+				config.AddHeadersToContext = [][]byte{[]byte("roles"), []byte("scopes")}
+				return proxy.NewStaticRequestConfigProvider(config)
+			},
+			WantClientResponseStatusCode:    http.StatusOK,
+			WantProxyErrorHandlerInvocation: false,
+		})
+	})
+	t.Run("rejected unauthorized fields respond with 403", func(t *testing.T) {
+		schema := []byte(authorizationSchema)
+		config := proxy.RequestConfig{Schema: &schema, AddHeadersToContext: [][]byte{[]byte("scopes")}}
+		prx := NewDefaultProxy(proxy.NewStaticRequestConfigProvider(config), &middleware.AuthorizationMiddleware{RejectUnauthorized: true})
+		request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(authorizationQuery))
+		request.Header.Set("scopes", "documents:read")
+		recorder := httptest.NewRecorder()
+		prx.ServeHTTP(recorder, request)
+		if recorder.Code != http.StatusForbidden {
+			t.Fatalf("want status code: %d, got: %d", http.StatusForbidden, recorder.Code)
+		}
+		wantBody := `{"errors":[{"message":"not authorized to access field 'Document.sensitiveInformation'","path":["documents","sensitiveInformation"],"extensions":{"code":"FORBIDDEN"}}]}`
+		if recorder.Body.String() != wantBody {
+			t.Fatalf("want body: %s, got: %s", wantBody, recorder.Body.String())
+		}
+	})
+	t.Run("claims of a verified token are added to the context", func(t *testing.T) {
+		RunTestCase(t, ProxyTestCase{
+			Schema: publicSchema,
+			MiddleWares: []middleware.GraphqlMiddleware{
+				&middleware.ContextMiddleware{},
+			},
+			ClientRequest: publicQuery,
+			ClientHeaders: map[string]string{
+				userKey:       "admin@example.org",
+				Authorization: "Bearer " + hs256Token(jwtSecret, `{"sub":"jsmith@example.org"}`),
+			},
+			ExpectedProxiedRequest:          privateQuery,
+			BackendStatusCode:               http.StatusOK,
+			BackendResponse:                 backendResponse,
+			WantClientResponseBody:          backendResponse,
+			RequestConfigProviderFactory:    authenticationRequestConfigProvider,
+			WantClientResponseStatusCode:    http.StatusOK,
+			WantProxyErrorHandlerInvocation: false,
+		})
+	})
+	t.Run("reject invalid token", func(t *testing.T) {
+		RunTestCase(t, ProxyTestCase{
+			Schema: publicSchema,
+			MiddleWares: []middleware.GraphqlMiddleware{
+				&middleware.ContextMiddleware{},
+			},
+			ClientRequest: publicQuery,
+			ClientHeaders: map[string]string{
+				userKey:       userValue,
+				Authorization: "Bearer " + hs256Token("guessed", `{"sub":"jsmith@example.org"}`),
+			},
+			RequestConfigProviderFactory:    authenticationRequestConfigProvider,
+			WantClientResponseStatusCode:    http.StatusOK,
+			WantProxyErrorHandlerInvocation: true,
+		})
+	})
+	t.Run("invalid token responds with 401", func(t *testing.T) {
+		schema := []byte(publicSchema)
+		prx := NewDefaultProxy(authenticationRequestConfigProvider(proxy.RequestConfig{Schema: &schema}), &middleware.ContextMiddleware{})
+		request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(publicQuery))
+		request.Header.Set(Authorization, "Bearer invalid")
+		recorder := httptest.NewRecorder()
+		prx.ServeHTTP(recorder, request)
+		if recorder.Code != http.StatusUnauthorized {
+			t.Fatalf("want status code: %d, got: %d", http.StatusUnauthorized, recorder.Code)
+		}
+	})
+	t.Run("context values are sent as variables", func(t *testing.T) {
+		RunTestCase(t, ProxyTestCase{
+			Schema: strings.Replace(publicSchema, `contextKey: "user")`, `contextKey: "user", variableName: "user")`, 1),
+			MiddleWares: []middleware.GraphqlMiddleware{
+				&middleware.ContextMiddleware{},
+			},
+			ClientRequest:          publicQuery,
+			ClientHeaders:          map[string]string{userKey: userValue},
+			ExpectedProxiedRequest: `{"query":"query myDocuments($user:String) {documents(user:$user) {sensitiveInformation}}","variables":{"user":"jsmith@example.org"}}`,
+			BackendStatusCode:      http.StatusOK,
+			BackendResponse:        backendResponse,
+			WantClientResponseBody: backendResponse,
+			RequestConfigProviderFactory: func(config proxy.RequestConfig) proxy.RequestConfigProvider {
+				config.AddHeadersToContext = [][]byte{[]byte(userKey)}
+				return proxy.NewStaticRequestConfigProvider(config)
+			},
+			WantClientResponseStatusCode:    http.StatusOK,
+			WantProxyErrorHandlerInvocation: false,
+		})
+	})
+	t.Run("persisted query by hash", func(t *testing.T) {
+		RunTestCase(t, ProxyTestCase{
+			Schema: publicSchema,
+			MiddleWares: []middleware.GraphqlMiddleware{
+				&middleware.ContextMiddleware{},
+			},
+			ClientRequest:                   persistedQueryRequest(""),
+			ClientHeaders:                   map[string]string{userKey: userValue},
+			ExpectedProxiedRequest:          privateQuery,
+			BackendStatusCode:               http.StatusOK,
+			BackendResponse:                 backendResponse,
+			WantClientResponseBody:          backendResponse,
+			RequestConfigProviderFactory:    persistedQueriesRequestConfigProvider(false),
+			WantClientResponseStatusCode:    http.StatusOK,
+			WantProxyErrorHandlerInvocation: false,
+		})
+	})
+	t.Run("persisted query errors are graphql responses", func(t *testing.T) {
+		run := func(allowListOnly bool, body string, wantStatusCode int, wantBody string) {
+			schema := []byte(publicSchema)
+			prx := NewDefaultProxy(persistedQueriesRequestConfigProvider(allowListOnly)(proxy.RequestConfig{Schema: &schema}), &middleware.ContextMiddleware{})
+			recorder := httptest.NewRecorder()
+			prx.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+			if recorder.Code != wantStatusCode {
+				t.Fatalf("want status code: %d, got: %d", wantStatusCode, recorder.Code)
+			}
+			if recorder.Body.String() != wantBody {
+				t.Fatalf("want body: %s, got: %s", wantBody, recorder.Body.String())
+			}
+		}
+		run(false, strings.Replace(persistedQueryRequest(""), persistedquery.Hash(persistedQuery), persistedquery.Hash("{__typename}"), 1), http.StatusOK,
+			`{"errors":[{"message":"PersistedQueryNotFound","extensions":{"code":"PERSISTED_QUERY_NOT_FOUND"}}]}`)
+		run(true, `{"query":"query myDocuments {documents {owner}}"}`, http.StatusForbidden,
+			`{"errors":[{"message":"query is not allowed","extensions":{"code":"PERSISTED_QUERY_NOT_ALLOWED"}}]}`)
+	})
+	t.Run("rate limited requests respond with 429", func(t *testing.T) {
+		schema := []byte(introspectionSchema)
+		rateLimit := &middleware.RateLimitMiddleware{
+			AnonymousLimit: ratelimit.Limit{Capacity: 1, Period: time.Minute},
+		}
+		prx := NewDefaultProxy(proxy.NewStaticRequestConfigProvider(proxy.RequestConfig{Schema: &schema}), rateLimit, &middleware.IntrospectionMiddleware{}, &middleware.ValidationMiddleware{})
+		serve := func() *httptest.ResponseRecorder {
+			recorder := httptest.NewRecorder()
+			prx.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"query":"{__typename}"}`)))
+			return recorder
+		}
+		if recorder := serve(); recorder.Code != http.StatusOK {
+			t.Fatalf("want status code: %d, got: %d", http.StatusOK, recorder.Code)
+		}
+		recorder := serve()
+		if recorder.Code != http.StatusTooManyRequests {
+			t.Fatalf("want status code: %d, got: %d", http.StatusTooManyRequests, recorder.Code)
+		}
+		if recorder.Header().Get("Retry-After") != "60" {
+			t.Fatalf("want Retry-After: 60, got: %s", recorder.Header().Get("Retry-After"))
+		}
+		wantBody := `{"errors":[{"message":"rate limit exceeded","extensions":{"code":"RATE_LIMITED","retryAfter":60}}]}`
+		if recorder.Body.String() != wantBody {
+			t.Fatalf("want body: %s, got: %s", wantBody, recorder.Body.String())
+		}
+	})
+	t.Run("concurrent requests don't wait for each other", func(t *testing.T) {
+		const concurrentRequests = 20
+
+		// the backend answers once all requests arrived, i.e. it fails if the proxy limits concurrent requests
+		var arrived int32
+		allArrived := make(chan struct{})
+		backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&arrived, 1) == concurrentRequests {
+				close(allArrived)
+			}
+			select {
+			case <-allArrived:
+				_, _ = w.Write([]byte(`{"data":{"documents":[]}}`))
+			case <-time.After(5 * time.Second):
+				w.WriteHeader(http.StatusGatewayTimeout)
+			}
+		}))
+		defer backend.Close()
+
+		backendURL, err := url.Parse(backend.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		schema := []byte(publicSchema)
+		prx := NewDefaultProxy(proxy.NewStaticRequestConfigProvider(proxy.RequestConfig{Schema: &schema, BackendURL: *backendURL}))
+
+		codes := make(chan int, concurrentRequests)
+		for i := 0; i < concurrentRequests; i++ {
+			go func() {
+				recorder := httptest.NewRecorder()
+				prx.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"query":"{documents {owner}}"}`)))
+				codes <- recorder.Code
+			}()
+		}
+
+		for i := 0; i < concurrentRequests; i++ {
+			if code := <-codes; code != http.StatusOK {
+				t.Fatalf("want status code: %d, got: %d", http.StatusOK, code)
+			}
+		}
+	})
 	t.Run("handle request response e2e", func(t *testing.T) {
 		RunTestCase(t, ProxyTestCase{
 			Schema: publicSchema,
@@ -306,6 +664,38 @@ const assetOutput = `{"query":"query testQueryWithoutHandle {assets(first:1) {id
 const variableAssetInput = `{"query":"query testQueryWithoutHandle {assets(first: 1) { id fileName url(transformation: {image: {resize: {width: 100, height: 100}}})}}","variables":{"id":1}}`
 const variableAssetOutput = `{"query":"query testQueryWithoutHandle {assets(first:1) {id fileName handle}}","variables":{"id":1}}`
 
+const coerceVariablesInput = `{"query":"query assetsQuery($first: Int = 10, $unused: String) {assets(first: $first) {id}}","variables":{"unused":"foo","undefined":true}}`
+const coerceVariablesOutput = `{"query":"query assetsQuery($first:Int=10 $unused:String) {assets(first:$first) {id}}","variables":{"first":10,"unused":"foo"}}`
+const invalidVariablesInput = `{"query":"query assetsQuery($first: Int) {assets(first: $first) {id}}","variables":{"first":"one"}}`
+
+const unusedVariableInput = `{"query":"query assetsQuery($unused: Int) {assets {id}}"}`
+const unusedVariableOutput = `{"query":"query assetsQuery($unused:Int) {assets {id}}"}`
+
+func authenticationRequestConfigProvider(config proxy.RequestConfig) proxy.RequestConfigProvider {
+	keys := jwt.NewKeySet()
+	keys.AddHMACKey("", []byte(jwtSecret))
+	config.AddHeadersToContext = [][]byte{[]byte(userKey)}
+	config.Authentication = &proxy.Authentication{
+		Verifier: jwt.NewVerifier(keys),
+		ClaimsToContext: map[string]string{
+			"sub": userKey,
+		},
+	}
+	return proxy.NewStaticRequestConfigProvider(config)
+}
+
+func hs256Token(secret, claims string) string {
+	signingInput := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`)) + "." + base64.RawURLEncoding.EncodeToString([]byte(claims))
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(signingInput))
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func coerceVariablesRequestConfigProvider(config proxy.RequestConfig) proxy.RequestConfigProvider {
+	config.CoerceVariables = true
+	return proxy.NewStaticRequestConfigProvider(config)
+}
+
 /*
 
 the public schema for reference
@@ -324,6 +714,27 @@ type Document implements Node {
 }
 */
 
+const persistedQuery = "query myDocuments {documents {sensitiveInformation}}"
+
+// persistedQueryRequest returns a request sending the hash of persistedQuery and the query (optional)
+func persistedQueryRequest(query string) string {
+	return `{"query":"` + query + `","extensions":{"persistedQuery":{"version":1,"sha256Hash":"` + persistedquery.Hash(persistedQuery) + `"}}}`
+}
+
+// persistedQueriesRequestConfigProvider returns a request config provider with persistedQuery in the store
+func persistedQueriesRequestConfigProvider(allowListOnly bool) func(config proxy.RequestConfig) proxy.RequestConfigProvider {
+	return func(config proxy.RequestConfig) proxy.RequestConfigProvider {
+		manifest := persistedquery.NewManifest()
+		manifest.Add(persistedQuery)
+		config.AddHeadersToContext = [][]byte{[]byte(userKey)}
+		config.PersistedQueries = &proxy.PersistedQueries{
+			Store:         manifest,
+			AllowListOnly: allowListOnly,
+		}
+		return proxy.NewStaticRequestConfigProvider(config)
+	}
+}
+
 // e2e test data
 const (
 	publicSchema = `
@@ -354,8 +765,72 @@ type Document implements Node {
 	Authorization     = "Authorization"
 	userKey           = "user"
 	userValue         = "jsmith@example.org"
+	jwtSecret         = "secret"
+)
+
+const (
+	introspectionSchema = `
+schema {
+	query: Query
+}
+
+type Query {
+	documents: [Document] @addArgumentFromContext(name: "user",contextKey: "user")
+}
+
+type Document {
+	owner: String
+	sensitiveInformation: String
+}
+`
+	introspectionQuery    = `{"operationName":"typeByName","query":"query typeByName($name: String!) {__type(name: $name) {name fields {name args {name}}}}","variables":{"name":"Query"}}`
+	introspectionResponse = `{"data":{"__type":{"name":"Query","fields":[{"name":"documents","args":[]}]}}}`
+)
+
+const (
+	authorizationSchema = `
+schema {
+	query: Query
+}
+
+type Query {
+	documents: [Document] @requiresScope(scopes: ["documents:read"])
+}
+
+type Document {
+	owner: String
+	sensitiveInformation: String @hasRole(roles: ["owner"])
+}
+`
+	authorizationQuery           = `{"query":"query myDocuments {documents {owner sensitiveInformation}}"}`
+	authorizationProxiedQuery    = `{"query":"query myDocuments {documents {owner sensitiveInformation:__typename}}"}`
+	authorizationBackendResponse = `{"data":{"documents":[{"owner":"jsmith","sensitiveInformation":"Document"}]}}`
+	authorizationClientResponse  = `{"errors":[{"message":"not authorized to access field 'Document.sensitiveInformation'","path":["documents",0,"sensitiveInformation"]}],"data":{"documents":[{"owner":"jsmith","sensitiveInformation":null}]}}`
 )
 
+// responseSuffixMiddleware appends suffix to the response from the backend
+
+type responseSuffixMiddleware struct {
+	suffix string
+	err    error
+}
+
+func (r *responseSuffixMiddleware) PrepareSchema(ctx context.Context, l *lookup.Lookup, w *lookup.Walker, parser *parser.Parser, mod *parser.ManualAstMod) error {
+	return nil
+}
+
+func (r *responseSuffixMiddleware) OnRequest(ctx context.Context, l *lookup.Lookup, w *lookup.Walker, parser *parser.Parser, mod *parser.ManualAstMod) error {
+	return nil
+}
+
+func (r *responseSuffixMiddleware) OnResponse(ctx context.Context, response *[]byte, l *lookup.Lookup, w *lookup.Walker, parser *parser.Parser, mod *parser.ManualAstMod) error {
+	if r.err != nil {
+		return r.err
+	}
+	*response = append(*response, r.suffix...)
+	return nil
+}
+
 // failing request config provider
 
 type failingRequestConfigProvider struct{}
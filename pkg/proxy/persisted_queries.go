@@ -0,0 +1,79 @@
+package proxy
+
+import (
+	"context"
+
+	"github.com/jensneuse/graphql-go-tools/pkg/middleware"
+	"github.com/jensneuse/graphql-go-tools/pkg/persistedquery"
+)
+
+// PersistedQueries configures the support of persisted queries sent as
+// {"extensions":{"persistedQuery":{"version":1,"sha256Hash":"<sha256 of the query>"}}}
+type PersistedQueries struct {
+	// Store holds the known queries, e.g. a persistedquery.LRU for automatic persisted queries
+	// or a persistedquery.Manifest of the operations of the production clients
+	Store persistedquery.Store
+	// AllowListOnly rejects all queries which aren't part of the store, clients can't register new queries
+	// this blocks ad-hoc queries entirely
+	AllowListOnly bool
+}
+
+// ResolveQuery sets the query of a request sent with a hash from the store
+// requests sending the query together with its hash register the query (automatic persisted queries)
+// unknown hashes are answered with persistedquery.ErrNotFound so that clients retry with the query
+func (p *PersistedQueries) ResolveQuery(ctx context.Context, request *middleware.GraphQLRequest) error {
+
+	var extension *middleware.PersistedQueryExtension
+	if request.Extensions != nil {
+		extension = request.Extensions.PersistedQuery
+	}
+
+	if extension == nil {
+		if p.AllowListOnly {
+			return p.allowed(ctx, persistedquery.Hash(request.Query))
+		}
+		return nil
+	}
+
+	if extension.Version != 1 {
+		return persistedquery.ErrUnsupportedVersion
+	}
+
+	if request.Query == "" {
+		query, ok, err := p.Store.Get(ctx, extension.Sha256Hash)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return persistedquery.ErrNotFound
+		}
+		request.Query = query
+		return nil
+	}
+
+	if persistedquery.Hash(request.Query) != extension.Sha256Hash {
+		return persistedquery.ErrHashMismatch
+	}
+
+	if p.AllowListOnly {
+		return p.allowed(ctx, extension.Sha256Hash)
+	}
+
+	err := p.Store.Put(ctx, extension.Sha256Hash, request.Query)
+	if err == persistedquery.ErrReadOnly {
+		return nil
+	}
+
+	return err
+}
+
+func (p *PersistedQueries) allowed(ctx context.Context, hash string) error {
+	_, ok, err := p.Store.Get(ctx, hash)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return persistedquery.ErrNotAllowed
+	}
+	return nil
+}
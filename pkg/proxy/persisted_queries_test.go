@@ -0,0 +1,91 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/jensneuse/graphql-go-tools/pkg/middleware"
+	"github.com/jensneuse/graphql-go-tools/pkg/persistedquery"
+)
+
+func TestPersistedQueries_ResolveQuery(t *testing.T) {
+
+	ctx := context.Background()
+	registered := "query documents {documents {owner}}"
+	adHoc := "{documents {sensitiveInformation}}"
+
+	persistedQueries := func(allowListOnly bool) *PersistedQueries {
+		store := persistedquery.NewLRU(8)
+		_ = store.Put(ctx, persistedquery.Hash(registered), registered)
+		return &PersistedQueries{
+			Store:         store,
+			AllowListOnly: allowListOnly,
+		}
+	}
+
+	request := func(query, hash string) *middleware.GraphQLRequest {
+		request := &middleware.GraphQLRequest{Query: query}
+		if hash != "" {
+			request.Extensions = &middleware.GraphQLRequestExtensions{
+				PersistedQuery: &middleware.PersistedQueryExtension{Version: 1, Sha256Hash: hash},
+			}
+		}
+		return request
+	}
+
+	run := func(persistedQueries *PersistedQueries, request *middleware.GraphQLRequest, want string) {
+		err := persistedQueries.ResolveQuery(ctx, request)
+		if err != nil {
+			panic(err)
+		}
+		if request.Query != want {
+			panic(fmt.Errorf("want query: %s, got: %s", want, request.Query))
+		}
+	}
+
+	runErr := func(persistedQueries *PersistedQueries, request *middleware.GraphQLRequest, wantErr error) {
+		err := persistedQueries.ResolveQuery(ctx, request)
+		if err != wantErr {
+			panic(fmt.Errorf("want err: %v, got: %v", wantErr, err))
+		}
+		if _, ok := err.(ResponseError); !ok {
+			panic(fmt.Errorf("want ResponseError, got: %T", err))
+		}
+	}
+
+	t.Run("query by hash", func(t *testing.T) {
+		run(persistedQueries(false), request("", persistedquery.Hash(registered)), registered)
+		run(persistedQueries(true), request("", persistedquery.Hash(registered)), registered)
+	})
+	t.Run("unknown hash", func(t *testing.T) {
+		runErr(persistedQueries(false), request("", persistedquery.Hash(adHoc)), persistedquery.ErrNotFound)
+	})
+	t.Run("query with hash is registered", func(t *testing.T) {
+		queries := persistedQueries(false)
+		run(queries, request(adHoc, persistedquery.Hash(adHoc)), adHoc)
+		run(queries, request("", persistedquery.Hash(adHoc)), adHoc)
+	})
+	t.Run("hash mismatch", func(t *testing.T) {
+		runErr(persistedQueries(false), request(adHoc, persistedquery.Hash(registered)), persistedquery.ErrHashMismatch)
+	})
+	t.Run("unsupported version", func(t *testing.T) {
+		r := request("", persistedquery.Hash(registered))
+		r.Extensions.PersistedQuery.Version = 2
+		runErr(persistedQueries(false), r, persistedquery.ErrUnsupportedVersion)
+	})
+	t.Run("ad-hoc queries", func(t *testing.T) {
+		run(persistedQueries(false), request(adHoc, ""), adHoc)
+		run(persistedQueries(true), request(registered, ""), registered)
+		runErr(persistedQueries(true), request(adHoc, ""), persistedquery.ErrNotAllowed)
+	})
+	t.Run("allow list only doesn't register queries", func(t *testing.T) {
+		queries := persistedQueries(true)
+		runErr(queries, request(adHoc, persistedquery.Hash(adHoc)), persistedquery.ErrNotAllowed)
+		runErr(queries, request("", persistedquery.Hash(adHoc)), persistedquery.ErrNotFound)
+	})
+	t.Run("read only store", func(t *testing.T) {
+		manifest := persistedquery.NewManifest()
+		run(&PersistedQueries{Store: manifest}, request(adHoc, persistedquery.Hash(adHoc)), adHoc)
+	})
+}
@@ -22,11 +22,16 @@ type Request struct {
 	Body           io.Reader
 	Context        context.Context
 	GraphQLRequest middleware.GraphQLRequest
+	// Resolved is set by AcceptRequest if a middleware resolved the request
+	// the buffer then contains the response for the client and the request must not be dispatched to the backend
+	Resolved bool
 }
 
 type RequestInterface interface {
 	AcceptRequest(buff *bytes.Buffer) error
 	DispatchRequest(buff *bytes.Buffer) (io.ReadCloser, error)
-	AcceptResponse()
-	DispatchResponse()
+	// AcceptResponse runs the OnResponse handlers of the middlewares over the response in buff
+	AcceptResponse(buff *bytes.Buffer) error
+	// DispatchResponse writes the response in buff to the client
+	DispatchResponse(buff *bytes.Buffer, w io.Writer) error
 }
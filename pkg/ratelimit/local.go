@@ -0,0 +1,120 @@
+package ratelimit
+
+import (
+	"container/list"
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Local is an in-memory store which keeps the buckets of a limited number of clients
+// only buckets which have refilled are evicted, least recently seen first, so that evicting a bucket never resets a limit,
+// if the buckets of all clients are in use new clients are rejected until the first bucket has refilled
+type Local struct {
+	// Now defaults to time.Now
+	Now func() time.Time
+
+	capacity int
+	mux      sync.Mutex
+	buckets  map[string]*list.Element
+	order    *list.List
+}
+
+type bucket struct {
+	key     string
+	tokens  float64
+	updated time.Time
+	// full is the time the bucket is full again
+	full time.Time
+}
+
+// NewLocal returns a store for the buckets of up to capacity clients
+func NewLocal(capacity int) *Local {
+	return &Local{
+		capacity: capacity,
+		buckets:  make(map[string]*list.Element, capacity),
+		order:    list.New(),
+	}
+}
+
+func (l *Local) Take(ctx context.Context, key string, tokens int, limit Limit) (Result, error) {
+	if !limit.Valid() {
+		return Result{}, ErrInvalidLimit
+	}
+
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	now := l.now()
+	b, retryAfter := l.bucket(key, limit, now)
+	if b == nil {
+		return Result{RetryAfter: retryAfter}, nil
+	}
+
+	rate := limit.tokensPerSecond()
+	b.tokens = math.Min(float64(limit.Capacity), b.tokens+now.Sub(b.updated).Seconds()*rate)
+	b.updated = now
+
+	if b.tokens < float64(tokens) {
+		retryAfter := time.Duration((float64(tokens) - b.tokens) / rate * float64(time.Second))
+		return Result{Remaining: int(b.tokens), RetryAfter: retryAfter}, nil
+	}
+
+	b.tokens -= float64(tokens)
+	b.full = now.Add(time.Duration((float64(limit.Capacity) - b.tokens) / rate * float64(time.Second)))
+	return Result{Allowed: true, Remaining: int(b.tokens)}, nil
+}
+
+// Len returns the number of buckets
+func (l *Local) Len() int {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	return l.order.Len()
+}
+
+// bucket returns the bucket of the key, new buckets are full
+// if there's no room for a new bucket it returns nil and the duration until the first bucket has refilled
+func (l *Local) bucket(key string, limit Limit, now time.Time) (*bucket, time.Duration) {
+	if element, ok := l.buckets[key]; ok {
+		l.order.MoveToFront(element)
+		return element.Value.(*bucket), 0
+	}
+
+	if l.order.Len() >= l.capacity {
+		retryAfter, evicted := l.evictRefilled(now)
+		if !evicted {
+			return nil, retryAfter
+		}
+	}
+
+	b := &bucket{key: key, tokens: float64(limit.Capacity), updated: now, full: now}
+	l.buckets[key] = l.order.PushFront(b)
+
+	return b, 0
+}
+
+// evictRefilled evicts the least recently seen bucket which has refilled
+// if no bucket has refilled yet it returns the duration until the first one has
+func (l *Local) evictRefilled(now time.Time) (retryAfter time.Duration, evicted bool) {
+	retryAfter = time.Duration(math.MaxInt64)
+	for element := l.order.Back(); element != nil; element = element.Prev() {
+		b := element.Value.(*bucket)
+		if !b.full.After(now) {
+			l.order.Remove(element)
+			delete(l.buckets, b.key)
+			return 0, true
+		}
+		if until := b.full.Sub(now); until < retryAfter {
+			retryAfter = until
+		}
+	}
+	return retryAfter, false
+}
+
+func (l *Local) now() time.Time {
+	if l.Now != nil {
+		return l.Now()
+	}
+	return time.Now()
+}
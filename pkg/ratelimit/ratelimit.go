@@ -0,0 +1,107 @@
+// Package ratelimit implements token bucket rate limits for graphql requests
+//
+// Each client has its own bucket which holds up to Limit.Capacity tokens and gets refilled evenly,
+// an empty bucket is full again after Limit.Period.
+// Requests take tokens from the bucket of their client, e.g. a single token per request or the cost of the operation.
+// Buckets are kept by a Store, the Local store keeps them in memory of a single process,
+// shared stores (e.g. redis) allow multiple proxies to enforce a common limit.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jensneuse/graphql-go-tools/pkg/graphqlerror"
+)
+
+// Limit configures the token bucket of a client
+type Limit struct {
+	// Capacity is the maximum number of tokens of a bucket
+	Capacity int
+	// Period is the duration in which an empty bucket gets refilled
+	Period time.Duration
+}
+
+// Valid returns true if the limit has a positive capacity and period
+func (l Limit) Valid() bool {
+	return l.Capacity > 0 && l.Period > 0
+}
+
+// tokensPerSecond is the refill rate of the bucket
+func (l Limit) tokensPerSecond() float64 {
+	return float64(l.Capacity) / l.Period.Seconds()
+}
+
+// Result is the outcome of taking tokens from a bucket
+type Result struct {
+	// Allowed is true if the tokens were taken
+	Allowed bool
+	// Remaining is the number of tokens left in the bucket
+	Remaining int
+	// RetryAfter is the duration until enough tokens are available, it's 0 if the tokens were taken
+	RetryAfter time.Duration
+}
+
+// Store keeps the token buckets of the clients
+type Store interface {
+	// Take takes tokens from the bucket identified by key, if the bucket doesn't hold enough tokens none are taken
+	Take(ctx context.Context, key string, tokens int, limit Limit) (Result, error)
+}
+
+// ErrInvalidLimit is returned by stores for limits without capacity or period
+var ErrInvalidLimit = errors.New("ratelimit: limit must have a positive capacity and period")
+
+// Error is an error which is answered with a graphql response
+type Error struct {
+	Message string
+	Code    string
+	Status  int
+	// RetryAfter (optional) tells the client when to retry, it's sent as Retry-After header and retryAfter extension
+	RetryAfter time.Duration
+}
+
+var (
+	// ErrRateLimited rejects requests of clients which ran out of tokens
+	ErrRateLimited = Error{Message: "rate limit exceeded", Code: "RATE_LIMITED", Status: http.StatusTooManyRequests}
+	// ErrCostExceedsLimit rejects requests which cost more tokens than a bucket can hold, retrying them doesn't help
+	ErrCostExceedsLimit = Error{Message: "query cost exceeds the rate limit", Code: "QUERY_COST_EXCEEDS_RATE_LIMIT", Status: http.StatusBadRequest}
+	// ErrMissingClient rejects requests which don't identify their client and therefore have no bucket
+	ErrMissingClient = Error{Message: "rate limited requests must identify their client", Code: "MISSING_CLIENT", Status: http.StatusUnauthorized}
+)
+
+// WithRetryAfter returns a copy of the error with the retry hint set
+func (e Error) WithRetryAfter(retryAfter time.Duration) Error {
+	e.RetryAfter = retryAfter
+	return e
+}
+
+func (e Error) Error() string {
+	return "ratelimit: " + e.Message
+}
+
+func (e Error) StatusCode() int {
+	return e.Status
+}
+
+// Header returns the Retry-After header in seconds, rounded up
+func (e Error) Header() http.Header {
+	header := http.Header{}
+	if seconds := e.retryAfterSeconds(); seconds > 0 {
+		header.Set("Retry-After", strconv.Itoa(seconds))
+	}
+	return header
+}
+
+// Response returns the graphql response for the error, e.g.:
+// {"errors":[{"message":"rate limit exceeded","extensions":{"code":"RATE_LIMITED","retryAfter":2}}]}
+func (e Error) Response() []byte {
+	return graphqlerror.Response(graphqlerror.Error{Message: e.Message, Extensions: graphqlerror.Extensions{Code: e.Code, RetryAfter: e.retryAfterSeconds()}})
+}
+
+func (e Error) retryAfterSeconds() int {
+	return int(math.Ceil(e.RetryAfter.Seconds()))
+}
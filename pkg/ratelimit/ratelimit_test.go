@@ -0,0 +1,111 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestLocal(t *testing.T) {
+
+	ctx := context.Background()
+	now := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	limit := Limit{Capacity: 10, Period: 10 * time.Second}
+
+	newStore := func(capacity int) *Local {
+		store := NewLocal(capacity)
+		store.Now = func() time.Time {
+			return now
+		}
+		return store
+	}
+
+	take := func(store *Local, key string, tokens int, want Result) {
+		got, err := store.Take(ctx, key, tokens, limit)
+		if err != nil {
+			panic(err)
+		}
+		if got != want {
+			panic(fmt.Errorf("want: %+v for %d tokens of %s, got: %+v", want, tokens, key, got))
+		}
+	}
+
+	t.Run("tokens are taken until the bucket is empty", func(t *testing.T) {
+		store := newStore(10)
+		take(store, "a", 4, Result{Allowed: true, Remaining: 6})
+		take(store, "a", 6, Result{Allowed: true, Remaining: 0})
+		take(store, "a", 2, Result{Remaining: 0, RetryAfter: 2 * time.Second})
+	})
+	t.Run("denied requests don't take tokens", func(t *testing.T) {
+		store := newStore(10)
+		take(store, "a", 8, Result{Allowed: true, Remaining: 2})
+		take(store, "a", 5, Result{Remaining: 2, RetryAfter: 3 * time.Second})
+		take(store, "a", 2, Result{Allowed: true, Remaining: 0})
+	})
+	t.Run("buckets are refilled over time", func(t *testing.T) {
+		store := newStore(10)
+		take(store, "a", 10, Result{Allowed: true, Remaining: 0})
+		now = now.Add(3 * time.Second)
+		take(store, "a", 3, Result{Allowed: true, Remaining: 0})
+		now = now.Add(time.Minute)
+		take(store, "a", 1, Result{Allowed: true, Remaining: 9})
+	})
+	t.Run("clients have their own bucket", func(t *testing.T) {
+		store := newStore(10)
+		take(store, "a", 10, Result{Allowed: true, Remaining: 0})
+		take(store, "b", 1, Result{Allowed: true, Remaining: 9})
+	})
+	t.Run("least recently seen refilled buckets are evicted", func(t *testing.T) {
+		store := newStore(2)
+		take(store, "a", 10, Result{Allowed: true, Remaining: 0})
+		take(store, "b", 5, Result{Allowed: true, Remaining: 5})
+		now = now.Add(5 * time.Second)
+		take(store, "a", 0, Result{Allowed: true, Remaining: 5})
+		take(store, "c", 10, Result{Allowed: true, Remaining: 0})
+		if store.Len() != 2 {
+			panic(fmt.Errorf("want 2 buckets, got: %d", store.Len()))
+		}
+		take(store, "a", 5, Result{Allowed: true, Remaining: 0})
+		take(store, "c", 1, Result{Remaining: 0, RetryAfter: time.Second})
+	})
+	t.Run("buckets which haven't refilled aren't evicted", func(t *testing.T) {
+		store := newStore(2)
+		take(store, "victim", 10, Result{Allowed: true, Remaining: 0})
+		take(store, "a", 1, Result{Allowed: true, Remaining: 9})
+		for i := 0; i < 1024; i++ {
+			take(store, fmt.Sprintf("throwaway%d", i), 1, Result{RetryAfter: time.Second})
+		}
+		take(store, "victim", 1, Result{Remaining: 0, RetryAfter: time.Second})
+		now = now.Add(time.Second)
+		take(store, "b", 1, Result{Allowed: true, Remaining: 9})
+		take(store, "victim", 1, Result{Allowed: true, Remaining: 0})
+	})
+	t.Run("invalid limit", func(t *testing.T) {
+		_, err := newStore(10).Take(ctx, "a", 1, Limit{Capacity: 10})
+		if err != ErrInvalidLimit {
+			panic(fmt.Errorf("want ErrInvalidLimit, got: %v", err))
+		}
+	})
+}
+
+func TestError(t *testing.T) {
+
+	err := ErrRateLimited.WithRetryAfter(1500 * time.Millisecond)
+
+	want := `{"errors":[{"message":"rate limit exceeded","extensions":{"code":"RATE_LIMITED","retryAfter":2}}]}`
+	if got := string(err.Response()); got != want {
+		panic(fmt.Errorf("want: %s, got: %s", want, got))
+	}
+	if got := err.Header().Get("Retry-After"); got != "2" {
+		panic(fmt.Errorf("want Retry-After: 2, got: %s", got))
+	}
+
+	want = `{"errors":[{"message":"query cost exceeds the rate limit","extensions":{"code":"QUERY_COST_EXCEEDS_RATE_LIMIT"}}]}`
+	if got := string(ErrCostExceedsLimit.Response()); got != want {
+		panic(fmt.Errorf("want: %s, got: %s", want, got))
+	}
+	if got := ErrCostExceedsLimit.Header().Get("Retry-After"); got != "" {
+		panic(fmt.Errorf("want no Retry-After, got: %s", got))
+	}
+}
@@ -0,0 +1,97 @@
+package responsecache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// LRU is an in-memory store which keeps a limited number of responses, the least recently used response is evicted first
+type LRU struct {
+	// Now defaults to time.Now
+	Now func() time.Time
+
+	capacity int
+	mux      sync.Mutex
+	entries  map[string]*list.Element
+	order    *list.List
+}
+
+type lruEntry struct {
+	key      string
+	response []byte
+	expires  time.Time
+}
+
+// NewLRU returns a store for up to capacity responses
+func NewLRU(capacity int) *LRU {
+	return &LRU{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element, capacity),
+		order:    list.New(),
+	}
+}
+
+func (l *LRU) Get(ctx context.Context, key string) (response []byte, ok bool, err error) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	element, ok := l.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	entry := element.Value.(*lruEntry)
+	if !l.now().Before(entry.expires) {
+		l.remove(element)
+		return nil, false, nil
+	}
+
+	l.order.MoveToFront(element)
+	return entry.response, true, nil
+}
+
+func (l *LRU) Set(ctx context.Context, key string, response []byte, ttl time.Duration) error {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	entry := &lruEntry{
+		key:      key,
+		response: append([]byte(nil), response...),
+		expires:  l.now().Add(ttl),
+	}
+
+	if element, ok := l.entries[key]; ok {
+		element.Value = entry
+		l.order.MoveToFront(element)
+		return nil
+	}
+
+	l.entries[key] = l.order.PushFront(entry)
+
+	for l.order.Len() > l.capacity {
+		l.remove(l.order.Back())
+	}
+
+	return nil
+}
+
+// Len returns the number of stored responses including expired ones which weren't evicted yet
+func (l *LRU) Len() int {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	return l.order.Len()
+}
+
+func (l *LRU) remove(element *list.Element) {
+	l.order.Remove(element)
+	delete(l.entries, element.Value.(*lruEntry).key)
+}
+
+func (l *LRU) now() time.Time {
+	if l.Now != nil {
+		return l.Now()
+	}
+	return time.Now()
+}
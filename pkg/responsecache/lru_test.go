@@ -0,0 +1,55 @@
+package responsecache
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestLRU(t *testing.T) {
+
+	ctx := context.Background()
+	now := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	lru := NewLRU(2)
+	lru.Now = func() time.Time {
+		return now
+	}
+
+	get := func(key, want string, wantOk bool) {
+		response, ok, err := lru.Get(ctx, key)
+		if err != nil {
+			panic(err)
+		}
+		if ok != wantOk || string(response) != want {
+			panic(fmt.Errorf("want: '%s' (%t) for key %s, got: '%s' (%t)", want, wantOk, key, string(response), ok))
+		}
+	}
+
+	t.Run("least recently used responses are evicted", func(t *testing.T) {
+		_ = lru.Set(ctx, "a", []byte("a"), time.Minute)
+		_ = lru.Set(ctx, "b", []byte("b"), time.Minute)
+		get("a", "a", true)
+		_ = lru.Set(ctx, "c", []byte("c"), time.Minute)
+		get("b", "", false)
+		get("a", "a", true)
+		get("c", "c", true)
+	})
+	t.Run("responses expire", func(t *testing.T) {
+		_ = lru.Set(ctx, "a", []byte("updated"), 10*time.Second)
+		now = now.Add(9 * time.Second)
+		get("a", "updated", true)
+		now = now.Add(time.Second)
+		get("a", "", false)
+		if lru.Len() != 1 {
+			panic(fmt.Errorf("want expired response removed, got: %d responses", lru.Len()))
+		}
+	})
+	t.Run("responses are copied", func(t *testing.T) {
+		response := []byte("d")
+		_ = lru.Set(ctx, "d", response, time.Minute)
+		response[0] = 'x'
+		get("d", "d", true)
+	})
+}
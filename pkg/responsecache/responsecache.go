@@ -0,0 +1,15 @@
+// Package responsecache implements stores for cached graphql responses, see middleware.ResponseCacheMiddleware
+package responsecache
+
+import (
+	"context"
+	"time"
+)
+
+// Store stores responses by the cache key of the request
+type Store interface {
+	// Get returns the response of the key, ok is false if there's no response or it's expired
+	Get(ctx context.Context, key string) (response []byte, ok bool, err error)
+	// Set stores the response for the duration of ttl, the store must not keep a reference to the response
+	Set(ctx context.Context, key string, response []byte, ttl time.Duration) error
+}
@@ -0,0 +1,70 @@
+package responsewalker
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+)
+
+// Editor collects edits of a response, e.g. from a Visitor, and applies them at once
+// edits are located by their offset so that they don't invalidate the offsets of fields visited later
+type Editor struct {
+	edits []edit
+}
+
+type edit struct {
+	offset      int
+	length      int
+	replacement []byte
+}
+
+// NewEditor returns an empty Editor
+func NewEditor() *Editor {
+	return &Editor{
+		edits: make([]edit, 0, 8),
+	}
+}
+
+// Reset removes all collected edits
+func (e *Editor) Reset() {
+	e.edits = e.edits[:0]
+}
+
+// Replace replaces old at offset with replacement, e.g. field.Value at field.Offset or field.Key at field.KeyOffset
+func (e *Editor) Replace(offset int, old []byte, replacement []byte) {
+	e.edits = append(e.edits, edit{
+		offset:      offset,
+		length:      len(old),
+		replacement: replacement,
+	})
+}
+
+// Apply returns the response with all edits applied
+// it's an error if edits overlap, e.g. after replacing the value of a field and one of its children
+func (e *Editor) Apply(response []byte) ([]byte, error) {
+
+	if len(e.edits) == 0 {
+		return response, nil
+	}
+
+	sort.SliceStable(e.edits, func(i, j int) bool {
+		return e.edits[i].offset < e.edits[j].offset
+	})
+
+	out := bytes.Buffer{}
+	out.Grow(len(response))
+
+	position := 0
+	for _, edit := range e.edits {
+		if edit.offset < position || edit.offset+edit.length > len(response) {
+			return nil, fmt.Errorf("Apply: invalid edit at offset %d", edit.offset)
+		}
+		out.Write(response[position:edit.offset])
+		out.Write(edit.replacement)
+		position = edit.offset + edit.length
+	}
+
+	out.Write(response[position:])
+
+	return out.Bytes(), nil
+}
@@ -0,0 +1,377 @@
+// Package responsewalker walks graphql json responses together with the executable definition of the request
+//
+// Each value in the response is mapped back to the field in the query which selected it and to the field definition
+// in the schema, so that response transformations can be written per schema field instead of per json path:
+//
+//	walker := responsewalker.New()
+//	walker.SetInput(l)
+//	err := walker.Walk("", response, func(field responsewalker.Field) error {
+//		// field.Definition, field.TypeName, field.Path, field.Value, ...
+//		return nil
+//	})
+//
+// Aliases, inline fragments, fragment spreads, lists and __typename are taken into account.
+// Values of abstract types (interfaces, unions) are resolved to their concrete type if __typename is part of the response,
+// otherwise fragments on all possible types are matched against the keys present in the response.
+package responsewalker
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/jensneuse/graphql-go-tools/pkg/document"
+	"github.com/jensneuse/graphql-go-tools/pkg/lookup"
+	"github.com/tidwall/gjson"
+)
+
+// SkipChildren might be returned by a Visitor to not walk into the value of the current field
+var SkipChildren = errors.New("skip children")
+
+var typeNameFieldName = []byte("__typename")
+
+// Field is a field in the response
+type Field struct {
+	// Ref is the reference of the field in the executable definition
+	// if multiple fields are merged into the same response key it's the first one
+	Ref int
+	// Definition is the field definition in the schema, HasDefinition is false for __typename
+	Definition    document.FieldDefinition
+	HasDefinition bool
+	// TypeName is the name of the type the field is defined on
+	// for abstract types it's the concrete type if it could be resolved using __typename
+	TypeName document.ByteSliceReference
+	// Path is the path of the value in the response, e.g. 'data.assets.0.url', it can be used with gjson/sjson
+	Path string
+	// Key is the raw (quoted) json key of the field, KeyOffset its position in the response
+	Key       []byte
+	KeyOffset int
+	// Value is the raw json value of the field, Offset its position in the response
+	Value  []byte
+	Offset int
+}
+
+// Visitor gets called for each field in the response
+// returning SkipChildren skips the selections of the field, any other error stops the walk
+type Visitor func(field Field) error
+
+// Walker walks a json response together with the executable definition
+type Walker struct {
+	l         *lookup.Lookup
+	visit     Visitor
+	fragments []document.ByteSliceReference
+}
+
+// selection is a response key with all fields selecting it
+type selection struct {
+	responseKey document.ByteSliceReference
+	fieldRefs   []int
+	typeName    document.ByteSliceReference
+}
+
+// New returns a Walker, SetInput has to be called before walking a response
+func New() *Walker {
+	return &Walker{
+		fragments: make([]document.ByteSliceReference, 0, 8),
+	}
+}
+
+func (w *Walker) SetInput(l *lookup.Lookup) {
+	w.l = l
+}
+
+// Walk walks the 'data' of the response for the operation with the given name
+// operationName might be empty if the executable definition contains exactly one operation
+func (w *Walker) Walk(operationName string, response []byte, visit Visitor) error {
+
+	operation, err := w.operation(operationName)
+	if err != nil {
+		return err
+	}
+
+	return w.WalkOperation(operation, response, visit)
+}
+
+// WalkOperation walks the 'data' of the response for the operation
+func (w *Walker) WalkOperation(operation document.OperationDefinition, response []byte, visit Visitor) error {
+
+	json := string(response)
+	if !gjson.Valid(json) {
+		return fmt.Errorf("WalkOperation: invalid json response")
+	}
+
+	w.visit = visit
+	w.fragments = w.fragments[:0]
+
+	root := gjson.Parse(json)
+	rootOffset := len(response) - len(bytes.TrimLeft(response, " \t\r\n"))
+
+	_, data, ok := w.member(root, "data")
+	if !ok || !data.IsObject() {
+		return nil
+	}
+
+	return w.walkObject([]int{operation.SelectionSet}, w.rootTypeName(operation), data, rootOffset+data.Index, "data")
+}
+
+func (w *Walker) operation(operationName string) (document.OperationDefinition, error) {
+
+	operations := w.l.OperationDefinitions()
+
+	if operationName == "" {
+		if len(operations) != 1 {
+			return document.OperationDefinition{}, fmt.Errorf("Walk: operation name must be provided if the document contains %d operations", len(operations))
+		}
+		return operations[0], nil
+	}
+
+	for i := range operations {
+		if string(w.l.ByteSlice(operations[i].Name)) == operationName {
+			return operations[i], nil
+		}
+	}
+
+	return document.OperationDefinition{}, fmt.Errorf("Walk: operation '%s' not found", operationName)
+}
+
+func (w *Walker) rootTypeName(operation document.OperationDefinition) document.ByteSliceReference {
+
+	var rootType document.ObjectTypeDefinition
+	var ok bool
+
+	switch operation.OperationType {
+	case document.OperationTypeMutation:
+		rootType, ok = w.l.MutationObjectTypeDefinition()
+	case document.OperationTypeSubscription:
+		rootType, ok = w.l.SubscriptionObjectTypeDefinition()
+	default:
+		rootType, ok = w.l.QueryObjectTypeDefinition()
+	}
+
+	if !ok {
+		return document.ByteSliceReference{}
+	}
+
+	return rootType.Name
+}
+
+// walkObject walks a json object which got selected by the selection sets setRefs on a value of type typeName
+func (w *Walker) walkObject(setRefs []int, typeName document.ByteSliceReference, object gjson.Result, offset int, path string) error {
+
+	concreteTypeName, concrete := w.concreteTypeName(typeName, object)
+
+	selections := make([]selection, 0, 8)
+	for _, setRef := range setRefs {
+		w.collectSelections(setRef, typeName, concreteTypeName, concrete, &selections)
+	}
+
+	for i := range selections {
+
+		responseKey := string(w.l.ByteSlice(selections[i].responseKey))
+		key, value, ok := w.member(object, responseKey)
+		if !ok {
+			continue
+		}
+
+		fieldRef := selections[i].fieldRefs[0]
+		field := Field{
+			Ref:       fieldRef,
+			TypeName:  selections[i].typeName,
+			Path:      path + "." + responseKey,
+			Key:       []byte(key.Raw),
+			KeyOffset: offset + key.Index,
+			Value:     []byte(value.Raw),
+			Offset:    offset + value.Index,
+		}
+
+		name := w.l.Field(fieldRef).Name
+		if !bytes.Equal(w.l.ByteSlice(name), typeNameFieldName) {
+			field.Definition, field.HasDefinition = w.fieldDefinition(selections[i].typeName, name)
+		}
+
+		err := w.visit(field)
+		if err == SkipChildren {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if !field.HasDefinition {
+			continue
+		}
+
+		childSetRefs := make([]int, 0, len(selections[i].fieldRefs))
+		for _, ref := range selections[i].fieldRefs {
+			if setRef := w.l.Field(ref).SelectionSet; setRef != -1 {
+				childSetRefs = append(childSetRefs, setRef)
+			}
+		}
+
+		if len(childSetRefs) == 0 {
+			continue
+		}
+
+		fieldTypeName := w.l.UnwrappedNamedType(w.l.Type(field.Definition.Type)).Name
+		err = w.walkValue(childSetRefs, fieldTypeName, value, field.Offset, field.Path)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// walkValue walks objects and (nested) lists of objects, null values are skipped
+func (w *Walker) walkValue(setRefs []int, typeName document.ByteSliceReference, value gjson.Result, offset int, path string) error {
+
+	if value.IsObject() {
+		return w.walkObject(setRefs, typeName, value, offset, path)
+	}
+
+	if !value.IsArray() {
+		return nil
+	}
+
+	var err error
+	i := 0
+	value.ForEach(func(_, item gjson.Result) bool {
+		err = w.walkValue(setRefs, typeName, item, offset+item.Index, path+"."+strconv.Itoa(i))
+		i++
+		return err == nil
+	})
+
+	return err
+}
+
+// member returns the key and the value of the object member with the given name
+// the indexes of the results are relative to the object
+func (w *Walker) member(object gjson.Result, name string) (key, value gjson.Result, ok bool) {
+	object.ForEach(func(k, v gjson.Result) bool {
+		if k.Str != name {
+			return true
+		}
+		key, value, ok = k, v, true
+		return false
+	})
+	return
+}
+
+// concreteTypeName resolves abstract types using the __typename of the object
+func (w *Walker) concreteTypeName(typeName document.ByteSliceReference, object gjson.Result) (document.ByteSliceReference, bool) {
+
+	if _, ok := w.l.ObjectTypeDefinitionByName(typeName); ok {
+		return typeName, true
+	}
+
+	name := object.Get(string(typeNameFieldName))
+	if name.Type != gjson.String {
+		return typeName, false
+	}
+
+	definitions := w.l.ObjectTypeDefinitions()
+	for i := range definitions {
+		if string(w.l.ByteSlice(definitions[i].Name)) == name.Str {
+			return definitions[i].Name, true
+		}
+	}
+
+	return typeName, false
+}
+
+// collectSelections collects the fields of the selection set grouped by their response key
+// setTypeName is the type the selections are made on, fragments get applied if they match the concrete type
+// if the concrete type is unknown all fragments are applied, the response decides which keys exist
+func (w *Walker) collectSelections(setRef int, setTypeName, concreteTypeName document.ByteSliceReference, concrete bool, selections *[]selection) {
+
+	contents := w.l.SelectionSetContentsIterator(setRef)
+	for contents.Next() {
+		kind, ref := contents.Value()
+		switch kind {
+		case lookup.FIELD:
+			w.addSelection(ref, setTypeName, concreteTypeName, concrete, selections)
+		case lookup.INLINE_FRAGMENT:
+			inlineFragment := w.l.InlineFragment(ref)
+			fragmentTypeName := setTypeName
+			if inlineFragment.TypeCondition != -1 {
+				fragmentTypeName = w.l.Type(inlineFragment.TypeCondition).Name
+			}
+			if !w.fragmentApplies(fragmentTypeName, concreteTypeName, concrete) {
+				continue
+			}
+			w.collectSelections(inlineFragment.SelectionSet, fragmentTypeName, concreteTypeName, concrete, selections)
+		case lookup.FRAGMENT_SPREAD:
+			spread := w.l.FragmentSpread(ref)
+			if w.l.ByteSliceReferencesContainName(w.fragments, spread.FragmentName) {
+				continue // cyclic fragment spreads are rejected by validation
+			}
+			fragment, _, ok := w.l.FragmentDefinitionByName(spread.FragmentName)
+			if !ok {
+				continue
+			}
+			fragmentTypeName := w.l.Type(fragment.TypeCondition).Name
+			if !w.fragmentApplies(fragmentTypeName, concreteTypeName, concrete) {
+				continue
+			}
+			w.fragments = append(w.fragments, spread.FragmentName)
+			w.collectSelections(fragment.SelectionSet, fragmentTypeName, concreteTypeName, concrete, selections)
+			w.fragments = w.fragments[:len(w.fragments)-1]
+		}
+	}
+}
+
+func (w *Walker) addSelection(fieldRef int, setTypeName, concreteTypeName document.ByteSliceReference, concrete bool, selections *[]selection) {
+
+	field := w.l.Field(fieldRef)
+	responseKey := field.Name
+	if field.Alias.Length() != 0 {
+		responseKey = field.Alias
+	}
+
+	for i := range *selections {
+		if w.l.ByteSliceReferenceContentsEquals((*selections)[i].responseKey, responseKey) {
+			(*selections)[i].fieldRefs = append((*selections)[i].fieldRefs, fieldRef)
+			return
+		}
+	}
+
+	// fields are looked up on the concrete type so that directives on object type fields are visible
+	// even if the field is selected on an interface
+	typeName := setTypeName
+	if concrete {
+		if _, ok := w.fieldDefinition(concreteTypeName, field.Name); ok || bytes.Equal(w.l.ByteSlice(field.Name), typeNameFieldName) {
+			typeName = concreteTypeName
+		}
+	}
+
+	*selections = append(*selections, selection{
+		responseKey: responseKey,
+		fieldRefs:   []int{fieldRef},
+		typeName:    typeName,
+	})
+}
+
+// fragmentApplies returns true if a fragment on fragmentTypeName applies to a value of the concrete type
+func (w *Walker) fragmentApplies(fragmentTypeName, concreteTypeName document.ByteSliceReference, concrete bool) bool {
+
+	if !concrete || w.l.ByteSliceReferenceContentsEquals(fragmentTypeName, concreteTypeName) {
+		return true
+	}
+
+	objectType, ok := w.l.ObjectTypeDefinitionByName(concreteTypeName)
+	if !ok {
+		return false
+	}
+
+	if w.l.ObjectTypeDefinitionImplementsInterface(objectType, fragmentTypeName) {
+		return true
+	}
+
+	unionType, ok := w.l.UnionTypeDefinitionByName(fragmentTypeName)
+	return ok && w.l.UnionTypeDefinitionContainsType(unionType, concreteTypeName)
+}
+
+func (w *Walker) fieldDefinition(typeName, fieldName document.ByteSliceReference) (document.FieldDefinition, bool) {
+	return w.l.FieldDefinitionByNameFromDefinitions(w.l.FieldsDefinitionFromNamedType(typeName), fieldName)
+}
@@ -0,0 +1,317 @@
+package responsewalker
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/jensneuse/graphql-go-tools/pkg/lookup"
+	"github.com/jensneuse/graphql-go-tools/pkg/parser"
+)
+
+func TestWalker(t *testing.T) {
+
+	walker := func(query string) (*lookup.Lookup, *Walker) {
+		p := parser.NewParser()
+		err := p.ParseTypeSystemDefinition([]byte(walkerTestSchema))
+		if err != nil {
+			panic(err)
+		}
+		err = p.ParseExecutableDefinition([]byte(query))
+		if err != nil {
+			panic(err)
+		}
+		l := lookup.New(p)
+		w := New()
+		w.SetInput(l)
+		return l, w
+	}
+
+	// run walks the response and compares the visited fields, one per line:
+	// path type.field value
+	run := func(operationName, query, response string, want ...string) {
+
+		l, w := walker(query)
+
+		var got []string
+		err := w.Walk(operationName, []byte(response), func(field Field) error {
+
+			if !bytes.Equal([]byte(response)[field.Offset:field.Offset+len(field.Value)], field.Value) {
+				panic(fmt.Errorf("invalid offset %d for value %s", field.Offset, string(field.Value)))
+			}
+			if !bytes.Equal([]byte(response)[field.KeyOffset:field.KeyOffset+len(field.Key)], field.Key) {
+				panic(fmt.Errorf("invalid offset %d for key %s", field.KeyOffset, string(field.Key)))
+			}
+
+			definitionName := "__typename"
+			if field.HasDefinition {
+				definitionName = string(l.ByteSlice(field.Definition.Name))
+			}
+
+			got = append(got, fmt.Sprintf("%s %s.%s %s", field.Path, string(l.ByteSlice(field.TypeName)), definitionName, string(field.Value)))
+			return nil
+		})
+		if err != nil {
+			panic(err)
+		}
+
+		if strings.Join(want, "\n") != strings.Join(got, "\n") {
+			panic(fmt.Errorf("want:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n")))
+		}
+	}
+
+	runErr := func(operationName, query, response string) {
+		_, w := walker(query)
+		err := w.Walk(operationName, []byte(response), func(field Field) error {
+			return nil
+		})
+		if err == nil {
+			panic(fmt.Errorf("want err for response: %s", response))
+		}
+	}
+
+	t.Run("fields", func(t *testing.T) {
+		run("", `{assets {id url}}`,
+			`{"data":{"assets":[{"id":"1","url":"a"},{"id":"2","url":"b"}]}}`,
+			`data.assets Query.assets [{"id":"1","url":"a"},{"id":"2","url":"b"}]`,
+			`data.assets.0.id Asset.id "1"`,
+			`data.assets.0.url Asset.url "a"`,
+			`data.assets.1.id Asset.id "2"`,
+			`data.assets.1.url Asset.url "b"`,
+		)
+	})
+	t.Run("whitespace", func(t *testing.T) {
+		run("", `{asset {id}}`,
+			"\n  { \"errors\": [], \"data\" : {\n\t\"asset\" :  {\"id\": \"1\" } } }",
+			`data.asset Query.asset {"id": "1" }`,
+			`data.asset.id Asset.id "1"`,
+		)
+	})
+	t.Run("aliases", func(t *testing.T) {
+		run("", `{first: asset(id: 1) {key: id} second: asset(id: 2) {id}}`,
+			`{"data":{"first":{"key":"1"},"second":{"id":"2"}}}`,
+			`data.first Query.asset {"key":"1"}`,
+			`data.first.key Asset.id "1"`,
+			`data.second Query.asset {"id":"2"}`,
+			`data.second.id Asset.id "2"`,
+		)
+	})
+	t.Run("merged fields", func(t *testing.T) {
+		run("", `{asset {id} asset {url}}`,
+			`{"data":{"asset":{"id":"1","url":"a"}}}`,
+			`data.asset Query.asset {"id":"1","url":"a"}`,
+			`data.asset.id Asset.id "1"`,
+			`data.asset.url Asset.url "a"`,
+		)
+	})
+	t.Run("nested lists and nulls", func(t *testing.T) {
+		run("", `{matrix {id}}`,
+			`{"data":{"matrix":[[{"id":"1"},null],null,[{"id":"2"}]]}}`,
+			`data.matrix Query.matrix [[{"id":"1"},null],null,[{"id":"2"}]]`,
+			`data.matrix.0.0.id Asset.id "1"`,
+			`data.matrix.2.0.id Asset.id "2"`,
+		)
+	})
+	t.Run("missing fields and null data", func(t *testing.T) {
+		run("", `{asset {id url}}`, `{"data":{"asset":{"id":"1"}}}`,
+			`data.asset Query.asset {"id":"1"}`,
+			`data.asset.id Asset.id "1"`,
+		)
+		run("", `{asset {id}}`, `{"data":null,"errors":[{"message":"failed"}]}`)
+		run("", `{asset {id}}`, `{"errors":[{"message":"failed"}]}`)
+	})
+	t.Run("fragments on interfaces resolved by __typename", func(t *testing.T) {
+		run("", `
+			query q {
+				nodes {
+					__typename
+					id
+					...assetFields
+					... on User {
+						name
+					}
+				}
+			}
+			fragment assetFields on Asset {
+				url
+			}`,
+			`{"data":{"nodes":[{"__typename":"Asset","id":"1","url":"a"},{"__typename":"User","id":"2","name":"n"}]}}`,
+			`data.nodes Query.nodes [{"__typename":"Asset","id":"1","url":"a"},{"__typename":"User","id":"2","name":"n"}]`,
+			`data.nodes.0.__typename Asset.__typename "Asset"`,
+			`data.nodes.0.id Asset.id "1"`,
+			`data.nodes.0.url Asset.url "a"`,
+			`data.nodes.1.__typename User.__typename "User"`,
+			`data.nodes.1.id User.id "2"`,
+			`data.nodes.1.name User.name "n"`,
+		)
+	})
+	t.Run("fragments on unions without __typename", func(t *testing.T) {
+		run("", `{search {... on Asset {id url} ... on User {id name}}}`,
+			`{"data":{"search":[{"id":"1","url":"a"},{"id":"2","name":"n"}]}}`,
+			`data.search Query.search [{"id":"1","url":"a"},{"id":"2","name":"n"}]`,
+			`data.search.0.id Asset.id "1"`,
+			`data.search.0.url Asset.url "a"`,
+			`data.search.1.id Asset.id "2"`,
+			`data.search.1.name User.name "n"`,
+		)
+	})
+	t.Run("fragments on unions with __typename", func(t *testing.T) {
+		run("", `{search {__typename ... on Asset {id url} ... on User {id name}}}`,
+			`{"data":{"search":[{"__typename":"User","id":"2","name":"n"}]}}`,
+			`data.search Query.search [{"__typename":"User","id":"2","name":"n"}]`,
+			`data.search.0.__typename User.__typename "User"`,
+			`data.search.0.id User.id "2"`,
+			`data.search.0.name User.name "n"`,
+		)
+	})
+	t.Run("inline fragment without type condition", func(t *testing.T) {
+		run("", `{asset {... {id}}}`, `{"data":{"asset":{"id":"1"}}}`,
+			`data.asset Query.asset {"id":"1"}`,
+			`data.asset.id Asset.id "1"`,
+		)
+	})
+	t.Run("operation name", func(t *testing.T) {
+		run("second", `query first {asset {id}} mutation second {updateAsset {url}}`,
+			`{"data":{"updateAsset":{"url":"a"}}}`,
+			`data.updateAsset Mutation.updateAsset {"url":"a"}`,
+			`data.updateAsset.url Asset.url "a"`,
+		)
+	})
+	t.Run("skip children", func(t *testing.T) {
+		l, w := walker(`{assets {id} asset {id}}`)
+		var got []string
+		err := w.Walk("", []byte(`{"data":{"assets":[{"id":"1"}],"asset":{"id":"2"}}}`), func(field Field) error {
+			got = append(got, field.Path)
+			if string(l.ByteSlice(field.Definition.Name)) == "assets" {
+				return SkipChildren
+			}
+			return nil
+		})
+		if err != nil {
+			panic(err)
+		}
+		want := "data.assets data.asset data.asset.id"
+		if strings.Join(got, " ") != want {
+			panic(fmt.Errorf("want: %s, got: %s", want, strings.Join(got, " ")))
+		}
+	})
+	t.Run("visitor error", func(t *testing.T) {
+		_, w := walker(`{asset {id}}`)
+		err := w.Walk("", []byte(`{"data":{"asset":{"id":"1"}}}`), func(field Field) error {
+			return fmt.Errorf("failing")
+		})
+		if err == nil || err.Error() != "failing" {
+			panic(fmt.Errorf("want err failing, got: %v", err))
+		}
+	})
+	t.Run("invalid input", func(t *testing.T) {
+		runErr("", `{asset {id}}`, `{"data":`)
+		runErr("", `query a {asset {id}} query b {asset {id}}`, `{"data":{}}`)
+		runErr("c", `query a {asset {id}}`, `{"data":{}}`)
+	})
+}
+
+func TestEditor(t *testing.T) {
+
+	newWalker := func(query string) (*lookup.Lookup, *Walker) {
+		p := parser.NewParser()
+		err := p.ParseTypeSystemDefinition([]byte(walkerTestSchema))
+		if err != nil {
+			panic(err)
+		}
+		err = p.ParseExecutableDefinition([]byte(query))
+		if err != nil {
+			panic(err)
+		}
+		l := lookup.New(p)
+		w := New()
+		w.SetInput(l)
+		return l, w
+	}
+
+	t.Run("replace keys and values", func(t *testing.T) {
+		l, w := newWalker(`{assets {id handle}}`)
+		response := []byte(`{"data":{"assets":[{"id":"1","handle":"a"},{"handle":"b","id":"2"}]}}`)
+		editor := NewEditor()
+		err := w.Walk("", response, func(field Field) error {
+			if string(l.ByteSlice(field.Definition.Name)) != "handle" {
+				return nil
+			}
+			editor.Replace(field.KeyOffset, field.Key, []byte(`"url"`))
+			editor.Replace(field.Offset, field.Value, []byte(fmt.Sprintf(`"https://example.com/%s"`, strings.Trim(string(field.Value), `"`))))
+			return nil
+		})
+		if err != nil {
+			panic(err)
+		}
+		got, err := editor.Apply(response)
+		if err != nil {
+			panic(err)
+		}
+		want := `{"data":{"assets":[{"id":"1","url":"https://example.com/a"},{"url":"https://example.com/b","id":"2"}]}}`
+		if want != string(got) {
+			panic(fmt.Errorf("want:\n%s\ngot:\n%s", want, string(got)))
+		}
+	})
+	t.Run("without edits", func(t *testing.T) {
+		got, err := NewEditor().Apply([]byte(`{}`))
+		if err != nil {
+			panic(err)
+		}
+		if string(got) != `{}` {
+			panic(fmt.Errorf("want: {}, got: %s", string(got)))
+		}
+	})
+	t.Run("overlapping edits", func(t *testing.T) {
+		editor := NewEditor()
+		editor.Replace(1, []byte("abc"), []byte("x"))
+		editor.Replace(2, []byte("b"), []byte("y"))
+		_, err := editor.Apply([]byte("_abc_"))
+		if err == nil {
+			panic("want err")
+		}
+		editor.Reset()
+		editor.Replace(4, []byte("abc"), []byte("x"))
+		_, err = editor.Apply([]byte("_abc_"))
+		if err == nil {
+			panic("want err")
+		}
+	})
+}
+
+const walkerTestSchema = `
+schema {
+	query: Query
+	mutation: Mutation
+}
+
+type Query {
+	asset(id: Int): Asset
+	assets: [Asset]
+	matrix: [[Asset]]
+	nodes: [Node]
+	search: [SearchResult]
+}
+
+type Mutation {
+	updateAsset: Asset
+}
+
+interface Node {
+	id: ID!
+}
+
+type Asset implements Node {
+	id: ID!
+	url: String
+	handle: String
+}
+
+type User implements Node {
+	id: ID!
+	name: String
+}
+
+union SearchResult = Asset | User
+`
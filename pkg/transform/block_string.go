@@ -0,0 +1,46 @@
+package transform
+
+import (
+	"bytes"
+	"github.com/jensneuse/graphql-go-tools/pkg/lexing/literal"
+)
+
+// BlockStringValue returns the value of a block string as specified in:
+// http://facebook.github.io/graphql/draft/#BlockStringValue()
+// the common indentation of all lines but the first gets removed as well as leading and trailing blank lines
+func BlockStringValue(raw []byte) []byte {
+
+	raw = bytes.Replace(raw, []byte(`\"""`), []byte(`"""`), -1)
+	lines := bytes.Split(bytes.Replace(raw, []byte("\r\n"), literal.LINETERMINATOR, -1), literal.LINETERMINATOR)
+
+	commonIndent := -1
+	for i := 1; i < len(lines); i++ {
+		indent := len(lines[i]) - len(bytes.TrimLeft(lines[i], " \t"))
+		if indent == len(lines[i]) {
+			continue
+		}
+		if commonIndent == -1 || indent < commonIndent {
+			commonIndent = indent
+		}
+	}
+
+	if commonIndent > 0 {
+		for i := 1; i < len(lines); i++ {
+			if len(lines[i]) < commonIndent {
+				lines[i] = lines[i][:0]
+				continue
+			}
+			lines[i] = lines[i][commonIndent:]
+		}
+	}
+
+	for len(lines) != 0 && len(bytes.TrimLeft(lines[0], " \t")) == 0 {
+		lines = lines[1:]
+	}
+
+	for len(lines) != 0 && len(bytes.TrimLeft(lines[len(lines)-1], " \t")) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+
+	return bytes.Join(lines, literal.LINETERMINATOR)
+}
@@ -0,0 +1,104 @@
+package transform
+
+import (
+	"strconv"
+	"unicode/utf8"
+)
+
+// UnescapeString resolves the escape sequences of a graphql string value literal
+// e.g. 'foo\"bar' will be transformed into 'foo"bar'
+// invalid escape sequences are kept as they are
+func UnescapeString(input []byte) []byte {
+
+	escapeIndex := -1
+	for i := range input {
+		if input[i] == '\\' {
+			escapeIndex = i
+			break
+		}
+	}
+
+	if escapeIndex == -1 {
+		return input
+	}
+
+	out := make([]byte, 0, len(input))
+	out = append(out, input[:escapeIndex]...)
+
+	for i := escapeIndex; i < len(input); i++ {
+
+		if input[i] != '\\' || i == len(input)-1 {
+			out = append(out, input[i])
+			continue
+		}
+
+		i++
+
+		switch input[i] {
+		case '"', '\\', '/':
+			out = append(out, input[i])
+		case 'b':
+			out = append(out, '\b')
+		case 'f':
+			out = append(out, '\f')
+		case 'n':
+			out = append(out, '\n')
+		case 'r':
+			out = append(out, '\r')
+		case 't':
+			out = append(out, '\t')
+		case 'u':
+			if i+4 >= len(input) {
+				out = append(out, '\\', input[i])
+				continue
+			}
+			code, err := strconv.ParseUint(string(input[i+1:i+5]), 16, 32)
+			if err != nil {
+				out = append(out, '\\', input[i])
+				continue
+			}
+			var encoded [utf8.UTFMax]byte
+			n := utf8.EncodeRune(encoded[:], rune(code))
+			out = append(out, encoded[:n]...)
+			i += 4
+		default:
+			out = append(out, '\\', input[i])
+		}
+	}
+
+	return out
+}
+
+// EscapeString escapes a string so that it can be used as the content of a graphql string value literal
+// e.g. 'foo"bar' will be transformed into 'foo\"bar'
+func EscapeString(input []byte) []byte {
+
+	out := make([]byte, 0, len(input))
+
+	for _, b := range input {
+		switch b {
+		case '"', '\\':
+			out = append(out, '\\', b)
+		case '\b':
+			out = append(out, '\\', 'b')
+		case '\f':
+			out = append(out, '\\', 'f')
+		case '\n':
+			out = append(out, '\\', 'n')
+		case '\r':
+			out = append(out, '\\', 'r')
+		case '\t':
+			out = append(out, '\\', 't')
+		default:
+			if b < 0x20 {
+				out = append(out, '\\', 'u', '0', '0', hex[b>>4], hex[b&0xF])
+				continue
+			}
+			out = append(out, b)
+		}
+	}
+
+	return out
+}
+
+const hex = "0123456789abcdef"
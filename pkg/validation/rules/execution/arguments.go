@@ -1,6 +1,8 @@
 package execution
 
 import (
+	"bytes"
+
 	"github.com/jensneuse/graphql-go-tools/pkg/document"
 	"github.com/jensneuse/graphql-go-tools/pkg/lookup"
 	"github.com/jensneuse/graphql-go-tools/pkg/validation"
@@ -69,6 +71,8 @@ func ArgumentUniqueness() rules.Rule {
 	}
 }
 
+var typeNameFieldName = []byte("__typename")
+
 // RequiredArguments checks if required arguments are defined
 func RequiredArguments() rules.Rule {
 	return func(l *lookup.Lookup, w *lookup.Walker) validation.Result {
@@ -88,6 +92,10 @@ func RequiredArguments() rules.Rule {
 		for fields.Next() {
 
 			field, _, parent := fields.Value()
+			if bytes.Equal(l.ByteSlice(field.Name), typeNameFieldName) {
+				continue // __typename is implicitly defined on all types and has no arguments
+			}
+
 			typeName := w.SelectionSetTypeName(l.SelectionSet(field.SelectionSet), parent)
 
 			fieldsDefinition := l.FieldsDefinitionFromNamedType(typeName)
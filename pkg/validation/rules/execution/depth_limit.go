@@ -0,0 +1,105 @@
+package execution
+
+import (
+	"bytes"
+	"github.com/jensneuse/graphql-go-tools/pkg/document"
+	"github.com/jensneuse/graphql-go-tools/pkg/lookup"
+	"github.com/jensneuse/graphql-go-tools/pkg/validation"
+	"github.com/jensneuse/graphql-go-tools/pkg/validation/rules"
+)
+
+var introspectionFieldPrefix = []byte("__")
+
+// DepthLimit is an optional rule which is not part of the graphql spec
+// it rejects operations where the nesting of fields is deeper than maxDepth, e.g. '{ dog { owner { name } } }' has a depth of 3
+// fragment spreads and inline fragments get resolved, they don't add to the depth themselves
+// if ignoreIntrospection is true introspection fields (__schema, __type, __typename) and their selections don't count
+// the result contains the path to the first field exceeding the limit
+func DepthLimit(maxDepth int, ignoreIntrospection bool) rules.Rule {
+	return func(l *lookup.Lookup, w *lookup.Walker) validation.Result {
+
+		limit := depthLimit{
+			l:                   l,
+			maxDepth:            maxDepth,
+			ignoreIntrospection: ignoreIntrospection,
+		}
+
+		operations := w.OperationDefinitionIterable()
+		for operations.Next() {
+			operation := operations.Value()
+			limit.path = limit.path[:0]
+			limit.fragments = limit.fragments[:0]
+			if fieldRef, exceeded := limit.selectionSetExceedsLimit(operation.SelectionSet, 0); exceeded {
+				field := l.Field(fieldRef)
+				result := validation.Invalid(validation.DepthLimit, validation.MaxDepthExceeded, field.Position, field.Name)
+				result.Meta.SubjectPath = append([]document.ByteSliceReference(nil), limit.path...)
+				return result
+			}
+		}
+
+		return validation.Valid()
+	}
+}
+
+type depthLimit struct {
+	l                   *lookup.Lookup
+	maxDepth            int
+	ignoreIntrospection bool
+	path                []document.ByteSliceReference
+	fragments           []document.ByteSliceReference
+}
+
+// selectionSetExceedsLimit walks the selection set depth first
+// on success path contains the path up to (and including) the field exceeding the limit
+func (d *depthLimit) selectionSetExceedsLimit(ref int, depth int) (fieldRef int, exceeded bool) {
+
+	set := d.l.SelectionSet(ref)
+
+	for _, ref := range set.Fields {
+		field := d.l.Field(ref)
+		if d.ignoreIntrospection && bytes.HasPrefix(d.l.ByteSlice(field.Name), introspectionFieldPrefix) {
+			continue
+		}
+
+		if field.Alias.Length() != 0 {
+			d.path = append(d.path, field.Alias)
+		} else {
+			d.path = append(d.path, field.Name)
+		}
+
+		if depth+1 > d.maxDepth {
+			return ref, true
+		}
+
+		if fieldRef, exceeded = d.selectionSetExceedsLimit(field.SelectionSet, depth+1); exceeded {
+			return
+		}
+
+		d.path = d.path[:len(d.path)-1]
+	}
+
+	for _, ref := range set.InlineFragments {
+		if fieldRef, exceeded = d.selectionSetExceedsLimit(d.l.InlineFragment(ref).SelectionSet, depth); exceeded {
+			return
+		}
+	}
+
+	for _, ref := range set.FragmentSpreads {
+		spread := d.l.FragmentSpread(ref)
+		if d.l.ByteSliceReferencesContainName(d.fragments, spread.FragmentName) {
+			continue // cyclic fragment spreads are reported by the Fragments rule
+		}
+		fragment, _, ok := d.l.FragmentDefinitionByName(spread.FragmentName)
+		if !ok {
+			continue
+		}
+
+		d.fragments = append(d.fragments, spread.FragmentName)
+		if fieldRef, exceeded = d.selectionSetExceedsLimit(fragment.SelectionSet, depth); exceeded {
+			return
+		}
+		d.fragments = d.fragments[:len(d.fragments)-1]
+	}
+
+	return -1, false
+}
@@ -1,10 +1,12 @@
 package execution
 
 import (
+	"bytes"
 	"fmt"
 	"github.com/jensneuse/graphql-go-tools/pkg/lookup"
 	"github.com/jensneuse/graphql-go-tools/pkg/parser"
 	"github.com/jensneuse/graphql-go-tools/pkg/validation/rules"
+	"strings"
 	"testing"
 )
 
@@ -1473,6 +1475,15 @@ func TestExecutionValidation(t *testing.T) {
 								}`,
 						RequiredArguments(), true)
 				})
+				t.Run("125 variant __typename", func(t *testing.T) {
+					run(`	{
+									__typename
+									arguments {
+										__typename
+									}
+								}`,
+						RequiredArguments(), true)
+				})
 			})
 		})
 	})
@@ -2523,6 +2534,191 @@ func TestExecutionValidation(t *testing.T) {
 			})
 		})
 	})
+	t.Run("optional rules", func(t *testing.T) {
+		t.Run("named mutations", func(t *testing.T) {
+			t.Run("named mutation", func(t *testing.T) {
+				run(`	mutation dogOperation {
+								mutateDog {
+									id
+								}
+							}`,
+					NamedMutations(), true)
+			})
+			t.Run("anonymous query", func(t *testing.T) {
+				run(`	{
+								dog {
+									name
+								}
+							}`,
+					NamedMutations(), true)
+			})
+			t.Run("anonymous mutation", func(t *testing.T) {
+				run(`	mutation {
+								mutateDog {
+									id
+								}
+							}`,
+					NamedMutations(), false)
+			})
+		})
+		t.Run("no introspection", func(t *testing.T) {
+			t.Run("typename is allowed", func(t *testing.T) {
+				run(`	query dogOperation {
+								dog {
+									__typename
+									name
+								}
+							}`,
+					NoIntrospection(), true)
+			})
+			t.Run("schema", func(t *testing.T) {
+				run(`	query introspection {
+								__schema {
+									queryType {
+										name
+									}
+								}
+							}`,
+					NoIntrospection(), false)
+			})
+			t.Run("type inside fragment", func(t *testing.T) {
+				run(`	query introspection {
+								...typeFragment
+							}
+							fragment typeFragment on Query {
+								__type(name: "Dog") {
+									name
+								}
+							}`,
+					NoIntrospection(), false)
+			})
+		})
+	})
+}
+
+func TestAllVariableUsesDefined_ManyArgumentsInSchema(t *testing.T) {
+
+	// argument refs of the executable definition come after the arguments used in the schema
+	fields := bytes.Buffer{}
+	for i := 0; i < 50; i++ {
+		fmt.Fprintf(&fields, "\tfield%d: String @deprecated(reason: \"unused\")\n", i)
+	}
+	schema := append(append([]byte{}, testDefinition...), []byte("\ntype ManyArguments {\n"+fields.String()+"}\n")...)
+
+	run := func(input string, valid bool) {
+		p := parser.NewParser()
+		err := p.ParseTypeSystemDefinition(schema)
+		if err != nil {
+			panic(err)
+		}
+
+		l := lookup.New(p)
+
+		err = p.ParseExecutableDefinition([]byte(input))
+		if err != nil {
+			panic(err)
+		}
+
+		walker := lookup.NewWalker(1024, 8)
+		walker.SetLookup(l)
+		walker.WalkExecutable()
+
+		result := AllVariableUsesDefined()(l, walker)
+		if valid != result.Valid {
+			panic(fmt.Errorf("want valid: %t, got: %t (result: %+v)", valid, result.Valid, result))
+		}
+	}
+
+	run(`query variableIsDefined($atOtherHomes: Boolean) {
+				dog {
+					isHousetrained(atOtherHomes: $atOtherHomes)
+				}
+			}`, true)
+	run(`query variableIsNotDefined {
+				dog {
+					isHousetrained(atOtherHomes: $atOtherHomes)
+				}
+			}`, false)
+}
+
+func TestDepthLimit(t *testing.T) {
+
+	run := func(input string, maxDepth int, ignoreIntrospection bool, wantPath string) {
+		p := parser.NewParser()
+		err := p.ParseTypeSystemDefinition(testDefinition)
+		if err != nil {
+			panic(err)
+		}
+
+		l := lookup.New(p)
+
+		err = p.ParseExecutableDefinition([]byte(input))
+		if err != nil {
+			panic(err)
+		}
+
+		walker := lookup.NewWalker(1024, 8)
+		walker.SetLookup(l)
+		walker.WalkExecutable()
+
+		result := DepthLimit(maxDepth, ignoreIntrospection)(l, walker)
+
+		if wantPath == "" {
+			if !result.Valid {
+				panic(fmt.Errorf("want valid, got: %+v", result))
+			}
+			return
+		}
+
+		if result.Valid {
+			panic(fmt.Errorf("want invalid result with path: %s", wantPath))
+		}
+
+		path := make([]string, len(result.Meta.SubjectPath))
+		for i := range result.Meta.SubjectPath {
+			path[i] = string(l.ByteSlice(result.Meta.SubjectPath[i]))
+		}
+
+		if gotPath := strings.Join(path, "."); wantPath != gotPath {
+			panic(fmt.Errorf("want path: %s, got: %s", wantPath, gotPath))
+		}
+	}
+
+	t.Run("within limit", func(t *testing.T) {
+		run(`query q { dog { owner { name } } }`, 3, false, "")
+	})
+	t.Run("exceeds limit", func(t *testing.T) {
+		run(`query q { dog { name owner { name } } }`, 2, false, "dog.owner.name")
+	})
+	t.Run("alias", func(t *testing.T) {
+		run(`query q { dog { master: owner { name } } }`, 2, false, "dog.master.name")
+	})
+	t.Run("inline fragments", func(t *testing.T) {
+		run(`query q { catOrDog { ... on Dog { owner { name } } } }`, 2, false, "catOrDog.owner.name")
+	})
+	t.Run("fragment spreads", func(t *testing.T) {
+		run(`query q { dog { ...dogFields } } fragment dogFields on Dog { extra { ...extraFields } } fragment extraFields on DogExtra { string }`,
+			2, false, "dog.extra.string")
+	})
+	t.Run("fragment spreads within limit", func(t *testing.T) {
+		run(`query q { dog { ...dogFields } } fragment dogFields on Dog { extra { ...extraFields } } fragment extraFields on DogExtra { string }`,
+			3, false, "")
+	})
+	t.Run("multiple operations", func(t *testing.T) {
+		run(`query first { dog { name } } query second { dog { owner { name } } }`, 2, false, "dog.owner.name")
+	})
+	t.Run("cyclic fragments", func(t *testing.T) {
+		run(`query q { dog { ...first } } fragment first on Dog { ...second } fragment second on Dog { ...first name }`, 2, false, "")
+	})
+	t.Run("introspection counts", func(t *testing.T) {
+		run(`query q { __schema { types { fields { name } } } }`, 3, false, "__schema.types.fields.name")
+	})
+	t.Run("ignore introspection", func(t *testing.T) {
+		run(`query q { __schema { types { fields { name } } } dog { __typename } }`, 1, true, "")
+	})
+	t.Run("ignore introspection still limits other fields", func(t *testing.T) {
+		run(`query q { __schema { types { fields { name } } } dog { owner { name } } }`, 2, true, "dog.owner.name")
+	})
 }
 
 func BenchmarkExecutionValidation(t *testing.B) {
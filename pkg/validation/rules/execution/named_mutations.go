@@ -0,0 +1,26 @@
+package execution
+
+import (
+	"github.com/jensneuse/graphql-go-tools/pkg/document"
+	"github.com/jensneuse/graphql-go-tools/pkg/lookup"
+	"github.com/jensneuse/graphql-go-tools/pkg/validation"
+	"github.com/jensneuse/graphql-go-tools/pkg/validation/rules"
+)
+
+// NamedMutations is an optional rule which is not part of the graphql spec
+// it requires every mutation to have an operation name so that mutations can be identified, e.g. in logs
+func NamedMutations() rules.Rule {
+	return func(l *lookup.Lookup, w *lookup.Walker) validation.Result {
+
+		for _, operation := range l.OperationDefinitions() {
+			if operation.OperationType != document.OperationTypeMutation {
+				continue
+			}
+			if operation.Name.Length() == 0 {
+				return validation.Invalid(validation.NamedMutations, validation.MutationMustBeNamed, operation.Position, operation.Name)
+			}
+		}
+
+		return validation.Valid()
+	}
+}
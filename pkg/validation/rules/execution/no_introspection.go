@@ -0,0 +1,32 @@
+package execution
+
+import (
+	"bytes"
+	"github.com/jensneuse/graphql-go-tools/pkg/lookup"
+	"github.com/jensneuse/graphql-go-tools/pkg/validation"
+	"github.com/jensneuse/graphql-go-tools/pkg/validation/rules"
+)
+
+var (
+	introspectionSchemaField = []byte("__schema")
+	introspectionTypeField   = []byte("__type")
+)
+
+// NoIntrospection is an optional rule which is not part of the graphql spec
+// it rejects all operations selecting the introspection fields __schema or __type, e.g. to hide the schema in production
+// __typename is still allowed as clients rely on it
+func NoIntrospection() rules.Rule {
+	return func(l *lookup.Lookup, w *lookup.Walker) validation.Result {
+
+		fields := w.FieldsIterable()
+		for fields.Next() {
+			field, _, _ := fields.Value()
+			name := l.ByteSlice(field.Name)
+			if bytes.Equal(name, introspectionSchemaField) || bytes.Equal(name, introspectionTypeField) {
+				return validation.Invalid(validation.NoIntrospection, validation.IntrospectionNotAllowed, field.Position, field.Name)
+			}
+		}
+
+		return validation.Valid()
+	}
+}
@@ -80,15 +80,15 @@ func AllVariableUsesDefined() rules.Rule {
 
 		iter := w.ArgumentSetIterable()
 		for iter.Next() {
-			set, _ := iter.Value()
+			set, parent := iter.Value()
 			arguments := l.ArgumentsIterable(set)
 
 			for arguments.Next() {
-				argument, ref := arguments.Value()
+				argument, _ := arguments.Value()
 				value := l.Value(argument.Value)
 				if isVariable(value) {
 
-					operationDefinitions := w.NodeUsageInOperationsIterator(ref)
+					operationDefinitions := w.NodeUsageInOperationsIterator(parent)
 					for operationDefinitions.Next() {
 						operationDefinition := l.OperationDefinition(operationDefinitions.Value())
 						_, isDefined := l.VariableDefinition(value.Raw, operationDefinition.VariableDefinitions)
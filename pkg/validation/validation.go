@@ -34,6 +34,8 @@ type Result struct {
 type Meta struct {
 	SubjectPosition position.Position
 	SubjectNameRef  document.ByteSliceReference
+	// SubjectPath (optional) is the path of field names (or aliases) from the operation root to the subject
+	SubjectPath []document.ByteSliceReference
 }
 
 /*
@@ -61,6 +63,11 @@ VariableUniqueness
 VariablesAreInputTypes
 AllVariablesUsed
 AllVariableUsesDefined
+NamedMutations
+NoIntrospection
+DepthLimit
+MaxCost
+NoDeprecatedUsage
 )
 */
 type RuleName int
@@ -94,6 +101,14 @@ VariableMustBeUniquePerOperation
 VariableMustBeValidInputType
 VariableNotDefined
 VariableDefinedButNotUsed
+MutationMustBeNamed
+IntrospectionNotAllowed
+MaxDepthExceeded
+MaxCostExceeded
+DeprecatedFieldUsed
+DeprecatedArgumentUsed
+DeprecatedInputFieldUsed
+DeprecatedEnumValueUsed
 )
 */
 type Description int
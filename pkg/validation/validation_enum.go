@@ -62,9 +62,25 @@ const (
 	VariableNotDefined
 	// VariableDefinedButNotUsed is a Description of type VariableDefinedButNotUsed
 	VariableDefinedButNotUsed
+	// MutationMustBeNamed is a Description of type MutationMustBeNamed
+	MutationMustBeNamed
+	// IntrospectionNotAllowed is a Description of type IntrospectionNotAllowed
+	IntrospectionNotAllowed
+	// MaxDepthExceeded is a Description of type MaxDepthExceeded
+	MaxDepthExceeded
+	// MaxCostExceeded is a Description of type MaxCostExceeded
+	MaxCostExceeded
+	// DeprecatedFieldUsed is a Description of type DeprecatedFieldUsed
+	DeprecatedFieldUsed
+	// DeprecatedArgumentUsed is a Description of type DeprecatedArgumentUsed
+	DeprecatedArgumentUsed
+	// DeprecatedInputFieldUsed is a Description of type DeprecatedInputFieldUsed
+	DeprecatedInputFieldUsed
+	// DeprecatedEnumValueUsed is a Description of type DeprecatedEnumValueUsed
+	DeprecatedEnumValueUsed
 )
 
-const _DescriptionName = "NoDescriptionAnonymousOperationMustBeLonePerDocumentArgumentMustBeUniqueArgumentRequiredArgumentValueTypeMismatchDirectiveNotDefinedDirectiveLocationInvalidDirectiveMustBeUniquePerLocationFieldNameOrAliasMismatchFieldSelectionsInvalidFragmentNotDefinedFragmentSpreadCyclicReferenceFragmentDefinitionOnLeafNodeFragmentRedeclaredFragmentDeclaredButNeverUsedInputValueNotDefinedOperationNameMustBeUniqueRootTypeNotDefinedSelectionSetInvalidSelectionSetResponseShapesCannotMergeSubscriptionsMustHaveMaxOneRootFieldTypeNotDefinedValueInvalidVariableMustBeUniquePerOperationVariableMustBeValidInputTypeVariableNotDefinedVariableDefinedButNotUsed"
+const _DescriptionName = "NoDescriptionAnonymousOperationMustBeLonePerDocumentArgumentMustBeUniqueArgumentRequiredArgumentValueTypeMismatchDirectiveNotDefinedDirectiveLocationInvalidDirectiveMustBeUniquePerLocationFieldNameOrAliasMismatchFieldSelectionsInvalidFragmentNotDefinedFragmentSpreadCyclicReferenceFragmentDefinitionOnLeafNodeFragmentRedeclaredFragmentDeclaredButNeverUsedInputValueNotDefinedOperationNameMustBeUniqueRootTypeNotDefinedSelectionSetInvalidSelectionSetResponseShapesCannotMergeSubscriptionsMustHaveMaxOneRootFieldTypeNotDefinedValueInvalidVariableMustBeUniquePerOperationVariableMustBeValidInputTypeVariableNotDefinedVariableDefinedButNotUsedMutationMustBeNamedIntrospectionNotAllowedMaxDepthExceededMaxCostExceededDeprecatedFieldUsedDeprecatedArgumentUsedDeprecatedInputFieldUsedDeprecatedEnumValueUsed"
 
 var _DescriptionMap = map[Description]string{
 	0:  _DescriptionName[0:13],
@@ -94,6 +110,14 @@ var _DescriptionMap = map[Description]string{
 	24: _DescriptionName[568:596],
 	25: _DescriptionName[596:614],
 	26: _DescriptionName[614:639],
+	27: _DescriptionName[639:658],
+	28: _DescriptionName[658:681],
+	29: _DescriptionName[681:697],
+	30: _DescriptionName[697:712],
+	31: _DescriptionName[712:731],
+	32: _DescriptionName[731:753],
+	33: _DescriptionName[753:777],
+	34: _DescriptionName[777:800],
 }
 
 // String implements the Stringer interface.
@@ -132,6 +156,14 @@ var _DescriptionValue = map[string]Description{
 	_DescriptionName[568:596]: 24,
 	_DescriptionName[596:614]: 25,
 	_DescriptionName[614:639]: 26,
+	_DescriptionName[639:658]: 27,
+	_DescriptionName[658:681]: 28,
+	_DescriptionName[681:697]: 29,
+	_DescriptionName[697:712]: 30,
+	_DescriptionName[712:731]: 31,
+	_DescriptionName[731:753]: 32,
+	_DescriptionName[753:777]: 33,
+	_DescriptionName[777:800]: 34,
 }
 
 // ParseDescription attempts to convert a string to a Description
@@ -189,9 +221,19 @@ const (
 	AllVariablesUsed
 	// AllVariableUsesDefined is a RuleName of type AllVariableUsesDefined
 	AllVariableUsesDefined
+	// NamedMutations is a RuleName of type NamedMutations
+	NamedMutations
+	// NoIntrospection is a RuleName of type NoIntrospection
+	NoIntrospection
+	// DepthLimit is a RuleName of type DepthLimit
+	DepthLimit
+	// MaxCost is a RuleName of type MaxCost
+	MaxCost
+	// NoDeprecatedUsage is a RuleName of type NoDeprecatedUsage
+	NoDeprecatedUsage
 )
 
-const _RuleNameName = "NoRuleArgumentUniquenessDirectivesAreDefinedDirectivesAreInValidLocationsDirectivesAreUniquePerLocationDirectivesHaveRequiredArgumentsDirectivesArgumentsAreDefinedDirectiveArgumentsAreConstantsDirectiveDefinitionArgumentsAreConstantsDirectiveDefinitionDefaultValuesAreOfCorrectTypeFieldSelectionMergingFieldSelectionsFragmentsLoneAnonymousOperationOperationNameUniquenessRequiredArgumentsSubscriptionSingleRootFieldValidArgumentsValuesVariableUniquenessVariablesAreInputTypesAllVariablesUsedAllVariableUsesDefined"
+const _RuleNameName = "NoRuleArgumentUniquenessDirectivesAreDefinedDirectivesAreInValidLocationsDirectivesAreUniquePerLocationDirectivesHaveRequiredArgumentsDirectivesArgumentsAreDefinedDirectiveArgumentsAreConstantsDirectiveDefinitionArgumentsAreConstantsDirectiveDefinitionDefaultValuesAreOfCorrectTypeFieldSelectionMergingFieldSelectionsFragmentsLoneAnonymousOperationOperationNameUniquenessRequiredArgumentsSubscriptionSingleRootFieldValidArgumentsValuesVariableUniquenessVariablesAreInputTypesAllVariablesUsedAllVariableUsesDefinedNamedMutationsNoIntrospectionDepthLimitMaxCostNoDeprecatedUsage"
 
 var _RuleNameMap = map[RuleName]string{
 	0:  _RuleNameName[0:6],
@@ -217,6 +259,11 @@ var _RuleNameMap = map[RuleName]string{
 	20: _RuleNameName[453:475],
 	21: _RuleNameName[475:491],
 	22: _RuleNameName[491:513],
+	23: _RuleNameName[513:527],
+	24: _RuleNameName[527:542],
+	25: _RuleNameName[542:552],
+	26: _RuleNameName[552:559],
+	27: _RuleNameName[559:576],
 }
 
 // String implements the Stringer interface.
@@ -251,6 +298,11 @@ var _RuleNameValue = map[string]RuleName{
 	_RuleNameName[453:475]: 20,
 	_RuleNameName[475:491]: 21,
 	_RuleNameName[491:513]: 22,
+	_RuleNameName[513:527]: 23,
+	_RuleNameName[527:542]: 24,
+	_RuleNameName[542:552]: 25,
+	_RuleNameName[552:559]: 26,
+	_RuleNameName[559:576]: 27,
 }
 
 // ParseRuleName attempts to convert a string to a RuleName
@@ -0,0 +1,177 @@
+package validator
+
+import (
+	"fmt"
+	"github.com/jensneuse/graphql-go-tools/pkg/deprecation"
+	"github.com/jensneuse/graphql-go-tools/pkg/validation"
+	"github.com/jensneuse/graphql-go-tools/pkg/validation/rules"
+	"github.com/jensneuse/graphql-go-tools/pkg/validation/rules/execution"
+)
+
+// Severity defines how a violation of a rule affects the validation outcome
+type Severity int
+
+const (
+	// SeverityError makes the validation fail
+	SeverityError Severity = iota
+	// SeverityWarning reports the violation but keeps the request valid
+	SeverityWarning
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	default:
+		return fmt.Sprintf("Severity(%d)", int(s))
+	}
+}
+
+// RegisteredRule is a named rule inside a Registry
+type RegisteredRule struct {
+	Name     string
+	Rule     rules.Rule
+	Enabled  bool
+	Severity Severity
+}
+
+// Registry is an ordered set of named execution rules
+// rules can be enabled, disabled and configured individually
+// rules get evaluated in the order of registration
+type Registry struct {
+	rules []RegisteredRule
+}
+
+// NewRegistry returns an empty Registry
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// DefaultRegistry returns a Registry containing all DefaultExecutionRules enabled with SeverityError
+// optional rules which are not part of the graphql spec are registered but disabled
+func DefaultRegistry() *Registry {
+	r := NewRegistry()
+
+	r.mustRegister(validation.ArgumentUniqueness, execution.ArgumentUniqueness(), true)
+	r.mustRegister(validation.RequiredArguments, execution.RequiredArguments(), true)
+	r.mustRegister(validation.ValidArguments, execution.ValidArguments(), true)
+	r.mustRegister(validation.DirectivesAreUniquePerLocation, execution.DirectivesAreUniquePerLocation(), true)
+	r.mustRegister(validation.DirectivesAreInValidLocations, execution.DirectivesAreInValidLocations(), true)
+	r.mustRegister(validation.DirectivesAreDefined, execution.DirectivesAreDefined(), true)
+	r.mustRegister(validation.FieldSelections, execution.FieldSelections(), true)
+	r.mustRegister(validation.FieldSelectionMerging, execution.FieldSelectionMerging(), true)
+	r.mustRegister(validation.Fragments, execution.Fragments(), true)
+	r.mustRegister(validation.LoneAnonymousOperation, execution.LoneAnonymousOperation(), true)
+	r.mustRegister(validation.OperationNameUniqueness, execution.OperationNameUniqueness(), true)
+	r.mustRegister(validation.SubscriptionSingleRootField, execution.SubscriptionSingleRootField(), true)
+	r.mustRegister(validation.Values, execution.Values(), true)
+	r.mustRegister(validation.VariablesAreInputTypes, execution.VariablesAreInputTypes(), true)
+	r.mustRegister(validation.VariableUniqueness, execution.VariableUniqueness(), true)
+	r.mustRegister(validation.AllVariableUsesDefined, execution.AllVariableUsesDefined(), true)
+	r.mustRegister(validation.AllVariablesUsed, execution.AllVariablesUsed(), true)
+
+	r.mustRegister(validation.NamedMutations, execution.NamedMutations(), false)
+	r.mustRegister(validation.NoIntrospection, execution.NoIntrospection(), false)
+	r.mustRegister(validation.NoDeprecatedUsage, deprecation.NoDeprecatedUsage(), false)
+
+	return r
+}
+
+func (r *Registry) mustRegister(name validation.RuleName, rule rules.Rule, enabled bool) {
+	err := r.Register(name.String(), rule, SeverityError)
+	if err != nil {
+		panic(err)
+	}
+	if !enabled {
+		r.rules[len(r.rules)-1].Enabled = false
+	}
+}
+
+// Register adds an enabled rule to the registry
+// the name must be unique inside the registry
+func (r *Registry) Register(name string, rule rules.Rule, severity Severity) error {
+	if name == "" {
+		return fmt.Errorf("Register: rule name must not be empty")
+	}
+	if rule == nil {
+		return fmt.Errorf("Register: rule '%s' must not be nil", name)
+	}
+	if _, ok := r.index(name); ok {
+		return fmt.Errorf("Register: rule '%s' already registered", name)
+	}
+
+	r.rules = append(r.rules, RegisteredRule{
+		Name:     name,
+		Rule:     rule,
+		Enabled:  true,
+		Severity: severity,
+	})
+
+	return nil
+}
+
+// Enable enables the rules with the given names
+func (r *Registry) Enable(names ...string) error {
+	return r.setEnabled(true, names)
+}
+
+// Disable disables the rules with the given names
+func (r *Registry) Disable(names ...string) error {
+	return r.setEnabled(false, names)
+}
+
+func (r *Registry) setEnabled(enabled bool, names []string) error {
+	for _, name := range names {
+		i, ok := r.index(name)
+		if !ok {
+			return fmt.Errorf("rule '%s' not registered", name)
+		}
+		r.rules[i].Enabled = enabled
+	}
+	return nil
+}
+
+// SetSeverity sets the severity of the rule with the given name
+func (r *Registry) SetSeverity(name string, severity Severity) error {
+	i, ok := r.index(name)
+	if !ok {
+		return fmt.Errorf("SetSeverity: rule '%s' not registered", name)
+	}
+	r.rules[i].Severity = severity
+	return nil
+}
+
+// Rule returns the registered rule with the given name
+func (r *Registry) Rule(name string) (RegisteredRule, bool) {
+	i, ok := r.index(name)
+	if !ok {
+		return RegisteredRule{}, false
+	}
+	return r.rules[i], true
+}
+
+// Rules returns all registered rules in order of registration
+func (r *Registry) Rules() []RegisteredRule {
+	out := make([]RegisteredRule, len(r.rules))
+	copy(out, r.rules)
+	return out
+}
+
+// Copy returns an independent copy of the registry
+// this is useful to derive a per route configuration from a shared base registry
+func (r *Registry) Copy() *Registry {
+	return &Registry{
+		rules: r.Rules(),
+	}
+}
+
+func (r *Registry) index(name string) (int, bool) {
+	for i := range r.rules {
+		if r.rules[i].Name == name {
+			return i, true
+		}
+	}
+	return -1, false
+}
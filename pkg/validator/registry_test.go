@@ -0,0 +1,175 @@
+package validator
+
+import (
+	"fmt"
+	"github.com/jensneuse/graphql-go-tools/pkg/lookup"
+	"github.com/jensneuse/graphql-go-tools/pkg/parser"
+	"github.com/jensneuse/graphql-go-tools/pkg/validation"
+	"github.com/jensneuse/graphql-go-tools/pkg/validation/rules/execution"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+
+	run := func(executable string, registry *Registry, wantErrors, wantWarnings []string) {
+		p := parser.NewParser()
+		err := p.ParseTypeSystemDefinition(testDefinition)
+		if err != nil {
+			panic(err)
+		}
+
+		err = p.ParseExecutableDefinition([]byte(executable))
+		if err != nil {
+			panic(err)
+		}
+
+		l := lookup.New(p)
+		w := lookup.NewWalker(1024, 8)
+		w.SetLookup(l)
+		w.WalkExecutable()
+		v := New()
+		v.SetInput(l, w)
+
+		report := v.ValidateExecutableDefinitionWithRegistry(registry)
+
+		assertViolations := func(kind string, want []string, got []Violation) {
+			if len(want) != len(got) {
+				panic(fmt.Errorf("want %d %s, got: %+v", len(want), kind, got))
+			}
+			for i := range want {
+				if want[i] != got[i].RuleName {
+					panic(fmt.Errorf("want %s[%d]: %s, got: %s", kind, i, want[i], got[i].RuleName))
+				}
+			}
+		}
+
+		assertViolations("errors", wantErrors, report.Errors)
+		assertViolations("warnings", wantWarnings, report.Warnings)
+
+		if report.Valid() != (len(wantErrors) == 0) {
+			panic(fmt.Errorf("want valid: %t, got: %t", len(wantErrors) == 0, report.Valid()))
+		}
+	}
+
+	unusedVariable := `query dogQuery($unused: Boolean) { dog { name } }`
+
+	t.Run("default registry", func(t *testing.T) {
+		run(string(introspectionQuery), DefaultRegistry(), nil, nil)
+	})
+	t.Run("default registry invalid", func(t *testing.T) {
+		run(unusedVariable, DefaultRegistry(), []string{"AllVariablesUsed"}, nil)
+	})
+	t.Run("disable rule", func(t *testing.T) {
+		registry := DefaultRegistry()
+		err := registry.Disable(validation.AllVariablesUsed.String())
+		if err != nil {
+			panic(err)
+		}
+		run(unusedVariable, registry, nil, nil)
+	})
+	t.Run("enable optional rule", func(t *testing.T) {
+		registry := DefaultRegistry()
+		err := registry.Enable(validation.NoIntrospection.String())
+		if err != nil {
+			panic(err)
+		}
+		run(string(introspectionQuery), registry, []string{"NoIntrospection"}, nil)
+	})
+	t.Run("warning severity", func(t *testing.T) {
+		registry := DefaultRegistry()
+		err := registry.Enable(validation.NoIntrospection.String())
+		if err != nil {
+			panic(err)
+		}
+		err = registry.SetSeverity(validation.NoIntrospection.String(), SeverityWarning)
+		if err != nil {
+			panic(err)
+		}
+		run(string(introspectionQuery), registry, nil, []string{"NoIntrospection"})
+	})
+	t.Run("warnings are collected until the first error", func(t *testing.T) {
+		registry := NewRegistry()
+		mustRegister := func(name string, severity Severity, valid bool) {
+			err := registry.Register(name, func(l *lookup.Lookup, w *lookup.Walker) validation.Result {
+				if valid {
+					return validation.Valid()
+				}
+				return validation.Invalid(validation.NoRule, validation.NoDescription, l.OperationDefinitions()[0].Position, l.OperationDefinitions()[0].Name)
+			}, severity)
+			if err != nil {
+				panic(err)
+			}
+		}
+		mustRegister("firstWarning", SeverityWarning, false)
+		mustRegister("valid", SeverityError, true)
+		mustRegister("secondWarning", SeverityWarning, false)
+		mustRegister("firstError", SeverityError, false)
+		mustRegister("secondError", SeverityError, false)
+		run(`query dogQuery { dog { name } }`, registry, []string{"firstError"}, []string{"firstWarning", "secondWarning"})
+	})
+	t.Run("custom rule", func(t *testing.T) {
+		registry := DefaultRegistry()
+		err := registry.Register("NoDogs", func(l *lookup.Lookup, w *lookup.Walker) validation.Result {
+			fields := w.FieldsIterable()
+			for fields.Next() {
+				field, _, _ := fields.Value()
+				if string(l.ByteSlice(field.Name)) == "dog" {
+					return validation.Invalid(validation.NoRule, validation.NoDescription, field.Position, field.Name)
+				}
+			}
+			return validation.Valid()
+		}, SeverityError)
+		if err != nil {
+			panic(err)
+		}
+		run(`query dogQuery { dog { name } }`, registry, []string{"NoDogs"}, nil)
+		run(`query catQuery { cat { name } }`, registry, nil, nil)
+	})
+	t.Run("named mutations", func(t *testing.T) {
+		registry := NewRegistry()
+		err := registry.Register(validation.NamedMutations.String(), execution.NamedMutations(), SeverityError)
+		if err != nil {
+			panic(err)
+		}
+		run(`mutation namedMutation { dog { name } }`, registry, nil, nil)
+		run(`mutation { dog { name } }`, registry, []string{"NamedMutations"}, nil)
+	})
+	t.Run("copy is independent", func(t *testing.T) {
+		base := DefaultRegistry()
+		derived := base.Copy()
+		err := derived.Enable(validation.NoIntrospection.String())
+		if err != nil {
+			panic(err)
+		}
+		run(string(introspectionQuery), base, nil, nil)
+		run(string(introspectionQuery), derived, []string{"NoIntrospection"}, nil)
+	})
+	t.Run("register errors", func(t *testing.T) {
+		registry := DefaultRegistry()
+		if err := registry.Register(validation.Values.String(), func(l *lookup.Lookup, w *lookup.Walker) validation.Result {
+			return validation.Valid()
+		}, SeverityError); err == nil {
+			t.Fatal("want err for duplicate rule name")
+		}
+		if err := registry.Register("nilRule", nil, SeverityError); err == nil {
+			t.Fatal("want err for nil rule")
+		}
+		if err := registry.Enable("unknown"); err == nil {
+			t.Fatal("want err for unknown rule")
+		}
+		if err := registry.SetSeverity("unknown", SeverityWarning); err == nil {
+			t.Fatal("want err for unknown rule")
+		}
+	})
+	t.Run("rules", func(t *testing.T) {
+		registry := DefaultRegistry()
+		rules := registry.Rules()
+		if len(rules) != len(DefaultExecutionRules)+3 {
+			t.Fatalf("want %d rules, got: %d", len(DefaultExecutionRules)+3, len(rules))
+		}
+		rule, ok := registry.Rule(validation.NoIntrospection.String())
+		if !ok || rule.Enabled || rule.Severity != SeverityError {
+			t.Fatalf("want disabled NoIntrospection rule with severity error, got: %+v (exists: %t)", rule, ok)
+		}
+	})
+}
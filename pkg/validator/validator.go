@@ -54,3 +54,53 @@ func (v *Validator) ValidateExecutableDefinition(executionRules []rules.Rule) (r
 
 	return validation.Valid()
 }
+
+// Violation is a failed rule of a Registry
+type Violation struct {
+	RuleName string
+	Severity Severity
+	Result   validation.Result
+}
+
+// Report is the outcome of validating an executable definition against a Registry
+type Report struct {
+	Errors   []Violation
+	Warnings []Violation
+}
+
+// Valid returns true if no rule with SeverityError failed
+func (r Report) Valid() bool {
+	return len(r.Errors) == 0
+}
+
+// ValidateExecutableDefinitionWithRegistry validates the executable definition against all enabled rules of the registry
+// violations of rules with SeverityWarning get collected, the validation stops at the first violation of a rule with SeverityError
+func (v *Validator) ValidateExecutableDefinitionWithRegistry(registry *Registry) (report Report) {
+
+	for _, rule := range registry.rules {
+		if !rule.Enabled {
+			continue
+		}
+
+		result := rule.Rule(v.l, v.w)
+		if result.Valid {
+			continue
+		}
+
+		violation := Violation{
+			RuleName: rule.Name,
+			Severity: rule.Severity,
+			Result:   result,
+		}
+
+		if rule.Severity == SeverityWarning {
+			report.Warnings = append(report.Warnings, violation)
+			continue
+		}
+
+		report.Errors = append(report.Errors, violation)
+		return
+	}
+
+	return
+}